@@ -7,6 +7,8 @@ import (
 	"slices"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 func capitalizeFirstLetter(s string) string {
@@ -39,7 +41,7 @@ type Flag interface {
 
 	GetEntities() []string
 
-	MarkDisclosed()
+	MarkDisclosed(evidence DisclosureEvidence)
 
 	GetDetailFields() []KeyValue
 
@@ -51,6 +53,14 @@ type Flag interface {
 	GetDetailsFieldsForReport(useDisclosure bool) []KeyValueURL
 
 	IsDisclosed() bool
+
+	// The hash and triage are not part of the stored flag data, they are set when
+	// the report is loaded so that the client can triage individual flags.
+	SetFlagHash(hash string)
+
+	SetTriage(triage *FlagTriage)
+
+	GetTriage() *FlagTriage
 }
 
 const (
@@ -144,24 +154,87 @@ func ParseFlag(ftype string, data []byte) (Flag, error) {
 	}
 }
 
+type DisclosureEvidence struct {
+	DocumentId uuid.UUID
+	Snippet    string
+	Page       int
+	Confidence float64
+}
+
 type DisclosableFlag struct {
 	Disclosed bool
+
+	// Only set if the flag is disclosed, this is the evidence from the disclosure
+	// document with the highest confidence.
+	DisclosureEvidence *DisclosureEvidence `json:",omitempty"`
 }
 
-func (flag *DisclosableFlag) MarkDisclosed() {
+func (flag *DisclosableFlag) MarkDisclosed(evidence DisclosureEvidence) {
 	flag.Disclosed = true
+	if flag.DisclosureEvidence == nil || evidence.Confidence > flag.DisclosureEvidence.Confidence {
+		flag.DisclosureEvidence = &evidence
+	}
 }
 
 func (flag *DisclosableFlag) IsDisclosed() bool {
 	return flag.Disclosed
 }
 
+type TriageableFlag struct {
+	FlagHash string
+	Triage   *FlagTriage `json:",omitempty"`
+}
+
+func (flag *TriageableFlag) SetFlagHash(hash string) {
+	flag.FlagHash = hash
+}
+
+func (flag *TriageableFlag) SetTriage(triage *FlagTriage) {
+	flag.Triage = triage
+}
+
+func (flag *TriageableFlag) GetTriage() *FlagTriage {
+	return flag.Triage
+}
+
 type WorkSummary struct {
 	WorkId          string
 	DisplayName     string
 	WorkUrl         string
 	OaUrl           string
 	PublicationDate time.Time
+
+	// Only set for merged reports, these are the sources the work was found in,
+	// and the author profiles in those sources.
+	Sources    []string
+	Identities []AuthorIdentity
+}
+
+// Lists the profiles the work was found in, flags created before the profiles
+// were recorded only list the sources.
+func (w *WorkSummary) sourcesValue() string {
+	if len(w.Identities) == 0 {
+		return strings.Join(w.Sources, ", ")
+	}
+	profiles := make([]string, 0, len(w.Identities))
+	for _, identity := range w.Identities {
+		profiles = append(profiles, fmt.Sprintf("%s (%s)", identity.Source, identity.AuthorId))
+	}
+	return strings.Join(profiles, ", ")
+}
+
+func (w *WorkSummary) sourceDetailFields() []KeyValue {
+	if len(w.Sources) == 0 {
+		return nil
+	}
+	return []KeyValue{{Key: "Sources", Value: w.sourcesValue()}}
+}
+
+func (w *WorkSummary) sourceFields() []KeyValueURL {
+	if len(w.Sources) == 0 {
+		return nil
+	}
+	return []KeyValueURL{{Key: "Sources", Value: w.sourcesValue()}}
 }
 
 type AcknowledgementEntity struct {
@@ -172,6 +245,7 @@ type AcknowledgementEntity struct {
 
 type TalentContractFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message               string
 	Work                  WorkSummary
 	Entities              []AcknowledgementEntity
@@ -201,13 +275,13 @@ func (flag *TalentContractFlag) GetHeading() string {
 }
 
 func (flag *TalentContractFlag) GetDetailFields() []KeyValue {
-	return []KeyValue{
+	return append([]KeyValue{
 		{Key: "Disclosed", Value: fmt.Sprintf("%v", flag.Disclosed)},
 		{Key: "Title", Value: flag.Work.DisplayName},
 		{Key: "URL", Value: flag.Work.WorkUrl},
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.String()},
 		{Key: "Acknowledgements", Value: strings.Join(flag.RawAcknowledgements, ", ")},
-	}
+	}, flag.Work.sourceDetailFields()...)
 }
 
 func (flag *TalentContractFlag) Date() (time.Time, bool) {
@@ -220,6 +294,7 @@ func (flag *TalentContractFlag) GetDetailsFieldsForReport(useDisclosure bool) []
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.Format(time.DateOnly)},
 		{Key: "Acknowledgements", Value: strings.Join(flag.RawAcknowledgements, ", ")},
 	}
+	fields = append(fields, flag.Work.sourceFields()...)
 	if useDisclosure {
 		fields = append([]KeyValueURL{
 			{Key: "Disclosed", Value: capitalizeFirstLetter(fmt.Sprintf("%v", flag.Disclosed))},
@@ -230,6 +305,7 @@ func (flag *TalentContractFlag) GetDetailsFieldsForReport(useDisclosure bool) []
 
 type AssociationWithDeniedEntityFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message             string
 	Work                WorkSummary
 	Entities            []AcknowledgementEntity
@@ -258,13 +334,13 @@ func (flag *AssociationWithDeniedEntityFlag) GetHeading() string {
 }
 
 func (flag *AssociationWithDeniedEntityFlag) GetDetailFields() []KeyValue {
-	return []KeyValue{
+	return append([]KeyValue{
 		{Key: "Disclosed", Value: fmt.Sprintf("%v", flag.Disclosed)},
 		{Key: "Title", Value: flag.Work.DisplayName},
 		{Key: "URL", Value: flag.Work.WorkUrl},
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.Format(time.DateOnly)},
 		{Key: "Acknowledgements", Value: strings.Join(flag.RawAcknowledgements, ", ")},
-	}
+	}, flag.Work.sourceDetailFields()...)
 }
 
 func (flag *AssociationWithDeniedEntityFlag) Date() (time.Time, bool) {
@@ -277,6 +353,7 @@ func (flag *AssociationWithDeniedEntityFlag) GetDetailsFieldsForReport(useDisclo
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.Format(time.DateOnly)},
 		{Key: "Acknowledgements", Value: strings.Join(flag.RawAcknowledgements, ", ")},
 	}
+	fields = append(fields, flag.Work.sourceFields()...)
 	if useDisclosure {
 		fields = append([]KeyValueURL{
 			{Key: "Disclosed", Value: capitalizeFirstLetter(fmt.Sprintf("%v", flag.Disclosed))},
@@ -287,6 +364,7 @@ func (flag *AssociationWithDeniedEntityFlag) GetDetailsFieldsForReport(useDisclo
 
 type HighRiskFunderFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message               string
 	Work                  WorkSummary
 	Funders               []string
@@ -312,13 +390,13 @@ func (flag *HighRiskFunderFlag) GetHeading() string {
 }
 
 func (flag *HighRiskFunderFlag) GetDetailFields() []KeyValue {
-	return []KeyValue{
+	return append([]KeyValue{
 		{Key: "Disclosed", Value: fmt.Sprintf("%v", flag.Disclosed)},
 		{Key: "Paper Title", Value: flag.Work.DisplayName},
 		{Key: "URL", Value: flag.Work.WorkUrl},
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.String()},
 		{Key: "Funders", Value: strings.Join(flag.Funders, ", ")},
-	}
+	}, flag.Work.sourceDetailFields()...)
 }
 
 func (flag *HighRiskFunderFlag) Date() (time.Time, bool) {
@@ -331,6 +409,7 @@ func (flag *HighRiskFunderFlag) GetDetailsFieldsForReport(useDisclosure bool) []
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.Format(time.DateOnly)},
 		{Key: "Funders", Value: strings.Join(flag.Funders, ", ")},
 	}
+	fields = append(fields, flag.Work.sourceFields()...)
 	if useDisclosure {
 		fields = append([]KeyValueURL{
 			{Key: "Disclosed", Value: capitalizeFirstLetter(fmt.Sprintf("%v", flag.Disclosed))},
@@ -341,6 +420,7 @@ func (flag *HighRiskFunderFlag) GetDetailsFieldsForReport(useDisclosure bool) []
 
 type AuthorAffiliationFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message      string
 	Work         WorkSummary
 	Affiliations []string
@@ -364,13 +444,13 @@ func (flag *AuthorAffiliationFlag) GetHeading() string {
 }
 
 func (flag *AuthorAffiliationFlag) GetDetailFields() []KeyValue {
-	return []KeyValue{
+	return append([]KeyValue{
 		{Key: "Disclosed", Value: fmt.Sprintf("%v", flag.Disclosed)},
 		{Key: "Paper Title", Value: flag.Work.DisplayName},
 		{Key: "URL", Value: flag.Work.WorkUrl},
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.String()},
 		{Key: "Affiliations", Value: strings.Join(flag.Affiliations, ", ")},
-	}
+	}, flag.Work.sourceDetailFields()...)
 }
 
 func (flag *AuthorAffiliationFlag) Date() (time.Time, bool) {
@@ -383,6 +463,7 @@ func (flag *AuthorAffiliationFlag) GetDetailsFieldsForReport(useDisclosure bool)
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.Format(time.DateOnly)},
 		{Key: "Affiliations", Value: strings.Join(flag.Affiliations, ", ")},
 	}
+	fields = append(fields, flag.Work.sourceFields()...)
 	if useDisclosure {
 		fields = append([]KeyValueURL{
 			{Key: "Disclosed", Value: capitalizeFirstLetter(fmt.Sprintf("%v", flag.Disclosed))},
@@ -393,6 +474,7 @@ func (flag *AuthorAffiliationFlag) GetDetailsFieldsForReport(useDisclosure bool)
 
 type PotentialAuthorAffiliationFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message       string
 	University    string
 	UniversityUrl string
@@ -446,6 +528,7 @@ type Connection struct {
 
 type MiscHighRiskAssociationFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message          string
 	DocTitle         string
 	DocUrl           string
@@ -530,6 +613,7 @@ func (flag *MiscHighRiskAssociationFlag) GetDetailsFieldsForReport(useDisclosure
 
 type CoauthorAffiliationFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message      string
 	Work         WorkSummary
 	Coauthors    []string
@@ -554,14 +638,14 @@ func (flag *CoauthorAffiliationFlag) GetHeading() string {
 }
 
 func (flag *CoauthorAffiliationFlag) GetDetailFields() []KeyValue {
-	return []KeyValue{
+	return append([]KeyValue{
 		{Key: "Disclosed", Value: fmt.Sprintf("%v", flag.Disclosed)},
 		{Key: "Paper Title", Value: flag.Work.DisplayName},
 		{Key: "URL", Value: flag.Work.WorkUrl},
 		{Key: "Publication Date", Value: flag.Work.PublicationDate.String()},
 		{Key: "Co-authors", Value: strings.Join(flag.Coauthors, ", ")},
 		{Key: "Affiliations", Value: strings.Join(flag.Affiliations, ", ")},
-	}
+	}, flag.Work.sourceDetailFields()...)
 }
 
 func (flag *CoauthorAffiliationFlag) Date() (time.Time, bool) {
@@ -575,6 +659,7 @@ func (flag *CoauthorAffiliationFlag) GetDetailsFieldsForReport(useDisclosure boo
 		{Key: "Co-authors", Value: strings.Join(flag.Coauthors, ", ")},
 		{Key: "Affiliations", Value: strings.Join(flag.Affiliations, ", ")},
 	}
+	fields = append(fields, flag.Work.sourceFields()...)
 	if useDisclosure {
 		fields = append([]KeyValueURL{
 			{Key: "Disclosed", Value: capitalizeFirstLetter(fmt.Sprintf("%v", flag.Disclosed))},
@@ -588,6 +673,7 @@ func (flag *CoauthorAffiliationFlag) GetDetailsFieldsForReport(useDisclosure boo
 
 type MultipleAffiliationFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message      string
 	Work         WorkSummary
 	Affiliations []string
@@ -640,6 +726,7 @@ func (flag *MultipleAffiliationFlag) GetDetailsFieldsForReport(useDisclosure boo
 
 type HighRiskPublisherFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message    string
 	Work       WorkSummary
 	Publishers []string
@@ -692,6 +779,7 @@ func (flag *HighRiskPublisherFlag) GetDetailsFieldsForReport(useDisclosure bool)
 
 type HighRiskCoauthorFlag struct {
 	DisclosableFlag
+	TriageableFlag
 	Message   string
 	Work      WorkSummary
 	Coauthors []string
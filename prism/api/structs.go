@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,12 +16,102 @@ type Report struct {
 	AuthorId          string
 	AuthorName        string
 	Source            string
+	Orcid             string
 	Affiliations      string
 	ResearchInterests string
 
+	// Only set for merged reports, these are the author profiles whose works are
+	// combined in the report.
+	Identities []AuthorIdentity
+
+	// The number of years before the current date that the report covers, 0 if
+	// the report is not limited to a lookback window.
+	LookbackYears int
+
 	Status string
 
+	// The user that owns the report, this is only different from the current
+	// user for reports shared with the user's organization.
+	OwnerId    uuid.UUID
+	OwnerEmail string `json:",omitempty"`
+
+	// The access the owner's organization has to the report, empty if the report
+	// is not shared.
+	SharedAccess string `json:",omitempty"`
+
 	Content map[string][]Flag
+
+	// Only set for completed reports.
+	Risk *RiskScore `json:",omitempty"`
+
+	// The progress of the last time the report was processed, omitted if the
+	// report has not started processing yet.
+	Progress *ReportProgress `json:",omitempty"`
+}
+
+type FlaggerProgress struct {
+	Flagger string
+	// The number of batches of works the flagger has finished processing.
+	BatchesProcessed int
+}
+
+type ReportProgress struct {
+	StartedAt time.Time
+	UpdatedAt time.Time
+
+	WorksFetched       int
+	WorkBatchesFetched int
+	// Until all of the works have been fetched the total number of batches each
+	// flagger will process is not known.
+	AllWorksFetched bool
+
+	Flaggers []FlaggerProgress
+
+	PdfsDownloaded     int
+	PdfsFailed         int
+	GrobidCallsPending int
+
+	// Omitted if there are no previous reports or progress to estimate from.
+	EstimatedCompletionAt *time.Time `json:",omitempty"`
+	// Only set while the report is in progress.
+	EstimatedSecondsRemaining *int `json:",omitempty"`
+}
+
+type RiskScore struct {
+	// The sum of the scores of the flags in the report.
+	Score float64
+	// One of low, medium, or high.
+	Tier string
+	// The score of each flag, keyed by the hash of the flag.
+	FlagScores map[string]float64
+}
+
+// The weights used to score the flags in a report. The score of a flag is the
+// weight of its type, multiplied by the factors for its recency, sources,
+// disclosure, and triage status.
+type RiskWeights struct {
+	// The weight of each flag type, flag types that are not listed use the
+	// default weight.
+	FlagTypes map[string]float64
+
+	// The factor for flags whose entities are on a source list, keyed by the name
+	// of the list. If a flag has entities on multiple lists the highest factor is
+	// used. Lists that are not listed have a factor of 1.
+	Sources map[string]float64
+
+	// The score of a flag halves every RecencyHalfLifeYears since its date, down to
+	// MinRecencyFactor. Flags without a date are not discounted. A half life of 0
+	// disables the discount.
+	RecencyHalfLifeYears float64
+	MinRecencyFactor     float64
+
+	DisclosedFactor float64
+	DismissedFactor float64
+
+	// Reports with a score of at least these thresholds are in the medium or high
+	// tiers respectively.
+	MediumTierThreshold float64
+	HighTierThreshold   float64
 }
 
 // We have to define a custom Unmarshal method because Flag is an interface so we
@@ -61,23 +152,377 @@ type CreateAuthorReportRequest struct {
 	AuthorId   string
 	AuthorName string
 	Source     string
+
+	// Only used for the unstructured source, this is the text of the author's CV,
+	// bio, etc. that the works of the author are extracted from.
+	UnstructuredText string
+
+	// Optional, if specified the ORCID is resolved to an openalex author and the
+	// AuthorId is ignored. This can only be used with the openalex source.
+	Orcid string
+
+	// Only used for the merged source, these are the author profiles (from any of
+	// the other sources besides unstructured) whose works are combined in the report.
+	Identities []AuthorIdentity
+
+	// Optional, limits the report to works published in the given number of years
+	// before the current date (e.g. 5 for NIH/NSF disclosure requirements). If
+	// not specified all works since 2000 are included.
+	LookbackYears int
+}
+
+type FlagTriage struct {
+	Status     string
+	Note       string
+	ReviewerId uuid.UUID
+	UpdatedAt  time.Time
+}
+
+type UpdateFlagTriageRequest struct {
+	// One of confirmed, dismissed, or needs-follow-up.
+	Status string
+	Note   string
+}
+
+type ReportQueueStats struct {
+	// The number of reports with each status.
+	AuthorReports     map[string]int64
+	UniversityReports map[string]int64
+}
+
+type Watchlist struct {
+	Name string
+	// The number of entities in the watchlist, entities with an effective date
+	// in the future are included.
+	Entities int64
+	Version  string
+}
+
+type WatchlistEntityRequest struct {
+	Name string
+	// Required for the watchlists that are matched against OpenAlex ids.
+	OpenAlexId    string
+	Aliases       []string
+	Source        string
+	EffectiveDate *time.Time
+	Justification string
+}
+
+type WatchlistEntity struct {
+	Id            uuid.UUID
+	Watchlist     string
+	Name          string
+	OpenAlexId    string
+	Aliases       []string
+	Source        string
+	EffectiveDate *time.Time
+	Justification string
+
+	CreatedBy uuid.UUID
+	CreatedAt time.Time
+	UpdatedBy uuid.UUID
+	UpdatedAt time.Time
+
+	// Only set if the entity has been removed.
+	RemovedBy *uuid.UUID
+	RemovedAt *time.Time
+}
+
+type WatchlistImportResult struct {
+	Imported int
+	// Entities that are already in the watchlist are skipped.
+	Skipped int
+}
+
+type WatchlistUpdate struct {
+	Id        uuid.UUID
+	Version   string
+	CreatedAt time.Time
+	// The version of each of the watchlists.
+	Watchlists map[string]string
+	// The watchlists that changed since the previous update.
+	ChangedWatchlists []string
+
+	ReportsRequeued int
+	// The number of requeued reports that have not been reprocessed yet.
+	ReportsPending int64
+	// The number of new flags of each type that were found when the requeued
+	// reports were reprocessed.
+	NewFlags map[string]int64
+}
+
+type WatchlistUpdateReport struct {
+	ReportId   uuid.UUID
+	AuthorId   string
+	AuthorName string
+	Source     string
+	NewFlags   map[string]int64
+}
+
+type WatchlistUpdateDetails struct {
+	WatchlistUpdate
+	// The reports that have new flags from the update.
+	Reports []WatchlistUpdateReport
+}
+
+type AuditLogEntry struct {
+	Id         uuid.UUID
+	Timestamp  time.Time
+	UserId     uuid.UUID
+	Email      string
+	Action     string
+	Method     string
+	Path       string
+	Query      string     `json:",omitempty"`
+	ReportId   *uuid.UUID `json:",omitempty"`
+	AuthorId   string     `json:",omitempty"`
+	AuthorName string     `json:",omitempty"`
+	StatusCode int
+	Outcome    string
+}
+
+type QuotaUsage struct {
+	Used int
+	// The limit and remaining allowance are omitted if the usage is unlimited.
+	Limit     *int `json:",omitempty"`
+	Remaining *int `json:",omitempty"`
+}
+
+type Quota struct {
+	Metric string
+	// Either "day" or "month", periods start at midnight UTC.
+	Period   string
+	ResetsAt time.Time
+	User     QuotaUsage
+	// The usage of all members of the user's organization, this is omitted for
+	// users that are not in an organization.
+	Organization *QuotaUsage `json:",omitempty"`
+	// The smaller of the user and organization allowances, it is omitted if
+	// neither is limited.
+	Remaining *int `json:",omitempty"`
+}
+
+type Usage struct {
+	Quotas []Quota
+}
+
+type AuditLogPage struct {
+	// The number of entries matching the filters.
+	Total    int
+	Page     int
+	PageSize int
+	Entries  []AuditLogEntry
+}
+
+type ShareAuthorReportRequest struct {
+	// Either read or write.
+	Access string
+}
+
+type OrganizationMember struct {
+	UserId   uuid.UUID
+	Email    string
+	Role     string
+	JoinedAt time.Time
+}
+
+type OrganizationInvite struct {
+	OrganizationId   uuid.UUID
+	OrganizationName string
+	Email            string
+	Role             string
+	InvitedAt        time.Time
+}
+
+type Organization struct {
+	Id   uuid.UUID
+	Name string
+
+	// The role of the current user in the organization.
+	Role string
+
+	Members []OrganizationMember
+
+	// Only set for admins of the organization.
+	Invites []OrganizationInvite `json:",omitempty"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string
+}
+
+type InviteOrganizationMemberRequest struct {
+	Email string
+	// Either member or admin, defaults to member.
+	Role string
+}
+
+type FlagSuppression struct {
+	Id             uuid.UUID
+	OrganizationId uuid.UUID
+
+	AuthorId   string
+	AuthorName string
+	FlagType   string
+	Entity     string
+	FlagHash   string
+	Reason     string
+
+	CreatedBy uuid.UUID
+	CreatedAt time.Time
+
+	// Only set if the suppression has been removed.
+	RemovedBy *uuid.UUID
+	RemovedAt *time.Time
+}
+
+type CreateFlagSuppressionRequest struct {
+	AuthorId string
+	// Optional, if set the suppression also applies to the reports for the
+	// author with this name, such as reports created from unstructured text.
+	AuthorName string
+
+	// Either the FlagType and Entity, or the FlagHash must be specified.
+	FlagType string
+	Entity   string
+	FlagHash string
+
+	Reason string
+}
+
+type DisclosureFileError struct {
+	Filename string
+	Error    string
+}
+
+// The report with the updated disclosures, and the files that could not be
+// read. The fields of the report are at the top level of the response.
+type CheckDisclosureResponse struct {
+	Report
+	FileErrors []DisclosureFileError
+}
+
+func (r *CheckDisclosureResponse) UnmarshalJSON(data []byte) error {
+	// Report implements UnmarshalJSON, so it would be promoted and the other
+	// fields would be ignored.
+	if err := json.Unmarshal(data, &r.Report); err != nil {
+		return err
+	}
+
+	aux := struct{ FileErrors []DisclosureFileError }{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.FileErrors = aux.FileErrors
+
+	return nil
+}
+
+type DisclosureDocument struct {
+	Id         uuid.UUID
+	Filename   string
+	UploadedBy uuid.UUID
+	UploadedAt time.Time
+
+	// The flags in the report that were disclosed by the document.
+	DisclosedFlags []DisclosedFlag
+}
+
+type DisclosedFlag struct {
+	FlagHash    string
+	DisclosedAt time.Time
+
+	// The text around the match in the document, and the page it is on.
+	Snippet    string
+	Page       int
+	Confidence float64
+}
+
+type AuthorIdentity struct {
+	AuthorId string
+	Source   string
+}
+
+func CompareAuthorIdentities(a, b AuthorIdentity) int {
+	if c := strings.Compare(a.Source, b.Source); c != 0 {
+		return c
+	}
+	return strings.Compare(a.AuthorId, b.AuthorId)
+}
+
+// Controls which authors at the university are included in a university report.
+type UniversityAuthorDiscovery struct {
+	// The number of years of works used to find the authors at the university.
+	// If not specified the default of 4 years is used.
+	LookbackYears int
+
+	// The minimum number of works the author must have at the university in the
+	// lookback window. If not specified it defaults to 1.
+	MinWorks int
+
+	// If the university must be one of the author's last known affiliations.
+	RequireLastKnownInstitution bool
 }
 
 type CreateUniversityReportRequest struct {
 	UniversityId       string
 	UniversityName     string
 	UniversityLocation string
+
+	UniversityAuthorDiscovery
 }
 
 type CreateReportResponse struct {
 	Id uuid.UUID
 }
 
+type AuthorReportBatchRow struct {
+	// The row number in the roster file, the header is row 1.
+	Row int
+
+	Name        string `json:",omitempty"`
+	Institution string `json:",omitempty"`
+	Orcid       string `json:",omitempty"`
+	OpenAlexId  string `json:",omitempty"`
+
+	// One of created, ambiguous, unresolved, or failed.
+	Status  string
+	Message string `json:",omitempty"`
+
+	// The authors matching the row if it is ambiguous.
+	Candidates []Author `json:",omitempty"`
+
+	// The report for the row, this is omitted if the row has no report or the
+	// report was deleted. The report content is not included.
+	Report *Report `json:",omitempty"`
+}
+
+type AuthorReportBatch struct {
+	Id            uuid.UUID
+	Name          string
+	LookbackYears int
+	CreatedAt     time.Time
+
+	// The number of rows with each row status.
+	RowStatuses map[string]int
+	// The number of reports in the batch with each report status.
+	ReportStatuses map[string]int
+
+	// The rows are omitted when listing batches.
+	Rows []AuthorReportBatchRow `json:",omitempty"`
+}
+
+type ResolveAuthorReportBatchRowRequest struct {
+	// The OpenAlex id of the author, usually one of the candidates of the row.
+	AuthorId string
+}
+
 const (
 	OpenAlexSource      = "openalex"
 	GoogleScholarSource = "google-scholar"
 	UnstructuredSource  = "unstructured"
 	ScopusSource        = "scopus"
+	MergedSource        = "merged"
 )
 
 type CreateHookRequest struct {
@@ -115,6 +560,8 @@ type UniversityReport struct {
 	UniversityName     string
 	UniversityLocation string
 
+	UniversityAuthorDiscovery
+
 	Status string
 
 	Content UniversityReportContent
@@ -124,6 +571,55 @@ type UniversityReportContent struct {
 	TotalAuthors    int
 	AuthorsReviewed int
 	Flags           map[string][]UniversityAuthorFlag
+
+	// The following fields are only set when getting a single report.
+
+	// The page of authors matching the filters, ranked by risk by default.
+	Authors *UniversityAuthorPage `json:",omitempty"`
+
+	Departments  []UniversityDepartment  `json:",omitempty"`
+	YearlyTrends []UniversityYearlyTrend `json:",omitempty"`
+}
+
+type UniversityAuthorSummary struct {
+	// The id of the author report for the author in PRISM.
+	AuthorReportId uuid.UUID
+
+	AuthorId   string
+	AuthorName string
+	Source     string
+	Department string
+	Status     string
+
+	FlagCount  int
+	FlagCounts map[string]int
+
+	RiskScore float64
+	RiskTier  string
+}
+
+type UniversityAuthorPage struct {
+	// The number of authors matching the filters.
+	Total    int
+	Page     int
+	PageSize int
+	Authors  []UniversityAuthorSummary
+}
+
+type UniversityDepartment struct {
+	Department      string
+	TotalAuthors    int
+	FlaggedAuthors  int
+	HighRiskAuthors int
+	FlagCount       int
+	RiskScore       float64
+}
+
+type UniversityYearlyTrend struct {
+	Year           int
+	FlagCount      int
+	FlagCounts     map[string]int
+	FlaggedAuthors int
 }
 
 type Autocompletion struct {
@@ -145,6 +641,11 @@ type GScholarSearchResults struct {
 	Cursor  string
 }
 
+type ScopusSearchResults struct {
+	Authors []Author
+	Cursor  string
+}
+
 type Institution struct {
 	InstitutionId   string
 	InstitutionName string
@@ -14,7 +14,9 @@ import (
 	"prism/prism/licensing"
 	"prism/prism/openalex"
 	"prism/prism/reports"
+	"prism/prism/reports/flaggers/eoc"
 	"prism/prism/schema/migrations"
+	"prism/prism/scopus"
 	"prism/prism/search"
 	"prism/prism/services"
 	"prism/prism/services/auth"
@@ -57,6 +59,26 @@ type Config struct {
 	SendGridKey string `env:"SENDGRID_KEY"`
 
 	BackendUrl string `env:"BACKEND_URL" envDefault:"http://localhost"`
+
+	ScopusApiKey  string `env:"SCOPUS_API_KEY" envDefault:""`
+	ScopusBaseUrl string `env:"SCOPUS_BASE_URL" envDefault:"https://api.elsevier.com"`
+
+	// How often the watchlists are reloaded from the database, the watchlist
+	// aliases are used to match flags against disclosure documents.
+	WatchlistReloadInterval time.Duration `env:"WATCHLIST_RELOAD_INTERVAL" envDefault:"1m"`
+
+	// Limits of 0 are unlimited. The organization limits apply to the combined
+	// usage of all members of an organization.
+	Quotas struct {
+		AuthorReportsPerDay           int `env:"AUTHOR_REPORTS_PER_DAY" envDefault:"0"`
+		OrgAuthorReportsPerDay        int `env:"ORG_AUTHOR_REPORTS_PER_DAY" envDefault:"0"`
+		UniversityReportsPerMonth     int `env:"UNIVERSITY_REPORTS_PER_MONTH" envDefault:"0"`
+		OrgUniversityReportsPerMonth  int `env:"ORG_UNIVERSITY_REPORTS_PER_MONTH" envDefault:"0"`
+		LLMCallsPerDay                int `env:"LLM_CALLS_PER_DAY" envDefault:"0"`
+		OrgLLMCallsPerDay             int `env:"ORG_LLM_CALLS_PER_DAY" envDefault:"0"`
+		SearchRequestsPerMinute       int `env:"SEARCH_REQUESTS_PER_MINUTE" envDefault:"60"`
+		AutocompleteRequestsPerMinute int `env:"AUTOCOMPLETE_REQUESTS_PER_MINUTE" envDefault:"300"`
+	} `envPrefix:"QUOTA_"`
 }
 
 func (c *Config) logfile() string {
@@ -173,11 +195,36 @@ func main() {
 
 	verifyResourceFolder(config.ResourceFolder)
 
-	reportManager := reports.NewManager(db)
+	var scopusClient *scopus.Client = nil
+	if config.ScopusApiKey != "" {
+		scopusClient = scopus.NewClient(config.ScopusBaseUrl, config.ScopusApiKey)
+	}
+
+	reportManager := reports.NewManager(db).
+		SetQuotas(reports.Quotas{
+			AuthorReportsPerDay:       reports.QuotaLimit{User: config.Quotas.AuthorReportsPerDay, Organization: config.Quotas.OrgAuthorReportsPerDay},
+			UniversityReportsPerMonth: reports.QuotaLimit{User: config.Quotas.UniversityReportsPerMonth, Organization: config.Quotas.OrgUniversityReportsPerMonth},
+			LLMCallsPerDay:            reports.QuotaLimit{User: config.Quotas.LLMCallsPerDay, Organization: config.Quotas.OrgLLMCallsPerDay},
+		})
 
 	reportManager.StartReportUpdateCheck()
 	defer reportManager.StopReportUpdateCheck()
 
+	initialWatchlists, err := reportManager.LoadWatchlists()
+	if err != nil {
+		log.Fatalf("error loading watchlists: %v", err)
+	}
+	watchlists := eoc.NewStore(initialWatchlists)
+	reportManager.SetDisclosureMatcher(reports.NewDisclosureMatcher(initialWatchlists.AliasToSource))
+
+	go func() {
+		for range time.Tick(config.WatchlistReloadInterval) {
+			if _, err := reportManager.ReloadWatchlists(watchlists); err != nil {
+				slog.Error("error reloading watchlists", "error", err)
+			}
+		}
+	}()
+
 	var notifier *services.EmailMessenger = nil
 	if config.SendGridKey != "" {
 		notifier = services.NewEmailMessenger(config.SendGridKey)
@@ -196,11 +243,17 @@ func main() {
 	hooks.RunHooks(30 * time.Minute)
 	defer hooks.Stop()
 
+	audit := services.NewAuditLog(db)
+
 	backend := services.NewBackend(
-		services.NewReportService(reportManager, licensing, openalex, config.ResourceFolder),
-		services.NewSearchService(openalex, loadSearchableEntities(config.SearchableEntitiesData)),
-		services.NewAutoCompleteService(openalex),
+		services.NewReportService(reportManager, licensing, openalex, scopusClient, config.ResourceFolder),
+		services.NewSearchService(openalex, scopusClient, loadSearchableEntities(config.SearchableEntitiesData), reportManager, services.NewRateLimiter(config.Quotas.SearchRequestsPerMinute)),
+		services.NewAutoCompleteService(openalex, services.NewRateLimiter(config.Quotas.AutocompleteRequestsPerMinute)),
 		hooks,
+		services.NewOrganizationService(reportManager),
+		services.NewAdminService(reportManager, audit),
+		services.NewUsageService(reportManager),
+		audit,
 		userAuth,
 	)
 
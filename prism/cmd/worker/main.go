@@ -1,19 +1,26 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"log"
 	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
 
 	"prism/prism/cmd"
 	"prism/prism/licensing"
+	"prism/prism/llms"
 	"prism/prism/openalex"
 	"prism/prism/reports"
 	"prism/prism/reports/flaggers"
 	"prism/prism/reports/flaggers/eoc"
 	"prism/prism/reports/utils"
+	"prism/prism/scopus"
 	"prism/prism/search"
 	"prism/prism/triangulation"
 	"time"
@@ -39,12 +46,22 @@ type Config struct {
 	// listed here so that and error is raised if it's missing.
 	OpenaiKey string `env:"OPENAI_API_KEY,notEmpty,required"`
 
-	MaxDownloadThreads int `env:"MAX_DOWNLOAD_THREADS" envDefault:"40"`
-	MaxGrobidThreads   int `env:"MAX_GROBID_THREADS" envDefault:"10"`
+	// The number of reports that are processed concurrently. The download,
+	// grobid, and llm limits are shared by all of the reports.
+	MaxConcurrentReports int `env:"MAX_CONCURRENT_REPORTS" envDefault:"4"`
+	MaxDownloadThreads   int `env:"MAX_DOWNLOAD_THREADS" envDefault:"40"`
+	MaxGrobidThreads     int `env:"MAX_GROBID_THREADS" envDefault:"10"`
+	MaxLLMThreads        int `env:"MAX_LLM_THREADS" envDefault:"20"`
 
 	S3Bucket string `env:"S3_BUCKET" envDefault:"thirdai-prism"`
 
 	PpxApiKey string `env:"PPX_API_KEY" envDefault:""`
+
+	ScopusApiKey  string `env:"SCOPUS_API_KEY" envDefault:""`
+	ScopusBaseUrl string `env:"SCOPUS_BASE_URL" envDefault:"https://api.elsevier.com"`
+
+	// How often the watchlists are reloaded from the database.
+	WatchlistReloadInterval time.Duration `env:"WATCHLIST_RELOAD_INTERVAL" envDefault:"1m"`
 }
 
 func (c *Config) logfile() string {
@@ -70,6 +87,10 @@ func main() {
 
 	cmd.InitLogging(logFile)
 
+	if config.MaxConcurrentReports < 1 {
+		log.Fatalf("MAX_CONCURRENT_REPORTS must be at least 1")
+	}
+
 	licensing, err := licensing.NewLicenseVerifier(config.PrismLicense)
 	if err != nil {
 		log.Fatalf("error initializing licensing: %v", err)
@@ -88,8 +109,6 @@ func main() {
 		log.Fatalf("error creating work dir: %v", err)
 	}
 
-	entityStore := flaggers.BuildWatchlistEntityIndex(eoc.LoadSourceToAlias())
-
 	authorCache, err := utils.NewCache[openalex.Author]("authors", filepath.Join(config.WorkDir, "authors.cache"))
 	if err != nil {
 		log.Fatalf("error creating author info cache: %v", err)
@@ -101,16 +120,21 @@ func main() {
 
 	db := cmd.OpenDB(config.PostgresUri)
 
+	llms.SetMaxConcurrentCalls(config.MaxLLMThreads)
+
 	reportManager := reports.NewManager(db)
 
-	concerningEntities := eoc.LoadGeneralEOC()
-	concerningFunders := eoc.LoadFunderEOC()
-	concerningInstitutions := eoc.LoadInstitutionEOC()
+	initialWatchlists, err := reportManager.LoadWatchlists()
+	if err != nil {
+		log.Fatalf("error loading watchlists: %v", err)
+	}
+	watchlists := eoc.NewStore(initialWatchlists)
+	reportManager.SetDisclosureMatcher(reports.NewDisclosureMatcher(initialWatchlists.AliasToSource))
 
 	authorFlaggers := []reports.AuthorFlagger{
 		flaggers.NewAuthorIsFacultyAtEOCFlagger(
 			flaggers.BuildUniversityNDB(config.UniversityData, filepath.Join(ndbDir, "university.ndb")),
-		),
+		).SetDataVersion(flaggers.DataVersion(config.UniversityData)),
 	}
 	if config.PpxApiKey != "" {
 		authorFlaggers = append(authorFlaggers, flaggers.NewAuthorNewsArticlesFlagger(config.PpxApiKey))
@@ -118,53 +142,123 @@ func main() {
 
 	processor := reports.NewProcessor(
 		[]reports.WorkFlagger{
-			flaggers.NewOpenAlexFunderIsEOC(
-				concerningFunders, concerningEntities,
-			),
-			flaggers.NewOpenAlexAuthorAffiliationIsEOC(
-				concerningEntities, concerningInstitutions,
-			),
-			flaggers.NewOpenAlexCoauthorAffiliationIsEOC(
-				concerningEntities, concerningInstitutions,
-			),
+			flaggers.NewOpenAlexFunderIsEOC(watchlists),
+			flaggers.NewOpenAlexAuthorAffiliationIsEOC(watchlists),
+			flaggers.NewOpenAlexCoauthorAffiliationIsEOC(watchlists),
 			flaggers.NewOpenAlexAcknowledgementIsEOC(
-				entityStore,
+				watchlists,
 				authorCache,
 				flaggers.NewGrobidExtractor(
 					ackCache,
 					config.GrobidEndpoint,
-					config.MaxGrobidThreads,
 					config.MaxDownloadThreads,
+					config.MaxGrobidThreads,
 					config.S3Bucket,
 				),
-				eoc.LoadSussyBakas(),
 				triangulation.CreateTriangulationDB(cmd.OpenDB(config.FundcodeTriangulationUri)),
 			),
 			flaggers.NewAuthorIsAssociatedWithEOCFlagger(
 				flaggers.BuildDocIndex(config.DocData),
 				flaggers.BuildAuxIndex(config.AuxData),
-			),
+			).SetDataVersion(flaggers.DataVersion(config.DocData, config.AuxData)),
 		},
 		authorFlaggers,
 		reportManager,
 	)
 
-	lastLicenseCheck := time.Now()
-	for {
-		if time.Since(lastLicenseCheck) > 10*time.Minute {
-			if err := licensing.VerifyLicense(); err != nil {
-				slog.Error("error verifying license", "error", err)
-				time.Sleep(5 * time.Minute)
+	if config.ScopusApiKey != "" {
+		processor.SetScopus(scopus.NewClient(config.ScopusBaseUrl, config.ScopusApiKey))
+	}
+
+	processor.SetWatchlists(watchlists)
+
+	// Reports that were processed with older versions of the watchlists are
+	// requeued so that newly listed entities are flagged right away.
+	checkWatchlistVersions := func() {
+		if requeued, err := reportManager.CheckWatchlistVersions(watchlists.Current().Versions); err != nil {
+			slog.Error("error checking watchlist versions", "error", err)
+		} else if requeued > 0 {
+			slog.Info("requeued reports for watchlist update", "n_reports", requeued)
+		}
+	}
+	checkWatchlistVersions()
+
+	// Reports that are in progress when the worker is stopped are requeued so
+	// that another worker can pick them up right away.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	go func() {
+		ticker := time.NewTicker(config.WatchlistReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			changed, err := reportManager.ReloadWatchlists(watchlists)
+			if err != nil {
+				slog.Error("error reloading watchlists", "error", err)
 				continue
 			}
-			lastLicenseCheck = time.Now()
+			if changed {
+				slog.Info("watchlists changed, reloaded watchlists", "versions", watchlists.Current().Versions)
+				checkWatchlistVersions()
+			}
 		}
+	}()
 
-		foundAuthorReport := processor.ProcessNextAuthorReport()
-		foundUniversityReport := processor.ProcessNextUniversityReport()
+	var licenseValid atomic.Bool
+	licenseValid.Store(true)
+	go func() {
+		interval := 10 * time.Minute
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
 
-		if !foundAuthorReport && !foundUniversityReport {
-			time.Sleep(10 * time.Second)
+			if err := licensing.VerifyLicense(); err != nil {
+				slog.Error("error verifying license", "error", err)
+				licenseValid.Store(false)
+				interval = 5 * time.Minute
+			} else {
+				licenseValid.Store(true)
+				interval = 10 * time.Minute
+			}
 		}
+	}()
+
+	slog.Info("starting report processing", "max_concurrent_reports", config.MaxConcurrentReports)
+
+	wg := sync.WaitGroup{}
+	for range config.MaxConcurrentReports {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				found := false
+				if licenseValid.Load() {
+					foundAuthorReport := processor.ProcessNextAuthorReport(ctx)
+					foundUniversityReport := ctx.Err() == nil && processor.ProcessNextUniversityReport(ctx)
+					found = foundAuthorReport || foundUniversityReport
+				}
+
+				if !found {
+					select {
+					case <-ctx.Done():
+					case <-time.After(10 * time.Second):
+					}
+				}
+			}
+		}()
 	}
+
+	wg.Wait()
+
+	slog.Info("worker stopped")
 }
@@ -10,10 +10,35 @@ import (
 
 	"github.com/go-resty/resty/v2"
 	"github.com/openai/openai-go"
+	"golang.org/x/sync/semaphore"
 )
 
 var ErrGenerationFailed = errors.New("generation failed")
 
+// Limits the number of llm calls that are made concurrently by all of the
+// clients in the process, nil means there is no limit.
+var callLimit *semaphore.Weighted
+
+// Sets the maximum number of concurrent llm calls. This should be called
+// before any llm clients are used.
+func SetMaxConcurrentCalls(n int) {
+	if n <= 0 {
+		callLimit = nil
+		return
+	}
+	callLimit = semaphore.NewWeighted(int64(n))
+}
+
+func acquireCall(ctx context.Context) (func(), error) {
+	if callLimit == nil {
+		return func() {}, nil
+	}
+	if err := callLimit.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	return func() { callLimit.Release(1) }, nil
+}
+
 type Options struct {
 	Model        string
 	ZeroTemp     bool
@@ -42,6 +67,13 @@ func NewOpenAI() LLM {
 }
 
 func (o *OpenAI) Generate(prompt string, opts *Options) (string, error) {
+	release, err := acquireCall(context.Background())
+	if err != nil {
+		slog.Error("openai error: unable to acquire llm call", "error", err)
+		return "", ErrGenerationFailed
+	}
+	defer release()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
 	defer cancel()
 
@@ -156,6 +188,13 @@ func (p *PerplexityAI) Generate(userPrompt, systemPrompt string, opt *Perplexity
 		payload.Model = "sonar-pro"
 	}
 
+	release, err := acquireCall(context.Background())
+	if err != nil {
+		slog.Error("perplexity error: unable to acquire llm call", "error", err)
+		return "", make([]string, 0), fmt.Errorf("failed to acquire llm call: %w", err)
+	}
+	defer release()
+
 	resp, err := p.client.R().
 		SetBody(payload).
 		Post("/chat/completions")
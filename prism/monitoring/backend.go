@@ -86,6 +86,7 @@ func ExposeBackendMetrics(port int) {
 		UniReportsFoundInCache,
 		OpenalexCalls,
 		SerpapiCalls,
+		ScopusCalls,
 	)
 
 	slog.Info("exposing backend metrics", "port", port)
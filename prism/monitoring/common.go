@@ -12,4 +12,9 @@ var (
 		Name: "serpai_calls",
 		Help: "Total calls made to serpapi",
 	}, []string{"status"})
+
+	ScopusCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scopus_calls",
+		Help: "Total calls made to scopus",
+	}, []string{"status"})
 )
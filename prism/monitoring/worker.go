@@ -5,10 +5,12 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 )
 
 var (
@@ -73,6 +75,23 @@ var (
 	}, []string{"status"})
 )
 
+// Returns the mean time taken to process the reports observed in
+// ReportsProcessed, or false if no reports have been processed yet.
+func MeanReportProcessingTime() (time.Duration, bool) {
+	var metric dto.Metric
+	if err := ReportsProcessed.Write(&metric); err != nil {
+		slog.Error("error reading reports processed metric", "error", err)
+		return 0, false
+	}
+
+	count := metric.GetSummary().GetSampleCount()
+	if count == 0 {
+		return 0, false
+	}
+
+	return time.Duration(metric.GetSummary().GetSampleSum() / float64(count) * float64(time.Second)), true
+}
+
 func ExposeWorkerMetrics(port int) {
 	registry := prometheus.NewRegistry()
 
@@ -93,6 +112,7 @@ func ExposeWorkerMetrics(port int) {
 		TotalDownloads,
 		OpenalexCalls,
 		SerpapiCalls,
+		ScopusCalls,
 	)
 
 	slog.Info("exposing worker metrics", "port", port)
@@ -44,10 +44,15 @@ type Work struct {
 	WorkUrl         string
 	DownloadUrl     string
 	PublicationDate time.Time
+	UpdatedDate     time.Time // The last time the work was changed in openalex.
 	Authors         []Author
 	Grants          []Grant
 	Locations       []Location
 	DOI             string
+
+	// Only set for merged reports, these are the author profiles of the report
+	// that the work was found in.
+	Identities []api.AuthorIdentity
 }
 
 func (w *Work) GetDisplayName() string {
@@ -66,6 +71,27 @@ type WorkBatch struct {
 type InstitutionAuthor struct {
 	AuthorId   string
 	AuthorName string
+
+	// The number of works in the date range where the author is affiliated with
+	// the institution.
+	WorkCount int
+
+	// The department (or other sub-unit) of the institution the author is most
+	// often affiliated with in their works. If it cannot be determined from the
+	// affiliations, the most common field of the author's works is used instead.
+	Department string
+}
+
+type InstitutionAuthorFilter struct {
+	StartDate time.Time
+	EndDate   time.Time
+
+	// Authors with fewer works at the institution in the date range are excluded.
+	MinWorks int
+
+	// Only include authors whose last known affiliations include the
+	// institution, this excludes visiting authors and authors who have left.
+	RequireLastKnownInstitution bool
 }
 
 type KnowledgeBase interface {
@@ -79,11 +105,13 @@ type KnowledgeBase interface {
 
 	FindAuthorByOrcidId(orcidId string) (Author, error)
 
-	StreamWorks(authorId string, startDate, endDate time.Time) chan WorkBatch
+	// Only works that were added or updated in openalex since updatedSince are
+	// returned, a zero updatedSince returns all of the works in the date range.
+	StreamWorks(authorId string, startDate, endDate, updatedSince time.Time) chan WorkBatch
 
-	FindWorksByTitle(titles []string, startDate, endDate time.Time) ([]Work, error)
+	FindWorksByTitle(titles []string, startDate, endDate, updatedSince time.Time) ([]Work, error)
 
 	GetAuthor(authorId string) (Author, error)
 
-	GetInstitutionAuthors(institutionId string, startDate, endDate time.Time) ([]InstitutionAuthor, error)
+	GetInstitutionAuthors(institutionId string, filter InstitutionAuthorFilter) ([]InstitutionAuthor, error)
 }
@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"prism/prism/api"
 	"prism/prism/monitoring"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -105,6 +107,7 @@ type oaAuthor struct {
 	WorksCount              int             `json:"works_count"`
 	Affiliations            []oaAffiliation `json:"affiliations"`
 	Concepts                []oaConcept     `json:"x_concepts"`
+	LastKnownInstitutions   []oaInstitution `json:"last_known_institutions"`
 }
 
 type oaInstitution struct {
@@ -215,6 +218,7 @@ type oaWork struct {
 	DOI             string `json:"doi"`
 	DisplayName     string `json:"display_name"`
 	PublicationDate string `json:"publication_date"`
+	UpdatedDate     string `json:"updated_date"`
 
 	Ids oaWorkIds `json:"ids"`
 
@@ -226,6 +230,16 @@ type oaWork struct {
 	Authorships []oaAuthorship `json:"authorships"`
 
 	Grants []oaGrant `json:"grants"`
+
+	PrimaryTopic *oaTopic `json:"primary_topic"`
+}
+
+type oaTopicField struct {
+	DisplayName string `json:"display_name"`
+}
+
+type oaTopic struct {
+	Field oaTopicField `json:"field"`
 }
 
 func (work *oaWork) getWorkUrl() string {
@@ -259,10 +273,11 @@ type oaWorkAuthor struct {
 }
 
 type oaAuthorship struct {
-	AuthorPosition string          `json:"author_position"`
-	Author         oaWorkAuthor    `json:"author"`
-	Institutions   []oaInstitution `json:"institutions"`
-	RawAuthorName  string          `json:"raw_author_name"`
+	AuthorPosition        string          `json:"author_position"`
+	Author                oaWorkAuthor    `json:"author"`
+	Institutions          []oaInstitution `json:"institutions"`
+	RawAuthorName         string          `json:"raw_author_name"`
+	RawAffiliationStrings []string        `json:"raw_affiliation_strings"`
 }
 
 type oaLocation struct {
@@ -282,8 +297,28 @@ type oaGrant struct {
 	FunderDisplayName string `json:"funder_display_name"`
 }
 
-func getYearFilter(startDate, endDate time.Time) string {
-	return fmt.Sprintf(",from_publication_date:%s,to_publication_date:%s", startDate.Format(time.DateOnly), endDate.Format(time.DateOnly))
+func getYearFilter(startDate, endDate, updatedSince time.Time) string {
+	filter := fmt.Sprintf(",from_publication_date:%s,to_publication_date:%s", startDate.Format(time.DateOnly), endDate.Format(time.DateOnly))
+	if !updatedSince.IsZero() {
+		filter += fmt.Sprintf(",from_updated_date:%s", updatedSince.Format(time.DateOnly))
+	}
+	return filter
+}
+
+// Openalex returns the updated date without a timezone, it is in UTC.
+const oaUpdatedDateLayout = "2006-01-02T15:04:05.999999"
+
+func parseUpdatedDate(date string) time.Time {
+	if date == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{oaUpdatedDateLayout, time.DateOnly} {
+		if parsed, err := time.Parse(layout, date); err == nil {
+			return parsed
+		}
+	}
+	slog.Error("error parsing openalex updated date", "date", date)
+	return time.Time{}
 }
 
 func convertOpenalexWork(work oaWork) Work {
@@ -333,6 +368,7 @@ func convertOpenalexWork(work oaWork) Work {
 		WorkUrl:         work.getWorkUrl(),
 		DownloadUrl:     work.downloadUrl(),
 		PublicationDate: publicationDate,
+		UpdatedDate:     parseUpdatedDate(work.UpdatedDate),
 		Authors:         authors,
 		Grants:          grants,
 		Locations:       locations,
@@ -340,12 +376,12 @@ func convertOpenalexWork(work oaWork) Work {
 	}
 }
 
-func (oa *RemoteKnowledgeBase) StreamWorks(authorId string, startDate, endDate time.Time) chan WorkBatch {
+func (oa *RemoteKnowledgeBase) StreamWorks(authorId string, startDate, endDate, updatedSince time.Time) chan WorkBatch {
 	outputCh := make(chan WorkBatch, 10)
 
 	cursor := "*"
 
-	yearFilter := getYearFilter(startDate, endDate)
+	yearFilter := getYearFilter(startDate, endDate, updatedSince)
 
 	go func() {
 		defer close(outputCh)
@@ -384,10 +420,10 @@ func (oa *RemoteKnowledgeBase) StreamWorks(authorId string, startDate, endDate t
 	return outputCh
 }
 
-func (oa *RemoteKnowledgeBase) FindWorksByTitle(titles []string, startDate, endDate time.Time) ([]Work, error) {
+func (oa *RemoteKnowledgeBase) FindWorksByTitle(titles []string, startDate, endDate, updatedSince time.Time) ([]Work, error) {
 	works := make([]Work, 0, len(titles))
 
-	yearFilter := getYearFilter(startDate, endDate)
+	yearFilter := getYearFilter(startDate, endDate, updatedSince)
 
 	for _, title := range titles {
 		res, err := oa.client.R().
@@ -436,19 +472,92 @@ func (oa *RemoteKnowledgeBase) GetAuthor(authorId string) (Author, error) {
 
 	if len(results.Results) < 1 {
 		slog.Error("openalex: expected 1 author in get author, got 0", "author_id", authorId)
-		return Author{}, fmt.Errorf("no authors returned in get author")
+		return Author{}, ErrAuthorNotFound
 	}
 
 	return convertOpenalexAuthor(results.Results[0]), nil
 }
 
-func (oa *RemoteKnowledgeBase) GetInstitutionAuthors(institutionId string, startDate, endDate time.Time) ([]InstitutionAuthor, error) {
-	filter := fmt.Sprintf("institutions.id:%s%s", institutionId, getYearFilter(startDate, endDate))
+var departmentRe = regexp.MustCompile(`(?i)^(department|dept\.?|school|college|faculty|division)\b`)
+
+// Returns the department (or other sub-unit) in the raw affiliation string, for
+// example "Department of Physics" in "Department of Physics, Rice University,
+// Houston, TX".
+func departmentFromAffiliation(affiliation string) string {
+	for _, part := range strings.Split(affiliation, ",") {
+		part = strings.TrimSpace(part)
+		if departmentRe.MatchString(part) {
+			return part
+		}
+	}
+	return ""
+}
+
+func mostCommon(counts map[string]int) string {
+	best, bestCount := "", 0
+	for value, count := range counts {
+		if count > bestCount || (count == bestCount && value < best) {
+			best, bestCount = value, count
+		}
+	}
+	return best
+}
+
+// OpenAlex limits the number of values that can be OR'd together in a filter.
+const maxAuthorsPerFilter = 50
+
+// Returns the ids of the authors whose last known institutions include the
+// institution.
+func (oa *RemoteKnowledgeBase) authorsWithLastKnownInstitution(institutionId string, authorIds []string) (map[string]bool, error) {
+	output := make(map[string]bool)
+
+	for start := 0; start < len(authorIds); start += maxAuthorsPerFilter {
+		batch := authorIds[start:min(start+maxAuthorsPerFilter, len(authorIds))]
+
+		res, err := oa.client.R().
+			SetResult(&oaResults[oaAuthor]{}).
+			SetQueryParam("filter", "openalex:"+strings.Join(batch, "|")).
+			SetQueryParam("select", "id,last_known_institutions").
+			SetQueryParam("per-page", strconv.Itoa(maxAuthorsPerFilter)).
+			Get("/authors")
+
+		if err != nil {
+			slog.Error("openalex: get last known institutions failed", "institution_id", institutionId, "error", err)
+			return nil, ErrSearchFailed
+		}
+
+		if !res.IsSuccess() {
+			slog.Error("openalex: get last known institutions returned error", "status_code", res.StatusCode(), "body", res.String())
+			return nil, ErrSearchFailed
+		}
+
+		for _, author := range res.Result().(*oaResults[oaAuthor]).Results {
+			for _, inst := range author.LastKnownInstitutions {
+				if inst.Id == institutionId {
+					output[author.Id] = true
+					break
+				}
+			}
+		}
+	}
+
+	return output, nil
+}
+
+func (oa *RemoteKnowledgeBase) GetInstitutionAuthors(institutionId string, authorFilter InstitutionAuthorFilter) ([]InstitutionAuthor, error) {
+	filter := fmt.Sprintf("institutions.id:%s%s", institutionId, getYearFilter(authorFilter.StartDate, authorFilter.EndDate, time.Time{}))
 	cursor := "*"
 
 	seen := make(map[string]bool)
 	authors := make([]InstitutionAuthor, 0)
 
+	// Works are counted for all authors at the institution, but only last
+	// authors are included in the output.
+	workCounts := make(map[string]int)
+
+	departments := make(map[string]map[string]int)
+	fields := make(map[string]map[string]int)
+
 	for cursor != "" {
 		res, err := oa.client.R().
 			SetResult(&oaWorkResults{}).
@@ -471,17 +580,34 @@ func (oa *RemoteKnowledgeBase) GetInstitutionAuthors(institutionId string, start
 
 		for _, work := range result.Results {
 			for _, author := range work.Authorships {
-				if author.AuthorPosition == "last" {
-					for _, inst := range author.Institutions {
-						if inst.Id == institutionId && !seen[author.Author.Id] {
-							seen[author.Author.Id] = true
-							authors = append(authors, InstitutionAuthor{
-								AuthorId:   author.Author.Id,
-								AuthorName: author.Author.DisplayName,
-							})
-							break
+				for _, inst := range author.Institutions {
+					if inst.Id != institutionId {
+						continue
+					}
+
+					workCounts[author.Author.Id]++
+
+					if author.AuthorPosition == "last" && !seen[author.Author.Id] {
+						seen[author.Author.Id] = true
+						authors = append(authors, InstitutionAuthor{
+							AuthorId:   author.Author.Id,
+							AuthorName: author.Author.DisplayName,
+						})
+					}
+
+					if departments[author.Author.Id] == nil {
+						departments[author.Author.Id] = make(map[string]int)
+						fields[author.Author.Id] = make(map[string]int)
+					}
+					for _, affiliation := range author.RawAffiliationStrings {
+						if department := departmentFromAffiliation(affiliation); department != "" {
+							departments[author.Author.Id][department]++
 						}
 					}
+					if work.PrimaryTopic != nil && work.PrimaryTopic.Field.DisplayName != "" {
+						fields[author.Author.Id][work.PrimaryTopic.Field.DisplayName]++
+					}
+					break
 				}
 			}
 		}
@@ -489,5 +615,41 @@ func (oa *RemoteKnowledgeBase) GetInstitutionAuthors(institutionId string, start
 		cursor = result.Meta.NextCursor
 	}
 
-	return authors, nil
+	filtered := make([]InstitutionAuthor, 0, len(authors))
+	for _, author := range authors {
+		author.WorkCount = workCounts[author.AuthorId]
+		if author.WorkCount < authorFilter.MinWorks {
+			continue
+		}
+
+		if department := mostCommon(departments[author.AuthorId]); department != "" {
+			author.Department = department
+		} else {
+			author.Department = mostCommon(fields[author.AuthorId])
+		}
+		filtered = append(filtered, author)
+	}
+
+	if !authorFilter.RequireLastKnownInstitution {
+		return filtered, nil
+	}
+
+	authorIds := make([]string, 0, len(filtered))
+	for _, author := range filtered {
+		authorIds = append(authorIds, author.AuthorId)
+	}
+
+	lastKnown, err := oa.authorsWithLastKnownInstitution(institutionId, authorIds)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]InstitutionAuthor, 0, len(lastKnown))
+	for _, author := range filtered {
+		if lastKnown[author.AuthorId] {
+			output = append(output, author)
+		}
+	}
+
+	return output, nil
 }
@@ -126,7 +126,7 @@ func TestStreamWorks(t *testing.T) {
 	oa := openalex.NewRemoteKnowledgeBase()
 
 	authorId := "https://openalex.org/A5024993683"
-	stream := oa.StreamWorks(authorId, yearStart(2024), yearEnd(2024))
+	stream := oa.StreamWorks(authorId, yearStart(2024), yearEnd(2024), time.Time{})
 
 	results := make([]openalex.Work, 0)
 	for result := range stream {
@@ -191,7 +191,7 @@ func TestStreamWorksWithFundersAndLocations(t *testing.T) {
 	workId := "https://openalex.org/W2910300516"
 
 	oa := openalex.NewRemoteKnowledgeBase()
-	stream := oa.StreamWorks(authorId, yearStart(2019), yearEnd(2019))
+	stream := oa.StreamWorks(authorId, yearStart(2019), yearEnd(2019), time.Time{})
 
 	results := make([]openalex.Work, 0)
 	for result := range stream {
@@ -262,7 +262,7 @@ func TestFindWorksByTitle(t *testing.T) {
 		"Learning Scalable Structural Representations for Link Prediction with Bloom Signatures",
 	}
 
-	results, err := oa.FindWorksByTitle(titles, yearStart(2023), yearEnd(2024))
+	results, err := oa.FindWorksByTitle(titles, yearStart(2023), yearEnd(2024), time.Time{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -317,7 +317,7 @@ func TestGetInstitutionAuthors(t *testing.T) {
 	institutionId := "https://openalex.org/I74775410" // Rice university
 
 	startDate, endDate := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
-	authors, err := oa.GetInstitutionAuthors(institutionId, startDate, endDate)
+	authors, err := oa.GetInstitutionAuthors(institutionId, openalex.InstitutionAuthorFilter{StartDate: startDate, EndDate: endDate})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -326,9 +326,59 @@ func TestGetInstitutionAuthors(t *testing.T) {
 		t.Fatal("expected > 0 authors")
 	}
 
+	withDepartment := 0
 	for _, author := range authors {
 		if author.AuthorId == "" || author.AuthorName == "" {
 			t.Fatal("author info cannot be empty")
 		}
+		if author.Department != "" {
+			withDepartment++
+		}
+	}
+
+	if withDepartment == 0 {
+		t.Fatal("expected departments for authors")
+	}
+}
+
+func TestGetInstitutionAuthorsFilters(t *testing.T) {
+	oa := openalex.NewRemoteKnowledgeBase()
+
+	institutionId := "https://openalex.org/I74775410" // Rice university
+
+	filter := openalex.InstitutionAuthorFilter{
+		StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	all, err := oa.GetInstitutionAuthors(institutionId, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter.MinWorks = 3
+	minWorks, err := oa.GetInstitutionAuthors(institutionId, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(minWorks) == 0 || len(minWorks) >= len(all) {
+		t.Fatalf("expected min works to filter authors, got %d of %d", len(minWorks), len(all))
+	}
+	for _, author := range minWorks {
+		if author.WorkCount < 3 {
+			t.Fatalf("author %s has %d works", author.AuthorId, author.WorkCount)
+		}
+	}
+
+	filter.MinWorks = 0
+	filter.RequireLastKnownInstitution = true
+	lastKnown, err := oa.GetInstitutionAuthors(institutionId, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lastKnown) == 0 || len(lastKnown) >= len(all) {
+		t.Fatalf("expected last known institution to filter authors, got %d of %d", len(lastKnown), len(all))
 	}
 }
@@ -0,0 +1,328 @@
+package reports
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBatchNotFound     = errors.New("batch not found")
+	ErrBatchRowNotFound  = errors.New("batch row not found")
+	ErrBatchRowHasReport = errors.New("batch row already has a report")
+)
+
+// The OpenAlex author that a roster row was resolved to.
+type BatchAuthor struct {
+	AuthorId          string
+	AuthorName        string
+	Orcid             string
+	Affiliations      string
+	ResearchInterests string
+}
+
+// A row of a roster file. Author is set if the row was resolved to a single
+// author, otherwise the Status and Message explain why it was not.
+type BatchRosterRow struct {
+	Row         int
+	Name        string
+	Institution string
+	Orcid       string
+	OpenAlexId  string
+
+	Author *BatchAuthor
+
+	Status     string
+	Message    string
+	Candidates []api.Author
+}
+
+// Returns the message shown to the user for a row whose report could not be
+// created.
+func batchRowError(err error) string {
+	if errors.Is(err, ErrQuotaExceeded) {
+		return err.Error()
+	}
+	return "unable to create report"
+}
+
+// The report is created in a nested transaction so that a row that fails, for
+// example because the quota has been reached, does not prevent the reports for
+// the other rows from being created.
+func (r *ReportManager) createBatchRowReport(txn *gorm.DB, userId uuid.UUID, lookbackYears int, author BatchAuthor) (uuid.UUID, error) {
+	var reportId uuid.UUID
+	err := txn.Transaction(func(txn *gorm.DB) error {
+		id, err := r.createUserAuthorReport(txn, userId, AuthorReportParams{
+			AuthorId:          author.AuthorId,
+			AuthorName:        author.AuthorName,
+			Source:            api.OpenAlexSource,
+			Orcid:             author.Orcid,
+			Affiliations:      author.Affiliations,
+			ResearchInterests: author.ResearchInterests,
+			LookbackYears:     lookbackYears,
+		})
+		if err != nil {
+			return err
+		}
+		reportId = id
+		return nil
+	})
+	return reportId, err
+}
+
+// Creates a batch with a report for each row that was resolved to an author.
+// Rows whose report cannot be created are marked as failed.
+func (r *ReportManager) CreateAuthorReportBatch(userId uuid.UUID, name string, lookbackYears int, rows []BatchRosterRow) (uuid.UUID, error) {
+	batch := schema.AuthorReportBatch{
+		Id:            uuid.New(),
+		UserId:        userId,
+		Name:          name,
+		LookbackYears: lookbackYears,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	err := r.db.Transaction(func(txn *gorm.DB) error {
+		if err := txn.Create(&batch).Error; err != nil {
+			slog.Error("error creating author report batch", "error", err)
+			return ErrReportCreationFailed
+		}
+
+		batchRows := make([]schema.AuthorReportBatchRow, 0, len(rows))
+		for _, row := range rows {
+			batchRow := schema.AuthorReportBatchRow{
+				BatchId:     batch.Id,
+				Row:         row.Row,
+				Name:        row.Name,
+				Institution: row.Institution,
+				Orcid:       row.Orcid,
+				OpenAlexId:  row.OpenAlexId,
+				Status:      row.Status,
+				Message:     row.Message,
+			}
+
+			if len(row.Candidates) > 0 {
+				candidates, err := json.Marshal(row.Candidates)
+				if err != nil {
+					slog.Error("error serializing batch row candidates", "error", err)
+					return ErrReportCreationFailed
+				}
+				batchRow.Candidates = candidates
+			}
+
+			if row.Author != nil {
+				reportId, err := r.createBatchRowReport(txn, userId, lookbackYears, *row.Author)
+				if err != nil {
+					batchRow.Status = schema.BatchRowFailed
+					batchRow.Message = batchRowError(err)
+				} else {
+					batchRow.Status = schema.BatchRowCreated
+					batchRow.Message = ""
+					batchRow.UserReportId = uuid.NullUUID{UUID: reportId, Valid: true}
+				}
+			}
+
+			batchRows = append(batchRows, batchRow)
+		}
+
+		if len(batchRows) > 0 {
+			if err := txn.CreateInBatches(batchRows, 100).Error; err != nil {
+				slog.Error("error creating author report batch rows", "error", err)
+				return ErrReportCreationFailed
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return batch.Id, nil
+}
+
+func convertAuthorReportBatch(batch schema.AuthorReportBatch, includeRows bool) (api.AuthorReportBatch, error) {
+	output := api.AuthorReportBatch{
+		Id:             batch.Id,
+		Name:           batch.Name,
+		LookbackYears:  batch.LookbackYears,
+		CreatedAt:      batch.CreatedAt,
+		RowStatuses:    make(map[string]int),
+		ReportStatuses: make(map[string]int),
+	}
+
+	if includeRows {
+		output.Rows = make([]api.AuthorReportBatchRow, 0, len(batch.Rows))
+	}
+
+	for _, row := range batch.Rows {
+		output.RowStatuses[row.Status]++
+
+		var report *api.Report
+		if row.UserReport != nil && row.UserReport.Report != nil {
+			output.ReportStatuses[row.UserReport.Report.Status]++
+
+			if includeRows {
+				converted, err := ConvertReport(*row.UserReport)
+				if err != nil {
+					return api.AuthorReportBatch{}, err
+				}
+				report = &converted
+			}
+		}
+
+		if !includeRows {
+			continue
+		}
+
+		var candidates []api.Author
+		if len(row.Candidates) > 0 {
+			if err := json.Unmarshal(row.Candidates, &candidates); err != nil {
+				return api.AuthorReportBatch{}, err
+			}
+		}
+
+		output.Rows = append(output.Rows, api.AuthorReportBatchRow{
+			Row:         row.Row,
+			Name:        row.Name,
+			Institution: row.Institution,
+			Orcid:       row.Orcid,
+			OpenAlexId:  row.OpenAlexId,
+			Status:      row.Status,
+			Message:     row.Message,
+			Candidates:  candidates,
+			Report:      report,
+		})
+	}
+
+	return output, nil
+}
+
+func preloadAuthorReportBatchRows(txn *gorm.DB) *gorm.DB {
+	return txn.Preload("Rows", func(db *gorm.DB) *gorm.DB {
+		return db.Order("row ASC")
+	}).Preload("Rows.UserReport").Preload("Rows.UserReport.Report")
+}
+
+func (r *ReportManager) ListAuthorReportBatches(userId uuid.UUID) ([]api.AuthorReportBatch, error) {
+	var batches []schema.AuthorReportBatch
+	if err := preloadAuthorReportBatchRows(r.db).Order("created_at DESC").Find(&batches, "user_id = ?", userId).Error; err != nil {
+		slog.Error("error listing author report batches", "user_id", userId, "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	output := make([]api.AuthorReportBatch, 0, len(batches))
+	for _, batch := range batches {
+		converted, err := convertAuthorReportBatch(batch, false)
+		if err != nil {
+			slog.Error("error converting author report batch", "batch_id", batch.Id, "error", err)
+			return nil, ErrReportAccessFailed
+		}
+		output = append(output, converted)
+	}
+
+	return output, nil
+}
+
+func (r *ReportManager) GetAuthorReportBatch(userId, batchId uuid.UUID) (api.AuthorReportBatch, error) {
+	var batch schema.AuthorReportBatch
+	result := preloadAuthorReportBatchRows(r.db).Limit(1).Find(&batch, "id = ? AND user_id = ?", batchId, userId)
+	if result.Error != nil {
+		slog.Error("error getting author report batch", "batch_id", batchId, "error", result.Error)
+		return api.AuthorReportBatch{}, ErrReportAccessFailed
+	}
+	if result.RowsAffected == 0 {
+		return api.AuthorReportBatch{}, ErrBatchNotFound
+	}
+
+	output, err := convertAuthorReportBatch(batch, true)
+	if err != nil {
+		slog.Error("error converting author report batch", "batch_id", batchId, "error", err)
+		return api.AuthorReportBatch{}, ErrReportAccessFailed
+	}
+
+	return output, nil
+}
+
+// Creates the report for a row that was ambiguous, unresolved, or failed, for
+// example after the user has picked one of the candidates of the row.
+func (r *ReportManager) ResolveAuthorReportBatchRow(userId, batchId uuid.UUID, row int, author BatchAuthor) (uuid.UUID, error) {
+	var reportId uuid.UUID
+
+	err := r.db.Transaction(func(txn *gorm.DB) error {
+		var batch schema.AuthorReportBatch
+		result := txn.Limit(1).Find(&batch, "id = ? AND user_id = ?", batchId, userId)
+		if result.Error != nil {
+			slog.Error("error getting author report batch", "batch_id", batchId, "error", result.Error)
+			return ErrReportAccessFailed
+		}
+		if result.RowsAffected == 0 {
+			return ErrBatchNotFound
+		}
+
+		var batchRow schema.AuthorReportBatchRow
+		result = txn.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&batchRow, "batch_id = ? AND row = ?", batchId, row)
+		if result.Error != nil {
+			slog.Error("error getting author report batch row", "batch_id", batchId, "row", row, "error", result.Error)
+			return ErrReportAccessFailed
+		}
+		if result.RowsAffected == 0 {
+			return ErrBatchRowNotFound
+		}
+		if batchRow.UserReportId.Valid {
+			return ErrBatchRowHasReport
+		}
+
+		id, err := r.createUserAuthorReport(txn, userId, AuthorReportParams{
+			AuthorId:          author.AuthorId,
+			AuthorName:        author.AuthorName,
+			Source:            api.OpenAlexSource,
+			Orcid:             author.Orcid,
+			Affiliations:      author.Affiliations,
+			ResearchInterests: author.ResearchInterests,
+			LookbackYears:     batch.LookbackYears,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := txn.Model(&batchRow).Updates(map[string]any{
+			"status":         schema.BatchRowCreated,
+			"message":        "",
+			"candidates":     nil,
+			"user_report_id": id,
+		}).Error; err != nil {
+			slog.Error("error updating author report batch row", "batch_id", batchId, "row", row, "error", err)
+			return ErrReportCreationFailed
+		}
+
+		reportId = id
+		return nil
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return reportId, nil
+}
+
+// Deletes the batch, the reports that were created for it are kept.
+func (r *ReportManager) DeleteAuthorReportBatch(userId, batchId uuid.UUID) error {
+	result := r.db.Delete(&schema.AuthorReportBatch{}, "id = ? AND user_id = ?", batchId, userId)
+	if result.Error != nil {
+		slog.Error("error deleting author report batch", "batch_id", batchId, "error", result.Error)
+		return ErrReportAccessFailed
+	}
+	if result.RowsAffected == 0 {
+		return ErrBatchNotFound
+	}
+	return nil
+}
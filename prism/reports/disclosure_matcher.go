@@ -0,0 +1,346 @@
+package reports
+
+import (
+	"prism/prism/api"
+	"prism/prism/reports/utils"
+	"prism/prism/schema"
+	"prism/prism/search"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Each token of an entity must have at least this similarity with the
+	// corresponding token in the document for the entity to match.
+	minTokenSimilarity = 0.9
+
+	// Tokens with at most this many characters must match exactly, since the
+	// similarity of short strings is not meaningful (e.g. acronyms).
+	maxExactTokenLen = 3
+
+	// Watchlist aliases must have at least this similarity with the flagged
+	// entity to be considered variants of the entity. All of the aliases with
+	// the same source as the variants are then matched.
+	minAliasSimilarity = 0.9
+
+	// Matches on aliases of the entity have slightly lower confidence than
+	// matches on the entity itself.
+	aliasConfidence = 0.95
+
+	// Flags are considered disclosed if the confidence is at least this value.
+	minDisclosureConfidence = 0.9
+
+	snippetContext = 80
+
+	// Pages of the extracted text are separated by form feeds.
+	pageSeparator = "\f"
+
+	// The maximum number of tokenized disclosure documents that are cached.
+	maxCachedDocuments = 256
+)
+
+// Matches the entities of flags against the text of disclosure documents. An
+// entity matches if all of its tokens appear consecutively in the document,
+// allowing for small differences in spelling (e.g. plurals). This avoids
+// matching entities like "Chinese Academy of Sciences" against any document that
+// contains "sciences". The aliases from the watchlists are also matched.
+type DisclosureMatcher struct {
+	aliases atomic.Pointer[watchlistAliases]
+
+	// The tokenized text of recently matched documents, since the documents of a
+	// report are matched against each batch of new flags while it is processed.
+	// The text of a document never changes so the entries are only removed when
+	// the cache is full or the document is deleted.
+	textsMu    sync.Mutex
+	texts      map[uuid.UUID]disclosureText
+	textsOrder []uuid.UUID
+}
+
+func NewDisclosureMatcher(aliasToSource map[string]string) *DisclosureMatcher {
+	matcher := &DisclosureMatcher{texts: make(map[uuid.UUID]disclosureText)}
+	matcher.SetAliases(aliasToSource)
+	return matcher
+}
+
+// Replaces the watchlist aliases that are matched, this is called when the
+// watchlists are reloaded.
+func (m *DisclosureMatcher) SetAliases(aliasToSource map[string]string) {
+	if len(aliasToSource) == 0 {
+		m.aliases.Store(nil)
+		return
+	}
+
+	aliases := &watchlistAliases{
+		sources:       make(map[string]string, len(aliasToSource)),
+		sourceAliases: make(map[string][]string),
+	}
+
+	records := make([]search.Record[string], 0, len(aliasToSource))
+	for alias, source := range aliasToSource {
+		records = append(records, search.Record[string]{Entity: alias, Metadata: source})
+		aliases.sources[strings.ToLower(strings.TrimSpace(alias))] = source
+		aliases.sourceAliases[source] = append(aliases.sourceAliases[source], alias)
+	}
+	aliases.index = search.NewIndex(records)
+
+	m.aliases.Store(aliases)
+}
+
+type watchlistAliases struct {
+	index *search.EntityIndex[string]
+	// The source of each alias, keyed by the lowercase alias.
+	sources map[string]string
+	// The aliases of each source.
+	sourceAliases map[string][]string
+}
+
+// Returns the sources of the entity, either because the entity is an alias of
+// the source, or because it is a variant of an alias of the source.
+func (a *watchlistAliases) sourcesForEntity(entity string) []string {
+	sources := make([]string, 0)
+	if source, ok := a.sources[strings.ToLower(strings.TrimSpace(entity))]; ok {
+		sources = append(sources, source)
+	}
+	for _, result := range a.index.Query(entity, 10) {
+		if utils.JaroWinklerSimilarity(strings.ToLower(entity), strings.ToLower(result.Entity)) >= minAliasSimilarity && !slices.Contains(sources, result.Metadata) {
+			sources = append(sources, result.Metadata)
+		}
+	}
+	return sources
+}
+
+type textToken struct {
+	word       string
+	start, end int
+}
+
+// Splits the text into lowercase words, punctuation is removed. Han characters
+// are treated as individual tokens since they are not separated by spaces.
+func tokenizeText(text string) []textToken {
+	tokens := make([]textToken, 0)
+
+	start := -1
+	for i, r := range text {
+		isHan := unicode.Is(unicode.Han, r)
+		isWordChar := !isHan && (unicode.IsLetter(r) || unicode.IsDigit(r))
+
+		if !isWordChar && start >= 0 {
+			tokens = append(tokens, textToken{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+
+		if isHan {
+			end := i + utf8.RuneLen(r)
+			tokens = append(tokens, textToken{word: text[i:end], start: i, end: end})
+		} else if isWordChar && start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, textToken{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+type disclosurePage struct {
+	text   string
+	tokens []textToken
+}
+
+type disclosureText []disclosurePage
+
+func prepareDisclosureText(text string) disclosureText {
+	pages := strings.Split(text, pageSeparator)
+
+	output := make(disclosureText, 0, len(pages))
+	for _, page := range pages {
+		output = append(output, disclosurePage{text: page, tokens: tokenizeText(page)})
+	}
+	return output
+}
+
+// Returns the tokenized text of the document, from the cache if possible.
+func (m *DisclosureMatcher) documentText(doc schema.DisclosureDocument) disclosureText {
+	m.textsMu.Lock()
+	text, ok := m.texts[doc.Id]
+	m.textsMu.Unlock()
+	if ok {
+		return text
+	}
+
+	text = prepareDisclosureText(doc.Text)
+
+	m.textsMu.Lock()
+	defer m.textsMu.Unlock()
+
+	if _, ok := m.texts[doc.Id]; !ok {
+		if len(m.textsOrder) >= maxCachedDocuments {
+			delete(m.texts, m.textsOrder[0])
+			m.textsOrder = m.textsOrder[1:]
+		}
+		m.texts[doc.Id] = text
+		m.textsOrder = append(m.textsOrder, doc.Id)
+	}
+
+	return text
+}
+
+func (m *DisclosureMatcher) forgetDocument(id uuid.UUID) {
+	m.textsMu.Lock()
+	defer m.textsMu.Unlock()
+
+	if _, ok := m.texts[id]; ok {
+		delete(m.texts, id)
+		m.textsOrder = slices.DeleteFunc(m.textsOrder, func(other uuid.UUID) bool { return other == id })
+	}
+}
+
+type disclosureName struct {
+	tokens []string
+	weight float64
+}
+
+func newDisclosureName(name string, weight float64) (disclosureName, bool) {
+	tokens := tokenizeText(name)
+	if len(tokens) == 0 {
+		return disclosureName{}, false
+	}
+
+	words := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		words = append(words, token.word)
+	}
+	return disclosureName{tokens: words, weight: weight}, true
+}
+
+// Returns the entities of the flag, and any aliases of them, that are matched
+// against the disclosure documents.
+func (m *DisclosureMatcher) namesForFlag(flag api.Flag) []disclosureName {
+	seen := make(map[string]bool)
+	names := make([]disclosureName, 0)
+
+	add := func(name string, weight float64) {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		if n, ok := newDisclosureName(name, weight); ok {
+			names = append(names, n)
+		}
+	}
+
+	entities := flag.GetEntities()
+	for _, entity := range entities {
+		add(entity, 1)
+	}
+
+	var ackEntities []api.AcknowledgementEntity
+	switch flag := flag.(type) {
+	case *api.TalentContractFlag:
+		ackEntities = flag.Entities
+	case *api.AssociationWithDeniedEntityFlag:
+		ackEntities = flag.Entities
+	}
+	for _, entity := range ackEntities {
+		for _, alias := range entity.Aliases {
+			add(alias, aliasConfidence)
+		}
+	}
+
+	if aliases := m.aliases.Load(); aliases != nil {
+		for _, entity := range entities {
+			for _, source := range aliases.sourcesForEntity(entity) {
+				for _, alias := range aliases.sourceAliases[source] {
+					add(alias, aliasConfidence)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if len(a) <= maxExactTokenLen || len(b) <= maxExactTokenLen {
+		return 0
+	}
+	return utils.JaroWinklerSimilarity(a, b)
+}
+
+// Returns the similarity of the name with the tokens starting at the given
+// offset, or 0 if any of the tokens do not match.
+func matchAt(name disclosureName, tokens []textToken, offset int) float64 {
+	total := 0.0
+	for i, word := range name.tokens {
+		sim := tokenSimilarity(word, tokens[offset+i].word)
+		if sim < minTokenSimilarity {
+			return 0
+		}
+		total += sim
+	}
+	return total / float64(len(name.tokens))
+}
+
+// Adjusts the index to the start of a utf8 character.
+func runeStart(text string, i int) int {
+	for i > 0 && i < len(text) && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
+
+func snippet(text string, start, end int) string {
+	from := runeStart(text, max(0, start-snippetContext))
+	to := runeStart(text, min(len(text), end+snippetContext))
+
+	output := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		output = "..." + output
+	}
+	if to < len(text) {
+		output = output + "..."
+	}
+	return output
+}
+
+type disclosureMatch struct {
+	snippet    string
+	page       int
+	confidence float64
+}
+
+// Returns the best match of the names in the document, if any.
+func matchDisclosure(names []disclosureName, doc disclosureText) (disclosureMatch, bool) {
+	best := disclosureMatch{}
+
+	for pageIdx, page := range doc {
+		for _, name := range names {
+			for offset := 0; offset+len(name.tokens) <= len(page.tokens); offset++ {
+				confidence := matchAt(name, page.tokens, offset) * name.weight
+				if confidence > best.confidence {
+					start := page.tokens[offset].start
+					end := page.tokens[offset+len(name.tokens)-1].end
+					best = disclosureMatch{
+						snippet:    snippet(page.text, start, end),
+						page:       pageIdx + 1,
+						confidence: confidence,
+					}
+				}
+			}
+		}
+	}
+
+	return best, best.confidence >= minDisclosureConfidence
+}
@@ -0,0 +1,152 @@
+package reports
+
+import (
+	"prism/prism/api"
+	"prism/prism/schema"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func matchFlag(t *testing.T, matcher *DisclosureMatcher, flag api.Flag, text string) (disclosureMatch, bool) {
+	t.Helper()
+	return matchDisclosure(matcher.namesForFlag(flag), prepareDisclosureText(text))
+}
+
+func TestDisclosureMatchWholeEntity(t *testing.T) {
+	matcher := NewDisclosureMatcher(nil)
+
+	flag := &api.HighRiskFunderFlag{Funders: []string{"Chinese Academy of Sciences"}}
+
+	if _, ok := matchFlag(t, matcher, flag, "This work was funded by the National Academy of Sciences."); ok {
+		t.Fatal("entity should not match partial overlap")
+	}
+
+	if _, ok := matchFlag(t, matcher, flag, "I hold a visiting position in the sciences department."); ok {
+		t.Fatal("entity should not match single token")
+	}
+
+	match, ok := matchFlag(t, matcher, flag, "I have an appointment at the chinese academy of sciences, Beijing.")
+	if !ok || match.confidence != 1 || match.page != 1 {
+		t.Fatalf("expected exact match: %+v", match)
+	}
+	if !strings.Contains(match.snippet, "chinese academy of sciences") {
+		t.Fatalf("incorrect snippet: %q", match.snippet)
+	}
+
+	// Small differences in spelling such as plurals are allowed.
+	match, ok = matchFlag(t, matcher, flag, "Funding: Chinese Academy of Science (grant 1234).")
+	if !ok || match.confidence >= 1 || match.confidence < minDisclosureConfidence {
+		t.Fatalf("expected fuzzy match: %+v", match)
+	}
+
+	// Short tokens must match exactly.
+	acronym := &api.HighRiskFunderFlag{Funders: []string{"NSFC"}}
+	if _, ok := matchFlag(t, matcher, acronym, "Supported by the NSF."); ok {
+		t.Fatal("acronym should not match different acronym")
+	}
+}
+
+func TestDisclosureMatchPageAndSnippet(t *testing.T) {
+	matcher := NewDisclosureMatcher(nil)
+
+	flag := &api.AuthorAffiliationFlag{Affiliations: []string{"Harbin Institute of Technology"}}
+
+	text := "Page one has nothing relevant." + pageSeparator +
+		strings.Repeat("filler ", 30) + "Adjunct professor, Harbin Institute of Technology, 2019-2021. " + strings.Repeat("more ", 30)
+
+	match, ok := matchFlag(t, matcher, flag, text)
+	if !ok {
+		t.Fatal("expected match")
+	}
+	if match.page != 2 {
+		t.Fatalf("expected match on page 2, got %d", match.page)
+	}
+	if !strings.HasPrefix(match.snippet, "...") || !strings.HasSuffix(match.snippet, "...") ||
+		!strings.Contains(match.snippet, "Adjunct professor, Harbin Institute of Technology, 2019-2021.") {
+		t.Fatalf("incorrect snippet: %q", match.snippet)
+	}
+}
+
+func TestDisclosureMatchAliases(t *testing.T) {
+	flag := &api.TalentContractFlag{
+		Entities: []api.AcknowledgementEntity{{Entity: "Thousand Talents Plan", Aliases: []string{"Recruitment Program of Global Experts"}}},
+	}
+
+	match, ok := matchFlag(t, NewDisclosureMatcher(nil), flag, "I participated in the Recruitment Program of Global Experts.")
+	if !ok || match.confidence != aliasConfidence {
+		t.Fatalf("expected alias match: %+v", match)
+	}
+
+	watchlist := NewDisclosureMatcher(map[string]string{"Thousand Talent Plan": "watchlist"})
+
+	noAliases := &api.TalentContractFlag{Entities: []api.AcknowledgementEntity{{Entity: "Thousand Talents Plan"}}}
+	match, ok = matchFlag(t, watchlist, noAliases, "Member of the thousand talent plan since 2015.")
+	if !ok || match.confidence < minDisclosureConfidence {
+		t.Fatalf("expected watchlist alias match: %+v", match)
+	}
+
+	if _, ok := matchFlag(t, watchlist, noAliases, "Member of the talent program since 2015."); ok {
+		t.Fatal("unrelated text should not match")
+	}
+
+	// Aliases of the same source are matched even if they look nothing like the
+	// flagged entity.
+	sources := NewDisclosureMatcher(map[string]string{
+		"Thousand Talents Plan":                 "Foreign Talent Recruitment Programs",
+		"Recruitment Program of Global Experts": "Foreign Talent Recruitment Programs",
+		"Academy of Military Sciences":          "China Defense Universities Tracker",
+	})
+
+	match, ok = matchFlag(t, sources, noAliases, "I participated in the Recruitment Program of Global Experts.")
+	if !ok || match.confidence != aliasConfidence {
+		t.Fatalf("expected match on alias of the same source: %+v", match)
+	}
+
+	if _, ok := matchFlag(t, sources, noAliases, "Visiting scholar at the Academy of Military Sciences."); ok {
+		t.Fatal("aliases of other sources should not match")
+	}
+}
+
+func TestDisclosureMatchHanText(t *testing.T) {
+	flag := &api.AuthorAffiliationFlag{Affiliations: []string{"清华大学"}}
+
+	match, ok := matchFlag(t, NewDisclosureMatcher(nil), flag, "我在清华大学工作。")
+	if !ok || match.confidence != 1 || match.snippet != "我在清华大学工作。" {
+		t.Fatalf("expected match: %+v", match)
+	}
+
+	if _, ok := matchFlag(t, NewDisclosureMatcher(nil), flag, "我在北京大学工作。"); ok {
+		t.Fatal("different entity should not match")
+	}
+}
+
+func TestDisclosureDocumentTextCache(t *testing.T) {
+	matcher := NewDisclosureMatcher(nil)
+
+	doc := schema.DisclosureDocument{Id: uuid.New(), Text: "page 1\fpage 2"}
+
+	text := matcher.documentText(doc)
+	if len(text) != 2 || len(matcher.texts) != 1 {
+		t.Fatalf("document text should be cached: %v", text)
+	}
+
+	// The cached text is used since the text of a document never changes.
+	doc.Text = "changed"
+	if cached := matcher.documentText(doc); len(cached) != 2 {
+		t.Fatalf("expected cached document text: %v", cached)
+	}
+
+	matcher.forgetDocument(doc.Id)
+	if len(matcher.texts) != 0 || len(matcher.textsOrder) != 0 {
+		t.Fatal("deleted document should be removed from the cache")
+	}
+
+	for range maxCachedDocuments + 10 {
+		matcher.documentText(schema.DisclosureDocument{Id: uuid.New(), Text: "text"})
+	}
+	if len(matcher.texts) != maxCachedDocuments || len(matcher.textsOrder) != maxCachedDocuments {
+		t.Fatalf("cache should be limited to %d documents: %d", maxCachedDocuments, len(matcher.texts))
+	}
+}
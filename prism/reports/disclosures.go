@@ -0,0 +1,236 @@
+package reports
+
+import (
+	"errors"
+	"log/slog"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDisclosureNotFound = errors.New("disclosure document not found")
+
+// Returns a FlagDisclosure for each pair of flag and document where the flag is
+// disclosed by the document.
+func (m *DisclosureMatcher) findFlagDisclosures(reportId uuid.UUID, flags []schema.AuthorFlag, documents []schema.DisclosureDocument) ([]schema.FlagDisclosure, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	texts := make([]disclosureText, 0, len(documents))
+	for _, doc := range documents {
+		texts = append(texts, m.documentText(doc))
+	}
+
+	now := time.Now().UTC()
+
+	disclosures := make([]schema.FlagDisclosure, 0)
+	for _, flag := range flags {
+		parsed, err := api.ParseFlag(flag.FlagType, flag.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		names := m.namesForFlag(parsed)
+		if len(names) == 0 {
+			continue
+		}
+
+		for i, text := range texts {
+			if match, ok := matchDisclosure(names, text); ok {
+				disclosures = append(disclosures, schema.FlagDisclosure{
+					ReportId:    reportId,
+					FlagHash:    flag.FlagHash,
+					DocumentId:  documents[i].Id,
+					DisclosedAt: now,
+					Snippet:     match.snippet,
+					Page:        match.page,
+					Confidence:  match.confidence,
+				})
+			}
+		}
+	}
+
+	return disclosures, nil
+}
+
+func saveFlagDisclosures(txn *gorm.DB, disclosures []schema.FlagDisclosure) error {
+	if len(disclosures) == 0 {
+		return nil
+	}
+	// A flag that is found again when a report is updated may already be disclosed.
+	return txn.Clauses(clause.OnConflict{DoNothing: true}).Create(&disclosures).Error
+}
+
+// Checks if the new flags in an author report are disclosed by the disclosure
+// documents uploaded to any of the user reports for the author report. This is
+// called before the flags are saved so that the documents are not matched
+// while the transaction that saves the flags is open.
+func (m *DisclosureMatcher) findNewFlagDisclosures(db *gorm.DB, authorReportId uuid.UUID, flags []schema.AuthorFlag) ([]schema.FlagDisclosure, error) {
+	var documents []schema.DisclosureDocument
+	if err := db.Where("report_id IN (?)", db.Model(&schema.UserAuthorReport{}).Select("id").Where("report_id = ?", authorReportId)).
+		Find(&documents).Error; err != nil {
+		return nil, err
+	}
+
+	documentsByReport := make(map[uuid.UUID][]schema.DisclosureDocument)
+	for _, doc := range documents {
+		documentsByReport[doc.ReportId] = append(documentsByReport[doc.ReportId], doc)
+	}
+
+	disclosures := make([]schema.FlagDisclosure, 0)
+	for reportId, documents := range documentsByReport {
+		found, err := m.findFlagDisclosures(reportId, flags, documents)
+		if err != nil {
+			return nil, err
+		}
+		disclosures = append(disclosures, found...)
+	}
+
+	return disclosures, nil
+}
+
+// Saves the disclosures found by findNewFlagDisclosures, skipping disclosures
+// for documents that were deleted after the disclosures were found.
+func saveNewFlagDisclosures(txn *gorm.DB, disclosures []schema.FlagDisclosure) error {
+	if len(disclosures) == 0 {
+		return nil
+	}
+
+	documentIds := make([]uuid.UUID, 0, len(disclosures))
+	for _, disclosure := range disclosures {
+		documentIds = append(documentIds, disclosure.DocumentId)
+	}
+
+	var existing []uuid.UUID
+	if err := txn.Model(&schema.DisclosureDocument{}).Where("id IN ?", documentIds).Pluck("id", &existing).Error; err != nil {
+		return err
+	}
+
+	disclosures = slices.DeleteFunc(disclosures, func(disclosure schema.FlagDisclosure) bool {
+		return !slices.Contains(existing, disclosure.DocumentId)
+	})
+
+	return saveFlagDisclosures(txn, disclosures)
+}
+
+type DisclosureUpload struct {
+	Filename string
+	Text     string
+}
+
+// Stores the disclosure documents for the user report and records which of the
+// flags in the report they disclose.
+func (r *ReportManager) AddDisclosureDocuments(userId, reportId uuid.UUID, uploads []DisclosureUpload) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		report, err := getUserAuthorReport(txn, userId, reportId, writeAccess)
+		if err != nil {
+			return err
+		}
+
+		if len(uploads) == 0 {
+			return nil
+		}
+
+		now := time.Now().UTC()
+
+		documents := make([]schema.DisclosureDocument, 0, len(uploads))
+		for _, upload := range uploads {
+			documents = append(documents, schema.DisclosureDocument{
+				Id:         uuid.New(),
+				ReportId:   reportId,
+				Filename:   upload.Filename,
+				Text:       upload.Text,
+				UploadedBy: userId,
+				UploadedAt: now,
+			})
+		}
+
+		if err := txn.Create(&documents).Error; err != nil {
+			slog.Error("error saving disclosure documents", "author_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		var flags []schema.AuthorFlag
+		if err := txn.Find(&flags, "report_id = ?", report.ReportId).Error; err != nil {
+			slog.Error("error getting author report flags", "author_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		disclosures, err := r.disclosureMatcher.findFlagDisclosures(reportId, flags, documents)
+		if err != nil {
+			slog.Error("error checking flag disclosures", "author_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		if err := saveFlagDisclosures(txn, disclosures); err != nil {
+			slog.Error("error saving flag disclosures", "author_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		return nil
+	})
+}
+
+func (r *ReportManager) ListDisclosureDocuments(userId, reportId uuid.UUID) ([]api.DisclosureDocument, error) {
+	if _, err := getUserAuthorReport(r.db, userId, reportId, readAccess); err != nil {
+		return nil, err
+	}
+
+	var documents []schema.DisclosureDocument
+	if err := r.db.Preload("FlagDisclosures").Order("uploaded_at DESC").Find(&documents, "report_id = ?", reportId).Error; err != nil {
+		slog.Error("error listing disclosure documents", "author_report_id", reportId, "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	results := make([]api.DisclosureDocument, 0, len(documents))
+	for _, doc := range documents {
+		disclosed := make([]api.DisclosedFlag, 0, len(doc.FlagDisclosures))
+		for _, disclosure := range doc.FlagDisclosures {
+			disclosed = append(disclosed, api.DisclosedFlag{
+				FlagHash:    disclosure.FlagHash,
+				DisclosedAt: disclosure.DisclosedAt,
+				Snippet:     disclosure.Snippet,
+				Page:        disclosure.Page,
+				Confidence:  disclosure.Confidence,
+			})
+		}
+
+		results = append(results, api.DisclosureDocument{
+			Id:             doc.Id,
+			Filename:       doc.Filename,
+			UploadedBy:     doc.UploadedBy,
+			UploadedAt:     doc.UploadedAt,
+			DisclosedFlags: disclosed,
+		})
+	}
+
+	return results, nil
+}
+
+// Deleting a document also removes the disclosures it satisfied.
+func (r *ReportManager) DeleteDisclosureDocument(userId, reportId, documentId uuid.UUID) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		if _, err := getUserAuthorReport(txn, userId, reportId, writeAccess); err != nil {
+			return err
+		}
+
+		result := txn.Delete(&schema.DisclosureDocument{}, "id = ? AND report_id = ?", documentId, reportId)
+		if result.Error != nil {
+			slog.Error("error deleting disclosure document", "author_report_id", reportId, "document_id", documentId, "error", result.Error)
+			return ErrReportAccessFailed
+		}
+		if result.RowsAffected != 1 {
+			return ErrDisclosureNotFound
+		}
+
+		r.disclosureMatcher.forgetDocument(documentId)
+
+		return nil
+	})
+}
@@ -11,6 +11,12 @@ type WorkFlagger interface {
 
 	Name() string
 
+	// When a report is updated the flagger is only run on the works that are new
+	// or updated since the last update, unless the version has changed since the
+	// flagger was last run on the report. The version should change whenever the
+	// flags the flagger returns for a work could change.
+	Version() string
+
 	DisableForUniversityReport() bool
 }
 
@@ -18,4 +24,9 @@ type AuthorFlagger interface {
 	Flag(logger *slog.Logger, authorName, affiliations string) ([]api.Flag, error)
 
 	Name() string
+
+	// When a report is updated the flagger is only run again if the version has
+	// changed since it was last run on the report. An empty version means that
+	// the flagger is run every time the report is updated.
+	Version() string
 }
@@ -23,12 +23,18 @@ import (
 )
 
 type AcknowledgementsExtractor interface {
-	GetAcknowledgements(logger *slog.Logger, works []openalex.Work) chan utils.CompletedTask[Acknowledgements]
+	// The progress is updated as the pdfs of the works are downloaded and
+	// processed, it can be nil.
+	GetAcknowledgements(logger *slog.Logger, works []openalex.Work, progress *utils.PdfProgress) chan utils.CompletedTask[Acknowledgements]
 }
 
+// The download and grobid limits are shared by all of the calls to
+// GetAcknowledgements, so that they hold across reports that are processed
+// concurrently.
 type GrobidAcknowledgementsExtractor struct {
 	cache            utils.DataCache[Acknowledgements]
 	maxThreads       int
+	downloadSem      *semaphore.Weighted
 	grobidSem        *semaphore.Weighted
 	grobidClient     *resty.Client
 	pdfS3CacheBucket string
@@ -36,9 +42,10 @@ type GrobidAcknowledgementsExtractor struct {
 
 func NewGrobidExtractor(cache utils.DataCache[Acknowledgements], grobidEndpoint string, maxDownloadThreads, maxGrobidThreads int, pdfS3CacheBucket string) *GrobidAcknowledgementsExtractor {
 	return &GrobidAcknowledgementsExtractor{
-		cache:      cache,
-		maxThreads: max(maxDownloadThreads, maxGrobidThreads),
-		grobidSem:  semaphore.NewWeighted(int64(maxGrobidThreads)),
+		cache:       cache,
+		maxThreads:  max(maxDownloadThreads, maxGrobidThreads),
+		downloadSem: semaphore.NewWeighted(int64(maxDownloadThreads)),
+		grobidSem:   semaphore.NewWeighted(int64(maxGrobidThreads)),
 		grobidClient: resty.New().
 			SetBaseURL(grobidEndpoint).
 			SetRetryCount(2).
@@ -77,7 +84,7 @@ type Acknowledgements struct {
 	Acknowledgements []Acknowledgement
 }
 
-func (extractor *GrobidAcknowledgementsExtractor) GetAcknowledgements(logger *slog.Logger, works []openalex.Work) chan utils.CompletedTask[Acknowledgements] {
+func (extractor *GrobidAcknowledgementsExtractor) GetAcknowledgements(logger *slog.Logger, works []openalex.Work, progress *utils.PdfProgress) chan utils.CompletedTask[Acknowledgements] {
 	outputCh := make(chan utils.CompletedTask[Acknowledgements], len(works))
 
 	queue := make(chan openalex.Work, len(works))
@@ -102,7 +109,7 @@ func (extractor *GrobidAcknowledgementsExtractor) GetAcknowledgements(logger *sl
 	worker := func(next openalex.Work) (Acknowledgements, error) {
 		workId := parseOpenAlexId(next)
 
-		acks, err := extractor.extractAcknowledgments(workId, next, downloader)
+		acks, err := extractor.extractAcknowledgments(workId, next, downloader, progress)
 		if err != nil {
 			return Acknowledgements{}, fmt.Errorf("error extracting acknowledgments for work %s: %w", next.WorkId, err)
 		}
@@ -119,13 +126,24 @@ func (extractor *GrobidAcknowledgementsExtractor) GetAcknowledgements(logger *sl
 	return outputCh
 }
 
-func (extractor *GrobidAcknowledgementsExtractor) extractAcknowledgments(workId string, work openalex.Work, downloader *pdf.PDFDownloader) (Acknowledgements, error) {
+func (extractor *GrobidAcknowledgementsExtractor) extractAcknowledgments(workId string, work openalex.Work, downloader *pdf.PDFDownloader, progress *utils.PdfProgress) (Acknowledgements, error) {
+	if err := extractor.downloadSem.Acquire(context.Background(), 1); err != nil {
+		slog.Error("error aquiring semaphore for pdf download", "error", err)
+		return Acknowledgements{}, fmt.Errorf("error acquiring semaphore for pdf download: %w", err)
+	}
+
 	pdfPath, err := downloader.DownloadWork(work)
+	extractor.downloadSem.Release(1)
 	if err != nil {
+		progress.DownloadFailed()
 		return Acknowledgements{}, err
 	}
 	defer os.Remove(pdfPath)
 
+	progress.Downloaded()
+	progress.GrobidStarted()
+	defer progress.GrobidFinished()
+
 	if err := extractor.grobidSem.Acquire(context.Background(), 1); err != nil {
 		// I don't think this can fail if we use context.Background, so this error check
 		// is just in case.
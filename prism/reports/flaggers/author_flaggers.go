@@ -18,12 +18,20 @@ import (
 
 type AuthorIsFacultyAtEOCFlagger struct {
 	universityNDB search.NeuralDB
+	dataVersion   string
 }
 
 func NewAuthorIsFacultyAtEOCFlagger(universityNDB search.NeuralDB) *AuthorIsFacultyAtEOCFlagger {
 	return &AuthorIsFacultyAtEOCFlagger{universityNDB: universityNDB}
 }
 
+// The data version should identify the data the university ndb was built from,
+// see DataVersion.
+func (flagger *AuthorIsFacultyAtEOCFlagger) SetDataVersion(version string) *AuthorIsFacultyAtEOCFlagger {
+	flagger.dataVersion = version
+	return flagger
+}
+
 type nameMatcher struct {
 	text_regex   *regexp.Regexp
 	entity_regex *regexp.Regexp
@@ -269,6 +277,10 @@ func (flagger *AuthorIsFacultyAtEOCFlagger) Name() string {
 	return "PotentialFacultyAtEOC"
 }
 
+func (flagger *AuthorIsFacultyAtEOCFlagger) Version() string {
+	return "1-" + flagger.dataVersion
+}
+
 func (flagger *AuthorIsFacultyAtEOCFlagger) Flag(logger *slog.Logger, authorName, affiliations string) ([]api.Flag, error) {
 	results, err := flagger.universityNDB.Query(authorName, numUniversityDocumentsToRetrieve, nil)
 	if err != nil {
@@ -313,14 +325,22 @@ type LinkMetadata struct {
 }
 
 type AuthorIsAssociatedWithEOCFlagger struct {
-	docIndex *search.ManyToOneIndex[LinkMetadata]
-	auxIndex *search.ManyToOneIndex[LinkMetadata]
+	docIndex    *search.ManyToOneIndex[LinkMetadata]
+	auxIndex    *search.ManyToOneIndex[LinkMetadata]
+	dataVersion string
 }
 
 func NewAuthorIsAssociatedWithEOCFlagger(docIndex, auxIndex *search.ManyToOneIndex[LinkMetadata]) *AuthorIsAssociatedWithEOCFlagger {
 	return &AuthorIsAssociatedWithEOCFlagger{docIndex: docIndex, auxIndex: auxIndex}
 }
 
+// The data version should identify the data the doc and aux indexes were built
+// from, see DataVersion.
+func (flagger *AuthorIsAssociatedWithEOCFlagger) SetDataVersion(version string) *AuthorIsAssociatedWithEOCFlagger {
+	flagger.dataVersion = version
+	return flagger
+}
+
 func (flagger *AuthorIsAssociatedWithEOCFlagger) DisableForUniversityReport() bool {
 	return false
 }
@@ -329,6 +349,10 @@ func (flagger *AuthorIsAssociatedWithEOCFlagger) Name() string {
 	return "MiscAssociationWithEOC"
 }
 
+func (flagger *AuthorIsAssociatedWithEOCFlagger) Version() string {
+	return "1-" + flagger.dataVersion
+}
+
 type authorCnt struct {
 	author string
 	cnt    int
@@ -605,6 +629,12 @@ func (flagger *AuthorNewsArticlesFlagger) Name() string {
 	return "NewsArticles"
 }
 
+// The news articles change over time, so the flagger has no version and is run
+// every time the report is updated.
+func (flagger *AuthorNewsArticlesFlagger) Version() string {
+	return ""
+}
+
 func (flagger *AuthorNewsArticlesFlagger) authorPrompts(authorName, affiliation string) (string, string) {
 	systemPrompt := `You are a research assistant specializing in investigative analysis.
 Your job is to assist with background checks on academic or professional authors by gathering and summarizing news articles that indicate misconduct by the author.`
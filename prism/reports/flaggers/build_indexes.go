@@ -1,7 +1,10 @@
 package flaggers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -24,6 +27,26 @@ func parseJsonData(filename string, dest any) {
 	}
 }
 
+// Returns a hash of the contents of the data files. This is used as the version
+// of the data that the indexes are built from, so that reports are flagged again
+// when the data changes.
+func DataVersion(dataPaths ...string) string {
+	hash := sha256.New()
+	for _, path := range dataPaths {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("error opening '%s': %v", filepath.Base(path), err)
+		}
+
+		_, err = io.Copy(hash, file)
+		file.Close()
+		if err != nil {
+			log.Fatalf("error reading '%s': %v", filepath.Base(path), err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
 type universityDataRecord struct {
 	Entity  string `json:"entity"`
 	Url     string `json:"url"`
@@ -6,21 +6,9 @@ import (
 	"log"
 )
 
-type EocSet map[string]struct{}
-
-func (s *EocSet) Contains(entity string) bool {
-	_, exists := (*s)[entity]
-	return exists
-}
-
-func convertToSet(list []string) EocSet {
-	set := make(EocSet)
-	for _, item := range list {
-		set[item] = struct{}{}
-	}
-	return set
-}
-
+// The watchlists that were built in before watchlists were stored in the
+// database, they are imported when the database is created.
+//
 //go:embed data/*
 var eoc embed.FS
 
@@ -35,52 +23,57 @@ func parseFile(filename string, dest any) {
 	}
 }
 
-func LoadGeneralEOC() EocSet {
-	var entities []string
-	parseFile("data/entities.json", &entities)
-
-	return convertToSet(entities)
-}
-
 type eocEntity struct {
-	Match struct {
+	Name    string   `json:"name"`
+	Source  string   `json:"source"`
+	Aliases []string `json:"aliases"`
+	Match   struct {
 		Id string `json:"id"`
 	} `json:"match"`
 }
 
-func loadMatchIds(filename string) EocSet {
+func loadMatchedEntities(watchlist, filename string) []Entity {
 	var entities []eocEntity
 	parseFile(filename, &entities)
 
-	ids := make(EocSet)
+	output := make([]Entity, 0, len(entities))
 	for _, entity := range entities {
-		ids[entity.Match.Id] = struct{}{}
+		output = append(output, Entity{
+			Watchlist:  watchlist,
+			Name:       entity.Name,
+			OpenAlexId: entity.Match.Id,
+			Aliases:    entity.Aliases,
+			Source:     entity.Source,
+		})
 	}
-	return ids
+	return output
 }
 
-func LoadFunderEOC() EocSet {
-	return loadMatchIds("data/funders.json")
-}
-
-func LoadInstitutionEOC() EocSet {
-	return loadMatchIds("data/institutions.json")
-}
+// Returns the entities of the built in watchlists.
+func SeedEntities() []Entity {
+	entities := make([]Entity, 0)
 
-func LoadPublisherEOC() EocSet {
-	return loadMatchIds("data/publishers.json")
-}
+	var general []string
+	parseFile("data/entities.json", &general)
+	for _, id := range general {
+		entities = append(entities, Entity{Watchlist: EntitiesWatchlist, Name: id, OpenAlexId: id})
+	}
 
-func LoadSussyBakas() []string {
-	var entities []string
-	parseFile("data/sussy_bakas.json", &entities)
+	entities = append(entities, loadMatchedEntities(FundersWatchlist, "data/funders.json")...)
+	entities = append(entities, loadMatchedEntities(InstitutionsWatchlist, "data/institutions.json")...)
+	entities = append(entities, loadMatchedEntities(PublishersWatchlist, "data/publishers.json")...)
 
-	return entities
-}
+	var terms []string
+	parseFile("data/sussy_bakas.json", &terms)
+	for _, term := range terms {
+		entities = append(entities, Entity{Watchlist: SuspiciousTermsWatchlist, Name: term})
+	}
 
-func LoadSourceToAlias() map[string]string {
-	var entities map[string]string
-	parseFile("data/alias_to_source.json", &entities)
+	var aliasToSource map[string]string
+	parseFile("data/alias_to_source.json", &aliasToSource)
+	for alias, source := range aliasToSource {
+		entities = append(entities, Entity{Watchlist: AcknowledgementsWatchlist, Name: alias, Source: source})
+	}
 
 	return entities
 }
@@ -0,0 +1,151 @@
+package eoc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// OpenAlex ids of entities of any type.
+	EntitiesWatchlist     = "entities"
+	FundersWatchlist      = "funders"
+	InstitutionsWatchlist = "institutions"
+	PublishersWatchlist   = "publishers"
+	// Names of entities that are matched against acknowledgements and
+	// disclosures.
+	AcknowledgementsWatchlist = "acknowledgements"
+	// Terms that make an acknowledgement suspicious if they appear in it.
+	SuspiciousTermsWatchlist = "suspicious_terms"
+)
+
+var Watchlists = []string{
+	EntitiesWatchlist, FundersWatchlist, InstitutionsWatchlist, PublishersWatchlist,
+	AcknowledgementsWatchlist, SuspiciousTermsWatchlist,
+}
+
+func IsWatchlist(name string) bool {
+	return slices.Contains(Watchlists, name)
+}
+
+// The entities of these watchlists are matched by their OpenAlex ids, the
+// entities of the other watchlists are matched by name.
+func RequiresOpenAlexId(watchlist string) bool {
+	switch watchlist {
+	case EntitiesWatchlist, FundersWatchlist, InstitutionsWatchlist, PublishersWatchlist:
+		return true
+	}
+	return false
+}
+
+type Entity struct {
+	Watchlist     string
+	Name          string
+	OpenAlexId    string
+	Aliases       []string
+	Source        string
+	EffectiveDate time.Time
+	Justification string
+}
+
+type EocSet map[string]struct{}
+
+func (s *EocSet) Contains(entity string) bool {
+	_, exists := (*s)[entity]
+	return exists
+}
+
+// The watchlists that reports are flagged with.
+type WatchlistSet struct {
+	Entities     EocSet
+	Funders      EocSet
+	Institutions EocSet
+	Publishers   EocSet
+
+	SuspiciousTerms []string
+	// Maps the names and aliases of the entities in the acknowledgements
+	// watchlist to the lists they are from.
+	AliasToSource map[string]string
+
+	// A hash of the entities in each watchlist, this is recorded with each report
+	// so that reports can be reprocessed when the watchlists change.
+	Versions map[string]string
+}
+
+func NewWatchlistSet(entities []Entity) *WatchlistSet {
+	set := &WatchlistSet{
+		Entities:        make(EocSet),
+		Funders:         make(EocSet),
+		Institutions:    make(EocSet),
+		Publishers:      make(EocSet),
+		SuspiciousTerms: make([]string, 0),
+		AliasToSource:   make(map[string]string),
+		Versions:        make(map[string]string, len(Watchlists)),
+	}
+
+	lines := make(map[string][]string)
+	for _, entity := range entities {
+		switch entity.Watchlist {
+		case EntitiesWatchlist:
+			set.Entities[entity.OpenAlexId] = struct{}{}
+		case FundersWatchlist:
+			set.Funders[entity.OpenAlexId] = struct{}{}
+		case InstitutionsWatchlist:
+			set.Institutions[entity.OpenAlexId] = struct{}{}
+		case PublishersWatchlist:
+			set.Publishers[entity.OpenAlexId] = struct{}{}
+		case SuspiciousTermsWatchlist:
+			set.SuspiciousTerms = append(set.SuspiciousTerms, strings.ToLower(strings.TrimSpace(entity.Name)))
+		case AcknowledgementsWatchlist:
+			set.AliasToSource[entity.Name] = entity.Source
+			for _, alias := range entity.Aliases {
+				set.AliasToSource[alias] = entity.Source
+			}
+		}
+
+		aliases := slices.Sorted(slices.Values(entity.Aliases))
+		lines[entity.Watchlist] = append(lines[entity.Watchlist], fmt.Sprintf("%s\t%s\t%s\t%s", entity.Name, entity.OpenAlexId, strings.Join(aliases, "|"), entity.Source))
+	}
+
+	for _, watchlist := range Watchlists {
+		slices.Sort(lines[watchlist])
+		hash := sha256.New()
+		for _, line := range lines[watchlist] {
+			fmt.Fprintln(hash, line)
+		}
+		set.Versions[watchlist] = hex.EncodeToString(hash.Sum(nil))[:16]
+	}
+
+	return set
+}
+
+// Holds the current watchlists so that they can be reloaded while the flaggers
+// are using them.
+type Store struct {
+	current atomic.Pointer[WatchlistSet]
+}
+
+func NewStore(watchlists *WatchlistSet) *Store {
+	store := &Store{}
+	store.current.Store(watchlists)
+	return store
+}
+
+func (s *Store) Current() *WatchlistSet {
+	return s.current.Load()
+}
+
+// Replaces the watchlists if any of them changed, returns true if they were
+// replaced.
+func (s *Store) Update(watchlists *WatchlistSet) bool {
+	if current := s.current.Load(); current != nil && maps.Equal(current.Versions, watchlists.Versions) {
+		return false
+	}
+	s.current.Store(watchlists)
+	return true
+}
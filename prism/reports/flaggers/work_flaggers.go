@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 
 	"prism/prism/api"
 	"prism/prism/llms"
@@ -17,13 +18,20 @@ import (
 )
 
 func getWorkSummary(w openalex.Work) api.WorkSummary {
-	return api.WorkSummary{
+	summary := api.WorkSummary{
 		WorkId:          w.WorkId,
 		DisplayName:     w.DisplayName,
 		WorkUrl:         w.WorkUrl,
 		OaUrl:           w.DownloadUrl,
 		PublicationDate: w.PublicationDate,
 	}
+	for _, identity := range w.Identities {
+		if !slices.Contains(summary.Sources, identity.Source) {
+			summary.Sources = append(summary.Sources, identity.Source)
+		}
+		summary.Identities = append(summary.Identities, identity)
+	}
+	return summary
 }
 
 type OpenAlexMultipleAffiliationsFlagger struct{}
@@ -36,6 +44,10 @@ func (flagger *OpenAlexMultipleAffiliationsFlagger) Name() string {
 	return "MultipleAffiliations"
 }
 
+func (flagger *OpenAlexMultipleAffiliationsFlagger) Version() string {
+	return "1"
+}
+
 func (flagger *OpenAlexMultipleAffiliationsFlagger) Flag(logger *slog.Logger, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error) {
 	flags := make([]api.Flag, 0)
 
@@ -61,28 +73,30 @@ func (flagger *OpenAlexMultipleAffiliationsFlagger) DisableForUniversityReport()
 }
 
 type OpenAlexFunderIsEOC struct {
-	concerningFunders  eoc.EocSet
-	concerningEntities eoc.EocSet
+	watchlists *eoc.Store
 }
 
-func NewOpenAlexFunderIsEOC(concerningFunders, concerningEntities eoc.EocSet) *OpenAlexFunderIsEOC {
-	return &OpenAlexFunderIsEOC{
-		concerningFunders:  concerningFunders,
-		concerningEntities: concerningEntities,
-	}
+func NewOpenAlexFunderIsEOC(watchlists *eoc.Store) *OpenAlexFunderIsEOC {
+	return &OpenAlexFunderIsEOC{watchlists: watchlists}
 }
 
 func (flagger *OpenAlexFunderIsEOC) Name() string {
 	return "FunderEOC"
 }
 
+func (flagger *OpenAlexFunderIsEOC) Version() string {
+	return "1"
+}
+
 func (flagger *OpenAlexFunderIsEOC) Flag(logger *slog.Logger, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error) {
 	flags := make([]api.Flag, 0)
 
+	watchlists := flagger.watchlists.Current()
+
 	for _, work := range works {
 		concerningFunders := make([]string, 0)
 		for _, grant := range work.Grants {
-			if flagger.concerningEntities.Contains(grant.FunderId) || flagger.concerningFunders.Contains(grant.FunderId) {
+			if watchlists.Entities.Contains(grant.FunderId) || watchlists.Funders.Contains(grant.FunderId) {
 				concerningFunders = append(concerningFunders, grant.FunderName)
 			}
 		}
@@ -104,26 +118,30 @@ func (flagger *OpenAlexFunderIsEOC) DisableForUniversityReport() bool {
 }
 
 type OpenAlexPublisherIsEOC struct {
-	concerningPublishers eoc.EocSet
+	watchlists *eoc.Store
 }
 
-func NewOpenAlexPublisherIsEOC(concerningPublishers eoc.EocSet) *OpenAlexPublisherIsEOC {
-	return &OpenAlexPublisherIsEOC{
-		concerningPublishers: concerningPublishers,
-	}
+func NewOpenAlexPublisherIsEOC(watchlists *eoc.Store) *OpenAlexPublisherIsEOC {
+	return &OpenAlexPublisherIsEOC{watchlists: watchlists}
 }
 
 func (flagger *OpenAlexPublisherIsEOC) Name() string {
 	return "PublisherEOC"
 }
 
+func (flagger *OpenAlexPublisherIsEOC) Version() string {
+	return "1"
+}
+
 func (flagger *OpenAlexPublisherIsEOC) Flag(logger *slog.Logger, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error) {
 	flags := make([]api.Flag, 0)
 
+	watchlists := flagger.watchlists.Current()
+
 	for _, work := range works {
 		concerningPublishers := make([]string, 0)
 		for _, loc := range work.Locations {
-			if flagger.concerningPublishers.Contains(loc.OrganizationId) {
+			if watchlists.Publishers.Contains(loc.OrganizationId) {
 				concerningPublishers = append(concerningPublishers, loc.OrganizationName)
 			}
 		}
@@ -145,26 +163,30 @@ func (flagger *OpenAlexPublisherIsEOC) DisableForUniversityReport() bool {
 }
 
 type OpenAlexCoauthorIsEOC struct {
-	concerningEntities eoc.EocSet
+	watchlists *eoc.Store
 }
 
-func NewOpenAlexCoauthorIsEOC(concerningEntities eoc.EocSet) *OpenAlexCoauthorIsEOC {
-	return &OpenAlexCoauthorIsEOC{
-		concerningEntities: concerningEntities,
-	}
+func NewOpenAlexCoauthorIsEOC(watchlists *eoc.Store) *OpenAlexCoauthorIsEOC {
+	return &OpenAlexCoauthorIsEOC{watchlists: watchlists}
 }
 
 func (flagger *OpenAlexCoauthorIsEOC) Name() string {
 	return "CoauthorEOC"
 }
 
+func (flagger *OpenAlexCoauthorIsEOC) Version() string {
+	return "1"
+}
+
 func (flagger *OpenAlexCoauthorIsEOC) Flag(logger *slog.Logger, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error) {
 	flags := make([]api.Flag, 0)
 
+	watchlists := flagger.watchlists.Current()
+
 	for _, work := range works {
 		concerningAuthors := make([]string, 0)
 		for _, author := range work.Authors {
-			if flagger.concerningEntities.Contains(author.AuthorId) {
+			if watchlists.Entities.Contains(author.AuthorId) {
 				concerningAuthors = append(concerningAuthors, author.DisplayName)
 			}
 		}
@@ -194,24 +216,26 @@ func (flagger *OpenAlexCoauthorIsEOC) DisableForUniversityReport() bool {
 }
 
 type OpenAlexAuthorAffiliationIsEOC struct {
-	concerningEntities     eoc.EocSet
-	concerningInstitutions eoc.EocSet
+	watchlists *eoc.Store
 }
 
-func NewOpenAlexAuthorAffiliationIsEOC(concerningEntities, concerningInstitutions eoc.EocSet) *OpenAlexAuthorAffiliationIsEOC {
-	return &OpenAlexAuthorAffiliationIsEOC{
-		concerningEntities:     concerningEntities,
-		concerningInstitutions: concerningInstitutions,
-	}
+func NewOpenAlexAuthorAffiliationIsEOC(watchlists *eoc.Store) *OpenAlexAuthorAffiliationIsEOC {
+	return &OpenAlexAuthorAffiliationIsEOC{watchlists: watchlists}
 }
 
 func (flagger *OpenAlexAuthorAffiliationIsEOC) Name() string {
 	return "AuthorAffiliationEOC"
 }
 
+func (flagger *OpenAlexAuthorAffiliationIsEOC) Version() string {
+	return "1"
+}
+
 func (flagger *OpenAlexAuthorAffiliationIsEOC) Flag(logger *slog.Logger, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error) {
 	flags := make([]api.Flag, 0)
 
+	watchlists := flagger.watchlists.Current()
+
 	for _, work := range works {
 		concerningAffiliations := make(map[string]bool)
 		for _, author := range work.Authors {
@@ -219,8 +243,8 @@ func (flagger *OpenAlexAuthorAffiliationIsEOC) Flag(logger *slog.Logger, works [
 				continue
 			}
 			for _, institution := range author.Institutions {
-				if flagger.concerningEntities.Contains(institution.InstitutionId) ||
-					flagger.concerningInstitutions.Contains(institution.InstitutionId) {
+				if watchlists.Entities.Contains(institution.InstitutionId) ||
+					watchlists.Institutions.Contains(institution.InstitutionId) {
 					concerningAffiliations[institution.InstitutionName] = true
 				}
 			}
@@ -244,24 +268,26 @@ func (flagger *OpenAlexAuthorAffiliationIsEOC) DisableForUniversityReport() bool
 }
 
 type OpenAlexCoauthorAffiliationIsEOC struct {
-	concerningEntities     eoc.EocSet
-	concerningInstitutions eoc.EocSet
+	watchlists *eoc.Store
 }
 
-func NewOpenAlexCoauthorAffiliationIsEOC(concerningEntities, concerningInstitutions eoc.EocSet) *OpenAlexCoauthorAffiliationIsEOC {
-	return &OpenAlexCoauthorAffiliationIsEOC{
-		concerningEntities:     concerningEntities,
-		concerningInstitutions: concerningInstitutions,
-	}
+func NewOpenAlexCoauthorAffiliationIsEOC(watchlists *eoc.Store) *OpenAlexCoauthorAffiliationIsEOC {
+	return &OpenAlexCoauthorAffiliationIsEOC{watchlists: watchlists}
 }
 
 func (flagger *OpenAlexCoauthorAffiliationIsEOC) Name() string {
 	return "CoauthorAffiliationEOC"
 }
 
+func (flagger *OpenAlexCoauthorAffiliationIsEOC) Version() string {
+	return "1"
+}
+
 func (flagger *OpenAlexCoauthorAffiliationIsEOC) Flag(logger *slog.Logger, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error) {
 	flags := make([]api.Flag, 0)
 
+	watchlists := flagger.watchlists.Current()
+
 	for _, work := range works {
 		concerningAffiliations := make(map[string]bool)
 		concerningCoauthors := make(map[string]bool)
@@ -270,8 +296,8 @@ func (flagger *OpenAlexCoauthorAffiliationIsEOC) Flag(logger *slog.Logger, works
 				continue
 			}
 			for _, institution := range author.Institutions {
-				if flagger.concerningEntities.Contains(institution.InstitutionId) ||
-					flagger.concerningInstitutions.Contains(institution.InstitutionId) {
+				if watchlists.Entities.Contains(institution.InstitutionId) ||
+					watchlists.Institutions.Contains(institution.InstitutionId) {
 					concerningAffiliations[institution.InstitutionName] = true
 					concerningCoauthors[author.DisplayName] = true
 				}
@@ -307,34 +333,53 @@ func BuildWatchlistEntityIndex(aliasToSource map[string]string) *search.EntityIn
 
 type OpenAlexAcknowledgementIsEOC struct {
 	openalex        openalex.KnowledgeBase
-	entityLookup    *search.EntityIndex[string]
+	watchlists      *eoc.Store
 	authorCache     utils.DataCache[openalex.Author]
 	extractor       AcknowledgementsExtractor
-	sussyBakas      []string
 	triangulationDB *triangulation.TriangulationDB
+
+	// The index of the acknowledgements watchlist is rebuilt when the watchlists
+	// are reloaded.
+	lookupLock        sync.Mutex
+	entityLookup      *search.EntityIndex[string]
+	entityLookupLists *eoc.WatchlistSet
 }
 
 func NewOpenAlexAcknowledgementIsEOC(
-	entityLookup *search.EntityIndex[string],
+	watchlists *eoc.Store,
 	authorCache utils.DataCache[openalex.Author],
 	extractor AcknowledgementsExtractor,
-	sussyBakas []string,
 	triangulationDB *triangulation.TriangulationDB,
 ) *OpenAlexAcknowledgementIsEOC {
 	return &OpenAlexAcknowledgementIsEOC{
 		openalex:        openalex.NewRemoteKnowledgeBase(),
-		entityLookup:    entityLookup,
+		watchlists:      watchlists,
 		authorCache:     authorCache,
 		extractor:       extractor,
-		sussyBakas:      sussyBakas,
 		triangulationDB: triangulationDB,
 	}
 }
 
+func (flagger *OpenAlexAcknowledgementIsEOC) getEntityLookup() *search.EntityIndex[string] {
+	flagger.lookupLock.Lock()
+	defer flagger.lookupLock.Unlock()
+
+	if watchlists := flagger.watchlists.Current(); watchlists != flagger.entityLookupLists {
+		flagger.entityLookup = BuildWatchlistEntityIndex(watchlists.AliasToSource)
+		flagger.entityLookupLists = watchlists
+	}
+
+	return flagger.entityLookup
+}
+
 func (flagger *OpenAlexAcknowledgementIsEOC) Name() string {
 	return "AcknowledgementEOC"
 }
 
+func (flagger *OpenAlexAcknowledgementIsEOC) Version() string {
+	return "1"
+}
+
 func (flagger *OpenAlexAcknowledgementIsEOC) getAuthorNames(authorIds []string) ([]string, error) {
 	authorNames := make([]string, 0, len(authorIds))
 
@@ -365,7 +410,7 @@ func (flagger *OpenAlexAcknowledgementIsEOC) getAuthorNames(authorIds []string)
 func (flagger *OpenAlexAcknowledgementIsEOC) containsSussyBakas(text string) bool {
 	text = fmt.Sprintf(" %s ", strings.ToLower(strings.TrimSpace(text)))
 
-	for _, sussyBaka := range flagger.sussyBakas {
+	for _, sussyBaka := range flagger.watchlists.Current().SuspiciousTerms {
 		if strings.Contains(text, fmt.Sprintf(" %s ", sussyBaka)) {
 			return true
 		}
@@ -408,8 +453,10 @@ type SourceToAliases map[string][]string
 func (flagger *OpenAlexAcknowledgementIsEOC) searchWatchlistEntities(entities []string) map[string]SourceToAliases {
 	matches := make(map[string]SourceToAliases)
 
+	entityLookup := flagger.getEntityLookup()
+
 	for _, entity := range entities {
-		results := flagger.entityLookup.Query(entity, 10)
+		results := entityLookup.Query(entity, 10)
 
 		sourceToAliases := make(SourceToAliases)
 		for _, result := range results {
@@ -611,6 +658,12 @@ func (flagger *OpenAlexAcknowledgementIsEOC) DisableForUniversityReport() bool {
 }
 
 func (flagger *OpenAlexAcknowledgementIsEOC) Flag(logger *slog.Logger, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error) {
+	return flagger.FlagWithPdfProgress(logger, nil, works, targetAuthorIds, authorName)
+}
+
+// The same as Flag, but the progress is updated as the pdfs of the works are
+// downloaded and processed.
+func (flagger *OpenAlexAcknowledgementIsEOC) FlagWithPdfProgress(logger *slog.Logger, progress *utils.PdfProgress, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error) {
 
 	flags := make([]api.Flag, 0)
 
@@ -641,7 +694,7 @@ func (flagger *OpenAlexAcknowledgementIsEOC) Flag(logger *slog.Logger, works []o
 		return nil, fmt.Errorf("error getting author infos: %w", err)
 	}
 
-	acknowledgementsStream := flagger.extractor.GetAcknowledgements(logger, remaining)
+	acknowledgementsStream := flagger.extractor.GetAcknowledgements(logger, remaining, progress)
 
 	fundCodes := make(map[string]bool)
 
@@ -54,10 +54,10 @@ func makeSet(entities ...string) eoc.EocSet {
 }
 
 func TestFunderEOC(t *testing.T) {
-	flagger := flaggers.NewOpenAlexFunderIsEOC(
-		makeSet("bad-abc", "bad-xyz"),
-		makeSet("bad-123", "bad-456"),
-	)
+	flagger := flaggers.NewOpenAlexFunderIsEOC(eoc.NewStore(&eoc.WatchlistSet{
+		Funders:  makeSet("bad-abc", "bad-xyz"),
+		Entities: makeSet("bad-123", "bad-456"),
+	}))
 
 	for funder, nflags := range map[string]int{"bad-xyz": 1, "bad-456": 1, "abc": 0, "123": 0} {
 		works := []openalex.Work{
@@ -78,10 +78,40 @@ func TestFunderEOC(t *testing.T) {
 	}
 }
 
+func TestFunderEOCReload(t *testing.T) {
+	watchlists := eoc.NewStore(eoc.NewWatchlistSet(nil))
+	flagger := flaggers.NewOpenAlexFunderIsEOC(watchlists)
+
+	works := []openalex.Work{{Grants: []openalex.Grant{{FunderId: "bad-abc"}}}}
+
+	checkFlags := func(expected int) {
+		t.Helper()
+		flags, err := flagger.Flag(slog.Default(), works, []string{"a"}, "abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(flags) != expected {
+			t.Fatalf("expected %d flags, got %d", expected, len(flags))
+		}
+	}
+
+	checkFlags(0)
+
+	updated := eoc.NewWatchlistSet([]eoc.Entity{{Watchlist: eoc.FundersWatchlist, Name: "abc", OpenAlexId: "bad-abc"}})
+	if !watchlists.Update(updated) {
+		t.Fatal("watchlists should be updated")
+	}
+	if watchlists.Update(eoc.NewWatchlistSet([]eoc.Entity{{Watchlist: eoc.FundersWatchlist, Name: "abc", OpenAlexId: "bad-abc"}})) {
+		t.Fatal("watchlists should not be updated if they did not change")
+	}
+
+	checkFlags(1)
+}
+
 func TestPublisherEOC(t *testing.T) {
-	flagger := flaggers.NewOpenAlexPublisherIsEOC(
-		makeSet("bad-abc", "bad-xyz"),
-	)
+	flagger := flaggers.NewOpenAlexPublisherIsEOC(eoc.NewStore(&eoc.WatchlistSet{
+		Publishers: makeSet("bad-abc", "bad-xyz"),
+	}))
 
 	for publisher, nflags := range map[string]int{"abc": 0, "bad-xyz": 1} {
 		works := []openalex.Work{
@@ -102,9 +132,9 @@ func TestPublisherEOC(t *testing.T) {
 }
 
 func TestCoauthorEOC(t *testing.T) {
-	flagger := flaggers.NewOpenAlexCoauthorIsEOC(
-		makeSet("bad-abc", "bad-xyz"),
-	)
+	flagger := flaggers.NewOpenAlexCoauthorIsEOC(eoc.NewStore(&eoc.WatchlistSet{
+		Entities: makeSet("bad-abc", "bad-xyz"),
+	}))
 
 	for coauthor, nflags := range map[string]int{"abc": 0, "bad-xyz": 1} {
 		works := []openalex.Work{
@@ -125,10 +155,10 @@ func TestCoauthorEOC(t *testing.T) {
 }
 
 func TestAuthorAffiliationEOC(t *testing.T) {
-	flagger := flaggers.NewOpenAlexAuthorAffiliationIsEOC(
-		makeSet("bad-abc", "bad-xyz"),
-		makeSet("bad-123", "bad-456"),
-	)
+	flagger := flaggers.NewOpenAlexAuthorAffiliationIsEOC(eoc.NewStore(&eoc.WatchlistSet{
+		Entities:     makeSet("bad-abc", "bad-xyz"),
+		Institutions: makeSet("bad-123", "bad-456"),
+	}))
 
 	for author, isTarget := range map[string]int{"a": 1, "c": 0} {
 		for institution, isBad := range map[string]int{"abc": 0, "bad-xyz": 1, "bad-123": 1} {
@@ -151,10 +181,10 @@ func TestAuthorAffiliationEOC(t *testing.T) {
 }
 
 func TestCoauthorAffiliationEOC(t *testing.T) {
-	flagger := flaggers.NewOpenAlexCoauthorAffiliationIsEOC(
-		makeSet("bad-abc", "bad-xyz"),
-		makeSet("bad-123", "bad-456"),
-	)
+	flagger := flaggers.NewOpenAlexCoauthorAffiliationIsEOC(eoc.NewStore(&eoc.WatchlistSet{
+		Entities:     makeSet("bad-abc", "bad-xyz"),
+		Institutions: makeSet("bad-123", "bad-456"),
+	}))
 
 	for author, isTarget := range map[string]int{"a": 1, "c": 0} {
 		for institution, isBad := range map[string]int{"abc": 0, "bad-xyz": 1, "bad-123": 1} {
@@ -178,7 +208,7 @@ func TestCoauthorAffiliationEOC(t *testing.T) {
 
 type mockAcknowledgmentExtractor struct{}
 
-func (m *mockAcknowledgmentExtractor) GetAcknowledgements(logger *slog.Logger, works []openalex.Work) chan utils.CompletedTask[flaggers.Acknowledgements] {
+func (m *mockAcknowledgmentExtractor) GetAcknowledgements(logger *slog.Logger, works []openalex.Work, progress *utils.PdfProgress) chan utils.CompletedTask[flaggers.Acknowledgements] {
 	output := make(chan utils.CompletedTask[flaggers.Acknowledgements], 1)
 
 	output <- utils.CompletedTask[flaggers.Acknowledgements]{
@@ -225,10 +255,9 @@ func TestAcknowledgementEOC(t *testing.T) {
 	triangulationDB := triangulation.CreateTriangulationDB(db)
 
 	flagger := flaggers.NewOpenAlexAcknowledgementIsEOC(
-		flaggers.BuildWatchlistEntityIndex(aliasToSource),
+		eoc.NewStore(&eoc.WatchlistSet{AliasToSource: aliasToSource, SuspiciousTerms: []string{"bad entity xyz"}}),
 		authorCache,
 		&mockAcknowledgmentExtractor{},
-		[]string{"bad entity xyz"},
 		triangulationDB,
 	)
 
@@ -306,10 +335,9 @@ func TestFundCodeTriangulation(t *testing.T) {
 	triangulationDB := triangulation.CreateTriangulationDB(db)
 
 	flagger := flaggers.NewOpenAlexAcknowledgementIsEOC(
-		flaggers.BuildWatchlistEntityIndex(aliasToSource),
+		eoc.NewStore(&eoc.WatchlistSet{AliasToSource: aliasToSource, SuspiciousTerms: []string{"bad entity xyz"}}),
 		authorCache,
 		&mockAcknowledgmentExtractor{},
-		[]string{"bad entity xyz"},
 		triangulationDB,
 	)
 
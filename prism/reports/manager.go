@@ -10,7 +10,7 @@ import (
 	"prism/prism/api"
 	"prism/prism/monitoring"
 	"prism/prism/schema"
-	"sort"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -34,6 +34,7 @@ var (
 	ErrReportCreationFailed   = errors.New("report creation failed")
 	ErrReportNotFound         = errors.New("report not found")
 	ErrUserCannotAccessReport = errors.New("user cannot access report")
+	ErrFlagNotFound           = errors.New("flag not found")
 )
 
 type ReportManager struct {
@@ -43,6 +44,9 @@ type ReportManager struct {
 	universityReportUpdateInterval time.Duration
 	universityReportTimeout        time.Duration
 	stopReportUpdate               chan struct{}
+	disclosureMatcher              *DisclosureMatcher
+	riskScorer                     RiskScorerFactory
+	quotas                         Quotas
 }
 
 func NewManager(db *gorm.DB) *ReportManager {
@@ -52,6 +56,8 @@ func NewManager(db *gorm.DB) *ReportManager {
 		authorReportTimeout:            AuthorReportTimeout,
 		universityReportUpdateInterval: UniversityReportUpdateInterval,
 		universityReportTimeout:        UniversityReportTimeout,
+		disclosureMatcher:              NewDisclosureMatcher(nil),
+		riskScorer:                     NewWeightedRiskScorer,
 	}
 }
 
@@ -102,10 +108,55 @@ func (r *ReportManager) SetUniversityReportTimeout(timeout time.Duration) *Repor
 	return r
 }
 
-func (r *ReportManager) ListAuthorReports(userId uuid.UUID) ([]api.Report, error) {
+func (r *ReportManager) SetDisclosureMatcher(matcher *DisclosureMatcher) *ReportManager {
+	r.disclosureMatcher = matcher
+	return r
+}
+
+func (r *ReportManager) SetRiskScorer(factory RiskScorerFactory) *ReportManager {
+	r.riskScorer = factory
+	return r
+}
+
+func (r *ReportManager) SetQuotas(quotas Quotas) *ReportManager {
+	r.quotas = quotas
+	return r
+}
+
+// Lists the reports of the user. If includeOrganization is true the reports
+// that other members of the user's organization have shared are also listed.
+func (r *ReportManager) ListAuthorReports(userId uuid.UUID, includeOrganization bool) ([]api.Report, error) {
 	var reports []schema.UserAuthorReport
 
-	if err := r.db.Preload("Report").Order("last_accessed_at DESC").Find(&reports, "user_id = ?", userId).Error; err != nil {
+	query := r.db.Preload("Report").Preload("Report.Identities").Order("last_accessed_at DESC")
+
+	owners := make(map[uuid.UUID]string)
+	if includeOrganization {
+		orgId, err := userOrganization(r.db, userId)
+		if err != nil {
+			return nil, ErrReportAccessFailed
+		}
+
+		var members []schema.OrganizationMember
+		if err := r.db.Find(&members, "organization_id = ?", orgId).Error; err != nil {
+			slog.Error("error listing organization members", "organization_id", orgId, "error", err)
+			return nil, ErrReportAccessFailed
+		}
+
+		memberIds := make([]uuid.UUID, 0, len(members))
+		for _, member := range members {
+			owners[member.UserId] = member.Email
+			if member.UserId != userId {
+				memberIds = append(memberIds, member.UserId)
+			}
+		}
+
+		query = query.Where("user_id = ? OR (shared_access != '' AND user_id IN ?)", userId, memberIds)
+	} else {
+		query = query.Where("user_id = ?", userId)
+	}
+
+	if err := query.Find(&reports).Error; err != nil {
 		slog.Error("error finding list of reports ")
 		return nil, ErrReportAccessFailed
 	}
@@ -116,34 +167,89 @@ func (r *ReportManager) ListAuthorReports(userId uuid.UUID) ([]api.Report, error
 		if err != nil {
 			return nil, ErrReportAccessFailed
 		}
+		res.OwnerEmail = owners[report.UserId]
 		results = append(results, res)
 	}
 
 	return results, nil
 }
 
-func createOrGetAuthorReport(txn *gorm.DB, authorId, authorName, source, affiliations, researchInterests string, forUniversityReport bool) (schema.AuthorReport, error) {
+// The author that a report is created for.
+type AuthorReportParams struct {
+	AuthorId   string
+	AuthorName string
+	Source     string
+	// Optional, if set an existing report for the ORCID is reused even if the
+	// author id it resolves to has changed.
+	Orcid string
+
+	// Only used by unstructured reports.
+	Affiliations      string
+	ResearchInterests string
+	UnstructuredText  string
+
+	// The profiles that are combined in a merged report.
+	Identities []api.AuthorIdentity
+
+	// 0 means all works since EarliestReportDate are included.
+	LookbackYears int
+}
+
+// The organization is only used for the monitoring labels. The queue group is
+// the id of the user or university report that the report is created for.
+func createOrGetAuthorReport(txn *gorm.DB, params AuthorReportParams, forUniversityReport bool, organization, queueGroup string) (schema.AuthorReport, error) {
+	priority := schema.ReportPriorityInteractive
+	if forUniversityReport {
+		priority = schema.ReportPriorityUniversity
+	}
+
+	// Reports with different lookback windows are kept distinct since they have
+	// different start dates for the works they process.
+	query := txn.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Where("lookback_years = ?", params.LookbackYears)
+	if params.Orcid != "" {
+		// The ORCID is checked in addition to the author id so that an existing
+		// report is still found if the author id the ORCID resolves to changes.
+		query = query.Where("(author_id = ? OR orcid = ?) AND source = ? AND for_university_report = ?", params.AuthorId, params.Orcid, params.Source, forUniversityReport)
+	} else {
+		query = query.Where("author_id = ? AND source = ? AND for_university_report = ?", params.AuthorId, params.Source, forUniversityReport)
+	}
+
 	var report schema.AuthorReport
-	result := txn.Clauses(clause.Locking{Strength: "UPDATE"}).
-		Limit(1).
-		Find(&report, "author_id = ? AND source = ? AND for_university_report = ?", authorId, source, forUniversityReport)
+	result := query.Find(&report)
 	if result.Error != nil {
 		slog.Error("error checking for existing author report", "error", result.Error)
 		return schema.AuthorReport{}, ErrReportCreationFailed
 	}
 
 	if result.RowsAffected == 0 {
+		reportId := uuid.New()
+
+		reportIdentities := make([]schema.AuthorReportIdentity, 0, len(params.Identities))
+		for _, identity := range params.Identities {
+			reportIdentities = append(reportIdentities, schema.AuthorReportIdentity{
+				ReportId: reportId,
+				Source:   identity.Source,
+				AuthorId: identity.AuthorId,
+			})
+		}
+
 		report = schema.AuthorReport{
-			Id:                  uuid.New(),
+			Id:                  reportId,
 			LastUpdatedAt:       EarliestReportDate,
-			AuthorId:            authorId,
-			AuthorName:          authorName,
-			Source:              source,
-			Affiliations:        affiliations,
-			ResearchInterests:   researchInterests,
+			AuthorId:            params.AuthorId,
+			AuthorName:          params.AuthorName,
+			Source:              params.Source,
+			Orcid:               params.Orcid,
+			Affiliations:        params.Affiliations,
+			ResearchInterests:   params.ResearchInterests,
+			UnstructuredText:    params.UnstructuredText,
+			LookbackYears:       params.LookbackYears,
 			Status:              schema.ReportQueued,
 			StatusUpdatedAt:     time.Now().UTC(),
 			ForUniversityReport: forUniversityReport,
+			Priority:            priority,
+			QueueGroup:          queueGroup,
+			Identities:          reportIdentities,
 		}
 
 		if err := txn.Create(&report).Error; err != nil {
@@ -151,60 +257,93 @@ func createOrGetAuthorReport(txn *gorm.DB, authorId, authorName, source, affilia
 			return schema.AuthorReport{}, ErrReportCreationFailed
 		}
 	} else {
+		if params.Orcid != "" && report.Orcid == "" {
+			// The report was originally created without the ORCID (i.e. from a name search).
+			if err := txn.Model(&report).Update("orcid", params.Orcid).Error; err != nil {
+				slog.Error("error updating author report orcid", "error", err)
+				return schema.AuthorReport{}, ErrReportCreationFailed
+			}
+		}
+
+		// A report that is waiting in the queue at a lower priority (i.e. for a
+		// refresh) is moved ahead when it is requested again.
+		if report.Status == schema.ReportQueued && report.Priority < priority {
+			if err := txn.Model(&report).Updates(map[string]any{"priority": priority, "queue_group": queueGroup}).Error; err != nil {
+				slog.Error("error updating author report priority", "error", err)
+				return schema.AuthorReport{}, ErrReportCreationFailed
+			}
+		}
+
 		if forUniversityReport {
-			monitoring.UniAuthorReportsFoundInCache.WithLabelValues("TODO ORG").Inc()
+			monitoring.UniAuthorReportsFoundInCache.WithLabelValues(organization).Inc()
 		} else {
-			monitoring.AuthorReportsFoundInCache.WithLabelValues("TODO ORG").Inc()
+			monitoring.AuthorReportsFoundInCache.WithLabelValues(organization).Inc()
 		}
 	}
 
 	if forUniversityReport {
-		monitoring.UniAuthorReportsCreated.WithLabelValues("TODO ORG").Inc()
+		monitoring.UniAuthorReportsCreated.WithLabelValues(organization).Inc()
 	} else {
-		monitoring.AuthorReportsCreated.WithLabelValues("TODO ORG").Inc()
+		monitoring.AuthorReportsCreated.WithLabelValues(organization).Inc()
 	}
 
 	return report, nil
 }
 
-func (r *ReportManager) CreateAuthorReport(userId uuid.UUID, authorId, authorName, source, affiliations, researchInterests string) (uuid.UUID, error) {
-	var userReport schema.UserAuthorReport
-	var userReportId uuid.UUID
+// Creates the user's report for the author, or updates the last access time if
+// the user already has the report.
+func (r *ReportManager) createUserAuthorReport(txn *gorm.DB, userId uuid.UUID, params AuthorReportParams) (uuid.UUID, error) {
 	now := time.Now().UTC()
 
-	err := r.db.Transaction(func(txn *gorm.DB) error {
-		report, err := createOrGetAuthorReport(txn, authorId, authorName, source, affiliations, researchInterests, false /*forUniversityReport*/)
-		if err != nil {
-			return err
-		}
+	report, err := createOrGetAuthorReport(txn, params, false /*forUniversityReport*/, organizationLabel(txn, userId), userId.String())
+	if err != nil {
+		return uuid.Nil, err
+	}
 
-		result := txn.Where("user_id = ? AND report_id = ?", userId, report.Id).Limit(1).Find(&userReport)
-		if result.Error != nil {
-			slog.Error("error finding existing user author report", "error", result.Error)
-			return ErrReportCreationFailed
+	var userReport schema.UserAuthorReport
+	result := txn.Where("user_id = ? AND report_id = ?", userId, report.Id).Limit(1).Find(&userReport)
+	if result.Error != nil {
+		slog.Error("error finding existing user author report", "error", result.Error)
+		return uuid.Nil, ErrReportCreationFailed
+	}
+
+	if result.RowsAffected == 0 {
+		// Only new reports count towards the quota, requesting a report the
+		// user already has just updates the last access time.
+		if err := consumeQuota(txn, r.quotas, userId, schema.UsageAuthorReports); err != nil {
+			return uuid.Nil, err
 		}
 
-		if result.RowsAffected == 0 {
-			userReportId = uuid.New()
-			userReport = schema.UserAuthorReport{
-				Id:             userReportId,
-				UserId:         userId,
-				LastAccessedAt: now,
-				ReportId:       report.Id,
-			}
-			if err := txn.Create(&userReport).Error; err != nil {
-				slog.Error("error creating new user author report", "error", err)
-				return ErrReportCreationFailed
-			}
-		} else {
-			userReport.LastAccessedAt = now
-			if err := txn.Save(&userReport).Error; err != nil {
-				slog.Error("error updating user author report", "error", err)
-				return ErrReportCreationFailed
-			}
-			userReportId = userReport.Id
+		userReport = schema.UserAuthorReport{
+			Id:             uuid.New(),
+			UserId:         userId,
+			LastAccessedAt: now,
+			ReportId:       report.Id,
+		}
+		if err := txn.Create(&userReport).Error; err != nil {
+			slog.Error("error creating new user author report", "error", err)
+			return uuid.Nil, ErrReportCreationFailed
 		}
+	} else {
+		userReport.LastAccessedAt = now
+		if err := txn.Save(&userReport).Error; err != nil {
+			slog.Error("error updating user author report", "error", err)
+			return uuid.Nil, ErrReportCreationFailed
+		}
+	}
+
+	return userReport.Id, nil
+}
 
+func (r *ReportManager) CreateAuthorReport(userId uuid.UUID, params AuthorReportParams) (uuid.UUID, error) {
+	var userReportId uuid.UUID
+
+	err := r.db.Transaction(func(txn *gorm.DB) error {
+		id, err := r.createUserAuthorReport(txn, userId, params)
+		if err != nil {
+			return err
+		}
+		userReportId = id
 		return nil
 	})
 
@@ -220,7 +359,7 @@ func (r *ReportManager) CheckForStaleAuthorReports() error {
 	if err := r.db.Model(&schema.AuthorReport{}).
 		Where("status != ? AND status != ? AND for_university_report = ?", schema.ReportInProgress, schema.ReportQueued, false).
 		Where("last_updated_at < ?", time.Now().UTC().Add(-r.authorReportUpdateInterval)).
-		Updates(map[string]any{"status": schema.ReportQueued, "status_updated_at": time.Now().UTC()}).Error; err != nil {
+		Updates(map[string]any{"status": schema.ReportQueued, "status_updated_at": time.Now().UTC(), "priority": schema.ReportPriorityRefresh}).Error; err != nil {
 		slog.Error("error checking for stale author reports", "error", err)
 		return ErrReportAccessFailed
 	}
@@ -233,7 +372,7 @@ func (r *ReportManager) CheckForStaleAuthorReports() error {
 			Where("user_author_reports.report_id = author_reports.id").
 			Where("author_report_hooks.last_ran_at < NOW() - (author_report_hooks.interval || ' seconds')::interval"),
 		).
-		Updates(map[string]any{"status": schema.ReportQueued, "status_updated_at": time.Now().UTC()}).Error; err != nil {
+		Updates(map[string]any{"status": schema.ReportQueued, "status_updated_at": time.Now().UTC(), "priority": schema.ReportPriorityRefresh}).Error; err != nil {
 		slog.Error("error checking report updates required by hooks", "error", err)
 		return ErrReportAccessFailed
 	}
@@ -242,7 +381,7 @@ func (r *ReportManager) CheckForStaleAuthorReports() error {
 	if err := r.db.Model(&schema.AuthorReport{}).
 		Where("status != ? AND status != ? AND for_university_report = ?", schema.ReportInProgress, schema.ReportQueued, true).
 		Where("last_updated_at < ?", time.Now().UTC().Add(-r.universityReportUpdateInterval)).
-		Updates(map[string]any{"status": schema.ReportQueued, "status_updated_at": time.Now().UTC()}).Error; err != nil {
+		Updates(map[string]any{"status": schema.ReportQueued, "status_updated_at": time.Now().UTC(), "priority": schema.ReportPriorityRefresh}).Error; err != nil {
 		slog.Error("error checking for stale author reports for university reports", "error", err)
 		return ErrReportAccessFailed
 	}
@@ -259,10 +398,44 @@ func (r *ReportManager) CheckForStaleAuthorReports() error {
 	return nil
 }
 
+func countReportsByStatus(txn *gorm.DB, model any) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := txn.Model(model).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// Returns the number of author and university reports with each status. The
+// author reports include the reports for the authors in university reports.
+func (r *ReportManager) GetReportQueueStats() (api.ReportQueueStats, error) {
+	authorReports, err := countReportsByStatus(r.db, &schema.AuthorReport{})
+	if err != nil {
+		slog.Error("error counting author reports by status", "error", err)
+		return api.ReportQueueStats{}, ErrReportAccessFailed
+	}
+
+	universityReports, err := countReportsByStatus(r.db, &schema.UniversityReport{})
+	if err != nil {
+		slog.Error("error counting university reports by status", "error", err)
+		return api.ReportQueueStats{}, ErrReportAccessFailed
+	}
+
+	return api.ReportQueueStats{AuthorReports: authorReports, UniversityReports: universityReports}, nil
+}
+
 func (r *ReportManager) GetAuthorReport(userId, reportId uuid.UUID) (api.Report, error) {
 	var report schema.UserAuthorReport
 
-	if err := r.db.Preload("Report").Preload("Report.Flags").
+	if err := r.db.Preload("Report").Preload("Report.Flags").Preload("Report.Identities").Preload("Triage").Preload("FlagDisclosures").
 		First(&report, "id = ?", reportId).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return api.Report{}, ErrReportNotFound
@@ -271,11 +444,39 @@ func (r *ReportManager) GetAuthorReport(userId, reportId uuid.UUID) (api.Report,
 		return api.Report{}, ErrReportAccessFailed
 	}
 
+	if err := checkUserReportAccess(r.db, userId, report, readAccess); err != nil {
+		return api.Report{}, err
+	}
+
+	output, err := ConvertReport(report)
+	if err != nil {
+		return api.Report{}, err
+	}
+
+	if err := ApplyFlagSuppressions(r.db, userId, &output); err != nil {
+		return api.Report{}, err
+	}
+
 	if report.UserId != userId {
-		return api.Report{}, ErrUserCannotAccessReport
+		owner, err := getOrganizationMember(r.db, report.UserId)
+		if err != nil {
+			return api.Report{}, ErrReportAccessFailed
+		}
+		if owner != nil {
+			output.OwnerEmail = owner.Email
+		}
+	}
+
+	if output.Status == schema.ReportCompleted {
+		// The score is computed when the report is loaded so that changes to the
+		// weights apply to existing reports.
+		output.Risk, err = r.ScoreReportContent(userId, output.Content)
+		if err != nil {
+			return api.Report{}, err
+		}
 	}
 
-	return ConvertReport(report)
+	return output, nil
 }
 
 func (r *ReportManager) DeleteAuthorReport(userId, reportId uuid.UUID) error {
@@ -299,30 +500,59 @@ type ReportUpdateTask struct {
 	EndDate             time.Time
 	ForUniversityReport bool
 	Affiliations        string
+	UnstructuredText    string
+	Identities          []api.AuthorIdentity
+
+	// The time of the last completed update of the report, zero if the report has
+	// not been completed before. Only works that are added or updated since then
+	// need to be checked by the flaggers that have not changed.
+	UpdatedSince time.Time
+	// The versions of the flaggers the last time they were run on the report.
+	FlaggerVersions map[string]string
+	// The versions of the watchlists the last time the report was processed.
+	WatchlistVersions map[string]string
 }
 
+// Reports are picked by priority, then from the queue group that was least
+// recently scheduled, and then in the order they were queued. Reports queued
+// by users are picked before reports for university reports with the same
+// priority. Locked reports are skipped so that concurrent workers do not wait
+// on each other.
 func (r *ReportManager) findNextAuthorReport(txn *gorm.DB) (*schema.AuthorReport, error) {
 	var report schema.AuthorReport
-	for _, for_university_report := range [2]bool{false, true} {
-		result := txn.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Limit(1).Order("status_updated_at ASC").
-			Where("for_university_report = ?", for_university_report).
-			Where("status = ?", schema.ReportQueued).
-			Find(&report)
-		if result.Error != nil {
-			slog.Error("error getting next author report from queue", "error", result.Error)
-			return nil, ErrReportAccessFailed
-		}
+	result := txn.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: clause.CurrentTable}, Options: clause.LockingOptionsSkipLocked}).
+		Select("author_reports.*").
+		Joins("LEFT JOIN report_queue_groups ON report_queue_groups.name = author_reports.queue_group").
+		Where("author_reports.status = ?", schema.ReportQueued).
+		Order("author_reports.priority DESC").
+		Order("author_reports.for_university_report ASC").
+		Order("report_queue_groups.last_scheduled_at IS NOT NULL, report_queue_groups.last_scheduled_at ASC").
+		Order("author_reports.status_updated_at ASC").
+		Limit(1).
+		Find(&report)
+	if result.Error != nil {
+		slog.Error("error getting next author report from queue", "error", result.Error)
+		return nil, ErrReportAccessFailed
+	}
 
-		if result.RowsAffected == 1 {
-			return &report, nil
-		}
+	if result.RowsAffected == 0 {
+		return nil, nil
 	}
-	return nil, nil
+
+	group := schema.ReportQueueGroup{Name: report.QueueGroup, LastScheduledAt: time.Now().UTC()}
+	if err := txn.Clauses(clause.OnConflict{UpdateAll: true}).Create(&group).Error; err != nil {
+		slog.Error("error updating report queue group", "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	return &report, nil
 }
 
 func (r *ReportManager) GetNextAuthorReport() (*ReportUpdateTask, error) {
 	var report *schema.AuthorReport
+	var identities []schema.AuthorReportIdentity
+	var flaggers []schema.AuthorReportFlagger
+	var watchlists []schema.AuthorReportWatchlist
 
 	err := r.db.Transaction(func(txn *gorm.DB) error {
 		var err error
@@ -332,11 +562,27 @@ func (r *ReportManager) GetNextAuthorReport() (*ReportUpdateTask, error) {
 		}
 
 		if report != nil {
-			updates := map[string]any{"status": schema.ReportInProgress, "status_updated_at": time.Now().UTC()}
+			// The progress of the previous time the report was processed is cleared.
+			updates := map[string]any{"status": schema.ReportInProgress, "status_updated_at": time.Now().UTC(), "progress": nil}
 			if err := txn.Model(report).Updates(updates).Error; err != nil {
 				slog.Error("error updating author report status to in progress", "error", err)
 				return ErrReportAccessFailed
 			}
+
+			if err := txn.Find(&identities, "report_id = ?", report.Id).Error; err != nil {
+				slog.Error("error getting author report identities", "error", err)
+				return ErrReportAccessFailed
+			}
+
+			if err := txn.Find(&flaggers, "report_id = ?", report.Id).Error; err != nil {
+				slog.Error("error getting author report flaggers", "error", err)
+				return ErrReportAccessFailed
+			}
+
+			if err := txn.Find(&watchlists, "report_id = ?", report.Id).Error; err != nil {
+				slog.Error("error getting author report watchlists", "error", err)
+				return ErrReportAccessFailed
+			}
 		}
 
 		return nil
@@ -347,15 +593,39 @@ func (r *ReportManager) GetNextAuthorReport() (*ReportUpdateTask, error) {
 	}
 
 	if report != nil {
+		now := time.Now().UTC()
+
+		// Works are fetched for the whole lookback window since works that were
+		// published before the last update can still be added or updated later.
+		var updatedSince time.Time
+		if report.LastUpdatedAt.After(EarliestReportDate) {
+			updatedSince = report.LastUpdatedAt
+		}
+
+		flaggerVersions := make(map[string]string, len(flaggers))
+		for _, flagger := range flaggers {
+			flaggerVersions[flagger.Flagger] = flagger.Version
+		}
+
+		watchlistVersions := make(map[string]string, len(watchlists))
+		for _, watchlist := range watchlists {
+			watchlistVersions[watchlist.Watchlist] = watchlist.Version
+		}
+
 		return &ReportUpdateTask{
 			Id:                  report.Id,
 			AuthorId:            report.AuthorId,
 			AuthorName:          report.AuthorName,
 			Source:              report.Source,
-			StartDate:           report.LastUpdatedAt,
-			EndDate:             time.Now().UTC(),
+			StartDate:           lookbackStartDate(report.LookbackYears, now),
+			EndDate:             now,
 			ForUniversityReport: report.ForUniversityReport,
 			Affiliations:        report.Affiliations,
+			UnstructuredText:    report.UnstructuredText,
+			Identities:          convertIdentities(identities),
+			UpdatedSince:        updatedSince,
+			FlaggerVersions:     flaggerVersions,
+			WatchlistVersions:   watchlistVersions,
 		}, nil
 	}
 
@@ -363,10 +633,77 @@ func (r *ReportManager) GetNextAuthorReport() (*ReportUpdateTask, error) {
 }
 
 func (r *ReportManager) UpdateAuthorReport(id uuid.UUID, status string, updateTime time.Time, updateFlags []api.Flag) error {
+	return r.UpdateAuthorReportFlags(id, status, updateTime, "", "", updateFlags)
+}
+
+// The same as UpdateAuthorReport, but the flags are recorded as being found by
+// the given version of the flagger.
+func (r *ReportManager) UpdateAuthorReportFlags(id uuid.UUID, status string, updateTime time.Time, flagger, flaggerVersion string, updateFlags []api.Flag) error {
+	newFlags := make([]schema.AuthorFlag, 0, len(updateFlags))
+	for _, flag := range updateFlags {
+		data, err := json.Marshal(flag)
+		if err != nil {
+			return fmt.Errorf("error serializing flag: %w", err)
+		}
+
+		flagHash := flag.Hash()
+
+		date, dateValid := flag.Date()
+
+		newFlags = append(newFlags, schema.AuthorFlag{
+			ReportId:       id,
+			FlagHash:       hex.EncodeToString(flagHash[:]),
+			FlagType:       flag.Type(),
+			Date:           sql.NullTime{Time: date, Valid: dateValid},
+			Data:           data,
+			Flagger:        flagger,
+			FlaggerVersion: flaggerVersion,
+		})
+	}
+
+	var disclosures []schema.FlagDisclosure
+	if len(newFlags) > 0 {
+		var err error
+		disclosures, err = r.disclosureMatcher.findNewFlagDisclosures(r.db, id, newFlags)
+		if err != nil {
+			slog.Error("error checking disclosures of new flags", "author_report_id", id, "error", err)
+			return ErrReportAccessFailed
+		}
+	}
+
 	return r.db.Transaction(func(txn *gorm.DB) error {
+		// Flags that are new to the report are attributed to the watchlist update
+		// that requeued the report, if any.
+		var watchlistUpdate string
+		if len(newFlags) > 0 || status == schema.ReportCompleted {
+			if err := txn.Model(&schema.AuthorReport{}).Where("id = ?", id).Pluck("watchlist_update", &watchlistUpdate).Error; err != nil {
+				slog.Error("error getting author report watchlist update", "author_report_id", id, "error", err)
+				return ErrReportAccessFailed
+			}
+		}
+
 		updates := map[string]any{"status": status, "status_updated_at": updateTime}
 		if status == schema.ReportCompleted {
 			updates["last_updated_at"] = updateTime
+			updates["watchlist_update"] = ""
+
+			requeueFor, err := watchlistUpdateToRequeue(txn, id, watchlistUpdate)
+			if err != nil {
+				slog.Error("error checking author report watchlist versions", "author_report_id", id, "error", err)
+				return ErrReportAccessFailed
+			}
+			if requeueFor != "" {
+				updates["status"] = schema.ReportQueued
+				updates["status_updated_at"] = time.Now().UTC()
+				updates["priority"] = schema.ReportPriorityRefresh
+				updates["watchlist_update"] = requeueFor
+
+				if err := txn.Model(&schema.WatchlistUpdate{}).Where("id = ?", requeueFor).Update("reports_requeued", gorm.Expr("reports_requeued + 1")).Error; err != nil {
+					slog.Error("error updating watchlist update", "watchlist_update_id", requeueFor, "error", err)
+					return ErrReportAccessFailed
+				}
+				slog.Info("author report was processed with other watchlist versions, requeued report", "author_report_id", id, "watchlist_update_id", requeueFor)
+			}
 		}
 
 		result := txn.Model(&schema.AuthorReport{Id: id}).Updates(updates)
@@ -380,32 +717,56 @@ func (r *ReportManager) UpdateAuthorReport(id uuid.UUID, status string, updateTi
 			return ErrReportNotFound
 		}
 
-		if len(updateFlags) == 0 {
+		if len(newFlags) == 0 {
 			return nil
 		}
 
-		newFlags := make([]schema.AuthorFlag, 0)
-		for _, flag := range updateFlags {
-			data, err := json.Marshal(flag)
-			if err != nil {
-				return fmt.Errorf("error serializing flag: %w", err)
-			}
+		for i := range newFlags {
+			newFlags[i].WatchlistUpdate = watchlistUpdate
+		}
 
-			flagHash := flag.Hash()
+		// The watchlist update of flags that are already in the report is kept.
+		if err := txn.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "report_id"}, {Name: "flag_hash"}},
+			DoUpdates: clause.AssignmentColumns([]string{"flag_type", "date", "data", "flagger", "flagger_version"}),
+		}).Create(&newFlags).Error; err != nil {
+			slog.Error("error adding new flags to author report", "author_report_id", id, "error", err)
+			return ErrReportAccessFailed
+		}
 
-			date, dateValid := flag.Date()
+		if err := saveNewFlagDisclosures(txn, disclosures); err != nil {
+			slog.Error("error saving disclosures of new flags", "author_report_id", id, "error", err)
+			return ErrReportAccessFailed
+		}
 
-			newFlags = append(newFlags, schema.AuthorFlag{
-				ReportId: id,
-				FlagHash: hex.EncodeToString(flagHash[:]),
-				FlagType: flag.Type(),
-				Date:     sql.NullTime{Time: date, Valid: dateValid},
-				Data:     data,
-			})
+		return invalidateAuthorUniversityReportSummaries(txn, id)
+	})
+}
+
+// Records the versions of the flaggers that were run successfully on the report.
+// The records for flaggers that failed are removed so that they are run on all
+// of the works in the next update. Flaggers that are in neither keep their
+// previous versions.
+func (r *ReportManager) RecordAuthorReportFlaggers(id uuid.UUID, versions map[string]string, failed []string, ranAt time.Time) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		if len(failed) > 0 {
+			if err := txn.Where("report_id = ? AND flagger IN ?", id, failed).Delete(&schema.AuthorReportFlagger{}).Error; err != nil {
+				slog.Error("error removing failed author report flaggers", "author_report_id", id, "error", err)
+				return ErrReportAccessFailed
+			}
 		}
 
-		if err := txn.Save(&newFlags).Error; err != nil {
-			slog.Error("error adding new flags to author report", "author_report_id", id, "error", err)
+		if len(versions) == 0 {
+			return nil
+		}
+
+		flaggers := make([]schema.AuthorReportFlagger, 0, len(versions))
+		for flagger, version := range versions {
+			flaggers = append(flaggers, schema.AuthorReportFlagger{ReportId: id, Flagger: flagger, Version: version, RanAt: ranAt})
+		}
+
+		if err := txn.Clauses(clause.OnConflict{UpdateAll: true}).Create(&flaggers).Error; err != nil {
+			slog.Error("error recording author report flaggers", "author_report_id", id, "error", err)
 			return ErrReportAccessFailed
 		}
 
@@ -413,6 +774,46 @@ func (r *ReportManager) UpdateAuthorReport(id uuid.UUID, status string, updateTi
 	})
 }
 
+// Returns an in progress report to the queue, this is used when a worker shuts
+// down before the report is complete so that the report does not need to wait
+// for the report timeout before it is processed again. The status update time
+// is left as the time the report was started so that the report stays ahead of
+// reports that were queued after it.
+func (r *ReportManager) RequeueAuthorReport(id uuid.UUID) error {
+	result := r.db.Model(&schema.AuthorReport{Id: id}).
+		Where("status = ?", schema.ReportInProgress).
+		Update("status", schema.ReportQueued)
+	if result.Error != nil {
+		slog.Error("error requeueing author report", "author_report_id", id, "error", result.Error)
+		return ErrReportAccessFailed
+	}
+
+	if result.RowsAffected != 1 {
+		return ErrReportNotFound
+	}
+
+	return nil
+}
+
+func (r *ReportManager) UpdateAuthorReportProgress(id uuid.UUID, progress api.ReportProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("error serializing report progress: %w", err)
+	}
+
+	result := r.db.Model(&schema.AuthorReport{Id: id}).Update("progress", data)
+	if result.Error != nil {
+		slog.Error("error updating author report progress", "author_report_id", id, "error", result.Error)
+		return ErrReportAccessFailed
+	}
+
+	if result.RowsAffected != 1 {
+		return ErrReportNotFound
+	}
+
+	return nil
+}
+
 func (r *ReportManager) CreateAuthorReportHook(userId, reportId uuid.UUID, action string, data []byte, interval int) error {
 	return r.db.Transaction(func(txn *gorm.DB) error {
 		var userReport schema.UserAuthorReport
@@ -446,22 +847,81 @@ func (r *ReportManager) CreateAuthorReportHook(userId, reportId uuid.UUID, actio
 	})
 }
 
-func flagsToReportContent(flags []schema.AuthorFlag) (map[string][]api.Flag, error) {
+// Returns the earliest date that works are included from for a report with the
+// given lookback window. A lookback of 0 years means the report is not limited.
+func lookbackStartDate(lookbackYears int, now time.Time) time.Time {
+	if lookbackYears <= 0 {
+		return EarliestReportDate
+	}
+	start := time.Date(now.Year()-lookbackYears, now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if start.Before(EarliestReportDate) {
+		return EarliestReportDate
+	}
+	return start
+}
+
+func flagsToReportContent(flags []schema.AuthorFlag, startDate time.Time, triage []schema.FlagTriage, disclosures []schema.FlagDisclosure) (map[string][]api.Flag, error) {
 	content := make(map[string][]api.Flag)
 
+	triageByHash := make(map[string]*api.FlagTriage, len(triage))
+	for _, t := range triage {
+		triageByHash[t.FlagHash] = convertTriage(t)
+	}
+
+	disclosed := make(map[string][]api.DisclosureEvidence, len(disclosures))
+	for _, disclosure := range disclosures {
+		disclosed[disclosure.FlagHash] = append(disclosed[disclosure.FlagHash], api.DisclosureEvidence{
+			DocumentId: disclosure.DocumentId,
+			Snippet:    disclosure.Snippet,
+			Page:       disclosure.Page,
+			Confidence: disclosure.Confidence,
+		})
+	}
+
 	for _, flag := range flags {
+		// Flags that were found while they were within the report's lookback window
+		// are omitted once they fall outside of the window.
+		if flag.Date.Valid && flag.Date.Time.Before(startDate) {
+			continue
+		}
+
 		output, err := api.ParseFlag(flag.FlagType, flag.Data)
 		if err != nil {
 			return nil, err
 		}
+		output.SetFlagHash(flag.FlagHash)
+		output.SetTriage(triageByHash[flag.FlagHash])
+		for _, evidence := range disclosed[flag.FlagHash] {
+			output.MarkDisclosed(evidence)
+		}
 		content[output.Type()] = append(content[output.Type()], output)
 	}
 
 	return content, nil
 }
 
+func convertIdentities(identities []schema.AuthorReportIdentity) []api.AuthorIdentity {
+	if len(identities) == 0 {
+		return nil
+	}
+
+	output := make([]api.AuthorIdentity, 0, len(identities))
+	for _, identity := range identities {
+		output = append(output, api.AuthorIdentity{AuthorId: identity.AuthorId, Source: identity.Source})
+	}
+
+	slices.SortFunc(output, api.CompareAuthorIdentities)
+
+	return output
+}
+
 func ConvertReport(report schema.UserAuthorReport) (api.Report, error) {
-	content, err := flagsToReportContent(report.Report.Flags)
+	content, err := flagsToReportContent(report.Report.Flags, lookbackStartDate(report.Report.LookbackYears, time.Now().UTC()), report.Triage, report.FlagDisclosures)
+	if err != nil {
+		return api.Report{}, err
+	}
+
+	progress, err := convertReportProgress(report.Report.Progress, report.Report.Status)
 	if err != nil {
 		return api.Report{}, err
 	}
@@ -472,13 +932,37 @@ func ConvertReport(report schema.UserAuthorReport) (api.Report, error) {
 		AuthorId:          report.Report.AuthorId,
 		AuthorName:        report.Report.AuthorName,
 		Source:            report.Report.Source,
+		Orcid:             report.Report.Orcid,
 		Affiliations:      report.Report.Affiliations,
 		ResearchInterests: report.Report.ResearchInterests,
+		Identities:        convertIdentities(report.Report.Identities),
+		LookbackYears:     report.Report.LookbackYears,
 		Status:            report.Report.Status,
+		OwnerId:           report.UserId,
+		SharedAccess:      report.SharedAccess,
 		Content:           content,
+		Progress:          progress,
 	}, nil
 }
 
+func convertReportProgress(data []byte, status string) (*api.ReportProgress, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var progress api.ReportProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("error parsing report progress: %w", err)
+	}
+
+	if status == schema.ReportInProgress && progress.EstimatedCompletionAt != nil {
+		remaining := max(int(time.Until(*progress.EstimatedCompletionAt).Seconds()), 0)
+		progress.EstimatedSecondsRemaining = &remaining
+	}
+
+	return &progress, nil
+}
+
 func (r *ReportManager) ListUniversityReports(userId uuid.UUID) ([]api.UniversityReport, error) {
 	var reports []schema.UserUniversityReport
 
@@ -504,19 +988,25 @@ func (r *ReportManager) ListUniversityReports(userId uuid.UUID) ([]api.Universit
 	return results, nil
 }
 
-func (r *ReportManager) CreateUniversityReport(userId uuid.UUID, universityId, universityName, universityLocation string) (uuid.UUID, error) {
+func (r *ReportManager) CreateUniversityReport(userId uuid.UUID, universityId, universityName, universityLocation string, discovery api.UniversityAuthorDiscovery) (uuid.UUID, error) {
 	var userReport schema.UserUniversityReport
 	var userReportId uuid.UUID
 	now := time.Now().UTC()
 
+	discovery = withDefaultUniversityAuthorDiscovery(discovery)
+
 	err := r.db.Transaction(func(txn *gorm.DB) error {
 		var report schema.UniversityReport
-		result := txn.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&report, "university_id = ?", universityId)
+		result := txn.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).
+			Where("lookback_years = ? AND min_works = ? AND require_last_known_institution = ?", discovery.LookbackYears, discovery.MinWorks, discovery.RequireLastKnownInstitution).
+			Find(&report, "university_id = ?", universityId)
 		if result.Error != nil {
 			slog.Error("error checking for existing university report", "error", result.Error)
 			return ErrReportCreationFailed
 		}
 
+		organization := organizationLabel(txn, userId)
+
 		if result.RowsAffected == 0 {
 			reportId := uuid.New()
 			report = schema.UniversityReport{
@@ -525,8 +1015,13 @@ func (r *ReportManager) CreateUniversityReport(userId uuid.UUID, universityId, u
 				UniversityId:       universityId,
 				UniversityName:     universityName,
 				UniversityLocation: universityLocation,
-				StatusUpdatedAt:    time.Now().UTC(),
-				Status:             schema.ReportQueued,
+
+				LookbackYears:               discovery.LookbackYears,
+				MinWorks:                    discovery.MinWorks,
+				RequireLastKnownInstitution: discovery.RequireLastKnownInstitution,
+
+				StatusUpdatedAt: time.Now().UTC(),
+				Status:          schema.ReportQueued,
 			}
 
 			if err := txn.Create(&report).Error; err != nil {
@@ -534,10 +1029,10 @@ func (r *ReportManager) CreateUniversityReport(userId uuid.UUID, universityId, u
 				return ErrReportCreationFailed
 			}
 		} else {
-			monitoring.UniAuthorReportsFoundInCache.WithLabelValues("TODO ORG").Inc()
+			monitoring.UniAuthorReportsFoundInCache.WithLabelValues(organization).Inc()
 		}
 
-		monitoring.UniAuthorReportsCreated.WithLabelValues("TODO ORG").Inc()
+		monitoring.UniAuthorReportsCreated.WithLabelValues(organization).Inc()
 
 		result = txn.Where("user_id = ? AND report_id = ?", userId, report.Id).
 			Limit(1).Find(&userReport)
@@ -547,6 +1042,10 @@ func (r *ReportManager) CreateUniversityReport(userId uuid.UUID, universityId, u
 		}
 
 		if result.RowsAffected == 0 {
+			if err := consumeQuota(txn, r.quotas, userId, schema.UsageUniversityReports); err != nil {
+				return err
+			}
+
 			userReportId = uuid.New()
 			userReport = schema.UserUniversityReport{
 				Id:             userReportId,
@@ -599,104 +1098,6 @@ func (r *ReportManager) CheckForStaleUniversityReports() error {
 	return nil
 }
 
-const (
-	// The last N years of flags that will be added to the university report.
-	yearsInUniversityReport = 5
-)
-
-func (r *ReportManager) GetUniversityReport(userId, reportId uuid.UUID) (api.UniversityReport, error) {
-	type universityAuthorFlags struct {
-		AuthorName string
-		AuthorId   string
-		Source     string
-		FlagType   string
-		Count      int
-	}
-
-	type authorReportStatus struct {
-		Status string
-		Count  int
-	}
-
-	var report schema.UserUniversityReport
-
-	var statusCounts []authorReportStatus
-	var flags []universityAuthorFlags
-
-	if err := r.db.Transaction(func(txn *gorm.DB) error {
-		if err := txn.Preload("Report").First(&report, "id = ?", reportId).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return ErrReportNotFound
-			}
-			slog.Error("error getting user university report", "university_report_id", reportId, "error", err)
-			return ErrReportAccessFailed
-		}
-
-		if report.UserId != userId {
-			return ErrUserCannotAccessReport
-		}
-
-		if err := txn.Model(&schema.AuthorReport{}).
-			Select("author_reports.status, count(*) as count").
-			Joins("JOIN university_authors ON author_reports.id = university_authors.author_report_id AND university_authors.university_report_id = ?", report.ReportId).
-			Group("author_reports.status").
-			Find(&statusCounts).Error; err != nil {
-			slog.Error("error checking status for author reports linked to university report", "university_report_id", reportId, "error", err)
-			return ErrReportAccessFailed
-		}
-
-		// Note: it is ok to select author_reports.author_name/author_id/source even though
-		// they are not not part of the group by clause or have an aggregator becuase
-		// we are grouping by the primary key of the table they come from, so only a single value is possible.
-		if err := txn.Model(&schema.AuthorFlag{}).
-			Select("author_reports.author_name, author_reports.author_id, author_reports.source, author_flags.flag_type, count(*) as count").
-			Joins("JOIN author_reports ON author_flags.report_id = author_reports.id").
-			Joins("JOIN university_authors ON author_reports.id = university_authors.author_report_id AND university_authors.university_report_id = ?", report.ReportId).
-			Where("author_flags.date IS NULL OR author_flags.date > ?", time.Now().UTC().AddDate(-yearsInUniversityReport, 0, 0)).
-			Group("author_reports.id, author_flags.flag_type").
-			Find(&flags).Error; err != nil {
-			slog.Error("error querying flags for author reports linked to university report", "university_report_id", reportId, "error", err)
-			return ErrReportAccessFailed
-		}
-
-		return nil
-	}); err != nil {
-		return api.UniversityReport{}, err
-	}
-
-	content := api.UniversityReportContent{
-		Flags: make(map[string][]api.UniversityAuthorFlag),
-	}
-
-	for _, flag := range flags {
-		content.Flags[flag.FlagType] = append(content.Flags[flag.FlagType], api.UniversityAuthorFlag{
-			AuthorId:   flag.AuthorId,
-			AuthorName: flag.AuthorName,
-			Source:     flag.Source,
-			FlagCount:  flag.Count,
-		})
-	}
-
-	for _, flagList := range content.Flags {
-		sort.Slice(flagList, func(i, j int) bool {
-			if flagList[i].FlagCount == flagList[j].FlagCount {
-				return flagList[i].AuthorName < flagList[j].AuthorName
-			}
-			return flagList[i].FlagCount > flagList[j].FlagCount
-		})
-	}
-
-	for _, status := range statusCounts {
-		switch status.Status {
-		case schema.ReportCompleted, schema.ReportFailed:
-			content.AuthorsReviewed += status.Count
-		}
-		content.TotalAuthors += status.Count
-	}
-
-	return convertUniversityReport(report, content), nil
-}
-
 func (r *ReportManager) DeleteUniversityReport(userId, reportId uuid.UUID) error {
 	result := r.db.Delete(&schema.UserUniversityReport{}, "id = ? AND user_id = ?", reportId, userId)
 	if result.Error != nil {
@@ -714,6 +1115,7 @@ type UniversityReportUpdateTask struct {
 	UniversityId       string
 	UniversityName     string
 	UniversityLocation string
+	AuthorDiscovery    api.UniversityAuthorDiscovery
 	UpdateDate         time.Time
 }
 
@@ -757,6 +1159,7 @@ func (r *ReportManager) GetNextUniversityReport() (*UniversityReportUpdateTask,
 			UniversityId:       report.UniversityId,
 			UniversityName:     report.UniversityName,
 			UniversityLocation: report.UniversityLocation,
+			AuthorDiscovery:    universityAuthorDiscovery(report),
 			UpdateDate:         time.Now().UTC(),
 		}, nil
 	}
@@ -764,10 +1167,29 @@ func (r *ReportManager) GetNextUniversityReport() (*UniversityReportUpdateTask,
 	return nil, nil
 }
 
+// Returns an in progress university report to the queue, this is the same as
+// RequeueAuthorReport but for university reports.
+func (r *ReportManager) RequeueUniversityReport(id uuid.UUID) error {
+	result := r.db.Model(&schema.UniversityReport{Id: id}).
+		Where("status = ?", schema.ReportInProgress).
+		Update("status", schema.ReportQueued)
+	if result.Error != nil {
+		slog.Error("error requeueing university report", "university_report_id", id, "error", result.Error)
+		return ErrReportAccessFailed
+	}
+
+	if result.RowsAffected != 1 {
+		return ErrReportNotFound
+	}
+
+	return nil
+}
+
 type UniversityAuthorReport struct {
 	AuthorId   string
 	AuthorName string
 	Source     string
+	Department string
 }
 
 func (r *ReportManager) UpdateUniversityReport(id uuid.UUID, status string, updateTime time.Time, authors []UniversityAuthorReport) error {
@@ -788,21 +1210,38 @@ func (r *ReportManager) UpdateUniversityReport(id uuid.UUID, status string, upda
 		if status == schema.ReportCompleted {
 			updates["last_updated_at"] = updateTime
 
-			var authorReports []schema.AuthorReport
+			universityAuthors := make([]schema.UniversityAuthor, 0, len(authors))
 
 			for _, author := range authors {
-				report, err := createOrGetAuthorReport(txn, author.AuthorId, author.AuthorName, author.Source, "", "", true /*forUniversityReport*/)
+				report, err := createOrGetAuthorReport(txn, AuthorReportParams{AuthorId: author.AuthorId, AuthorName: author.AuthorName, Source: author.Source}, true /*forUniversityReport*/, noOrganizationLabel, id.String())
 				if err != nil {
 					slog.Error("error getting author report to add to university report", "author_id", author.AuthorId, "university_report_id", id, "error", err)
 					return err
 				}
-				authorReports = append(authorReports, report)
+				universityAuthors = append(universityAuthors, schema.UniversityAuthor{
+					UniversityReportId: id,
+					AuthorReportId:     report.Id,
+					Department:         author.Department,
+				})
 			}
 
-			if err := txn.Model(&schema.UniversityReport{Id: id}).Association("Authors").Replace(authorReports); err != nil {
-				slog.Error("error replacing author univerisity associations", "university_report_id", id, "error", err)
+			// The join table is updated directly instead of replacing the association
+			// so that the department of each author is stored.
+			if err := txn.Delete(&schema.UniversityAuthor{}, "university_report_id = ?", id).Error; err != nil {
+				slog.Error("error removing author univerisity associations", "university_report_id", id, "error", err)
 				return ErrReportAccessFailed
 			}
+
+			if len(universityAuthors) > 0 {
+				if err := txn.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(universityAuthors, 1000).Error; err != nil {
+					slog.Error("error adding author univerisity associations", "university_report_id", id, "error", err)
+					return ErrReportAccessFailed
+				}
+			}
+
+			if err := invalidateUniversityReportSummaries(txn, "university_report_id = ?", id); err != nil {
+				return err
+			}
 		}
 
 		if err := txn.Model(&report).Updates(updates).Error; err != nil {
@@ -822,7 +1261,10 @@ func convertUniversityReport(report schema.UserUniversityReport, content api.Uni
 		UniversityId:       report.Report.UniversityId,
 		UniversityName:     report.Report.UniversityName,
 		UniversityLocation: report.Report.UniversityLocation,
-		Status:             report.Report.Status,
-		Content:            content,
+
+		UniversityAuthorDiscovery: universityAuthorDiscovery(*report.Report),
+
+		Status:  report.Report.Status,
+		Content: content,
 	}
 }
@@ -1,10 +1,15 @@
 package reports_test
 
 import (
+	"encoding/hex"
+	"errors"
+	"maps"
 	"prism/prism/api"
 	"prism/prism/reports"
+	"prism/prism/reports/flaggers/eoc"
 	"prism/prism/schema"
 	"runtime"
+	"slices"
 	"testing"
 	"time"
 
@@ -88,7 +93,7 @@ func TestCreateGetAuthorReports(t *testing.T) {
 
 	checkNoNextAuthorReport(t, manager)
 
-	reportId1, err := manager.CreateAuthorReport(user1, "1", "author1", api.OpenAlexSource, "", "")
+	reportId1, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -112,12 +117,12 @@ func TestCreateGetAuthorReports(t *testing.T) {
 
 	time.Sleep(500 * time.Millisecond) // This is so that we can wait 1 sec and only the first report is timed out.
 
-	reportId2, err := manager.CreateAuthorReport(user2, "2", "author2", api.GoogleScholarSource, "", "")
+	reportId2, err := manager.CreateAuthorReport(user2, reports.AuthorReportParams{AuthorId: "2", AuthorName: "author2", Source: api.GoogleScholarSource})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	reportId3, err := manager.CreateAuthorReport(user1, "3", "author3", api.OpenAlexSource, "", "")
+	reportId3, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "3", AuthorName: "author3", Source: api.OpenAlexSource})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -159,7 +164,7 @@ func TestCreateGetAuthorReports(t *testing.T) {
 	checkNoNextAuthorReport(t, manager)
 
 	// Check that reports are reused
-	reportId4, err := manager.CreateAuthorReport(user2, "1", "author1", api.OpenAlexSource, "", "")
+	reportId4, err := manager.CreateAuthorReport(user2, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -186,7 +191,11 @@ func TestCreateGetAuthorReports(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	checkNextAuthorReport(t, next4, "1", "author1", api.OpenAlexSource, report1End, time.Now(), false)
+	checkNextAuthorReport(t, next4, "1", "author1", api.OpenAlexSource, reports.EarliestReportDate, time.Now(), false)
+	// Only works that were added or updated since the last update are flagged.
+	if next4.UpdatedSince.Sub(report1End).Abs() > 100*time.Millisecond {
+		t.Fatalf("incorrect updated since: %v", next4.UpdatedSince)
+	}
 	// Check report was only queued once
 	checkNoNextAuthorReport(t, manager)
 
@@ -206,7 +215,7 @@ func TestAuthorReportAccessErrors(t *testing.T) {
 
 	user1, user2 := uuid.New(), uuid.New()
 
-	reportId, err := manager.CreateAuthorReport(user1, "1", "author1", api.OpenAlexSource, "", "")
+	reportId, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -229,12 +238,12 @@ func TestListAuthorReports(t *testing.T) {
 
 	user1, user2 := uuid.New(), uuid.New()
 
-	report1, err := manager.CreateAuthorReport(user1, "1", "author1", api.OpenAlexSource, "", "")
+	report1, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	noReports, err := manager.ListAuthorReports(user2)
+	noReports, err := manager.ListAuthorReports(user2, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -242,17 +251,17 @@ func TestListAuthorReports(t *testing.T) {
 		t.Fatal("should be no reports for user2")
 	}
 
-	report2, err := manager.CreateAuthorReport(user2, "2", "author2", api.OpenAlexSource, "", "")
+	report2, err := manager.CreateAuthorReport(user2, reports.AuthorReportParams{AuthorId: "2", AuthorName: "author2", Source: api.OpenAlexSource})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	report3, err := manager.CreateAuthorReport(user1, "3", "author3", api.GoogleScholarSource, "", "")
+	report3, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "3", AuthorName: "author3", Source: api.GoogleScholarSource})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	reports1, err := manager.ListAuthorReports(user1)
+	reports1, err := manager.ListAuthorReports(user1, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -268,7 +277,7 @@ func TestListAuthorReports(t *testing.T) {
 		t.Fatal("incorrect reports")
 	}
 
-	reports2, err := manager.ListAuthorReports(user2)
+	reports2, err := manager.ListAuthorReports(user2, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -302,7 +311,7 @@ func TestListAuthorReports(t *testing.T) {
 		t.Fatal("user cannot delete another user's report")
 	}
 
-	reports1, err = manager.ListAuthorReports(user1)
+	reports1, err = manager.ListAuthorReports(user1, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -322,7 +331,7 @@ func TestListAuthorReports(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	reports1, err = manager.ListAuthorReports(user1)
+	reports1, err = manager.ListAuthorReports(user1, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -352,7 +361,7 @@ func checkNextUniversityReport(t *testing.T, next *reports.UniversityReportUpdat
 }
 
 func checkUniversityReport(t *testing.T, manager *reports.ReportManager, userId, reportId uuid.UUID, universityId, universityName, universityLocation, status string, nauthorsFlagged, nflags, authorsReviewed, totalAuthors int) {
-	report, err := manager.GetUniversityReport(userId, reportId)
+	report, err := manager.GetUniversityReport(userId, reportId, reports.UniversityAuthorQuery{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -394,12 +403,120 @@ func checkNoNextUniversityReport(t *testing.T, manager *reports.ReportManager) {
 	}
 }
 
+func TestAuthorReportOrcidDedupe(t *testing.T) {
+	manager := setup(t)
+
+	user1, user2 := uuid.New(), uuid.New()
+
+	const orcid = "0000-0002-1825-0097"
+
+	checkOrcid := func(userId, reportId uuid.UUID, authorId string) {
+		report, err := manager.GetAuthorReport(userId, reportId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.AuthorId != authorId || report.Orcid != orcid {
+			t.Fatalf("incorrect report: %v", report)
+		}
+	}
+
+	// This is the report that would be created from the author name search.
+	reportId1, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNextAuthorReport(t, next, "1", "author1", api.OpenAlexSource, reports.EarliestReportDate, time.Now(), false)
+
+	// The ORCID resolves to the same author id, so the report should be reused
+	// and the ORCID should be added to it.
+	reportId2, err := manager.CreateAuthorReport(user2, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource, Orcid: orcid})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkNoNextAuthorReport(t, manager)
+
+	checkOrcid(user1, reportId1, "1")
+	checkOrcid(user2, reportId2, "1")
+
+	// If the author id the ORCID resolves to changes the existing report should
+	// still be found from the ORCID.
+	reportId3, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "1-new", AuthorName: "author1", Source: api.OpenAlexSource, Orcid: orcid})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reportId3 != reportId1 {
+		t.Fatal("report should be reused for the same ORCID")
+	}
+
+	checkNoNextAuthorReport(t, manager)
+}
+
+func TestMergedAuthorReportIdentities(t *testing.T) {
+	manager := setup(t)
+
+	user := uuid.New()
+
+	identities := []api.AuthorIdentity{
+		{AuthorId: "1", Source: api.OpenAlexSource},
+		{AuthorId: "2", Source: api.OpenAlexSource},
+		{AuthorId: "3", Source: api.GoogleScholarSource},
+	}
+
+	reportId, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "merged-1", AuthorName: "author1", Source: api.MergedSource, Identities: identities})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNextAuthorReport(t, next, "merged-1", "author1", api.MergedSource, reports.EarliestReportDate, time.Now(), false)
+
+	// The identities are returned in a canonical order.
+	slices.SortFunc(identities, api.CompareAuthorIdentities)
+
+	if !slices.Equal(next.Identities, identities) {
+		t.Fatalf("incorrect identities for next report: %v", next.Identities)
+	}
+
+	report, err := manager.GetAuthorReport(user, reportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(report.Identities, identities) {
+		t.Fatalf("incorrect identities for report: %v", report.Identities)
+	}
+
+	// Reports for a single source should not have any identities.
+	otherId, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := manager.GetAuthorReport(user, otherId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Identities) != 0 {
+		t.Fatal("single source report should not have identities")
+	}
+}
+
 func TestUserAuthorReportsAreNotUsedInUniversityReports(t *testing.T) {
 	manager := setup(t)
 
 	user1 := uuid.New()
 
-	_, err := manager.CreateAuthorReport(user1, "1", "author1", api.OpenAlexSource, "", "")
+	_, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -415,7 +532,7 @@ func TestUserAuthorReportsAreNotUsedInUniversityReports(t *testing.T) {
 
 	checkNoNextAuthorReport(t, manager)
 
-	if _, err := manager.CreateUniversityReport(user1, "1", "university1", "location1"); err != nil {
+	if _, err := manager.CreateUniversityReport(user1, "1", "university1", "location1", api.UniversityAuthorDiscovery{}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -448,7 +565,7 @@ func TestCreateGetUniversityReports(t *testing.T) {
 
 	user1 := uuid.New()
 
-	uniId1, err := manager.CreateUniversityReport(user1, "1", "university1", "location1")
+	uniId1, err := manager.CreateUniversityReport(user1, "1", "university1", "location1", api.UniversityAuthorDiscovery{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -503,7 +620,7 @@ func TestCreateGetUniversityReports(t *testing.T) {
 	checkNoNextUniversityReport(t, manager)
 
 	// Create another university report
-	uniId2, err := manager.CreateUniversityReport(user1, "2", "university2", "location2")
+	uniId2, err := manager.CreateUniversityReport(user1, "2", "university2", "location2", api.UniversityAuthorDiscovery{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -541,7 +658,7 @@ func TestCreateGetUniversityReports(t *testing.T) {
 	checkUniversityReport(t, manager, user1, uniId2, "2", "university2", "location2", "complete", 2, 2, 2, 2)
 
 	// Check that reports are reused
-	uniId3, err := manager.CreateUniversityReport(user1, "1", "university1", "location1")
+	uniId3, err := manager.CreateUniversityReport(user1, "1", "university1", "location1", api.UniversityAuthorDiscovery{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -601,7 +718,10 @@ func TestCreateGetUniversityReports(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	checkNextAuthorReport(t, nextAuthor5, "1", "author1", api.OpenAlexSource, report1EndUniv, time.Now(), true)
+	checkNextAuthorReport(t, nextAuthor5, "1", "author1", api.OpenAlexSource, reports.EarliestReportDate, time.Now(), true)
+	if nextAuthor5.UpdatedSince.Sub(report1EndUniv).Abs() > 100*time.Millisecond {
+		t.Fatalf("incorrect updated since: %v", nextAuthor5.UpdatedSince)
+	}
 
 	if err := manager.UpdateAuthorReport(nextAuthor5.Id, "complete", nextAuthor5.EndDate, dummyReportUpdate()); err != nil {
 		t.Fatal(err)
@@ -616,7 +736,7 @@ func TestListUniversityReport(t *testing.T) {
 
 	user1, user2 := uuid.New(), uuid.New()
 
-	uniId1, err := manager.CreateUniversityReport(user1, "1", "university1", "location1")
+	uniId1, err := manager.CreateUniversityReport(user1, "1", "university1", "location1", api.UniversityAuthorDiscovery{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -629,12 +749,12 @@ func TestListUniversityReport(t *testing.T) {
 		t.Fatal("should be no reports for user2")
 	}
 
-	uniId2, err := manager.CreateUniversityReport(user2, "2", "university2", "location2")
+	uniId2, err := manager.CreateUniversityReport(user2, "2", "university2", "location2", api.UniversityAuthorDiscovery{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	uniId3, err := manager.CreateUniversityReport(user1, "3", "university3", "location3")
+	uniId3, err := manager.CreateUniversityReport(user1, "3", "university3", "location3", api.UniversityAuthorDiscovery{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -712,12 +832,65 @@ func TestListUniversityReport(t *testing.T) {
 	}
 }
 
+func TestUniversityReportAuthorDiscovery(t *testing.T) {
+	manager := setup(t)
+
+	user := uuid.New()
+
+	defaultId, err := manager.CreateUniversityReport(user, "1", "university1", "location1", api.UniversityAuthorDiscovery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Explicitly passing the defaults should reuse the same report.
+	sameId, err := manager.CreateUniversityReport(user, "1", "university1", "location1", api.UniversityAuthorDiscovery{
+		LookbackYears: reports.DefaultUniversityLookbackYears, MinWorks: reports.DefaultUniversityMinWorks,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameId != defaultId {
+		t.Fatal("expected report to be reused")
+	}
+
+	discovery := api.UniversityAuthorDiscovery{LookbackYears: 2, MinWorks: 3, RequireLastKnownInstitution: true}
+	filteredId, err := manager.CreateUniversityReport(user, "1", "university1", "location1", discovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filteredId == defaultId {
+		t.Fatal("expected distinct report for different discovery settings")
+	}
+
+	report, err := manager.GetUniversityReport(user, filteredId, reports.UniversityAuthorQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.UniversityAuthorDiscovery != discovery {
+		t.Fatalf("incorrect discovery settings: %+v", report.UniversityAuthorDiscovery)
+	}
+
+	defaults := api.UniversityAuthorDiscovery{LookbackYears: reports.DefaultUniversityLookbackYears, MinWorks: reports.DefaultUniversityMinWorks}
+
+	seen := make(map[api.UniversityAuthorDiscovery]bool)
+	for range 2 {
+		next, err := manager.GetNextUniversityReport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[next.AuthorDiscovery] = true
+	}
+	if len(seen) != 2 || !seen[defaults] || !seen[discovery] {
+		t.Fatalf("incorrect discovery settings for tasks: %+v", seen)
+	}
+}
+
 func TestUniversityReportsFilterFlagsByDate(t *testing.T) {
 	manager := setup(t)
 
 	user := uuid.New()
 
-	uniId, err := manager.CreateUniversityReport(user, "1", "university1", "location1")
+	uniId, err := manager.CreateUniversityReport(user, "1", "university1", "location1", api.UniversityAuthorDiscovery{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -754,7 +927,7 @@ func TestUniversityReportsFilterFlagsByDate(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	report, err := manager.GetUniversityReport(user, uniId)
+	report, err := manager.GetUniversityReport(user, uniId, reports.UniversityAuthorQuery{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -767,16 +940,161 @@ func TestUniversityReportsFilterFlagsByDate(t *testing.T) {
 	}
 }
 
+func TestUniversityReportRanking(t *testing.T) {
+	manager := setup(t)
+
+	user := uuid.New()
+
+	uniId, err := manager.CreateUniversityReport(user, "1", "university1", "location1", api.UniversityAuthorDiscovery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nextUni, err := manager.GetNextUniversityReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.UpdateUniversityReport(nextUni.Id, "complete", nextUni.UpdateDate, []reports.UniversityAuthorReport{
+		{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource, Department: "Physics"},
+		{AuthorId: "2", AuthorName: "author2", Source: api.OpenAlexSource, Department: "Physics"},
+		{AuthorId: "3", AuthorName: "author3", Source: api.OpenAlexSource, Department: "Chemistry"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	lastYear := now.AddDate(-1, 0, 0)
+
+	flags := map[string][]api.Flag{
+		"1": {
+			&api.HighRiskFunderFlag{Work: api.WorkSummary{WorkId: uuid.NewString(), PublicationDate: now}},
+		},
+		"2": {
+			&api.TalentContractFlag{Work: api.WorkSummary{WorkId: uuid.NewString(), PublicationDate: now}},
+			&api.TalentContractFlag{Work: api.WorkSummary{WorkId: uuid.NewString(), PublicationDate: now}},
+			&api.TalentContractFlag{Work: api.WorkSummary{WorkId: uuid.NewString(), PublicationDate: lastYear}},
+			&api.HighRiskFunderFlag{Work: api.WorkSummary{WorkId: uuid.NewString(), PublicationDate: lastYear}},
+		},
+		"3": {},
+	}
+
+	reportIds := make(map[string]uuid.UUID)
+	for range flags {
+		next, err := manager.GetNextAuthorReport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := manager.UpdateAuthorReport(next.Id, "complete", next.EndDate, flags[next.AuthorId]); err != nil {
+			t.Fatal(err)
+		}
+		reportIds[next.AuthorId] = next.Id
+	}
+
+	report, err := manager.GetUniversityReport(user, uniId, reports.UniversityAuthorQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authors := report.Content.Authors
+	if authors == nil || authors.Total != 3 || authors.Page != 1 || len(authors.Authors) != 3 ||
+		authors.Authors[0].AuthorId != "2" || authors.Authors[1].AuthorId != "1" || authors.Authors[2].AuthorId != "3" {
+		t.Fatalf("incorrect authors: %+v", authors)
+	}
+	if top := authors.Authors[0]; top.FlagCount != 4 || top.FlagCounts[api.TalentContractType] != 3 ||
+		top.Department != "Physics" || top.RiskTier != reports.RiskTierHigh || top.RiskScore <= authors.Authors[1].RiskScore {
+		t.Fatalf("incorrect author summary: %+v", top)
+	}
+	if authors.Authors[2].FlagCount != 0 || authors.Authors[2].RiskScore != 0 || authors.Authors[2].RiskTier != reports.RiskTierLow {
+		t.Fatalf("incorrect author summary: %+v", authors.Authors[2])
+	}
+
+	departments := report.Content.Departments
+	if len(departments) != 2 ||
+		departments[0].Department != "Physics" || departments[0].TotalAuthors != 2 || departments[0].FlaggedAuthors != 2 || departments[0].FlagCount != 5 ||
+		departments[1].Department != "Chemistry" || departments[1].TotalAuthors != 1 || departments[1].FlaggedAuthors != 0 {
+		t.Fatalf("incorrect departments: %+v", departments)
+	}
+
+	trends := report.Content.YearlyTrends
+	if len(trends) == 0 || trends[len(trends)-1].Year != now.UTC().Year() {
+		t.Fatalf("incorrect trends: %+v", trends)
+	}
+	totalTrendFlags := 0
+	for _, trend := range trends {
+		totalTrendFlags += trend.FlagCount
+	}
+	if totalTrendFlags != 5 {
+		t.Fatalf("incorrect trends: %+v", trends)
+	}
+
+	page, err := manager.GetUniversityReport(user, uniId, reports.UniversityAuthorQuery{Page: 2, PageSize: 2, SortBy: reports.UniversityAuthorSortName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Content.Authors.Total != 3 || len(page.Content.Authors.Authors) != 1 || page.Content.Authors.Authors[0].AuthorId != "3" {
+		t.Fatalf("incorrect page: %+v", page.Content.Authors)
+	}
+
+	filtered, err := manager.GetUniversityReport(user, uniId, reports.UniversityAuthorQuery{Department: "physics", FlagType: api.TalentContractType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filtered.Content.Authors.Total != 1 || filtered.Content.Authors.Authors[0].AuthorId != "2" {
+		t.Fatalf("incorrect filtered authors: %+v", filtered.Content.Authors)
+	}
+
+	searched, err := manager.GetUniversityReport(user, uniId, reports.UniversityAuthorQuery{Search: "OR3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if searched.Content.Authors.Total != 1 || searched.Content.Authors.Authors[0].AuthorId != "3" {
+		t.Fatalf("incorrect searched authors: %+v", searched.Content.Authors)
+	}
+
+	// Wildcards in the search are matched literally.
+	searched, err = manager.GetUniversityReport(user, uniId, reports.UniversityAuthorQuery{Search: "author_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if searched.Content.Authors.Total != 0 {
+		t.Fatalf("search should not match wildcards: %+v", searched.Content.Authors)
+	}
+
+	// The summary of the authors is recomputed when the flags of an author change.
+	if err := manager.UpdateAuthorReport(reportIds["3"], "complete", time.Now(), []api.Flag{
+		&api.TalentContractFlag{Work: api.WorkSummary{WorkId: uuid.NewString(), PublicationDate: now}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := manager.GetUniversityReport(user, uniId, reports.UniversityAuthorQuery{FlagType: api.TalentContractType, SortBy: reports.UniversityAuthorSortName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authors := updated.Content.Authors; authors.Total != 2 || authors.Authors[0].AuthorId != "2" || authors.Authors[1].AuthorId != "3" ||
+		authors.Authors[1].FlagCount != 1 || authors.Authors[1].FlagCounts[api.TalentContractType] != 1 {
+		t.Fatalf("incorrect authors after flags changed: %+v", authors)
+	}
+	if len(updated.Content.Flags[api.TalentContractType]) != 2 || updated.Content.Flags[api.TalentContractType][0].AuthorId != "2" {
+		t.Fatalf("incorrect flagged authors after flags changed: %+v", updated.Content.Flags)
+	}
+
+	if err := (reports.UniversityAuthorQuery{SortBy: "bad"}).Validate(); err == nil {
+		t.Fatal("expected invalid sort")
+	}
+}
+
 func TestUserQueuedReportsArePrioritizedOverUniversityReports(t *testing.T) {
 	manager := setup(t)
 
 	user := uuid.New()
 
-	if _, err := manager.CreateAuthorReport(user, "1", "author1", api.OpenAlexSource, "", ""); err != nil {
+	if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource}); err != nil {
 		t.Fatal(err)
 	}
 
-	if _, err := manager.CreateUniversityReport(user, "1", "university1", "location1"); err != nil {
+	if _, err := manager.CreateUniversityReport(user, "1", "university1", "location1", api.UniversityAuthorDiscovery{}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -791,7 +1109,7 @@ func TestUserQueuedReportsArePrioritizedOverUniversityReports(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if _, err := manager.CreateAuthorReport(user, "3", "author3", api.OpenAlexSource, "", ""); err != nil {
+	if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "3", AuthorName: "author3", Source: api.OpenAlexSource}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -827,11 +1145,11 @@ func TestAuthorReportRetry(t *testing.T) {
 	manager := setup(t).SetAuthorReportUpdateInterval(reports.AuthorReportUpdateInterval).SetAuthorReportTimeout(time.Second)
 
 	user := uuid.New()
-	if _, err := manager.CreateAuthorReport(user, "1", "author1", api.OpenAlexSource, "", ""); err != nil {
+	if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource}); err != nil {
 		t.Fatal(err)
 	}
 
-	if _, err := manager.CreateAuthorReport(user, "2", "author2", api.OpenAlexSource, "", ""); err != nil {
+	if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "2", AuthorName: "author2", Source: api.OpenAlexSource}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -868,11 +1186,11 @@ func TestUniversityReportRetry(t *testing.T) {
 	manager := setup(t).SetUniversityReportTimeout(time.Second).SetUniversityReportUpdateInterval(reports.UniversityReportUpdateInterval)
 
 	user := uuid.New()
-	if _, err := manager.CreateUniversityReport(user, "1", "university1", "location1"); err != nil {
+	if _, err := manager.CreateUniversityReport(user, "1", "university1", "location1", api.UniversityAuthorDiscovery{}); err != nil {
 		t.Fatal(err)
 	}
 
-	if _, err := manager.CreateUniversityReport(user, "2", "university2", "location2"); err != nil {
+	if _, err := manager.CreateUniversityReport(user, "2", "university2", "location2", api.UniversityAuthorDiscovery{}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -904,3 +1222,983 @@ func TestUniversityReportRetry(t *testing.T) {
 	// University report 1 should be retried because the timeout is expired and its status is left as in-progress.
 	checkNextUniversityReport(t, next3, "1", "university1", "location1", time.Now())
 }
+
+func TestAuthorReportLookbackWindow(t *testing.T) {
+	manager := setup(t)
+
+	user := uuid.New()
+
+	allTimeId, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recentId, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource, LookbackYears: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if allTimeId == recentId {
+		t.Fatal("reports with different lookback windows should be distinct")
+	}
+
+	next1, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNextAuthorReport(t, next1, "1", "author1", api.OpenAlexSource, reports.EarliestReportDate, time.Now(), false)
+
+	next2, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only works in the lookback window should be fetched.
+	now := time.Now().UTC()
+	windowStart := time.Date(now.Year()-5, now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	checkNextAuthorReport(t, next2, "1", "author1", api.OpenAlexSource, windowStart, time.Now(), false)
+
+	checkNoNextAuthorReport(t, manager)
+
+	flags := func() []api.Flag {
+		return []api.Flag{
+			&api.HighRiskFunderFlag{Work: api.WorkSummary{WorkId: "recent", PublicationDate: now.AddDate(-1, 0, 0)}},
+			&api.HighRiskFunderFlag{Work: api.WorkSummary{WorkId: "old", PublicationDate: now.AddDate(-10, 0, 0)}},
+		}
+	}
+
+	for _, next := range []*reports.ReportUpdateTask{next1, next2} {
+		if err := manager.UpdateAuthorReport(next.Id, "complete", next.EndDate, flags()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	allTime, err := manager.GetAuthorReport(user, allTimeId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allTime.LookbackYears != 0 || len(allTime.Content[api.HighRiskFunderType]) != 2 {
+		t.Fatalf("incorrect report: %v", allTime)
+	}
+
+	// Flags outside of the lookback window are omitted.
+	recent, err := manager.GetAuthorReport(user, recentId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recent.LookbackYears != 5 || len(recent.Content[api.HighRiskFunderType]) != 1 ||
+		recent.Content[api.HighRiskFunderType][0].(*api.HighRiskFunderFlag).Work.WorkId != "recent" {
+		t.Fatalf("incorrect report: %v", recent)
+	}
+
+	// Creating a report with the same window reuses the existing report.
+	if _, err := manager.CreateAuthorReport(uuid.New(), reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource, LookbackYears: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkNoNextAuthorReport(t, manager)
+}
+
+func TestFlagSuppressions(t *testing.T) {
+	manager := setup(t)
+
+	user := uuid.New()
+	otherOrgUser := uuid.New()
+
+	if _, err := manager.CreateOrganization(user, "user@example.com", "org1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.CreateOrganization(otherOrgUser, "other@example.com", "org2"); err != nil {
+		t.Fatal(err)
+	}
+
+	reportId, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funderFlag := &api.HighRiskFunderFlag{Work: api.WorkSummary{WorkId: "w1", PublicationDate: time.Now()}, Funders: []string{"Funder X"}}
+	affiliationFlag := &api.PotentialAuthorAffiliationFlag{University: "University X"}
+	otherFlag := &api.PotentialAuthorAffiliationFlag{University: "University Y"}
+
+	if err := manager.UpdateAuthorReport(next.Id, "complete", next.EndDate, []api.Flag{funderFlag, affiliationFlag, otherFlag}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The report for the same author created from unstructured text has a
+	// different author id.
+	textReportId, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "text1", AuthorName: "Author1", Source: api.UnstructuredSource, UnstructuredText: "text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err = manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.UpdateAuthorReport(next.Id, "complete", next.EndDate, []api.Flag{affiliationFlag, otherFlag}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.CreateFlagSuppression(uuid.New(), api.CreateFlagSuppressionRequest{AuthorId: "1", FlagType: api.PotentialAuthorAffiliationType, Entity: "University Y"}); !errors.Is(err, reports.ErrUserNotInOrganization) {
+		t.Fatalf("users without an organization cannot create suppressions: %v", err)
+	}
+
+	funderHash := funderFlag.Hash()
+
+	byHash, err := manager.CreateFlagSuppression(user, api.CreateFlagSuppressionRequest{AuthorId: "1", FlagHash: hex.EncodeToString(funderHash[:]), Reason: "different author"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.CreateFlagSuppression(user, api.CreateFlagSuppressionRequest{AuthorId: "1", AuthorName: "author1", FlagType: api.PotentialAuthorAffiliationType, Entity: "university x", Reason: "different author"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Suppressions for other authors should not affect the report.
+	if _, err := manager.CreateFlagSuppression(user, api.CreateFlagSuppressionRequest{AuthorId: "2", FlagType: api.PotentialAuthorAffiliationType, Entity: "University Y"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Suppressions of other organizations should not affect the report.
+	if _, err := manager.CreateFlagSuppression(otherOrgUser, api.CreateFlagSuppressionRequest{AuthorId: "1", FlagType: api.PotentialAuthorAffiliationType, Entity: "University Y"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := manager.GetAuthorReport(user, reportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Content[api.HighRiskFunderType]) != 0 || len(report.Content[api.PotentialAuthorAffiliationType]) != 1 {
+		t.Fatalf("suppressed flags should be omitted: %v", report.Content)
+	}
+
+	// Suppressions with an author name apply to the reports for the author from
+	// other sources.
+	textReport, err := manager.GetAuthorReport(user, textReportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(textReport.Content[api.PotentialAuthorAffiliationType]) != 1 {
+		t.Fatalf("suppressed flags should be omitted from report with matching author name: %v", textReport.Content)
+	}
+
+	// The flags are not removed from the report, which is shared with the users
+	// of other organizations.
+	otherReportId, err := manager.CreateAuthorReport(otherOrgUser, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherReport, err := manager.GetAuthorReport(otherOrgUser, otherReportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(otherReport.Content[api.HighRiskFunderType]) != 1 || len(otherReport.Content[api.PotentialAuthorAffiliationType]) != 1 ||
+		otherReport.Content[api.PotentialAuthorAffiliationType][0].(*api.PotentialAuthorAffiliationFlag).University != "University X" {
+		t.Fatalf("suppressions should only apply to the organization: %v", otherReport.Content)
+	}
+
+	suppressions, err := manager.GetActiveFlagSuppressions(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filtered := suppressions.ForAuthor("author1", "1").Filter([]api.Flag{funderFlag, affiliationFlag, otherFlag})
+	if len(filtered) != 1 || filtered[0] != otherFlag {
+		t.Fatalf("incorrect flags after filtering: %v", filtered)
+	}
+
+	if err := manager.RemoveFlagSuppression(otherOrgUser, byHash.Id); err != reports.ErrSuppressionNotFound {
+		t.Fatalf("suppressions of other organizations cannot be removed: %v", err)
+	}
+
+	if err := manager.RemoveFlagSuppression(user, byHash.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.RemoveFlagSuppression(user, byHash.Id); err != reports.ErrSuppressionNotFound {
+		t.Fatalf("expected suppression not found error: %v", err)
+	}
+
+	active, err := manager.ListFlagSuppressions(user, "1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 || active[0].Entity != "university x" {
+		t.Fatalf("incorrect active suppressions: %v", active)
+	}
+
+	all, err := manager.ListFlagSuppressions(user, "1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := slices.IndexFunc(all, func(s api.FlagSuppression) bool { return s.Id == byHash.Id })
+	if len(all) != 2 || removed < 0 || all[removed].RemovedBy == nil || *all[removed].RemovedBy != user || all[removed].RemovedAt == nil {
+		t.Fatalf("incorrect suppression audit trail: %v", all)
+	}
+
+	// The suppressed flags are restored without reprocessing the report.
+	report, err = manager.GetAuthorReport(user, reportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Content[api.HighRiskFunderType]) != 1 {
+		t.Fatalf("flags should be restored when the suppression is removed: %v", report.Content)
+	}
+
+	checkNoNextAuthorReport(t, manager)
+}
+
+func TestAuthorReportProgress(t *testing.T) {
+	db := schema.SetupTestDB(t)
+	manager := reports.NewManager(db)
+
+	user := uuid.New()
+	reportId, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := manager.GetAuthorReport(user, reportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Progress != nil {
+		t.Fatal("queued report should have no progress")
+	}
+
+	completion := time.Now().UTC().Add(time.Minute)
+	progress := api.ReportProgress{
+		StartedAt:             time.Now().UTC(),
+		UpdatedAt:             time.Now().UTC(),
+		WorksFetched:          150,
+		WorkBatchesFetched:    2,
+		Flaggers:              []api.FlaggerProgress{{Flagger: "flagger1", BatchesProcessed: 1}, {Flagger: "flagger2", BatchesProcessed: 2}},
+		PdfsDownloaded:        10,
+		PdfsFailed:            3,
+		GrobidCallsPending:    4,
+		EstimatedCompletionAt: &completion,
+	}
+
+	if err := manager.UpdateAuthorReportProgress(uuid.New(), progress); err != reports.ErrReportNotFound {
+		t.Fatalf("expected report not found error, got %v", err)
+	}
+
+	// The progress from a previous time the report was processed is reset when
+	// the report is processed again.
+	var userReport schema.UserAuthorReport
+	if err := db.First(&userReport, "id = ?", reportId).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateAuthorReportProgress(userReport.ReportId, progress); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = manager.GetAuthorReport(user, reportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Progress != nil {
+		t.Fatalf("progress should be reset: %v", report.Progress)
+	}
+
+	if err := manager.UpdateAuthorReportProgress(next.Id, progress); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = manager.GetAuthorReport(user, reportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Progress == nil ||
+		report.Progress.WorksFetched != 150 ||
+		report.Progress.WorkBatchesFetched != 2 ||
+		!slices.Equal(report.Progress.Flaggers, progress.Flaggers) ||
+		report.Progress.PdfsDownloaded != 10 ||
+		report.Progress.PdfsFailed != 3 ||
+		report.Progress.GrobidCallsPending != 4 {
+		t.Fatalf("incorrect progress: %v", report.Progress)
+	}
+	if report.Progress.EstimatedSecondsRemaining == nil || *report.Progress.EstimatedSecondsRemaining < 55 || *report.Progress.EstimatedSecondsRemaining > 60 {
+		t.Fatalf("incorrect estimated time remaining: %v", report.Progress.EstimatedSecondsRemaining)
+	}
+
+	if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, next.EndDate, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The progress is kept once the report is complete, but there is no
+	// estimated time remaining.
+	report, err = manager.GetAuthorReport(user, reportId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Progress == nil || report.Progress.WorksFetched != 150 || report.Progress.EstimatedSecondsRemaining != nil {
+		t.Fatalf("incorrect progress for completed report: %v", report.Progress)
+	}
+}
+
+func TestRequeueAuthorReport(t *testing.T) {
+	manager := setup(t)
+
+	user := uuid.New()
+	reportId1, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next1, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNextAuthorReport(t, next1, "1", "author1", api.OpenAlexSource, reports.EarliestReportDate, time.Now(), false)
+
+	if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "2", AuthorName: "author2", Source: api.OpenAlexSource}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.RequeueAuthorReport(next1.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	checkAuthorReport(t, manager, user, reportId1, "1", "author1", api.OpenAlexSource, schema.ReportQueued, 0)
+
+	// The requeued report should be processed before reports that were queued
+	// after it was started.
+	next2, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNextAuthorReport(t, next2, "1", "author1", api.OpenAlexSource, reports.EarliestReportDate, time.Now(), false)
+
+	if err := manager.UpdateAuthorReport(next2.Id, schema.ReportCompleted, next2.EndDate, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only reports that are in progress can be requeued.
+	if err := manager.RequeueAuthorReport(next2.Id); err != reports.ErrReportNotFound {
+		t.Fatalf("expected report not found error, got %v", err)
+	}
+	checkAuthorReport(t, manager, user, reportId1, "1", "author1", api.OpenAlexSource, schema.ReportCompleted, 0)
+}
+
+func TestRequeueUniversityReport(t *testing.T) {
+	manager := setup(t)
+
+	user := uuid.New()
+	reportId, err := manager.CreateUniversityReport(user, "1", "university1", "location1", api.UniversityAuthorDiscovery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next1, err := manager.GetNextUniversityReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNextUniversityReport(t, next1, "1", "university1", "location1", time.Now())
+
+	if err := manager.RequeueUniversityReport(next1.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := manager.GetUniversityReport(user, reportId, reports.UniversityAuthorQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != schema.ReportQueued {
+		t.Fatalf("expected requeued report to be queued, got %s", report.Status)
+	}
+
+	next2, err := manager.GetNextUniversityReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNextUniversityReport(t, next2, "1", "university1", "location1", time.Now())
+
+	if err := manager.UpdateUniversityReport(next2.Id, schema.ReportCompleted, next2.UpdateDate, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only reports that are in progress can be requeued.
+	if err := manager.RequeueUniversityReport(next2.Id); err != reports.ErrReportNotFound {
+		t.Fatalf("expected report not found error, got %v", err)
+	}
+}
+
+func TestAuthorReportPriorityAndFairScheduling(t *testing.T) {
+	db := schema.SetupTestDB(t)
+	manager := reports.NewManager(db)
+
+	user1, user2 := uuid.New(), uuid.New()
+
+	for _, author := range []string{"1", "2", "3"} {
+		if _, err := manager.CreateUniversityReport(user1, "uni-"+author, "university"+author, "", api.UniversityAuthorDiscovery{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uniAuthors := map[string][]reports.UniversityAuthorReport{
+		"uni-1": {{AuthorId: "u1a", AuthorName: "u1a", Source: api.OpenAlexSource}, {AuthorId: "u1b", AuthorName: "u1b", Source: api.OpenAlexSource}, {AuthorId: "u1c", AuthorName: "u1c", Source: api.OpenAlexSource}},
+		"uni-2": {{AuthorId: "u2a", AuthorName: "u2a", Source: api.OpenAlexSource}},
+		"uni-3": nil,
+	}
+	for range 3 {
+		next, err := manager.GetNextUniversityReport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := manager.UpdateUniversityReport(next.Id, schema.ReportCompleted, next.UpdateDate, uniAuthors[next.UniversityId]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, author := range []string{"a1", "a2", "a3"} {
+		if _, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: author, AuthorName: author, Source: api.OpenAlexSource}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := manager.CreateAuthorReport(user2, reports.AuthorReportParams{AuthorId: "b1", AuthorName: "b1", Source: api.OpenAlexSource}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A report that is waiting to be refreshed is moved ahead when a user
+	// requests it.
+	if _, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "c1", AuthorName: "c1", Source: api.OpenAlexSource}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&schema.AuthorReport{}).Where("author_id = ?", "c1").Update("priority", schema.ReportPriorityRefresh).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	checkOrder := func(expected []string) {
+		t.Helper()
+		for _, authorId := range expected {
+			next, err := manager.GetNextAuthorReport()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next == nil || next.AuthorId != authorId {
+				t.Fatalf("expected report for %s, got %v", authorId, next)
+			}
+		}
+	}
+
+	// The reports queued by users are processed first, alternating between the
+	// users.
+	checkOrder([]string{"a1", "b1", "a2", "a3"})
+
+	if _, err := manager.CreateAuthorReport(user2, reports.AuthorReportParams{AuthorId: "c1", AuthorName: "c1", Source: api.OpenAlexSource}); err != nil {
+		t.Fatal(err)
+	}
+	checkOrder([]string{"c1"})
+
+	// The authors of the university reports alternate between universities.
+	checkOrder([]string{"u1a", "u2a", "u1b"})
+
+	// Reports requested by users are processed before the remaining university
+	// authors.
+	if _, err := manager.CreateAuthorReport(user1, reports.AuthorReportParams{AuthorId: "a4", AuthorName: "a4", Source: api.OpenAlexSource}); err != nil {
+		t.Fatal(err)
+	}
+	checkOrder([]string{"a4", "u1c"})
+
+	checkNoNextAuthorReport(t, manager)
+}
+
+func TestAuthorReportFlaggerVersions(t *testing.T) {
+	db := schema.SetupTestDB(t)
+	manager := reports.NewManager(db)
+
+	user := uuid.New()
+	if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource}); err != nil {
+		t.Fatal(err)
+	}
+
+	next1, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !next1.UpdatedSince.IsZero() || len(next1.FlaggerVersions) != 0 {
+		t.Fatalf("first update should be a full update: %v", next1)
+	}
+
+	flags := dummyReportUpdate()[:2]
+	if err := manager.UpdateAuthorReportFlags(next1.Id, schema.ReportInProgress, next1.EndDate, "flaggerA", "1", flags); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.RecordAuthorReportFlaggers(next1.Id, map[string]string{"flaggerA": "1", "flaggerB": "1"}, nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateAuthorReport(next1.Id, schema.ReportCompleted, next1.EndDate, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var authorFlags []schema.AuthorFlag
+	if err := db.Find(&authorFlags, "report_id = ?", next1.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(authorFlags) != 2 {
+		t.Fatalf("expected 2 flags, got %d", len(authorFlags))
+	}
+	for _, flag := range authorFlags {
+		if flag.Flagger != "flaggerA" || flag.FlaggerVersion != "1" {
+			t.Fatalf("incorrect flagger for flag: %s %s", flag.Flagger, flag.FlaggerVersion)
+		}
+	}
+
+	requeue := func() {
+		t.Helper()
+		if err := db.Model(&schema.AuthorReport{}).Where("id = ?", next1.Id).Update("status", schema.ReportQueued).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	requeue()
+
+	next2, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next2.UpdatedSince.Sub(next1.EndDate).Abs() > 100*time.Millisecond {
+		t.Fatalf("incorrect updated since: %v", next2.UpdatedSince)
+	}
+	if !maps.Equal(next2.FlaggerVersions, map[string]string{"flaggerA": "1", "flaggerB": "1"}) {
+		t.Fatalf("incorrect flagger versions: %v", next2.FlaggerVersions)
+	}
+
+	// Failed flaggers are removed so that they are run in full next time.
+	if err := manager.RecordAuthorReportFlaggers(next2.Id, map[string]string{"flaggerA": "2"}, []string{"flaggerB"}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateAuthorReport(next2.Id, schema.ReportCompleted, next2.EndDate, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	requeue()
+
+	next3, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(next3.FlaggerVersions, map[string]string{"flaggerA": "2"}) {
+		t.Fatalf("incorrect flagger versions: %v", next3.FlaggerVersions)
+	}
+}
+
+func TestWatchlistUpdates(t *testing.T) {
+	db := schema.SetupTestDB(t)
+	manager := reports.NewManager(db)
+
+	v1 := map[string]string{"entities": "a", "funders": "b"}
+	v2 := map[string]string{"entities": "c", "funders": "b"}
+
+	user := uuid.New()
+	oldFlag := dummyReportUpdate()[0]
+
+	// Report 1 is processed with the first watchlists, report 2 is processed
+	// without recording the watchlist versions, and report 3 is still queued.
+	for _, author := range []string{"1", "2", "3"} {
+		if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: author, AuthorName: "author" + author, Source: api.OpenAlexSource}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, author := range []string{"1", "2"} {
+		next, err := manager.GetNextAuthorReport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkNextAuthorReport(t, next, author, "author"+author, api.OpenAlexSource, reports.EarliestReportDate, time.Now(), false)
+		if author == "1" {
+			if err := manager.UpdateAuthorReport(next.Id, schema.ReportInProgress, next.EndDate, []api.Flag{oldFlag}); err != nil {
+				t.Fatal(err)
+			}
+			if err := manager.RecordAuthorReportWatchlists(next.Id, v1); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, next.EndDate, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkRequeued := func(versions map[string]string, expected int) {
+		t.Helper()
+		requeued, err := manager.CheckWatchlistVersions(versions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if requeued != expected {
+			t.Fatalf("expected %d reports to be requeued, got %d", expected, requeued)
+		}
+	}
+
+	// The first versions are the baseline.
+	checkRequeued(v1, 0)
+	checkRequeued(v1, 0)
+
+	// The completed reports should be requeued once.
+	checkRequeued(v2, 2)
+	checkRequeued(v2, 0)
+
+	next3, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNextAuthorReport(t, next3, "3", "author3", api.OpenAlexSource, reports.EarliestReportDate, time.Now(), false)
+
+	var next1 *reports.ReportUpdateTask
+	for range 2 {
+		next, err := manager.GetNextAuthorReport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next == nil {
+			t.Fatal("expected requeued report")
+		}
+		if next.AuthorId == "1" {
+			next1 = next
+		}
+	}
+	checkNoNextAuthorReport(t, manager)
+
+	if !maps.Equal(next1.WatchlistVersions, v1) {
+		t.Fatalf("incorrect watchlist versions: %v", next1.WatchlistVersions)
+	}
+
+	newFlag := dummyReportUpdate()[1]
+	if err := manager.UpdateAuthorReport(next1.Id, schema.ReportInProgress, next1.EndDate, []api.Flag{oldFlag, newFlag}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateAuthorReport(next1.Id, schema.ReportCompleted, next1.EndDate, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	updates, err := manager.ListWatchlistUpdates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 watchlist updates, got %d", len(updates))
+	}
+	update := updates[0]
+	if !slices.Equal(update.ChangedWatchlists, []string{"entities"}) || !maps.Equal(update.Watchlists, v2) ||
+		update.ReportsRequeued != 2 || update.ReportsPending != 1 ||
+		len(update.NewFlags) != 1 || update.NewFlags[newFlag.Type()] != 1 {
+		t.Fatalf("incorrect watchlist update: %+v", update)
+	}
+	if len(updates[1].ChangedWatchlists) != 0 || updates[1].ReportsRequeued != 0 {
+		t.Fatalf("incorrect baseline watchlist update: %+v", updates[1])
+	}
+
+	details, err := manager.GetWatchlistUpdate(update.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details.Reports) != 1 || details.Reports[0].ReportId != next1.Id || details.Reports[0].NewFlags[newFlag.Type()] != 1 {
+		t.Fatalf("incorrect watchlist update reports: %+v", details.Reports)
+	}
+
+	if _, err := manager.GetWatchlistUpdate(uuid.New()); err != reports.ErrWatchlistUpdateNotFound {
+		t.Fatalf("expected watchlist update not found error, got %v", err)
+	}
+}
+
+func TestWatchlistUpdateRevertedWatchlists(t *testing.T) {
+	db := schema.SetupTestDB(t)
+	manager := reports.NewManager(db)
+
+	user := uuid.New()
+	if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource}); err != nil {
+		t.Fatal(err)
+	}
+
+	watchlists := eoc.NewStore(eoc.NewWatchlistSet(nil))
+
+	// Reloads the watchlists, checks their versions, and processes the report
+	// with them if it was requeued.
+	checkRequeued := func(expected int) {
+		t.Helper()
+		if _, err := manager.ReloadWatchlists(watchlists); err != nil {
+			t.Fatal(err)
+		}
+		versions := watchlists.Current().Versions
+		requeued, err := manager.CheckWatchlistVersions(versions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if requeued != expected {
+			t.Fatalf("expected %d reports to be requeued, got %d", expected, requeued)
+		}
+
+		next, err := manager.GetNextAuthorReport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (next != nil) != (expected > 0) {
+			t.Fatalf("expected %d reports to be queued, got %+v", expected, next)
+		}
+		if next != nil {
+			if err := manager.RecordAuthorReportWatchlists(next.Id, versions); err != nil {
+				t.Fatal(err)
+			}
+			if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, next.EndDate, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if _, err := manager.ReloadWatchlists(watchlists); err != nil {
+		t.Fatal(err)
+	}
+	next, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.RecordAuthorReportWatchlists(next.Id, watchlists.Current().Versions); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, next.EndDate, nil); err != nil {
+		t.Fatal(err)
+	}
+	checkRequeued(0)
+	original := watchlists.Current().Versions
+
+	entity, err := manager.CreateWatchlistEntity(user, eoc.AcknowledgementsWatchlist, api.WatchlistEntityRequest{Name: "Org A", Source: "list a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRequeued(1)
+
+	// Removing the entity changes the watchlists back to the original versions,
+	// the report is requeued so that flags for the entity are removed.
+	if err := manager.RemoveWatchlistEntity(user, eoc.AcknowledgementsWatchlist, entity.Id); err != nil {
+		t.Fatal(err)
+	}
+	checkRequeued(1)
+	checkRequeued(0)
+
+	if !maps.Equal(watchlists.Current().Versions, original) {
+		t.Fatalf("expected original watchlist versions, got %v", watchlists.Current().Versions)
+	}
+
+	updates, err := manager.ListWatchlistUpdates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 3 || updates[0].Version != updates[2].Version || updates[0].Id == updates[2].Id ||
+		updates[0].ReportsRequeued != 1 || updates[1].ReportsRequeued != 1 || updates[2].ReportsRequeued != 0 {
+		t.Fatalf("incorrect watchlist updates: %+v", updates)
+	}
+}
+
+func TestWatchlistUpdateInProgressReports(t *testing.T) {
+	db := schema.SetupTestDB(t)
+	manager := reports.NewManager(db)
+
+	v1 := map[string]string{"entities": "a"}
+	v2 := map[string]string{"entities": "b"}
+
+	user := uuid.New()
+	for _, author := range []string{"1", "2"} {
+		if _, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: author, AuthorName: "author" + author, Source: api.OpenAlexSource}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := manager.CheckWatchlistVersions(v1); err != nil {
+		t.Fatal(err)
+	}
+
+	inProgress := make(map[string]*reports.ReportUpdateTask)
+	for range 2 {
+		next, err := manager.GetNextAuthorReport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next == nil {
+			t.Fatal("expected queued report")
+		}
+		inProgress[next.AuthorId] = next
+	}
+
+	// The watchlists change while the reports are in progress.
+	if requeued, err := manager.CheckWatchlistVersions(v2); err != nil || requeued != 0 {
+		t.Fatalf("expected in progress reports to not be requeued, got %d: %v", requeued, err)
+	}
+
+	// Report 1 was processed with the old watchlists, report 2 with the new ones.
+	for author, versions := range map[string]map[string]string{"1": v1, "2": v2} {
+		if err := manager.RecordAuthorReportWatchlists(inProgress[author].Id, versions); err != nil {
+			t.Fatal(err)
+		}
+		if err := manager.UpdateAuthorReport(inProgress[author].Id, schema.ReportCompleted, inProgress[author].EndDate, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	next, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == nil || next.AuthorId != "1" || !maps.Equal(next.WatchlistVersions, v1) {
+		t.Fatalf("expected report processed with old watchlists to be requeued: %+v", next)
+	}
+	checkNoNextAuthorReport(t, manager)
+
+	// The report is not requeued again for the same update, even if the
+	// watchlist versions are not recorded because a flagger failed.
+	if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, next.EndDate, nil); err != nil {
+		t.Fatal(err)
+	}
+	checkNoNextAuthorReport(t, manager)
+
+	updates, err := manager.ListWatchlistUpdates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || !maps.Equal(updates[0].Watchlists, v2) || updates[0].ReportsRequeued != 1 || updates[0].ReportsPending != 0 {
+		t.Fatalf("incorrect watchlist update: %+v", updates[0])
+	}
+}
+
+func TestWatchlistEntities(t *testing.T) {
+	db := schema.SetupTestDB(t)
+	manager := reports.NewManager(db)
+
+	user := uuid.New()
+
+	if _, err := manager.CreateWatchlistEntity(user, "missing", api.WatchlistEntityRequest{Name: "abc"}); !errors.Is(err, reports.ErrWatchlistNotFound) {
+		t.Fatalf("expected watchlist not found error, got %v", err)
+	}
+	for _, invalid := range []api.WatchlistEntityRequest{{Name: "abc"}, {OpenAlexId: "https://openalex.org/X1"}} {
+		if _, err := manager.CreateWatchlistEntity(user, eoc.InstitutionsWatchlist, invalid); !errors.Is(err, reports.ErrInvalidWatchlistEntity) {
+			t.Fatalf("expected invalid entity error for %+v, got %v", invalid, err)
+		}
+	}
+	if _, err := manager.CreateWatchlistEntity(user, eoc.SuspiciousTermsWatchlist, api.WatchlistEntityRequest{Name: "abc", OpenAlexId: "I1"}); !errors.Is(err, reports.ErrInvalidWatchlistEntity) {
+		t.Fatalf("expected invalid entity error, got %v", err)
+	}
+
+	institution, err := manager.CreateWatchlistEntity(user, eoc.InstitutionsWatchlist, api.WatchlistEntityRequest{
+		Name: "University A", OpenAlexId: "I123", Aliases: []string{"Uni A", " ", "Uni A"}, Source: "list", Justification: "reason",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if institution.OpenAlexId != "https://openalex.org/I123" || !slices.Equal(institution.Aliases, []string{"Uni A"}) || institution.CreatedBy != user {
+		t.Fatalf("incorrect watchlist entity: %+v", institution)
+	}
+	if _, err := manager.CreateWatchlistEntity(user, eoc.InstitutionsWatchlist, api.WatchlistEntityRequest{Name: "Other", OpenAlexId: "https://openalex.org/I123"}); !errors.Is(err, reports.ErrInvalidWatchlistEntity) {
+		t.Fatalf("expected duplicate entity error, got %v", err)
+	}
+
+	future := time.Now().Add(24 * time.Hour)
+	if _, err := manager.CreateWatchlistEntity(user, eoc.AcknowledgementsWatchlist, api.WatchlistEntityRequest{Name: "Org B", Aliases: []string{"B"}, Source: "list b"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.CreateWatchlistEntity(user, eoc.AcknowledgementsWatchlist, api.WatchlistEntityRequest{Name: "Org C", Source: "list c", EffectiveDate: &future}); err != nil {
+		t.Fatal(err)
+	}
+
+	watchlists := eoc.NewStore(eoc.NewWatchlistSet(nil))
+	for _, expected := range []bool{true, false} {
+		changed, err := manager.ReloadWatchlists(watchlists)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != expected {
+			t.Fatalf("expected changed to be %v", expected)
+		}
+	}
+
+	current := watchlists.Current()
+	if !current.Institutions.Contains("https://openalex.org/I123") || len(current.Institutions) != 1 {
+		t.Fatalf("incorrect institutions: %v", current.Institutions)
+	}
+	// Entities are not used until their effective date.
+	if !maps.Equal(current.AliasToSource, map[string]string{"Org B": "list b", "B": "list b"}) {
+		t.Fatalf("incorrect aliases: %v", current.AliasToSource)
+	}
+
+	updated, err := manager.UpdateWatchlistEntity(user, eoc.InstitutionsWatchlist, institution.Id, api.WatchlistEntityRequest{
+		Name: "University A", OpenAlexId: "I456", Justification: "new reason",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.OpenAlexId != "https://openalex.org/I456" || len(updated.Aliases) != 0 || updated.Justification != "new reason" || !updated.CreatedAt.Equal(institution.CreatedAt) {
+		t.Fatalf("incorrect updated entity: %+v", updated)
+	}
+	if _, err := manager.UpdateWatchlistEntity(user, eoc.FundersWatchlist, institution.Id, api.WatchlistEntityRequest{OpenAlexId: "F1"}); !errors.Is(err, reports.ErrWatchlistEntityNotFound) {
+		t.Fatalf("expected entity not found error, got %v", err)
+	}
+
+	result, err := manager.ImportWatchlistEntities(user, eoc.InstitutionsWatchlist, []api.WatchlistEntityRequest{
+		{OpenAlexId: "I456"}, {OpenAlexId: "I789"}, {OpenAlexId: "https://openalex.org/I789"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 1 || result.Skipped != 2 {
+		t.Fatalf("incorrect import result: %+v", result)
+	}
+	if _, err := manager.ImportWatchlistEntities(user, eoc.InstitutionsWatchlist, []api.WatchlistEntityRequest{{OpenAlexId: "I1"}, {Name: "abc"}}); !errors.Is(err, reports.ErrInvalidWatchlistEntity) {
+		t.Fatalf("expected invalid entity error, got %v", err)
+	}
+
+	if err := manager.RemoveWatchlistEntity(user, eoc.InstitutionsWatchlist, institution.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.RemoveWatchlistEntity(user, eoc.InstitutionsWatchlist, institution.Id); !errors.Is(err, reports.ErrWatchlistEntityNotFound) {
+		t.Fatalf("expected entity not found error, got %v", err)
+	}
+
+	entities, err := manager.ListWatchlistEntities(eoc.InstitutionsWatchlist, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].OpenAlexId != "https://openalex.org/I789" {
+		t.Fatalf("incorrect watchlist entities: %+v", entities)
+	}
+	entities, err = manager.ListWatchlistEntities(eoc.InstitutionsWatchlist, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 2 || entities[0].Id != institution.Id || entities[0].RemovedBy == nil || *entities[0].RemovedBy != user || entities[0].RemovedAt == nil {
+		t.Fatalf("incorrect watchlist entities: %+v", entities)
+	}
+
+	oldVersions := watchlists.Current().Versions
+	if changed, err := manager.ReloadWatchlists(watchlists); err != nil || !changed {
+		t.Fatalf("expected watchlists to change: %v", err)
+	}
+	current = watchlists.Current()
+	if !current.Institutions.Contains("https://openalex.org/I789") || len(current.Institutions) != 1 {
+		t.Fatalf("incorrect institutions: %v", current.Institutions)
+	}
+	if current.Versions[eoc.InstitutionsWatchlist] == oldVersions[eoc.InstitutionsWatchlist] ||
+		current.Versions[eoc.AcknowledgementsWatchlist] != oldVersions[eoc.AcknowledgementsWatchlist] {
+		t.Fatalf("only the institutions version should change: %v %v", oldVersions, current.Versions)
+	}
+
+	lists, err := manager.ListWatchlists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int64)
+	for _, list := range lists {
+		counts[list.Name] = list.Entities
+	}
+	if len(lists) != len(eoc.Watchlists) || counts[eoc.InstitutionsWatchlist] != 1 || counts[eoc.AcknowledgementsWatchlist] != 2 || counts[eoc.FundersWatchlist] != 0 {
+		t.Fatalf("incorrect watchlists: %+v", lists)
+	}
+}
@@ -0,0 +1,452 @@
+package reports
+
+import (
+	"errors"
+	"log/slog"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrganizationAccessFailed = errors.New("organization access failed")
+	ErrOrganizationNotFound     = errors.New("organization not found")
+	ErrOrganizationNameTaken    = errors.New("organization name is already taken")
+	ErrUserInOrganization       = errors.New("user is already in an organization")
+	ErrUserNotInOrganization    = errors.New("user is not in an organization")
+	ErrNotOrganizationAdmin     = errors.New("user is not an admin of the organization")
+	ErrMemberNotFound           = errors.New("organization member not found")
+	ErrInviteNotFound           = errors.New("organization invite not found")
+	ErrLastOrganizationAdmin    = errors.New("the last admin cannot leave an organization with other members")
+)
+
+// The access a user needs to a user author report for an operation. Members of
+// the owner's organization have read or write access to shared reports, other
+// operations (e.g. deleting or sharing the report) are only allowed for the owner.
+type reportAccess int
+
+const (
+	readAccess reportAccess = iota
+	writeAccess
+	ownerAccess
+)
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Returns nil if the user is not in an organization.
+func getOrganizationMember(txn *gorm.DB, userId uuid.UUID) (*schema.OrganizationMember, error) {
+	var member schema.OrganizationMember
+	result := txn.Limit(1).Find(&member, "user_id = ?", userId)
+	if result.Error != nil {
+		slog.Error("error getting organization member", "user_id", userId, "error", result.Error)
+		return nil, ErrOrganizationAccessFailed
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &member, nil
+}
+
+// Returns uuid.Nil if the user is not in an organization.
+func userOrganization(txn *gorm.DB, userId uuid.UUID) (uuid.UUID, error) {
+	member, err := getOrganizationMember(txn, userId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if member == nil {
+		return uuid.Nil, nil
+	}
+	return member.OrganizationId, nil
+}
+
+// The monitoring label for users that are not in an organization, it is also
+// used for university report authors since they are shared by all users with
+// reports for the university.
+const noOrganizationLabel = "none"
+
+// Returns the name of the user's organization for the monitoring labels.
+func organizationLabel(txn *gorm.DB, userId uuid.UUID) string {
+	var org schema.Organization
+	result := txn.Limit(1).
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userId).
+		Find(&org)
+	if result.Error != nil {
+		slog.Error("error getting organization for monitoring", "user_id", userId, "error", result.Error)
+		return noOrganizationLabel
+	}
+	if result.RowsAffected == 0 {
+		return noOrganizationLabel
+	}
+	return org.Name
+}
+
+func checkUserReportAccess(txn *gorm.DB, userId uuid.UUID, report schema.UserAuthorReport, access reportAccess) error {
+	if report.UserId == userId {
+		return nil
+	}
+
+	switch {
+	case access == ownerAccess, report.SharedAccess == "":
+		return ErrUserCannotAccessReport
+	case access == writeAccess && report.SharedAccess != schema.ReportAccessWrite:
+		return ErrUserCannotAccessReport
+	}
+
+	var members []schema.OrganizationMember
+	if err := txn.Find(&members, "user_id IN ?", []uuid.UUID{userId, report.UserId}).Error; err != nil {
+		slog.Error("error checking organization of report owner", "user_author_report_id", report.Id, "error", err)
+		return ErrReportAccessFailed
+	}
+
+	if len(members) != 2 || members[0].OrganizationId != members[1].OrganizationId {
+		return ErrUserCannotAccessReport
+	}
+
+	return nil
+}
+
+func convertOrganizationInvite(invite schema.OrganizationInvite, orgName string) api.OrganizationInvite {
+	return api.OrganizationInvite{
+		OrganizationId:   invite.OrganizationId,
+		OrganizationName: orgName,
+		Email:            invite.Email,
+		Role:             invite.Role,
+		InvitedAt:        invite.InvitedAt,
+	}
+}
+
+func (r *ReportManager) CreateOrganization(userId uuid.UUID, email, name string) (uuid.UUID, error) {
+	org := schema.Organization{
+		Id:        uuid.New(),
+		Name:      strings.TrimSpace(name),
+		CreatedBy: userId,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err := r.db.Transaction(func(txn *gorm.DB) error {
+		member, err := getOrganizationMember(txn, userId)
+		if err != nil {
+			return err
+		}
+		if member != nil {
+			return ErrUserInOrganization
+		}
+
+		var count int64
+		if err := txn.Model(&schema.Organization{}).Where("LOWER(name) = ?", strings.ToLower(org.Name)).Count(&count).Error; err != nil {
+			slog.Error("error checking for existing organization", "error", err)
+			return ErrOrganizationAccessFailed
+		}
+		if count > 0 {
+			return ErrOrganizationNameTaken
+		}
+
+		if err := txn.Create(&org).Error; err != nil {
+			slog.Error("error creating organization", "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		if err := txn.Create(&schema.OrganizationMember{
+			UserId:         userId,
+			OrganizationId: org.Id,
+			Email:          normalizeEmail(email),
+			Role:           schema.OrganizationRoleAdmin,
+			JoinedAt:       org.CreatedAt,
+		}).Error; err != nil {
+			slog.Error("error adding organization creator as admin", "organization_id", org.Id, "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return org.Id, nil
+}
+
+func (r *ReportManager) GetUserOrganization(userId uuid.UUID) (api.Organization, error) {
+	member, err := getOrganizationMember(r.db, userId)
+	if err != nil {
+		return api.Organization{}, err
+	}
+	if member == nil {
+		return api.Organization{}, ErrUserNotInOrganization
+	}
+
+	var org schema.Organization
+	if err := r.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("joined_at ASC")
+	}).Preload("Invites", func(db *gorm.DB) *gorm.DB {
+		return db.Order("invited_at ASC")
+	}).First(&org, "id = ?", member.OrganizationId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.Organization{}, ErrOrganizationNotFound
+		}
+		slog.Error("error getting organization", "organization_id", member.OrganizationId, "error", err)
+		return api.Organization{}, ErrOrganizationAccessFailed
+	}
+
+	output := api.Organization{
+		Id:      org.Id,
+		Name:    org.Name,
+		Role:    member.Role,
+		Members: make([]api.OrganizationMember, 0, len(org.Members)),
+	}
+
+	for _, m := range org.Members {
+		output.Members = append(output.Members, api.OrganizationMember{
+			UserId:   m.UserId,
+			Email:    m.Email,
+			Role:     m.Role,
+			JoinedAt: m.JoinedAt,
+		})
+	}
+
+	if member.Role == schema.OrganizationRoleAdmin {
+		output.Invites = make([]api.OrganizationInvite, 0, len(org.Invites))
+		for _, invite := range org.Invites {
+			output.Invites = append(output.Invites, convertOrganizationInvite(invite, org.Name))
+		}
+	}
+
+	return output, nil
+}
+
+func getOrganizationAdmin(txn *gorm.DB, userId uuid.UUID) (*schema.OrganizationMember, error) {
+	member, err := getOrganizationMember(txn, userId)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrUserNotInOrganization
+	}
+	if member.Role != schema.OrganizationRoleAdmin {
+		return nil, ErrNotOrganizationAdmin
+	}
+	return member, nil
+}
+
+// Invites the user with the email to the admin's organization, inviting the
+// same email again updates the role of the invite.
+func (r *ReportManager) InviteOrganizationMember(adminId uuid.UUID, email, role string) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		admin, err := getOrganizationAdmin(txn, adminId)
+		if err != nil {
+			return err
+		}
+
+		invite := schema.OrganizationInvite{
+			OrganizationId: admin.OrganizationId,
+			Email:          normalizeEmail(email),
+			Role:           role,
+			InvitedBy:      adminId,
+			InvitedAt:      time.Now().UTC(),
+		}
+
+		if err := txn.Save(&invite).Error; err != nil {
+			slog.Error("error creating organization invite", "organization_id", admin.OrganizationId, "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		return nil
+	})
+}
+
+func (r *ReportManager) RevokeOrganizationInvite(adminId uuid.UUID, email string) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		admin, err := getOrganizationAdmin(txn, adminId)
+		if err != nil {
+			return err
+		}
+
+		result := txn.Delete(&schema.OrganizationInvite{}, "organization_id = ? AND email = ?", admin.OrganizationId, normalizeEmail(email))
+		if result.Error != nil {
+			slog.Error("error deleting organization invite", "organization_id", admin.OrganizationId, "error", result.Error)
+			return ErrOrganizationAccessFailed
+		}
+		if result.RowsAffected != 1 {
+			return ErrInviteNotFound
+		}
+
+		return nil
+	})
+}
+
+// Lists the pending invites for the user's email.
+func (r *ReportManager) ListOrganizationInvites(email string) ([]api.OrganizationInvite, error) {
+	var invites []schema.OrganizationInvite
+	if err := r.db.Order("invited_at DESC").Find(&invites, "email = ?", normalizeEmail(email)).Error; err != nil {
+		slog.Error("error listing organization invites", "error", err)
+		return nil, ErrOrganizationAccessFailed
+	}
+
+	orgIds := make([]uuid.UUID, 0, len(invites))
+	for _, invite := range invites {
+		orgIds = append(orgIds, invite.OrganizationId)
+	}
+
+	var orgs []schema.Organization
+	if err := r.db.Find(&orgs, "id IN ?", orgIds).Error; err != nil {
+		slog.Error("error getting organizations for invites", "error", err)
+		return nil, ErrOrganizationAccessFailed
+	}
+
+	names := make(map[uuid.UUID]string, len(orgs))
+	for _, org := range orgs {
+		names[org.Id] = org.Name
+	}
+
+	output := make([]api.OrganizationInvite, 0, len(invites))
+	for _, invite := range invites {
+		output = append(output, convertOrganizationInvite(invite, names[invite.OrganizationId]))
+	}
+
+	return output, nil
+}
+
+func (r *ReportManager) AcceptOrganizationInvite(userId uuid.UUID, email string, organizationId uuid.UUID) error {
+	email = normalizeEmail(email)
+
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		var invite schema.OrganizationInvite
+		if err := txn.First(&invite, "organization_id = ? AND email = ?", organizationId, email).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInviteNotFound
+			}
+			slog.Error("error getting organization invite", "organization_id", organizationId, "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		member, err := getOrganizationMember(txn, userId)
+		if err != nil {
+			return err
+		}
+		if member != nil {
+			return ErrUserInOrganization
+		}
+
+		if err := txn.Create(&schema.OrganizationMember{
+			UserId:         userId,
+			OrganizationId: organizationId,
+			Email:          email,
+			Role:           invite.Role,
+			JoinedAt:       time.Now().UTC(),
+		}).Error; err != nil {
+			slog.Error("error adding organization member", "organization_id", organizationId, "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		if err := txn.Delete(&invite).Error; err != nil {
+			slog.Error("error deleting accepted organization invite", "organization_id", organizationId, "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		return nil
+	})
+}
+
+// Removes the member from the organization of the user. Admins can remove any
+// member, other members can only remove themselves. The member's reports are no
+// longer shared so that they are not visible if the member joins another
+// organization. If the last member leaves the organization it is deleted.
+func (r *ReportManager) RemoveOrganizationMember(userId, memberId uuid.UUID) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		user, err := getOrganizationMember(txn, userId)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotInOrganization
+		}
+		if userId != memberId && user.Role != schema.OrganizationRoleAdmin {
+			return ErrNotOrganizationAdmin
+		}
+
+		var members []schema.OrganizationMember
+		if err := txn.Find(&members, "organization_id = ?", user.OrganizationId).Error; err != nil {
+			slog.Error("error listing organization members", "organization_id", user.OrganizationId, "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		var member *schema.OrganizationMember
+		admins := 0
+		for i := range members {
+			if members[i].UserId == memberId {
+				member = &members[i]
+			}
+			if members[i].Role == schema.OrganizationRoleAdmin {
+				admins++
+			}
+		}
+		if member == nil {
+			return ErrMemberNotFound
+		}
+
+		if len(members) == 1 {
+			if err := txn.Delete(&schema.Organization{}, "id = ?", user.OrganizationId).Error; err != nil {
+				slog.Error("error deleting organization", "organization_id", user.OrganizationId, "error", err)
+				return ErrOrganizationAccessFailed
+			}
+			// The weights, suppressions, and university report summaries of the
+			// organization do not have foreign keys to it, so they are removed here.
+			for _, table := range []any{&schema.RiskWeights{}, &schema.FlagSuppression{}, &schema.UniversityReportSummary{},
+				&schema.UniversityAuthorSummary{}, &schema.UniversityAuthorFlagCount{}} {
+				if err := txn.Delete(table, "organization_id = ?", user.OrganizationId).Error; err != nil {
+					slog.Error("error deleting organization data", "organization_id", user.OrganizationId, "error", err)
+					return ErrOrganizationAccessFailed
+				}
+			}
+		} else if member.Role == schema.OrganizationRoleAdmin && admins == 1 {
+			return ErrLastOrganizationAdmin
+		}
+
+		if err := txn.Delete(member).Error; err != nil {
+			slog.Error("error removing organization member", "organization_id", user.OrganizationId, "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		if err := txn.Model(&schema.UserAuthorReport{}).Where("user_id = ?", memberId).Update("shared_access", "").Error; err != nil {
+			slog.Error("error unsharing reports of removed organization member", "organization_id", user.OrganizationId, "error", err)
+			return ErrOrganizationAccessFailed
+		}
+
+		return nil
+	})
+}
+
+// Shares the report with the owner's organization, an empty access stops
+// sharing the report.
+func (r *ReportManager) ShareAuthorReport(userId, reportId uuid.UUID, access string) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		report, err := getUserAuthorReport(txn, userId, reportId, ownerAccess)
+		if err != nil {
+			return err
+		}
+
+		if access != "" {
+			member, err := getOrganizationMember(txn, userId)
+			if err != nil {
+				return err
+			}
+			if member == nil {
+				return ErrUserNotInOrganization
+			}
+		}
+
+		if err := txn.Model(&report).Update("shared_access", access).Error; err != nil {
+			slog.Error("error updating report shared access", "user_author_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,244 @@
+package reports_test
+
+import (
+	"errors"
+	"prism/prism/api"
+	"prism/prism/reports"
+	"prism/prism/schema"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func checkListedReports(t *testing.T, manager *reports.ReportManager, userId uuid.UUID, includeOrganization bool, expected map[uuid.UUID]string) {
+	t.Helper()
+
+	listed, err := manager.ListAuthorReports(userId, includeOrganization)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(listed) != len(expected) {
+		t.Fatalf("expected %d reports, got %d", len(expected), len(listed))
+	}
+	for _, report := range listed {
+		owner, ok := expected[report.Id]
+		if !ok {
+			t.Fatalf("unexpected report %v", report.Id)
+		}
+		if report.OwnerEmail != owner {
+			t.Fatalf("expected owner '%s' for report %v, got '%s'", owner, report.Id, report.OwnerEmail)
+		}
+	}
+}
+
+func TestOrganizationMembership(t *testing.T) {
+	db := schema.SetupTestDB(t)
+	manager := reports.NewManager(db)
+
+	admin, member, other := uuid.New(), uuid.New(), uuid.New()
+
+	if _, err := manager.GetUserOrganization(admin); !errors.Is(err, reports.ErrUserNotInOrganization) {
+		t.Fatalf("expected not in organization error, got %v", err)
+	}
+
+	orgId, err := manager.CreateOrganization(admin, "Admin@Example.com", "org")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.CreateOrganization(admin, "admin@example.com", "org2"); !errors.Is(err, reports.ErrUserInOrganization) {
+		t.Fatalf("expected user in organization error, got %v", err)
+	}
+	if _, err := manager.CreateOrganization(other, "other@example.com", "ORG"); !errors.Is(err, reports.ErrOrganizationNameTaken) {
+		t.Fatalf("expected name taken error, got %v", err)
+	}
+
+	if err := manager.InviteOrganizationMember(admin, " Member@Example.com", schema.OrganizationRoleMember); err != nil {
+		t.Fatal(err)
+	}
+
+	invites, err := manager.ListOrganizationInvites("member@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invites) != 1 || invites[0].OrganizationId != orgId || invites[0].OrganizationName != "org" || invites[0].Role != schema.OrganizationRoleMember {
+		t.Fatalf("incorrect invites: %v", invites)
+	}
+
+	if err := manager.AcceptOrganizationInvite(other, "other@example.com", orgId); !errors.Is(err, reports.ErrInviteNotFound) {
+		t.Fatalf("expected invite not found error, got %v", err)
+	}
+	if err := manager.AcceptOrganizationInvite(member, "member@example.com", orgId); err != nil {
+		t.Fatal(err)
+	}
+
+	if invites, err := manager.ListOrganizationInvites("member@example.com"); err != nil || len(invites) != 0 {
+		t.Fatalf("invite should be removed once accepted: %v, %v", invites, err)
+	}
+
+	if err := manager.InviteOrganizationMember(member, "other@example.com", schema.OrganizationRoleMember); !errors.Is(err, reports.ErrNotOrganizationAdmin) {
+		t.Fatalf("expected not admin error, got %v", err)
+	}
+
+	org, err := manager.GetUserOrganization(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if org.Id != orgId || org.Role != schema.OrganizationRoleMember || len(org.Members) != 2 ||
+		org.Members[0].UserId != admin || org.Members[0].Email != "admin@example.com" || org.Members[0].Role != schema.OrganizationRoleAdmin ||
+		org.Members[1].UserId != member || org.Members[1].Email != "member@example.com" || org.Invites != nil {
+		t.Fatalf("incorrect organization: %+v", org)
+	}
+
+	if err := manager.RemoveOrganizationMember(member, admin); !errors.Is(err, reports.ErrNotOrganizationAdmin) {
+		t.Fatalf("expected not admin error, got %v", err)
+	}
+	if err := manager.RemoveOrganizationMember(admin, admin); !errors.Is(err, reports.ErrLastOrganizationAdmin) {
+		t.Fatalf("expected last admin error, got %v", err)
+	}
+
+	if err := manager.RemoveOrganizationMember(member, member); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.GetUserOrganization(member); !errors.Is(err, reports.ErrUserNotInOrganization) {
+		t.Fatalf("expected not in organization error, got %v", err)
+	}
+
+	if _, err := manager.CreateFlagSuppression(admin, api.CreateFlagSuppressionRequest{AuthorId: "1", FlagType: api.TalentContractType, Entity: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateRiskWeights(admin, reports.DefaultRiskWeights()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&schema.UniversityReportSummary{UniversityReportId: uuid.New(), OrganizationId: orgId}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// The organization and its data are deleted when the last member leaves.
+	if err := manager.RemoveOrganizationMember(admin, admin); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []any{&schema.FlagSuppression{}, &schema.RiskWeights{}, &schema.UniversityReportSummary{}} {
+		var count int64
+		if err := db.Model(table).Where("organization_id = ?", orgId).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Fatalf("expected organization data to be deleted, found %d rows of %T", count, table)
+		}
+	}
+	if _, err := manager.CreateOrganization(other, "other@example.com", "org"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSharedAuthorReports(t *testing.T) {
+	manager := setup(t)
+
+	owner, member, outsider := uuid.New(), uuid.New(), uuid.New()
+
+	orgId, err := manager.CreateOrganization(owner, "owner@example.com", "org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.InviteOrganizationMember(owner, "member@example.com", schema.OrganizationRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.AcceptOrganizationInvite(member, "member@example.com", orgId); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.ShareAuthorReport(outsider, uuid.New(), schema.ReportAccessRead); !errors.Is(err, reports.ErrReportNotFound) {
+		t.Fatalf("expected report not found error, got %v", err)
+	}
+
+	shared, err := manager.CreateAuthorReport(owner, reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := manager.CreateAuthorReport(owner, reports.AuthorReportParams{AuthorId: "2", AuthorName: "author2", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memberReport, err := manager.CreateAuthorReport(member, reports.AuthorReportParams{AuthorId: "3", AuthorName: "author3", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outsiderReport, err := manager.CreateAuthorReport(outsider, reports.AuthorReportParams{AuthorId: "4", AuthorName: "author4", Source: api.OpenAlexSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.ShareAuthorReport(outsider, outsiderReport, schema.ReportAccessRead); !errors.Is(err, reports.ErrUserNotInOrganization) {
+		t.Fatalf("expected not in organization error, got %v", err)
+	}
+
+	if _, err := manager.GetAuthorReport(member, shared); !errors.Is(err, reports.ErrUserCannotAccessReport) {
+		t.Fatalf("expected access error before the report is shared, got %v", err)
+	}
+
+	if err := manager.ShareAuthorReport(member, shared, schema.ReportAccessWrite); !errors.Is(err, reports.ErrUserCannotAccessReport) {
+		t.Fatalf("only the owner can share a report, got %v", err)
+	}
+	if err := manager.ShareAuthorReport(owner, shared, schema.ReportAccessRead); err != nil {
+		t.Fatal(err)
+	}
+
+	checkListedReports(t, manager, member, false, map[uuid.UUID]string{memberReport: ""})
+	checkListedReports(t, manager, member, true, map[uuid.UUID]string{memberReport: "member@example.com", shared: "owner@example.com"})
+	checkListedReports(t, manager, owner, true, map[uuid.UUID]string{shared: "owner@example.com", private: "owner@example.com"})
+	checkListedReports(t, manager, outsider, true, map[uuid.UUID]string{outsiderReport: ""})
+
+	report, err := manager.GetAuthorReport(member, shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OwnerId != owner || report.OwnerEmail != "owner@example.com" || report.SharedAccess != schema.ReportAccessRead {
+		t.Fatalf("incorrect shared report: %+v", report)
+	}
+
+	if _, err := manager.GetAuthorReport(outsider, shared); !errors.Is(err, reports.ErrUserCannotAccessReport) {
+		t.Fatalf("expected access error for user outside the organization, got %v", err)
+	}
+	if err := manager.DeleteAuthorReport(member, shared); !errors.Is(err, reports.ErrReportNotFound) {
+		t.Fatalf("only the owner can delete a report, got %v", err)
+	}
+
+	upload := []reports.DisclosureUpload{{Filename: "a.txt", Text: "text"}}
+
+	if err := manager.AddDisclosureDocuments(member, shared, upload); !errors.Is(err, reports.ErrUserCannotAccessReport) {
+		t.Fatalf("expected access error for read only report, got %v", err)
+	}
+	if _, err := manager.ListDisclosureDocuments(member, shared); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.ShareAuthorReport(owner, shared, schema.ReportAccessWrite); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.AddDisclosureDocuments(member, shared, upload); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reports are no longer shared once the owner leaves the organization.
+	if err := manager.InviteOrganizationMember(owner, "admin@example.com", schema.OrganizationRoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.AcceptOrganizationInvite(outsider, "admin@example.com", orgId); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.RemoveOrganizationMember(outsider, owner); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.GetAuthorReport(member, shared); !errors.Is(err, reports.ErrUserCannotAccessReport) {
+		t.Fatalf("expected access error after owner left the organization, got %v", err)
+	}
+	checkListedReports(t, manager, member, true, map[uuid.UUID]string{memberReport: "member@example.com"})
+
+	// Unsharing a report does not require an organization.
+	if err := manager.ShareAuthorReport(owner, shared, ""); err != nil {
+		t.Fatal(err)
+	}
+}
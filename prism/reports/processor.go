@@ -1,13 +1,18 @@
 package reports
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"log/slog"
+	"maps"
 	"prism/prism/api"
 	"prism/prism/monitoring"
 	"prism/prism/openalex"
+	"prism/prism/reports/flaggers/eoc"
 	"prism/prism/schema"
+	"prism/prism/scopus"
+	"slices"
 	"sync"
 	"time"
 )
@@ -17,6 +22,13 @@ type ReportProcessor struct {
 	workFlaggers   []WorkFlagger
 	authorFlaggers []AuthorFlagger
 	manager        *ReportManager
+
+	// scopus is nil if no scopus api key is configured.
+	scopus *scopus.Client
+
+	// The watchlists the work flaggers use, the work flaggers are run on all of
+	// the works of reports that were processed with different versions of them.
+	watchlists *eoc.Store
 }
 
 func NewProcessor(workFlaggers []WorkFlagger, authorFlaggers []AuthorFlagger, manager *ReportManager) *ReportProcessor {
@@ -28,36 +40,206 @@ func NewProcessor(workFlaggers []WorkFlagger, authorFlaggers []AuthorFlagger, ma
 	}
 }
 
-func (processor *ReportProcessor) getWorkStream(report ReportUpdateTask) (chan openalex.WorkBatch, error) {
+func (processor *ReportProcessor) SetScopus(client *scopus.Client) *ReportProcessor {
+	processor.scopus = client
+	return processor
+}
+
+func (processor *ReportProcessor) SetWatchlists(watchlists *eoc.Store) *ReportProcessor {
+	processor.watchlists = watchlists
+	return processor
+}
+
+// Only works that were added or updated since updatedSince are returned, unless
+// it is zero.
+func (processor *ReportProcessor) getWorkStream(report ReportUpdateTask, updatedSince time.Time) (chan openalex.WorkBatch, error) {
 	switch report.Source {
 	case api.OpenAlexSource:
-		return streamOpenAlexWorks(processor.openalex, report.AuthorId, report.StartDate, report.EndDate), nil
+		return streamOpenAlexWorks(processor.openalex, report.AuthorId, report.StartDate, report.EndDate, updatedSince), nil
 	case api.GoogleScholarSource:
-		return streamGScholarWorks(processor.openalex, report.AuthorName, report.AuthorId, report.StartDate, report.EndDate), nil
-	// case api.UnstructuredSource:
-	// 	return streamUnstructuredWorks(processor.openalex, report.AuthorName, "what should the text be", report.StartYear, report.EndYear), nil
-	// case api.ScopusSource:
-	// 	return streamScopusWorks()
+		return streamGScholarWorks(processor.openalex, report.AuthorName, report.AuthorId, report.StartDate, report.EndDate, updatedSince), nil
+	case api.UnstructuredSource:
+		return streamUnstructuredWorks(processor.openalex, report.AuthorName, report.UnstructuredText, report.StartDate, report.EndDate, updatedSince), nil
+	case api.ScopusSource:
+		if processor.scopus == nil {
+			return nil, fmt.Errorf("report source '%s' is not configured", report.Source)
+		}
+		return streamScopusWorks(processor.openalex, processor.scopus, report.AuthorName, report.AuthorId, report.StartDate, report.EndDate, updatedSince), nil
+	case api.MergedSource:
+		return processor.getMergedWorkStream(report, updatedSince)
 	default:
 		return nil, fmt.Errorf("invalid report source '%s'", report.Source)
 	}
 }
 
-func (processor *ReportProcessor) processWorks(logger *slog.Logger, authorName, affiliations string, workStream chan openalex.WorkBatch, flagsCh chan []api.Flag, forUniversityReport bool) {
+func (processor *ReportProcessor) getMergedWorkStream(report ReportUpdateTask, updatedSince time.Time) (chan openalex.WorkBatch, error) {
+	if len(report.Identities) == 0 {
+		return nil, fmt.Errorf("merged report has no identities")
+	}
+
+	// The identities are all checked before any streams are started so that the
+	// streams are not left blocked if one of the identities is invalid.
+	for _, identity := range report.Identities {
+		switch identity.Source {
+		case api.OpenAlexSource, api.GoogleScholarSource:
+			// ok
+		case api.ScopusSource:
+			if processor.scopus == nil {
+				return nil, fmt.Errorf("report source '%s' is not configured", identity.Source)
+			}
+		default:
+			return nil, fmt.Errorf("invalid source '%s' for merged report", identity.Source)
+		}
+	}
+
+	streams := make([]sourceWorkStream, 0, len(report.Identities))
+	for _, identity := range report.Identities {
+		task := report
+		task.AuthorId = identity.AuthorId
+		task.Source = identity.Source
+		task.Identities = nil
+
+		stream, err := processor.getWorkStream(task, updatedSince)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, sourceWorkStream{identity: identity, stream: stream})
+	}
+
+	return streamMergedWorks(streams), nil
+}
+
+// The flaggers that need to be run to update a report.
+type flaggerPlan struct {
+	workFlaggers []WorkFlagger
+	// The work flaggers that have not changed since the last update of the report,
+	// they only need to be run on the works that were added or updated since then.
+	incremental  map[string]bool
+	updatedSince time.Time
+
+	// Author flaggers that have not changed since the last update are skipped.
+	authorFlaggers []AuthorFlagger
+
+	// The versions of the watchlists when the report was planned, the
+	// watchlists may be reloaded while the report is processed, in which case
+	// the report is requeued when it completes.
+	watchlistVersions map[string]string
+}
+
+func (processor *ReportProcessor) planFlaggers(report ReportUpdateTask) flaggerPlan {
+	plan := flaggerPlan{incremental: make(map[string]bool), updatedSince: report.UpdatedSince}
+
+	unchanged := func(name, version string) bool {
+		last, ok := report.FlaggerVersions[name]
+		return ok && !report.UpdatedSince.IsZero() && last == version
+	}
+
+	if processor.watchlists != nil {
+		plan.watchlistVersions = processor.watchlists.Current().Versions
+	}
+	watchlistsChanged := plan.watchlistVersions != nil && !maps.Equal(report.WatchlistVersions, plan.watchlistVersions)
+
+	for _, flagger := range processor.workFlaggers {
+		if report.ForUniversityReport && flagger.DisableForUniversityReport() {
+			continue
+		}
+		plan.workFlaggers = append(plan.workFlaggers, flagger)
+		if !watchlistsChanged && unchanged(flagger.Name(), flagger.Version()) {
+			plan.incremental[flagger.Name()] = true
+		}
+	}
+
+	for _, flagger := range processor.authorFlaggers {
+		if flagger.Version() != "" && unchanged(flagger.Name(), flagger.Version()) {
+			continue
+		}
+		plan.authorFlaggers = append(plan.authorFlaggers, flagger)
+	}
+
+	return plan
+}
+
+// All of the works need to be fetched if any of the work flaggers need to be
+// run on all of the works.
+func (plan flaggerPlan) fetchUpdatedSince() time.Time {
+	if len(plan.incremental) < len(plan.workFlaggers) {
+		return time.Time{}
+	}
+	return plan.updatedSince
+}
+
+// Returns the works that were added or updated since the given time. Openalex
+// only filters by the date works were updated, so works updated earlier on the
+// same day are included as well.
+func newWorks(works []openalex.Work, updatedSince time.Time) []openalex.Work {
+	if updatedSince.IsZero() {
+		return works
+	}
+
+	since := time.Date(updatedSince.Year(), updatedSince.Month(), updatedSince.Day(), 0, 0, 0, 0, time.UTC)
+
+	output := make([]openalex.Work, 0, len(works))
+	for _, work := range works {
+		if work.UpdatedDate.IsZero() || !work.UpdatedDate.Before(since) {
+			output = append(output, work)
+		}
+	}
+	return output
+}
+
+// Returns the versions of the flaggers that ran without errors, and the names
+// of the flaggers that failed. If a batch of works could not be fetched none of
+// the work flaggers saw all of the works, so they are all treated as failed.
+func (plan flaggerPlan) results(failed map[string]bool) (map[string]string, []string) {
+	versions := make(map[string]string)
+	failedNames := make([]string, 0)
+	for _, flagger := range plan.workFlaggers {
+		if failed[""] || failed[flagger.Name()] {
+			failedNames = append(failedNames, flagger.Name())
+		} else {
+			versions[flagger.Name()] = flagger.Version()
+		}
+	}
+	for _, flagger := range plan.authorFlaggers {
+		if failed[flagger.Name()] {
+			failedNames = append(failedNames, flagger.Name())
+		} else {
+			versions[flagger.Name()] = flagger.Version()
+		}
+	}
+	return versions, failedNames
+}
+
+// The results of running a flagger on a batch of works, or for the author. An
+// error with no flagger means that a batch of works could not be fetched.
+type flaggerResult struct {
+	flagger string
+	version string
+	flags   []api.Flag
+	err     error
+}
+
+func (processor *ReportProcessor) processWorks(logger *slog.Logger, authorName, affiliations string, workStream chan openalex.WorkBatch, resultsCh chan flaggerResult, plan flaggerPlan, progress *progressTracker) {
 	wg := sync.WaitGroup{}
 
 	batch := -1
-	for works := range workStream {
+	for batchWorks := range workStream {
 		batch++
-		if works.Error != nil {
-			logger.Error("error getting next batch of author works", "batch", batch, "error", works.Error)
+		if batchWorks.Error != nil {
+			logger.Error("error getting next batch of author works", "batch", batch, "error", batchWorks.Error)
+			resultsCh <- flaggerResult{err: batchWorks.Error}
 			continue
 		}
-		logger.Info("got next batch of works", "batch", batch, "n_works", len(works.Works))
-		for _, flagger := range processor.workFlaggers {
-
-			if forUniversityReport && flagger.DisableForUniversityReport() {
-				continue
+		logger.Info("got next batch of works", "batch", batch, "n_works", len(batchWorks.Works))
+		progress.worksBatchFetched(len(batchWorks.Works))
+		for _, flagger := range plan.workFlaggers {
+			works := batchWorks.Works
+			if plan.incremental[flagger.Name()] {
+				works = newWorks(works, plan.updatedSince)
+				if len(works) == 0 {
+					progress.flaggerBatchDone(flagger.Name())
+					continue
+				}
 			}
 
 			wg.Add(1)
@@ -67,46 +249,63 @@ func (processor *ReportProcessor) processWorks(logger *slog.Logger, authorName,
 
 				logger := logger.With("flagger", flagger.Name(), "batch", batch)
 
-				flags, err := flagger.Flag(logger, works, authorIds, authorName)
+				defer progress.flaggerBatchDone(flagger.Name())
+
+				var flags []api.Flag
+				var err error
+				if pdfFlagger, ok := flagger.(PdfWorkFlagger); ok {
+					flags, err = pdfFlagger.FlagWithPdfProgress(logger, &progress.pdfs, works, authorIds, authorName)
+				} else {
+					flags, err = flagger.Flag(logger, works, authorIds, authorName)
+				}
 				if err != nil {
 					logger.Error("flagger error", "error", err)
 					monitoring.FlaggerErrors.WithLabelValues(flagger.Name()).Inc()
-				} else {
-					flagsCh <- flags
 				}
-			}(flagger, works.Works, works.TargetAuthorIds)
+				resultsCh <- flaggerResult{flagger: flagger.Name(), version: flagger.Version(), flags: flags, err: err}
+			}(flagger, works, batchWorks.TargetAuthorIds)
 		}
 	}
 
-	for _, flagger := range processor.authorFlaggers {
+	progress.allWorksDone()
+
+	for _, flagger := range plan.authorFlaggers {
 		wg.Add(1)
 		go func(flagger AuthorFlagger) {
 			defer wg.Done()
 
+			defer progress.flaggerBatchDone(flagger.Name())
+
 			logger := logger.With("flagger", flagger.Name())
 
 			flags, err := flagger.Flag(logger, authorName, affiliations)
 			if err != nil {
 				logger.Error("flagger error", "error", err)
 				monitoring.FlaggerErrors.WithLabelValues(flagger.Name()).Inc()
-			} else {
-				flagsCh <- flags
 			}
+			resultsCh <- flaggerResult{flagger: flagger.Name(), version: flagger.Version(), flags: flags, err: err}
 		}(flagger)
 	}
 
 	wg.Wait()
-	close(flagsCh)
+	close(resultsCh)
 }
 
-func (processor *ReportProcessor) ProcessAuthorReport(report ReportUpdateTask) {
+// If the context is cancelled before the report is complete the report is
+// requeued so that it is processed again by the next available worker.
+func (processor *ReportProcessor) ProcessAuthorReport(ctx context.Context, report ReportUpdateTask) {
 	start := time.Now()
 
 	logger := slog.With("report_id", report.Id)
 
 	logger.Info("starting report processing", "author_id", report.AuthorId, "author_name", report.AuthorName, "source", report.Source, "is_university_queued", report.ForUniversityReport)
 
-	workStream, err := processor.getWorkStream(report)
+	plan := processor.planFlaggers(report)
+	if !report.UpdatedSince.IsZero() {
+		logger.Info("refreshing report incrementally", "updated_since", report.UpdatedSince, "n_incremental_work_flaggers", len(plan.incremental), "n_work_flaggers", len(plan.workFlaggers), "n_author_flaggers", len(plan.authorFlaggers))
+	}
+
+	workStream, err := processor.getWorkStream(report, plan.fetchUpdatedSince())
 	if err != nil {
 		logger.Error("report failed: unable to get author works", "error", err)
 		if err := processor.manager.UpdateAuthorReport(report.Id, schema.ReportFailed, time.Time{}, nil); err != nil {
@@ -116,14 +315,45 @@ func (processor *ReportProcessor) ProcessAuthorReport(report ReportUpdateTask) {
 		return
 	}
 
-	flagsCh := make(chan []api.Flag, 100)
+	resultsCh := make(chan flaggerResult, 100)
 
-	go processor.processWorks(logger, report.AuthorName, report.Affiliations, workStream, flagsCh, report.ForUniversityReport)
+	progress := newProgressTracker(report.Id, processor.manager, plan.workFlaggers, plan.authorFlaggers)
+	progress.start()
+
+	go processor.processWorks(logger, report.AuthorName, report.Affiliations, workStream, resultsCh, plan, progress)
+
+	// Flaggers that fail are not recorded as having run, so that they are run
+	// on all of the works again in the next update.
+	failed := make(map[string]bool)
 
 	seen := make(map[[sha256.Size]byte]struct{})
 	flagCounts := make(map[string]int)
-	for flags := range flagsCh {
-		for _, flag := range flags {
+flagLoop:
+	for {
+		var result flaggerResult
+		select {
+		case next, ok := <-resultsCh:
+			if !ok {
+				break flagLoop
+			}
+			result = next
+		case <-ctx.Done():
+			// The flaggers are left to finish in the background since the worker is
+			// shutting down, their results are discarded.
+			progress.stop()
+			logger.Info("report interrupted, requeueing report")
+			if err := processor.manager.RequeueAuthorReport(report.Id); err != nil {
+				slog.Error("error requeueing author report", "error", err)
+				monitoring.ReportUpdateErrors.Inc()
+			}
+			return
+		}
+
+		if result.err != nil {
+			failed[result.flagger] = true
+		}
+
+		for _, flag := range result.flags {
 			hash := flag.Hash()
 			if _, ok := seen[hash]; !ok {
 				seen[hash] = struct{}{}
@@ -132,9 +362,9 @@ func (processor *ReportProcessor) ProcessAuthorReport(report ReportUpdateTask) {
 			}
 		}
 
-		if len(flags) > 0 {
-			slog.Info("received batch of flags", "type", flags[0].Type(), "n_flags", len(flags))
-			if err := processor.manager.UpdateAuthorReport(report.Id, schema.ReportInProgress, report.EndDate, flags); err != nil {
+		if len(result.flags) > 0 {
+			slog.Info("received batch of flags", "type", result.flags[0].Type(), "n_flags", len(result.flags))
+			if err := processor.manager.UpdateAuthorReportFlags(report.Id, schema.ReportInProgress, report.EndDate, result.flagger, result.version, result.flags); err != nil {
 				slog.Error("error updating author report status for partial flags", "error", err)
 				monitoring.ReportUpdateErrors.Inc()
 			}
@@ -147,8 +377,29 @@ func (processor *ReportProcessor) ProcessAuthorReport(report ReportUpdateTask) {
 		attrs = append(attrs, slog.Int(flagType, count))
 	}
 
+	progress.stop()
+
 	logger.Info("report complete", attrs...)
 
+	versions, failedFlaggers := plan.results(failed)
+	if err := processor.manager.RecordAuthorReportFlaggers(report.Id, versions, failedFlaggers, time.Now().UTC()); err != nil {
+		slog.Error("error recording author report flagger versions", "error", err)
+		monitoring.ReportUpdateErrors.Inc()
+	}
+
+	// The watchlist versions are only recorded if all of the work flaggers saw
+	// all of the works, otherwise they are run on all of the works again in the
+	// next update.
+	workFlaggerFailed := failed[""] || slices.ContainsFunc(plan.workFlaggers, func(flagger WorkFlagger) bool {
+		return failed[flagger.Name()]
+	})
+	if plan.watchlistVersions != nil && !workFlaggerFailed {
+		if err := processor.manager.RecordAuthorReportWatchlists(report.Id, plan.watchlistVersions); err != nil {
+			slog.Error("error recording author report watchlist versions", "error", err)
+			monitoring.ReportUpdateErrors.Inc()
+		}
+	}
+
 	if err := processor.manager.UpdateAuthorReport(report.Id, schema.ReportCompleted, report.EndDate, nil); err != nil {
 		slog.Error("error updating author report status to complete", "error", err)
 		monitoring.ReportUpdateErrors.Inc()
@@ -157,7 +408,7 @@ func (processor *ReportProcessor) ProcessAuthorReport(report ReportUpdateTask) {
 	monitoring.ReportsProcessed.Observe(time.Since(start).Seconds())
 }
 
-func (processor *ReportProcessor) ProcessNextAuthorReport() bool {
+func (processor *ReportProcessor) ProcessNextAuthorReport(ctx context.Context) bool {
 	report, err := processor.manager.GetNextAuthorReport()
 	if err != nil {
 		slog.Error("error checking for next report", "error", err)
@@ -167,13 +418,20 @@ func (processor *ReportProcessor) ProcessNextAuthorReport() bool {
 		return false
 	}
 
-	processor.ProcessAuthorReport(*report)
+	processor.ProcessAuthorReport(ctx, *report)
 
 	return true
 }
 
 func (processor *ReportProcessor) getUniversityAuthors(report UniversityReportUpdateTask) ([]UniversityAuthorReport, error) {
-	authors, err := processor.openalex.GetInstitutionAuthors(report.UniversityId, time.Now().AddDate(-4, 0, 0), time.Now())
+	now := time.Now()
+
+	authors, err := processor.openalex.GetInstitutionAuthors(report.UniversityId, openalex.InstitutionAuthorFilter{
+		StartDate:                   now.AddDate(-report.AuthorDiscovery.LookbackYears, 0, 0),
+		EndDate:                     now,
+		MinWorks:                    report.AuthorDiscovery.MinWorks,
+		RequireLastKnownInstitution: report.AuthorDiscovery.RequireLastKnownInstitution,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -184,13 +442,14 @@ func (processor *ReportProcessor) getUniversityAuthors(report UniversityReportUp
 			AuthorId:   author.AuthorId,
 			AuthorName: author.AuthorName,
 			Source:     api.OpenAlexSource,
+			Department: author.Department,
 		})
 	}
 
 	return output, nil
 }
 
-func (processor *ReportProcessor) ProcessNextUniversityReport() bool {
+func (processor *ReportProcessor) ProcessNextUniversityReport(ctx context.Context) bool {
 	nextReport, err := processor.manager.GetNextUniversityReport()
 	if err != nil {
 		slog.Error("error checking for next report", "error", err)
@@ -202,9 +461,33 @@ func (processor *ReportProcessor) ProcessNextUniversityReport() bool {
 
 	slog.Info("processing university report", "report_id", nextReport.Id, "university_report_id", nextReport.UniversityId, "university_name", nextReport.UniversityName)
 
-	authors, err := processor.getUniversityAuthors(*nextReport)
+	type universityAuthorsResult struct {
+		authors []UniversityAuthorReport
+		err     error
+	}
+
+	resultCh := make(chan universityAuthorsResult, 1)
+	go func() {
+		authors, err := processor.getUniversityAuthors(*nextReport)
+		resultCh <- universityAuthorsResult{authors: authors, err: err}
+	}()
+
+	var authors []UniversityAuthorReport
+	select {
+	case result := <-resultCh:
+		authors, err = result.authors, result.err
+	case <-ctx.Done():
+		// The authors are left to load in the background since the worker is
+		// shutting down, the result is discarded.
+		slog.Info("university report interrupted, requeueing report", "report_id", nextReport.Id)
+		if err := processor.manager.RequeueUniversityReport(nextReport.Id); err != nil {
+			slog.Error("error requeueing university report", "error", err)
+		}
+		return true
+	}
+
 	if err != nil {
-		slog.Error("error processing university report: %w")
+		slog.Error("error processing university report", "report_id", nextReport.Id, "error", err)
 
 		if err := processor.manager.UpdateUniversityReport(nextReport.Id, schema.ReportFailed, time.Time{}, nil); err != nil {
 			slog.Error("error updating report status to failed", "error", err)
@@ -1,6 +1,7 @@
 package reports_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"prism/prism/api"
@@ -58,7 +59,7 @@ func yearEnd(year int) time.Time {
 
 func getReportContent(t *testing.T, report reports.ReportUpdateTask, processor *reports.ReportProcessor, manager *reports.ReportManager) map[string][]api.Flag {
 	user := uuid.New()
-	reportId, err := manager.CreateAuthorReport(user, report.AuthorId, report.AuthorName, report.Source, "", "")
+	reportId, err := manager.CreateAuthorReport(user, reports.AuthorReportParams{AuthorId: report.AuthorId, AuthorName: report.AuthorName, Source: report.Source})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -72,7 +73,7 @@ func getReportContent(t *testing.T, report reports.ReportUpdateTask, processor *
 	}
 
 	report.Id = nextReport.Id
-	processor.ProcessAuthorReport(report)
+	processor.ProcessAuthorReport(context.Background(), report)
 
 	content, err := manager.GetAuthorReport(user, reportId)
 	if err != nil {
@@ -87,7 +88,7 @@ func TestProcessorCoauthorAffiliation(t *testing.T) {
 
 	processor := reports.NewProcessor(
 		[]reports.WorkFlagger{
-			flaggers.NewOpenAlexCoauthorAffiliationIsEOC(eoc.LoadGeneralEOC(), eoc.LoadInstitutionEOC()),
+			flaggers.NewOpenAlexCoauthorAffiliationIsEOC(eoc.NewStore(eoc.NewWatchlistSet(eoc.SeedEntities()))),
 		},
 		nil,
 		manager,
@@ -173,7 +174,7 @@ func TestProcessorAuthorAffiliation(t *testing.T) {
 
 	processor := reports.NewProcessor(
 		[]reports.WorkFlagger{
-			flaggers.NewOpenAlexAuthorAffiliationIsEOC(eoc.LoadGeneralEOC(), eoc.LoadInstitutionEOC()),
+			flaggers.NewOpenAlexAuthorAffiliationIsEOC(eoc.NewStore(eoc.NewWatchlistSet(eoc.SeedEntities()))),
 		},
 		nil,
 		manager,
@@ -457,7 +458,7 @@ func TestProcessorAcknowledgements(t *testing.T) {
 	}
 	defer ackCache.Close()
 
-	entityStore := flaggers.BuildWatchlistEntityIndex(eoc.LoadSourceToAlias())
+	watchlists := eoc.NewStore(eoc.NewWatchlistSet(eoc.SeedEntities()))
 
 	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
 	if err != nil {
@@ -477,7 +478,7 @@ func TestProcessorAcknowledgements(t *testing.T) {
 	processor := reports.NewProcessor(
 		[]reports.WorkFlagger{
 			flaggers.NewOpenAlexAcknowledgementIsEOC(
-				entityStore, authorCache, flaggers.NewGrobidExtractor(ackCache, grobidEndpoint, 40, 10, "thirdai-prism"), eoc.LoadSussyBakas(), triangulationDB,
+				watchlists, authorCache, flaggers.NewGrobidExtractor(ackCache, grobidEndpoint, 40, 10, "thirdai-prism"), triangulationDB,
 			),
 		},
 		nil,
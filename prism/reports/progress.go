@@ -0,0 +1,179 @@
+package reports
+
+import (
+	"log/slog"
+	"prism/prism/api"
+	"prism/prism/monitoring"
+	"prism/prism/openalex"
+	"prism/prism/reports/utils"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Flaggers that download the pdfs of works implement this so that the pdf
+// downloads and grobid calls can be included in the progress of the report.
+type PdfWorkFlagger interface {
+	FlagWithPdfProgress(logger *slog.Logger, progress *utils.PdfProgress, works []openalex.Work, targetAuthorIds []string, authorName string) ([]api.Flag, error)
+}
+
+const progressSaveInterval = 5 * time.Second
+
+// Tracks the progress of a report while it is being processed and periodically
+// saves it to the report so that it can be shown to users.
+type progressTracker struct {
+	reportId uuid.UUID
+	manager  *ReportManager
+
+	pdfs utils.PdfProgress
+
+	lock            sync.Mutex
+	startedAt       time.Time
+	worksFetched    int
+	batchesFetched  int
+	allWorksFetched bool
+	// The number of batches of works each flagger will process, author flaggers
+	// process a single batch.
+	workFlaggers     int
+	authorFlaggers   int
+	flaggers         []string
+	batchesProcessed map[string]int
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProgressTracker(reportId uuid.UUID, manager *ReportManager, workFlaggers []WorkFlagger, authorFlaggers []AuthorFlagger) *progressTracker {
+	tracker := &progressTracker{
+		reportId:         reportId,
+		manager:          manager,
+		startedAt:        time.Now().UTC(),
+		workFlaggers:     len(workFlaggers),
+		authorFlaggers:   len(authorFlaggers),
+		batchesProcessed: make(map[string]int),
+		done:             make(chan struct{}),
+	}
+
+	for _, flagger := range workFlaggers {
+		tracker.flaggers = append(tracker.flaggers, flagger.Name())
+	}
+	for _, flagger := range authorFlaggers {
+		tracker.flaggers = append(tracker.flaggers, flagger.Name())
+	}
+
+	return tracker
+}
+
+// Starts saving the progress periodically until stop is called.
+func (t *progressTracker) start() {
+	t.save()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(progressSaveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.save()
+			case <-t.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stops the periodic saves and saves the final progress.
+func (t *progressTracker) stop() {
+	close(t.done)
+	t.wg.Wait()
+	t.save()
+}
+
+func (t *progressTracker) worksBatchFetched(nWorks int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.worksFetched += nWorks
+	t.batchesFetched++
+}
+
+func (t *progressTracker) allWorksDone() {
+	t.lock.Lock()
+	t.allWorksFetched = true
+	t.lock.Unlock()
+
+	// The estimate can be based on the progress of this report once the total
+	// number of batches is known, so it is saved right away.
+	t.save()
+}
+
+func (t *progressTracker) flaggerBatchDone(flagger string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.batchesProcessed[flagger]++
+}
+
+// Estimates when the report will complete. Until all of the works have been
+// fetched the estimate is based on how long previous reports took to process,
+// after that it is based on the fraction of batches that have been processed.
+func (t *progressTracker) estimateCompletion(now time.Time) *time.Time {
+	elapsed := now.Sub(t.startedAt)
+
+	total := t.batchesFetched*t.workFlaggers + t.authorFlaggers
+	processed := 0
+	for _, n := range t.batchesProcessed {
+		processed += n
+	}
+
+	var remaining time.Duration
+	if t.allWorksFetched && processed > 0 {
+		remaining = time.Duration(float64(elapsed) * float64(max(total-processed, 0)) / float64(processed))
+	} else if mean, ok := monitoring.MeanReportProcessingTime(); ok {
+		remaining = max(mean-elapsed, 0)
+	} else {
+		return nil
+	}
+
+	completion := now.Add(remaining)
+	return &completion
+}
+
+func (t *progressTracker) progress() api.ReportProgress {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := time.Now().UTC()
+
+	flaggers := make([]api.FlaggerProgress, 0, len(t.flaggers))
+	for _, flagger := range t.flaggers {
+		flaggers = append(flaggers, api.FlaggerProgress{Flagger: flagger, BatchesProcessed: t.batchesProcessed[flagger]})
+	}
+
+	downloaded, failed, grobidPending := t.pdfs.Counts()
+
+	return api.ReportProgress{
+		StartedAt:             t.startedAt,
+		UpdatedAt:             now,
+		WorksFetched:          t.worksFetched,
+		WorkBatchesFetched:    t.batchesFetched,
+		AllWorksFetched:       t.allWorksFetched,
+		Flaggers:              flaggers,
+		PdfsDownloaded:        downloaded,
+		PdfsFailed:            failed,
+		GrobidCallsPending:    grobidPending,
+		EstimatedCompletionAt: t.estimateCompletion(now),
+	}
+}
+
+func (t *progressTracker) save() {
+	if err := t.manager.UpdateAuthorReportProgress(t.reportId, t.progress()); err != nil {
+		slog.Error("error updating author report progress", "author_report_id", t.reportId, "error", err)
+		monitoring.ReportUpdateErrors.Inc()
+	}
+}
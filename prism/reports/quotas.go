@@ -0,0 +1,212 @@
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUsageAccessFailed = errors.New("usage access failed")
+	ErrQuotaExceeded     = errors.New("quota exceeded")
+)
+
+const (
+	QuotaPeriodDay   = "day"
+	QuotaPeriodMonth = "month"
+)
+
+// A limit of 0 is unlimited.
+type QuotaLimit struct {
+	User int
+	// The limit on the combined usage of all members of an organization.
+	Organization int
+}
+
+// The quotas that are enforced when users create reports or make requests that
+// call an LLM. The LLM calls made while generating reports are not counted
+// since the reports are shared between users, they are bounded by the report
+// quotas instead.
+type Quotas struct {
+	AuthorReportsPerDay       QuotaLimit
+	UniversityReportsPerMonth QuotaLimit
+	LLMCallsPerDay            QuotaLimit
+}
+
+type usageQuota struct {
+	metric      string
+	description string
+	period      string
+	limit       QuotaLimit
+}
+
+func (q Quotas) list() []usageQuota {
+	return []usageQuota{
+		{metric: schema.UsageAuthorReports, description: "author reports", period: QuotaPeriodDay, limit: q.AuthorReportsPerDay},
+		{metric: schema.UsageUniversityReports, description: "university reports", period: QuotaPeriodMonth, limit: q.UniversityReportsPerMonth},
+		{metric: schema.UsageLLMCalls, description: "LLM calls", period: QuotaPeriodDay, limit: q.LLMCallsPerDay},
+	}
+}
+
+func (q Quotas) get(metric string) (usageQuota, error) {
+	for _, quota := range q.list() {
+		if quota.metric == metric {
+			return quota, nil
+		}
+	}
+	return usageQuota{}, fmt.Errorf("unknown usage metric '%s'", metric)
+}
+
+// Returns the start and end of the period containing the given time, periods
+// start at midnight UTC.
+func quotaPeriod(period string, now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	if period == QuotaPeriodMonth {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Increments the usage of the user or organization if it is below the limit.
+// Returns false if the limit has been reached. The check and increment are a
+// single update so that concurrent requests cannot exceed the limit.
+func incrementUsage(txn *gorm.DB, ownerId uuid.UUID, metric string, periodStart time.Time, limit int) (bool, error) {
+	counter := schema.UsageCounter{OwnerId: ownerId, Metric: metric, PeriodStart: periodStart}
+	if err := txn.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+		return false, err
+	}
+
+	query := txn.Model(&schema.UsageCounter{}).Where("owner_id = ? AND metric = ? AND period_start = ?", ownerId, metric, periodStart)
+	if limit > 0 {
+		query = query.Where("used < ?", limit)
+	}
+
+	result := query.Update("used", gorm.Expr("used + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Records a use of the metric by the user and their organization. Returns
+// ErrQuotaExceeded if either has reached its limit, in which case the
+// transaction should be rolled back since the user's usage may have already
+// been incremented.
+func consumeQuota(txn *gorm.DB, quotas Quotas, userId uuid.UUID, metric string) error {
+	quota, err := quotas.get(metric)
+	if err != nil {
+		return err
+	}
+
+	periodStart, _ := quotaPeriod(quota.period, time.Now())
+
+	orgId, err := userOrganization(txn, userId)
+	if err != nil {
+		return err
+	}
+
+	ok, err := incrementUsage(txn, userId, metric, periodStart, quota.limit.User)
+	if err != nil {
+		slog.Error("error updating user usage", "user_id", userId, "metric", metric, "error", err)
+		return ErrUsageAccessFailed
+	}
+	if !ok {
+		return fmt.Errorf("%w: the limit of %d %s per %s has been reached", ErrQuotaExceeded, quota.limit.User, quota.description, quota.period)
+	}
+
+	if orgId != uuid.Nil {
+		ok, err := incrementUsage(txn, orgId, metric, periodStart, quota.limit.Organization)
+		if err != nil {
+			slog.Error("error updating organization usage", "organization_id", orgId, "metric", metric, "error", err)
+			return ErrUsageAccessFailed
+		}
+		if !ok {
+			return fmt.Errorf("%w: your organization's limit of %d %s per %s has been reached", ErrQuotaExceeded, quota.limit.Organization, quota.description, quota.period)
+		}
+	}
+
+	return nil
+}
+
+// Records a use of the metric by the user and their organization, this is for
+// usage that is not tracked by the manager itself, such as LLM calls.
+func (r *ReportManager) ConsumeQuota(userId uuid.UUID, metric string) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		return consumeQuota(txn, r.quotas, userId, metric)
+	})
+}
+
+func getQuotaUsage(txn *gorm.DB, ownerId uuid.UUID, metric string, periodStart time.Time, limit int) (api.QuotaUsage, error) {
+	var counter schema.UsageCounter
+	if err := txn.Where("owner_id = ? AND metric = ? AND period_start = ?", ownerId, metric, periodStart).Limit(1).Find(&counter).Error; err != nil {
+		return api.QuotaUsage{}, err
+	}
+
+	usage := api.QuotaUsage{Used: counter.Used}
+	if limit > 0 {
+		remaining := max(limit-counter.Used, 0)
+		usage.Limit = &limit
+		usage.Remaining = &remaining
+	}
+
+	return usage, nil
+}
+
+// Returns the usage of the user and their organization in the current period
+// of each quota.
+func (r *ReportManager) GetUsage(userId uuid.UUID) (api.Usage, error) {
+	orgId, err := userOrganization(r.db, userId)
+	if err != nil {
+		return api.Usage{}, err
+	}
+
+	now := time.Now()
+
+	quotas := r.quotas.list()
+	usage := api.Usage{Quotas: make([]api.Quota, 0, len(quotas))}
+
+	for _, quota := range quotas {
+		periodStart, periodEnd := quotaPeriod(quota.period, now)
+
+		user, err := getQuotaUsage(r.db, userId, quota.metric, periodStart, quota.limit.User)
+		if err != nil {
+			slog.Error("error getting user usage", "user_id", userId, "metric", quota.metric, "error", err)
+			return api.Usage{}, ErrUsageAccessFailed
+		}
+
+		output := api.Quota{
+			Metric:    quota.metric,
+			Period:    quota.period,
+			ResetsAt:  periodEnd,
+			User:      user,
+			Remaining: user.Remaining,
+		}
+
+		if orgId != uuid.Nil {
+			org, err := getQuotaUsage(r.db, orgId, quota.metric, periodStart, quota.limit.Organization)
+			if err != nil {
+				slog.Error("error getting organization usage", "organization_id", orgId, "metric", quota.metric, "error", err)
+				return api.Usage{}, ErrUsageAccessFailed
+			}
+			output.Organization = &org
+
+			if org.Remaining != nil && (output.Remaining == nil || *org.Remaining < *output.Remaining) {
+				output.Remaining = org.Remaining
+			}
+		}
+
+		usage.Quotas = append(usage.Quotas, output)
+	}
+
+	return usage, nil
+}
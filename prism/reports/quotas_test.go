@@ -0,0 +1,111 @@
+package reports_test
+
+import (
+	"errors"
+	"prism/prism/api"
+	"prism/prism/reports"
+	"prism/prism/schema"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func findQuota(t *testing.T, manager *reports.ReportManager, userId uuid.UUID, metric string) api.Quota {
+	t.Helper()
+
+	usage, err := manager.GetUsage(userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, quota := range usage.Quotas {
+		if quota.Metric == metric {
+			return quota
+		}
+	}
+	t.Fatalf("quota for %s not found", metric)
+	return api.Quota{}
+}
+
+func TestQuotas(t *testing.T) {
+	manager := setup(t).SetQuotas(reports.Quotas{
+		AuthorReportsPerDay:       reports.QuotaLimit{User: 2, Organization: 1},
+		UniversityReportsPerMonth: reports.QuotaLimit{User: 1},
+	})
+
+	user1, user2 := uuid.New(), uuid.New()
+
+	createReport := func(userId uuid.UUID, authorId string) error {
+		_, err := manager.CreateAuthorReport(userId, reports.AuthorReportParams{AuthorId: authorId, AuthorName: "name", Source: api.OpenAlexSource})
+		return err
+	}
+
+	if err := createReport(user1, "1"); err != nil {
+		t.Fatal(err)
+	}
+	// Requesting a report the user already has does not count towards the quota.
+	if err := createReport(user1, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := createReport(user1, "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := createReport(user1, "3"); !errors.Is(err, reports.ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded error, got %v", err)
+	}
+
+	quota := findQuota(t, manager, user1, schema.UsageAuthorReports)
+	if quota.Period != reports.QuotaPeriodDay || quota.User.Used != 2 || *quota.User.Limit != 2 || *quota.Remaining != 0 || quota.Organization != nil {
+		t.Fatalf("incorrect quota: %+v", quota)
+	}
+
+	// Unlimited quotas are still counted.
+	if err := manager.ConsumeQuota(user1, schema.UsageLLMCalls); err != nil {
+		t.Fatal(err)
+	}
+	quota = findQuota(t, manager, user1, schema.UsageLLMCalls)
+	if quota.User.Used != 1 || quota.User.Limit != nil || quota.Remaining != nil {
+		t.Fatalf("incorrect quota: %+v", quota)
+	}
+
+	if _, err := manager.CreateUniversityReport(user2, "1", "university", "", api.UniversityAuthorDiscovery{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.CreateUniversityReport(user2, "2", "university", "", api.UniversityAuthorDiscovery{}); !errors.Is(err, reports.ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded error, got %v", err)
+	}
+	// The authors of university reports do not count towards the author report quota.
+	if quota := findQuota(t, manager, user2, schema.UsageAuthorReports); quota.User.Used != 0 {
+		t.Fatalf("incorrect quota: %+v", quota)
+	}
+
+	// The organization limit applies to the combined usage of its members. Usage
+	// from before a user joins the organization is not counted.
+	orgId, err := manager.CreateOrganization(user1, "user1@example.com", "org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.InviteOrganizationMember(user1, "user2@example.com", schema.OrganizationRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.AcceptOrganizationInvite(user2, "user2@example.com", orgId); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := createReport(user2, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := createReport(user2, "2"); !errors.Is(err, reports.ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded error, got %v", err)
+	}
+
+	// The user's usage is not incremented if the organization limit is reached.
+	quota = findQuota(t, manager, user2, schema.UsageAuthorReports)
+	if quota.User.Used != 1 || *quota.User.Remaining != 1 || quota.Organization.Used != 1 || *quota.Organization.Remaining != 0 || *quota.Remaining != 0 {
+		t.Fatalf("incorrect quota: %+v", quota)
+	}
+
+	// A report that exceeds the quota is not created.
+	if listed, err := manager.ListAuthorReports(user2, false); err != nil || len(listed) != 1 {
+		t.Fatalf("expected 1 report: %v, %v", listed, err)
+	}
+}
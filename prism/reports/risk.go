@@ -0,0 +1,281 @@
+package reports
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RiskTierLow    = "low"
+	RiskTierMedium = "medium"
+	RiskTierHigh   = "high"
+
+	defaultFlagTypeWeight = 1.0
+)
+
+type RiskScorer interface {
+	// Returns the score of the flag, higher scores are more concerning.
+	ScoreFlag(flag api.Flag, now time.Time) float64
+
+	// Returns the tier for the total score of a report.
+	Tier(score float64) string
+}
+
+// Creates the scorer for the weights of an organization.
+type RiskScorerFactory func(weights api.RiskWeights) RiskScorer
+
+func DefaultRiskWeights() api.RiskWeights {
+	return api.RiskWeights{
+		FlagTypes: map[string]float64{
+			api.TalentContractType:               10,
+			api.AssociationsWithDeniedEntityType: 10,
+			api.MiscHighRiskAssociationType:      8,
+			api.HighRiskFunderType:               6,
+			api.AuthorAffiliationType:            6,
+			api.PotentialAuthorAffiliationType:   3,
+			api.CoauthorAffiliationType:          2,
+			api.MultipleAffiliationType:          1,
+			api.HighRiskPublisherType:            1,
+			api.HighRiskCoauthorType:             1,
+		},
+		Sources:              map[string]float64{},
+		RecencyHalfLifeYears: 5,
+		MinRecencyFactor:     0.25,
+		DisclosedFactor:      0.25,
+		DismissedFactor:      0,
+		MediumTierThreshold:  10,
+		HighTierThreshold:    30,
+	}
+}
+
+func ValidateRiskWeights(weights api.RiskWeights) error {
+	valid := func(v float64) bool {
+		return v >= 0 && !math.IsInf(v, 0) && !math.IsNaN(v)
+	}
+
+	for flagType, weight := range weights.FlagTypes {
+		if _, ok := DefaultRiskWeights().FlagTypes[flagType]; !ok {
+			return errors.New("invalid flag type '" + flagType + "'")
+		}
+		if !valid(weight) {
+			return errors.New("flag type weights must be non-negative")
+		}
+	}
+	for _, factor := range weights.Sources {
+		if !valid(factor) {
+			return errors.New("source factors must be non-negative")
+		}
+	}
+
+	for _, v := range []float64{weights.RecencyHalfLifeYears, weights.MinRecencyFactor, weights.DisclosedFactor, weights.DismissedFactor, weights.MediumTierThreshold, weights.HighTierThreshold} {
+		if !valid(v) {
+			return errors.New("weights must be non-negative")
+		}
+	}
+
+	if weights.MinRecencyFactor > 1 {
+		return errors.New("min recency factor must be at most 1")
+	}
+	if weights.MediumTierThreshold > weights.HighTierThreshold {
+		return errors.New("medium tier threshold must not be greater than the high tier threshold")
+	}
+
+	return nil
+}
+
+// Scores flags with the weight of their type, discounted by how long ago the
+// flag was and if it is disclosed or dismissed. Flags whose entities are on
+// source lists are scaled by the factor of the lists.
+type WeightedRiskScorer struct {
+	weights api.RiskWeights
+}
+
+func NewWeightedRiskScorer(weights api.RiskWeights) RiskScorer {
+	return &WeightedRiskScorer{weights: weights}
+}
+
+func flagSources(flag api.Flag) []string {
+	var entities []api.AcknowledgementEntity
+	switch flag := flag.(type) {
+	case *api.TalentContractFlag:
+		entities = flag.Entities
+	case *api.AssociationWithDeniedEntityFlag:
+		entities = flag.Entities
+	}
+
+	sources := make([]string, 0)
+	for _, entity := range entities {
+		sources = append(sources, entity.Sources...)
+	}
+	return sources
+}
+
+func (s *WeightedRiskScorer) ScoreFlag(flag api.Flag, now time.Time) float64 {
+	score, ok := s.weights.FlagTypes[flag.Type()]
+	if !ok {
+		score = defaultFlagTypeWeight
+	}
+
+	if date, ok := flag.Date(); ok && s.weights.RecencyHalfLifeYears > 0 {
+		years := now.Sub(date).Hours() / (24 * 365.25)
+		recency := math.Pow(0.5, max(years, 0)/s.weights.RecencyHalfLifeYears)
+		score *= max(recency, s.weights.MinRecencyFactor)
+	}
+
+	sourceFactor := 0.0
+	hasSource := false
+	for _, source := range flagSources(flag) {
+		factor, ok := s.weights.Sources[source]
+		if !ok {
+			factor = 1
+		}
+		if !hasSource || factor > sourceFactor {
+			sourceFactor = factor
+			hasSource = true
+		}
+	}
+	if hasSource {
+		score *= sourceFactor
+	}
+
+	if flag.IsDisclosed() {
+		score *= s.weights.DisclosedFactor
+	}
+
+	if triage := flag.GetTriage(); triage != nil && triage.Status == schema.TriageDismissed {
+		score *= s.weights.DismissedFactor
+	}
+
+	return score
+}
+
+func (s *WeightedRiskScorer) Tier(score float64) string {
+	switch {
+	case score >= s.weights.HighTierThreshold:
+		return RiskTierHigh
+	case score >= s.weights.MediumTierThreshold:
+		return RiskTierMedium
+	default:
+		return RiskTierLow
+	}
+}
+
+func ScoreReportContent(scorer RiskScorer, content map[string][]api.Flag, now time.Time) *api.RiskScore {
+	output := &api.RiskScore{FlagScores: make(map[string]float64)}
+
+	for _, flags := range content {
+		for _, flag := range flags {
+			// The hash is computed from the flag since flags in reports uploaded for
+			// download do not have the hash set.
+			hash := flag.Hash()
+			score := scorer.ScoreFlag(flag, now)
+			output.FlagScores[hex.EncodeToString(hash[:])] = score
+			output.Score += score
+		}
+	}
+
+	output.Tier = scorer.Tier(output.Score)
+
+	return output
+}
+
+// Weights that are not set for the organization use the default weights.
+func getRiskWeights(txn *gorm.DB, organizationId uuid.UUID) (api.RiskWeights, error) {
+	weights := DefaultRiskWeights()
+
+	var stored schema.RiskWeights
+	if err := txn.Limit(1).Find(&stored, "organization_id = ?", organizationId).Error; err != nil {
+		slog.Error("error getting risk weights", "organization_id", organizationId, "error", err)
+		return api.RiskWeights{}, ErrReportAccessFailed
+	}
+
+	if len(stored.Weights) == 0 {
+		return weights, nil
+	}
+
+	if err := json.Unmarshal(stored.Weights, &weights); err != nil {
+		slog.Error("error parsing risk weights", "organization_id", organizationId, "error", err)
+		return api.RiskWeights{}, ErrReportAccessFailed
+	}
+
+	if weights.FlagTypes == nil {
+		weights.FlagTypes = make(map[string]float64)
+	}
+	for flagType, weight := range DefaultRiskWeights().FlagTypes {
+		if _, ok := weights.FlagTypes[flagType]; !ok {
+			weights.FlagTypes[flagType] = weight
+		}
+	}
+
+	return weights, nil
+}
+
+// Returns the weights of the user's organization, users that are not in an
+// organization use the default weights.
+func getUserRiskWeights(txn *gorm.DB, userId uuid.UUID) (api.RiskWeights, error) {
+	orgId, err := userOrganization(txn, userId)
+	if err != nil {
+		return api.RiskWeights{}, ErrReportAccessFailed
+	}
+	return getRiskWeights(txn, orgId)
+}
+
+func (r *ReportManager) GetRiskWeights(userId uuid.UUID) (api.RiskWeights, error) {
+	return getUserRiskWeights(r.db, userId)
+}
+
+// Updates the weights of the user's organization. Users that are not in an
+// organization cannot update the weights, since the default weights are shared
+// by all users that are not in an organization.
+func (r *ReportManager) UpdateRiskWeights(userId uuid.UUID, weights api.RiskWeights) error {
+	data, err := json.Marshal(weights)
+	if err != nil {
+		slog.Error("error serializing risk weights", "error", err)
+		return ErrReportAccessFailed
+	}
+
+	orgId, err := userOrganization(r.db, userId)
+	if err != nil {
+		return ErrReportAccessFailed
+	}
+	if orgId == uuid.Nil {
+		return ErrUserNotInOrganization
+	}
+
+	stored := schema.RiskWeights{
+		OrganizationId: orgId,
+		Weights:        data,
+		UpdatedBy:      userId,
+		UpdatedAt:      time.Now().UTC(),
+	}
+
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		if err := txn.Clauses(clause.OnConflict{UpdateAll: true}).Create(&stored).Error; err != nil {
+			slog.Error("error updating risk weights", "organization_id", stored.OrganizationId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		// The risk scores of the university reports depend on the weights.
+		return invalidateUniversityReportSummaries(txn, "organization_id = ?", orgId)
+	})
+}
+
+// Scores report content for the user, this is used for reports with content
+// that is not loaded from the database.
+func (r *ReportManager) ScoreReportContent(userId uuid.UUID, content map[string][]api.Flag) (*api.RiskScore, error) {
+	weights, err := r.GetRiskWeights(userId)
+	if err != nil {
+		return nil, err
+	}
+	return ScoreReportContent(r.riskScorer(weights), content, time.Now().UTC()), nil
+}
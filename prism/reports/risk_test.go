@@ -0,0 +1,95 @@
+package reports_test
+
+import (
+	"encoding/hex"
+	"math"
+	"prism/prism/api"
+	"prism/prism/reports"
+	"prism/prism/schema"
+	"testing"
+	"time"
+)
+
+func checkScore(t *testing.T, scorer reports.RiskScorer, flag api.Flag, now time.Time, expected float64) {
+	t.Helper()
+	if score := scorer.ScoreFlag(flag, now); math.Abs(score-expected) > 1e-9 {
+		t.Fatalf("expected score %f, got %f", expected, score)
+	}
+}
+
+func TestWeightedRiskScorer(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	weights := reports.DefaultRiskWeights()
+	weights.Sources = map[string]float64{"list-a": 2, "list-b": 0.5}
+	scorer := reports.NewWeightedRiskScorer(weights)
+
+	recent := api.WorkSummary{WorkId: "w1", PublicationDate: now}
+	talent := &api.TalentContractFlag{Work: recent}
+	checkScore(t, scorer, talent, now, 10)
+
+	potential := &api.PotentialAuthorAffiliationFlag{University: "uni"}
+	checkScore(t, scorer, potential, now, 3)
+
+	// The score halves every half life, down to the minimum factor.
+	old := &api.TalentContractFlag{Work: api.WorkSummary{WorkId: "w2", PublicationDate: now.AddDate(-5, 0, 0)}}
+	if score := scorer.ScoreFlag(old, now); math.Abs(score-5) > 0.01 {
+		t.Fatalf("expected score 5, got %f", score)
+	}
+	veryOld := &api.TalentContractFlag{Work: api.WorkSummary{WorkId: "w3", PublicationDate: now.AddDate(-20, 0, 0)}}
+	checkScore(t, scorer, veryOld, now, 10*weights.MinRecencyFactor)
+
+	// The highest factor of the sources is used.
+	sourced := &api.TalentContractFlag{Work: api.WorkSummary{WorkId: "w4", PublicationDate: now}, Entities: []api.AcknowledgementEntity{{Entity: "a", Sources: []string{"list-b"}}, {Entity: "b", Sources: []string{"list-a"}}}}
+	checkScore(t, scorer, sourced, now, 20)
+	unknownSource := &api.TalentContractFlag{Work: recent, Entities: []api.AcknowledgementEntity{{Entity: "a", Sources: []string{"list-b", "list-c"}}}}
+	checkScore(t, scorer, unknownSource, now, 10)
+
+	disclosed := &api.TalentContractFlag{Work: recent}
+	disclosed.MarkDisclosed(api.DisclosureEvidence{Confidence: 1})
+	checkScore(t, scorer, disclosed, now, 10*weights.DisclosedFactor)
+
+	dismissed := &api.TalentContractFlag{Work: recent}
+	dismissed.SetTriage(&api.FlagTriage{Status: schema.TriageDismissed})
+	checkScore(t, scorer, dismissed, now, 0)
+
+	score := reports.ScoreReportContent(scorer, map[string][]api.Flag{
+		api.TalentContractType:             {talent, sourced},
+		api.PotentialAuthorAffiliationType: {potential},
+	}, now)
+	if score.Score != 33 || score.Tier != reports.RiskTierHigh {
+		t.Fatalf("incorrect report score: %+v", score)
+	}
+	hash := sourced.Hash()
+	if len(score.FlagScores) != 3 || score.FlagScores[hex.EncodeToString(hash[:])] != 20 {
+		t.Fatalf("incorrect flag scores: %+v", score.FlagScores)
+	}
+
+	for expected, total := range map[string]float64{reports.RiskTierLow: 9.9, reports.RiskTierMedium: 10, reports.RiskTierHigh: 30} {
+		if tier := scorer.Tier(total); tier != expected {
+			t.Fatalf("expected tier %s for %f, got %s", expected, total, tier)
+		}
+	}
+}
+
+func TestValidateRiskWeights(t *testing.T) {
+	if err := reports.ValidateRiskWeights(reports.DefaultRiskWeights()); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []func(w *api.RiskWeights){
+		func(w *api.RiskWeights) { w.FlagTypes["NotAFlag"] = 1 },
+		func(w *api.RiskWeights) { w.FlagTypes[api.TalentContractType] = -1 },
+		func(w *api.RiskWeights) { w.Sources["list"] = math.Inf(1) },
+		func(w *api.RiskWeights) { w.MinRecencyFactor = 2 },
+		func(w *api.RiskWeights) { w.DisclosedFactor = math.NaN() },
+		func(w *api.RiskWeights) { w.MediumTierThreshold = 50 },
+	}
+	for i, update := range invalid {
+		weights := reports.DefaultRiskWeights()
+		update(&weights)
+		if err := reports.ValidateRiskWeights(weights); err == nil {
+			t.Fatalf("expected weights %d to be invalid", i)
+		}
+	}
+}
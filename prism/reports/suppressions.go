@@ -0,0 +1,265 @@
+package reports
+
+import (
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrSuppressionNotFound = errors.New("flag suppression not found")
+
+type FlagSuppressions []schema.FlagSuppression
+
+func (s FlagSuppressions) Suppresses(flag api.Flag) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	hash := flag.Hash()
+	flagHash := hex.EncodeToString(hash[:])
+
+	for _, suppression := range s {
+		if suppression.FlagHash != "" {
+			if suppression.FlagHash == flagHash {
+				return true
+			}
+			continue
+		}
+
+		if suppression.FlagType != flag.Type() {
+			continue
+		}
+		for _, entity := range flag.GetEntities() {
+			if strings.EqualFold(strings.TrimSpace(entity), suppression.Entity) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Returns the flags that are not suppressed.
+func (s FlagSuppressions) Filter(flags []api.Flag) []api.Flag {
+	if len(s) == 0 {
+		return flags
+	}
+
+	output := make([]api.Flag, 0, len(flags))
+	for _, flag := range flags {
+		if !s.Suppresses(flag) {
+			output = append(output, flag)
+		}
+	}
+	return output
+}
+
+// Returns the suppressions for the author. A suppression matches if its author
+// id is one of the author's ids (the author id, ORCID, or the ids of the merged
+// profiles of the report), or if it has an author name that matches the
+// author's name, so that it also applies to the reports for the author that
+// were created from unstructured text or other sources.
+func (s FlagSuppressions) ForAuthor(authorName string, authorIds ...string) FlagSuppressions {
+	var output FlagSuppressions
+	for _, suppression := range s {
+		if slices.Contains(authorIds, suppression.AuthorId) ||
+			(suppression.AuthorName != "" && strings.EqualFold(suppression.AuthorName, strings.TrimSpace(authorName))) {
+			output = append(output, suppression)
+		}
+	}
+	return output
+}
+
+func (s FlagSuppressions) forReport(report *api.Report) FlagSuppressions {
+	authorIds := []string{report.AuthorId}
+	if report.Orcid != "" {
+		authorIds = append(authorIds, report.Orcid)
+	}
+	for _, identity := range report.Identities {
+		authorIds = append(authorIds, identity.AuthorId)
+	}
+	return s.ForAuthor(report.AuthorName, authorIds...)
+}
+
+// Removes the flags that are suppressed from the content of the report.
+func (s FlagSuppressions) filterReport(report *api.Report) {
+	suppressions := s.forReport(report)
+	if len(suppressions) == 0 {
+		return
+	}
+
+	for flagType, flags := range report.Content {
+		report.Content[flagType] = suppressions.Filter(flags)
+	}
+}
+
+// Returns the active suppressions of the user's organization. Suppressions are
+// applied when the reports are loaded instead of when they are processed since
+// the author reports are shared by the users of all organizations.
+func getOrganizationFlagSuppressions(txn *gorm.DB, userId uuid.UUID) (FlagSuppressions, error) {
+	orgId, err := userOrganization(txn, userId)
+	if err != nil {
+		return nil, ErrReportAccessFailed
+	}
+	return getFlagSuppressions(txn, orgId)
+}
+
+func getFlagSuppressions(txn *gorm.DB, orgId uuid.UUID) (FlagSuppressions, error) {
+	if orgId == uuid.Nil {
+		return nil, nil
+	}
+
+	var suppressions []schema.FlagSuppression
+	if err := txn.Find(&suppressions, "organization_id = ? AND removed_at IS NULL", orgId).Error; err != nil {
+		slog.Error("error getting flag suppressions", "organization_id", orgId, "error", err)
+		return nil, ErrReportAccessFailed
+	}
+	return suppressions, nil
+}
+
+// Removes the flags that are suppressed by the user's organization from the
+// report.
+func ApplyFlagSuppressions(txn *gorm.DB, userId uuid.UUID, report *api.Report) error {
+	suppressions, err := getOrganizationFlagSuppressions(txn, userId)
+	if err != nil {
+		return err
+	}
+	suppressions.filterReport(report)
+	return nil
+}
+
+func convertSuppression(suppression schema.FlagSuppression) api.FlagSuppression {
+	output := api.FlagSuppression{
+		Id:             suppression.Id,
+		OrganizationId: suppression.OrganizationId,
+		AuthorId:       suppression.AuthorId,
+		AuthorName:     suppression.AuthorName,
+		FlagType:       suppression.FlagType,
+		Entity:         suppression.Entity,
+		FlagHash:       suppression.FlagHash,
+		Reason:         suppression.Reason,
+		CreatedBy:      suppression.CreatedBy,
+		CreatedAt:      suppression.CreatedAt,
+	}
+	if suppression.RemovedBy.Valid {
+		output.RemovedBy = &suppression.RemovedBy.UUID
+	}
+	if suppression.RemovedAt.Valid {
+		output.RemovedAt = &suppression.RemovedAt.Time
+	}
+	return output
+}
+
+func (r *ReportManager) GetActiveFlagSuppressions(userId uuid.UUID) (FlagSuppressions, error) {
+	return getOrganizationFlagSuppressions(r.db, userId)
+}
+
+// Creates the suppression for the user's organization. Flags that match the
+// suppression are omitted from the reports of the members of the organization
+// when the reports are loaded, the flags are not removed from the reports.
+func (r *ReportManager) CreateFlagSuppression(userId uuid.UUID, params api.CreateFlagSuppressionRequest) (api.FlagSuppression, error) {
+	orgId, err := userOrganization(r.db, userId)
+	if err != nil {
+		return api.FlagSuppression{}, ErrReportCreationFailed
+	}
+	if orgId == uuid.Nil {
+		return api.FlagSuppression{}, ErrUserNotInOrganization
+	}
+
+	suppression := schema.FlagSuppression{
+		Id:             uuid.New(),
+		OrganizationId: orgId,
+		AuthorId:       params.AuthorId,
+		AuthorName:     strings.TrimSpace(params.AuthorName),
+		FlagType:       params.FlagType,
+		Entity:         strings.TrimSpace(params.Entity),
+		FlagHash:       strings.ToLower(params.FlagHash),
+		Reason:         params.Reason,
+		CreatedBy:      userId,
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	if err := r.db.Transaction(func(txn *gorm.DB) error {
+		if err := txn.Create(&suppression).Error; err != nil {
+			slog.Error("error creating flag suppression", "error", err)
+			return ErrReportCreationFailed
+		}
+		return invalidateUniversityReportSummaries(txn, "organization_id = ?", orgId)
+	}); err != nil {
+		return api.FlagSuppression{}, err
+	}
+
+	return convertSuppression(suppression), nil
+}
+
+// Lists the suppressions of the user's organization, users that are not in an
+// organization have no suppressions.
+func (r *ReportManager) ListFlagSuppressions(userId uuid.UUID, authorId string, includeRemoved bool) ([]api.FlagSuppression, error) {
+	orgId, err := userOrganization(r.db, userId)
+	if err != nil {
+		return nil, ErrReportAccessFailed
+	}
+	if orgId == uuid.Nil {
+		return []api.FlagSuppression{}, nil
+	}
+
+	query := r.db.Where("organization_id = ?", orgId).Order("created_at DESC")
+	if authorId != "" {
+		query = query.Where("author_id = ?", authorId)
+	}
+	if !includeRemoved {
+		query = query.Where("removed_at IS NULL")
+	}
+
+	var suppressions []schema.FlagSuppression
+	if err := query.Find(&suppressions).Error; err != nil {
+		slog.Error("error listing flag suppressions", "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	results := make([]api.FlagSuppression, 0, len(suppressions))
+	for _, suppression := range suppressions {
+		results = append(results, convertSuppression(suppression))
+	}
+
+	return results, nil
+}
+
+// Marks the suppression as removed. The flags it suppressed were never removed
+// from the reports, so they are shown again the next time the reports are
+// loaded, including reports that are currently being processed.
+func (r *ReportManager) RemoveFlagSuppression(userId, suppressionId uuid.UUID) error {
+	orgId, err := userOrganization(r.db, userId)
+	if err != nil {
+		return ErrReportAccessFailed
+	}
+	if orgId == uuid.Nil {
+		return ErrSuppressionNotFound
+	}
+
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		result := txn.Model(&schema.FlagSuppression{}).
+			Where("id = ? AND organization_id = ? AND removed_at IS NULL", suppressionId, orgId).
+			Updates(map[string]any{
+				"removed_by": uuid.NullUUID{UUID: userId, Valid: true},
+				"removed_at": time.Now().UTC(),
+			})
+		if result.Error != nil {
+			slog.Error("error removing flag suppression", "suppression_id", suppressionId, "error", result.Error)
+			return ErrReportAccessFailed
+		}
+		if result.RowsAffected == 0 {
+			return ErrSuppressionNotFound
+		}
+
+		return invalidateUniversityReportSummaries(txn, "organization_id = ?", orgId)
+	})
+}
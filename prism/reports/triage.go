@@ -0,0 +1,117 @@
+package reports
+
+import (
+	"errors"
+	"log/slog"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func convertTriage(triage schema.FlagTriage) *api.FlagTriage {
+	return &api.FlagTriage{
+		Status:     triage.Status,
+		Note:       triage.Note,
+		ReviewerId: triage.ReviewerId,
+		UpdatedAt:  triage.UpdatedAt,
+	}
+}
+
+func getUserAuthorReport(txn *gorm.DB, userId, reportId uuid.UUID, access reportAccess) (schema.UserAuthorReport, error) {
+	var report schema.UserAuthorReport
+	if err := txn.First(&report, "id = ?", reportId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return schema.UserAuthorReport{}, ErrReportNotFound
+		}
+		slog.Error("error getting user author report", "author_report_id", reportId, "error", err)
+		return schema.UserAuthorReport{}, ErrReportAccessFailed
+	}
+
+	if err := checkUserReportAccess(txn, userId, report, access); err != nil {
+		return schema.UserAuthorReport{}, err
+	}
+
+	return report, nil
+}
+
+func (r *ReportManager) GetFlagTriage(userId, reportId uuid.UUID, flagHash string) (api.FlagTriage, error) {
+	if _, err := getUserAuthorReport(r.db, userId, reportId, readAccess); err != nil {
+		return api.FlagTriage{}, err
+	}
+
+	var triage schema.FlagTriage
+	if err := r.db.First(&triage, "report_id = ? AND flag_hash = ?", reportId, flagHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.FlagTriage{}, ErrFlagNotFound
+		}
+		slog.Error("error getting flag triage", "author_report_id", reportId, "flag_hash", flagHash, "error", err)
+		return api.FlagTriage{}, ErrReportAccessFailed
+	}
+
+	return *convertTriage(triage), nil
+}
+
+func (r *ReportManager) UpdateFlagTriage(userId, reportId uuid.UUID, flagHash, status, note string) (api.FlagTriage, error) {
+	var triage schema.FlagTriage
+
+	err := r.db.Transaction(func(txn *gorm.DB) error {
+		report, err := getUserAuthorReport(txn, userId, reportId, writeAccess)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := txn.Model(&schema.AuthorFlag{}).Where("report_id = ? AND flag_hash = ?", report.ReportId, flagHash).Count(&count).Error; err != nil {
+			slog.Error("error checking for flag", "author_report_id", reportId, "flag_hash", flagHash, "error", err)
+			return ErrReportAccessFailed
+		}
+		if count == 0 {
+			return ErrFlagNotFound
+		}
+
+		triage = schema.FlagTriage{
+			ReportId:   reportId,
+			FlagHash:   flagHash,
+			Status:     status,
+			Note:       note,
+			ReviewerId: userId,
+			UpdatedAt:  time.Now().UTC(),
+		}
+
+		if err := txn.Clauses(clause.OnConflict{UpdateAll: true}).Create(&triage).Error; err != nil {
+			slog.Error("error updating flag triage", "author_report_id", reportId, "flag_hash", flagHash, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return api.FlagTriage{}, err
+	}
+
+	return *convertTriage(triage), nil
+}
+
+func (r *ReportManager) DeleteFlagTriage(userId, reportId uuid.UUID, flagHash string) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		if _, err := getUserAuthorReport(txn, userId, reportId, writeAccess); err != nil {
+			return err
+		}
+
+		result := txn.Delete(&schema.FlagTriage{}, "report_id = ? AND flag_hash = ?", reportId, flagHash)
+		if result.Error != nil {
+			slog.Error("error deleting flag triage", "author_report_id", reportId, "flag_hash", flagHash, "error", result.Error)
+			return ErrReportAccessFailed
+		}
+		if result.RowsAffected != 1 {
+			return ErrFlagNotFound
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,608 @@
+package reports
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// The last N years of flags that will be added to the university report.
+	yearsInUniversityReport = 5
+
+	DefaultUniversityLookbackYears = 4
+	DefaultUniversityMinWorks      = 1
+
+	DefaultUniversityAuthorPageSize = 50
+	MaxUniversityAuthorPageSize     = 500
+
+	// Can be used as the page size to return all of the authors in a single
+	// page, e.g. for downloads. It is not valid in requests.
+	AllUniversityAuthors = -1
+
+	UniversityAuthorSortRisk  = "risk"
+	UniversityAuthorSortFlags = "flags"
+	UniversityAuthorSortName  = "name"
+
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+func withDefaultUniversityAuthorDiscovery(discovery api.UniversityAuthorDiscovery) api.UniversityAuthorDiscovery {
+	if discovery.LookbackYears <= 0 {
+		discovery.LookbackYears = DefaultUniversityLookbackYears
+	}
+	if discovery.MinWorks <= 0 {
+		discovery.MinWorks = DefaultUniversityMinWorks
+	}
+	return discovery
+}
+
+func universityAuthorDiscovery(report schema.UniversityReport) api.UniversityAuthorDiscovery {
+	return api.UniversityAuthorDiscovery{
+		LookbackYears:               report.LookbackYears,
+		MinWorks:                    report.MinWorks,
+		RequireLastKnownInstitution: report.RequireLastKnownInstitution,
+	}
+}
+
+// Controls which authors of a university report are returned. The flag
+// counts, department breakdowns, and yearly trends are always computed over
+// all of the authors, the query only applies to the page of authors.
+type UniversityAuthorQuery struct {
+	Page     int // Starts at 1
+	PageSize int
+
+	SortBy string
+	Order  string // Defaults to descending, except when sorting by name
+
+	FlagType   string // Only authors with a flag of this type
+	Department string
+	RiskTier   string
+	Search     string // Case insensitive substring of the author name
+}
+
+func (q UniversityAuthorQuery) Validate() error {
+	if q.Page < 0 {
+		return errors.New("page must be positive")
+	}
+	if q.PageSize < 0 || q.PageSize > MaxUniversityAuthorPageSize {
+		return fmt.Errorf("page size must be between 1 and %d", MaxUniversityAuthorPageSize)
+	}
+	switch q.SortBy {
+	case "", UniversityAuthorSortRisk, UniversityAuthorSortFlags, UniversityAuthorSortName:
+	default:
+		return errors.New("invalid sort '" + q.SortBy + "'")
+	}
+	switch q.Order {
+	case "", SortAscending, SortDescending:
+	default:
+		return errors.New("invalid order '" + q.Order + "'")
+	}
+	switch q.RiskTier {
+	case "", RiskTierLow, RiskTierMedium, RiskTierHigh:
+	default:
+		return errors.New("invalid risk tier '" + q.RiskTier + "'")
+	}
+	if q.FlagType != "" {
+		if _, ok := DefaultRiskWeights().FlagTypes[q.FlagType]; !ok {
+			return errors.New("invalid flag type '" + q.FlagType + "'")
+		}
+	}
+	return nil
+}
+
+func (q UniversityAuthorQuery) withDefaults() UniversityAuthorQuery {
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.PageSize == 0 {
+		q.PageSize = DefaultUniversityAuthorPageSize
+	}
+	if q.SortBy == "" {
+		q.SortBy = UniversityAuthorSortRisk
+	}
+	if q.Order == "" {
+		if q.SortBy == UniversityAuthorSortName {
+			q.Order = SortAscending
+		} else {
+			q.Order = SortDescending
+		}
+	}
+	return q
+}
+
+// Joins the author summaries of the university report with the author reports
+// and the departments of the authors.
+func universityAuthorSummaries(txn *gorm.DB, universityReportId, organizationId uuid.UUID) *gorm.DB {
+	return txn.Model(&schema.UniversityAuthorSummary{}).
+		Joins("JOIN author_reports ON author_reports.id = university_author_summaries.author_report_id").
+		Joins("JOIN university_authors ON university_authors.university_report_id = university_author_summaries.university_report_id AND university_authors.author_report_id = university_author_summaries.author_report_id").
+		Where("university_author_summaries.university_report_id = ? AND university_author_summaries.organization_id = ?", universityReportId, organizationId)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (q UniversityAuthorQuery) filter(db *gorm.DB) *gorm.DB {
+	if q.FlagType != "" {
+		db = db.Where(`EXISTS (SELECT 1 FROM university_author_flag_counts WHERE
+university_author_flag_counts.university_report_id = university_author_summaries.university_report_id AND
+university_author_flag_counts.organization_id = university_author_summaries.organization_id AND
+university_author_flag_counts.author_report_id = university_author_summaries.author_report_id AND
+university_author_flag_counts.flag_type = ?)`, q.FlagType)
+	}
+	if q.Department != "" {
+		db = db.Where("LOWER(university_authors.department) = ?", strings.ToLower(q.Department))
+	}
+	if q.RiskTier != "" {
+		db = db.Where("university_author_summaries.risk_tier = ?", q.RiskTier)
+	}
+	if q.Search != "" {
+		db = db.Where(`LOWER(author_reports.author_name) LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(strings.ToLower(q.Search))+"%")
+	}
+	return db
+}
+
+// Ties are broken by the author name in ascending order regardless of the order
+// of the sort.
+func (q UniversityAuthorQuery) orderBy() string {
+	dir := "ASC"
+	if q.Order == SortDescending {
+		dir = "DESC"
+	}
+
+	byName := "author_reports.author_name ASC, author_reports.author_id ASC"
+
+	switch q.SortBy {
+	case UniversityAuthorSortFlags:
+		return fmt.Sprintf("university_author_summaries.flag_count %s, university_author_summaries.risk_score %s, %s", dir, dir, byName)
+	case UniversityAuthorSortName:
+		return fmt.Sprintf("author_reports.author_name %s, author_reports.author_id %s", dir, dir)
+	default:
+		return fmt.Sprintf("university_author_summaries.risk_score %s, university_author_summaries.flag_count %s, %s", dir, dir, byName)
+	}
+}
+
+type universityAuthorRow struct {
+	AuthorReportId uuid.UUID
+	AuthorId       string
+	AuthorName     string
+	Source         string
+	Department     string
+	Status         string
+	FlagCount      int
+	RiskScore      float64
+	RiskTier       string
+}
+
+type universityAuthorFlagCountRow struct {
+	AuthorReportId uuid.UUID
+	AuthorId       string
+	AuthorName     string
+	Source         string
+	FlagType       string
+	FlagCount      int
+}
+
+func getUniversityAuthorPage(txn *gorm.DB, universityReportId, organizationId uuid.UUID, query UniversityAuthorQuery) (*api.UniversityAuthorPage, error) {
+	query = query.withDefaults()
+
+	var total int64
+	if err := query.filter(universityAuthorSummaries(txn, universityReportId, organizationId)).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	authors := query.filter(universityAuthorSummaries(txn, universityReportId, organizationId)).
+		Select("university_author_summaries.author_report_id, author_reports.author_id, author_reports.author_name, author_reports.source, university_authors.department, author_reports.status, university_author_summaries.flag_count, university_author_summaries.risk_score, university_author_summaries.risk_tier").
+		Order(query.orderBy())
+	if query.PageSize != AllUniversityAuthors {
+		authors = authors.Limit(query.PageSize).Offset((query.Page - 1) * query.PageSize)
+	}
+
+	var rows []universityAuthorRow
+	if err := authors.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	page := &api.UniversityAuthorPage{
+		Total:    int(total),
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Authors:  make([]api.UniversityAuthorSummary, 0, len(rows)),
+	}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	authorReportIds := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		authorReportIds = append(authorReportIds, row.AuthorReportId)
+	}
+
+	var counts []schema.UniversityAuthorFlagCount
+	if err := txn.Find(&counts, "university_report_id = ? AND organization_id = ? AND author_report_id IN ?", universityReportId, organizationId, authorReportIds).Error; err != nil {
+		return nil, err
+	}
+
+	flagCounts := make(map[uuid.UUID]map[string]int, len(rows))
+	for _, count := range counts {
+		if flagCounts[count.AuthorReportId] == nil {
+			flagCounts[count.AuthorReportId] = make(map[string]int)
+		}
+		flagCounts[count.AuthorReportId][count.FlagType] = count.FlagCount
+	}
+
+	for _, row := range rows {
+		counts := flagCounts[row.AuthorReportId]
+		if counts == nil {
+			counts = make(map[string]int)
+		}
+		page.Authors = append(page.Authors, api.UniversityAuthorSummary{
+			AuthorReportId: row.AuthorReportId,
+			AuthorId:       row.AuthorId,
+			AuthorName:     row.AuthorName,
+			Source:         row.Source,
+			Department:     row.Department,
+			Status:         row.Status,
+			FlagCount:      row.FlagCount,
+			FlagCounts:     counts,
+			RiskScore:      row.RiskScore,
+			RiskTier:       row.RiskTier,
+		})
+	}
+
+	return page, nil
+}
+
+func getUniversityDepartments(txn *gorm.DB, universityReportId, organizationId uuid.UUID) ([]api.UniversityDepartment, error) {
+	departments := make([]api.UniversityDepartment, 0)
+	if err := universityAuthorSummaries(txn, universityReportId, organizationId).
+		Select(`university_authors.department AS department,
+COUNT(*) AS total_authors,
+SUM(CASE WHEN university_author_summaries.flag_count > 0 THEN 1 ELSE 0 END) AS flagged_authors,
+SUM(CASE WHEN university_author_summaries.risk_tier = ? THEN 1 ELSE 0 END) AS high_risk_authors,
+SUM(university_author_summaries.flag_count) AS flag_count,
+SUM(university_author_summaries.risk_score) AS risk_score`, RiskTierHigh).
+		Group("university_authors.department").
+		Order("SUM(university_author_summaries.risk_score) DESC, university_authors.department ASC").
+		Scan(&departments).Error; err != nil {
+		return nil, err
+	}
+	return departments, nil
+}
+
+// Returns the authors with each type of flag, ordered by the number of flags.
+func getUniversityFlagAuthors(txn *gorm.DB, universityReportId, organizationId uuid.UUID) (map[string][]api.UniversityAuthorFlag, error) {
+	var rows []universityAuthorFlagCountRow
+	if err := txn.Model(&schema.UniversityAuthorFlagCount{}).
+		Select("author_reports.author_id, author_reports.author_name, author_reports.source, university_author_flag_counts.flag_type, university_author_flag_counts.flag_count").
+		Joins("JOIN author_reports ON author_reports.id = university_author_flag_counts.author_report_id").
+		Where("university_author_flag_counts.university_report_id = ? AND university_author_flag_counts.organization_id = ?", universityReportId, organizationId).
+		Order("university_author_flag_counts.flag_count DESC, author_reports.author_name ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	flags := make(map[string][]api.UniversityAuthorFlag)
+	for _, row := range rows {
+		flags[row.FlagType] = append(flags[row.FlagType], api.UniversityAuthorFlag{
+			AuthorId:   row.AuthorId,
+			AuthorName: row.AuthorName,
+			Source:     row.Source,
+			FlagCount:  row.FlagCount,
+		})
+	}
+	return flags, nil
+}
+
+type universityReportAuthor struct {
+	AuthorReportId uuid.UUID
+	AuthorId       string
+	AuthorName     string
+	Orcid          string
+}
+
+type universityReportFlag struct {
+	ReportId uuid.UUID
+	FlagType string
+	Date     sql.NullTime
+	Data     []byte
+}
+
+// Summaries are recomputed at least this often even if nothing changes, since
+// the flags in the report and the recency of the flags depend on the date.
+const universityReportSummaryMaxAge = 24 * time.Hour
+
+// Removes the summaries of the university reports that match the conditions so
+// that they are recomputed the next time the reports are loaded.
+func invalidateUniversityReportSummaries(txn *gorm.DB, query string, args ...any) error {
+	if err := txn.Where(query, args...).Delete(&schema.UniversityReportSummary{}).Error; err != nil {
+		slog.Error("error removing university report summaries", "error", err)
+		return ErrReportAccessFailed
+	}
+	return nil
+}
+
+// Removes the summaries of the university reports that include the author
+// report, this is called when the flags of the author report change.
+func invalidateAuthorUniversityReportSummaries(txn *gorm.DB, authorReportId uuid.UUID) error {
+	return invalidateUniversityReportSummaries(txn, "university_report_id IN (?)",
+		txn.Model(&schema.UniversityAuthor{}).Select("university_report_id").Where("author_report_id = ?", authorReportId))
+}
+
+// Returns the summary of the university report for the organization, computing
+// it if it does not exist or is out of date.
+func (r *ReportManager) getUniversityReportSummary(txn *gorm.DB, universityReportId, organizationId uuid.UUID) (schema.UniversityReportSummary, error) {
+	now := time.Now().UTC()
+
+	var summary schema.UniversityReportSummary
+	result := txn.Limit(1).Find(&summary, "university_report_id = ? AND organization_id = ?", universityReportId, organizationId)
+	if result.Error != nil {
+		slog.Error("error getting university report summary", "university_report_id", universityReportId, "error", result.Error)
+		return schema.UniversityReportSummary{}, ErrReportAccessFailed
+	}
+	if result.RowsAffected > 0 && now.Sub(summary.ComputedAt) < universityReportSummaryMaxAge {
+		return summary, nil
+	}
+
+	return r.computeUniversityReportSummary(txn, universityReportId, organizationId, now)
+}
+
+// Computes the summary of each author by streaming the flags of the authors
+// instead of loading them all at once, since large institutions can have many
+// thousands of authors. Flags are scored without the triage or disclosures of
+// the user's author reports, since those are specific to each author report.
+// Flags that are suppressed by the organization are omitted.
+func (r *ReportManager) computeUniversityReportSummary(txn *gorm.DB, universityReportId, organizationId uuid.UUID, now time.Time) (schema.UniversityReportSummary, error) {
+	weights, err := getRiskWeights(txn, organizationId)
+	if err != nil {
+		return schema.UniversityReportSummary{}, err
+	}
+	scorer := r.riskScorer(weights)
+
+	orgSuppressions, err := getFlagSuppressions(txn, organizationId)
+	if err != nil {
+		return schema.UniversityReportSummary{}, err
+	}
+
+	var authors []universityReportAuthor
+	if err := txn.Model(&schema.UniversityAuthor{}).
+		Select("author_reports.id as author_report_id, author_reports.author_id, author_reports.author_name, author_reports.orcid").
+		Joins("JOIN author_reports ON author_reports.id = university_authors.author_report_id").
+		Where("university_authors.university_report_id = ?", universityReportId).
+		Find(&authors).Error; err != nil {
+		slog.Error("error getting author reports linked to university report", "university_report_id", universityReportId, "error", err)
+		return schema.UniversityReportSummary{}, ErrReportAccessFailed
+	}
+
+	summaries := make(map[uuid.UUID]*schema.UniversityAuthorSummary, len(authors))
+	flagCounts := make(map[uuid.UUID]map[string]int, len(authors))
+	suppressions := make(map[uuid.UUID]FlagSuppressions)
+	for _, author := range authors {
+		summaries[author.AuthorReportId] = &schema.UniversityAuthorSummary{
+			UniversityReportId: universityReportId,
+			OrganizationId:     organizationId,
+			AuthorReportId:     author.AuthorReportId,
+		}
+		flagCounts[author.AuthorReportId] = make(map[string]int)
+
+		authorIds := []string{author.AuthorId}
+		if author.Orcid != "" {
+			authorIds = append(authorIds, author.Orcid)
+		}
+		suppressions[author.AuthorReportId] = orgSuppressions.ForAuthor(author.AuthorName, authorIds...)
+	}
+
+	trends := make(map[int]*api.UniversityYearlyTrend)
+	trendAuthors := make(map[int]map[uuid.UUID]bool)
+
+	rows, err := txn.Model(&schema.AuthorFlag{}).
+		Select("author_flags.report_id, author_flags.flag_type, author_flags.date, author_flags.data").
+		Joins("JOIN university_authors ON author_flags.report_id = university_authors.author_report_id AND university_authors.university_report_id = ?", universityReportId).
+		Where("author_flags.date IS NULL OR author_flags.date > ?", now.AddDate(-yearsInUniversityReport, 0, 0)).
+		Rows()
+	if err != nil {
+		slog.Error("error querying flags for author reports linked to university report", "university_report_id", universityReportId, "error", err)
+		return schema.UniversityReportSummary{}, ErrReportAccessFailed
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row universityReportFlag
+		if err := txn.ScanRows(rows, &row); err != nil {
+			slog.Error("error reading flag for university report", "university_report_id", universityReportId, "error", err)
+			return schema.UniversityReportSummary{}, ErrReportAccessFailed
+		}
+
+		summary, ok := summaries[row.ReportId]
+		if !ok {
+			continue
+		}
+
+		flag, err := api.ParseFlag(row.FlagType, row.Data)
+		if err != nil {
+			slog.Error("error parsing flag for university report", "university_report_id", universityReportId, "author_report_id", row.ReportId, "error", err)
+			return schema.UniversityReportSummary{}, ErrReportAccessFailed
+		}
+
+		if suppressions[row.ReportId].Suppresses(flag) {
+			continue
+		}
+
+		summary.FlagCount++
+		flagCounts[row.ReportId][row.FlagType]++
+		summary.RiskScore += scorer.ScoreFlag(flag, now)
+
+		if row.Date.Valid {
+			year := row.Date.Time.Year()
+			trend, ok := trends[year]
+			if !ok {
+				trend = &api.UniversityYearlyTrend{Year: year, FlagCounts: make(map[string]int)}
+				trends[year] = trend
+				trendAuthors[year] = make(map[uuid.UUID]bool)
+			}
+			trend.FlagCount++
+			trend.FlagCounts[row.FlagType]++
+			trendAuthors[year][row.ReportId] = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("error reading flags for university report", "university_report_id", universityReportId, "error", err)
+		return schema.UniversityReportSummary{}, ErrReportAccessFailed
+	}
+
+	authorSummaries := make([]schema.UniversityAuthorSummary, 0, len(summaries))
+	authorFlagCounts := make([]schema.UniversityAuthorFlagCount, 0)
+	for authorReportId, summary := range summaries {
+		summary.RiskTier = scorer.Tier(summary.RiskScore)
+		authorSummaries = append(authorSummaries, *summary)
+
+		for flagType, count := range flagCounts[authorReportId] {
+			authorFlagCounts = append(authorFlagCounts, schema.UniversityAuthorFlagCount{
+				UniversityReportId: universityReportId,
+				OrganizationId:     organizationId,
+				AuthorReportId:     authorReportId,
+				FlagType:           flagType,
+				FlagCount:          count,
+			})
+		}
+	}
+
+	yearlyTrends := make([]api.UniversityYearlyTrend, 0, len(trends))
+	for year, trend := range trends {
+		trend.FlaggedAuthors = len(trendAuthors[year])
+		yearlyTrends = append(yearlyTrends, *trend)
+	}
+	sort.Slice(yearlyTrends, func(i, j int) bool {
+		return yearlyTrends[i].Year < yearlyTrends[j].Year
+	})
+
+	trendData, err := json.Marshal(yearlyTrends)
+	if err != nil {
+		slog.Error("error serializing university report trends", "university_report_id", universityReportId, "error", err)
+		return schema.UniversityReportSummary{}, ErrReportAccessFailed
+	}
+
+	summary := schema.UniversityReportSummary{
+		UniversityReportId: universityReportId,
+		OrganizationId:     organizationId,
+		ComputedAt:         now,
+		YearlyTrends:       trendData,
+	}
+
+	for _, table := range []any{&schema.UniversityAuthorSummary{}, &schema.UniversityAuthorFlagCount{}} {
+		if err := txn.Delete(table, "university_report_id = ? AND organization_id = ?", universityReportId, organizationId).Error; err != nil {
+			slog.Error("error removing previous university report summary", "university_report_id", universityReportId, "error", err)
+			return schema.UniversityReportSummary{}, ErrReportAccessFailed
+		}
+	}
+
+	// The summary may be computed concurrently by another request for the report.
+	if len(authorSummaries) > 0 {
+		if err := txn.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(authorSummaries, 1000).Error; err != nil {
+			slog.Error("error saving university author summaries", "university_report_id", universityReportId, "error", err)
+			return schema.UniversityReportSummary{}, ErrReportAccessFailed
+		}
+	}
+
+	if len(authorFlagCounts) > 0 {
+		if err := txn.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(authorFlagCounts, 1000).Error; err != nil {
+			slog.Error("error saving university author flag counts", "university_report_id", universityReportId, "error", err)
+			return schema.UniversityReportSummary{}, ErrReportAccessFailed
+		}
+	}
+
+	if err := txn.Clauses(clause.OnConflict{UpdateAll: true}).Create(&summary).Error; err != nil {
+		slog.Error("error saving university report summary", "university_report_id", universityReportId, "error", err)
+		return schema.UniversityReportSummary{}, ErrReportAccessFailed
+	}
+
+	return summary, nil
+}
+
+type universityReportTotals struct {
+	TotalAuthors    int
+	AuthorsReviewed int
+}
+
+// The summary of the authors is computed for the user's organization when the
+// report is first loaded, and the authors are filtered, sorted, and paged by
+// the database.
+func (r *ReportManager) GetUniversityReport(userId, reportId uuid.UUID, query UniversityAuthorQuery) (api.UniversityReport, error) {
+	var report schema.UserUniversityReport
+	var content api.UniversityReportContent
+
+	if err := r.db.Transaction(func(txn *gorm.DB) error {
+		if err := txn.Preload("Report").First(&report, "id = ?", reportId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReportNotFound
+			}
+			slog.Error("error getting user university report", "university_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		if report.UserId != userId {
+			return ErrUserCannotAccessReport
+		}
+
+		orgId, err := userOrganization(txn, userId)
+		if err != nil {
+			return ErrReportAccessFailed
+		}
+
+		summary, err := r.getUniversityReportSummary(txn, report.ReportId, orgId)
+		if err != nil {
+			return err
+		}
+
+		var totals universityReportTotals
+		if err := txn.Model(&schema.UniversityAuthor{}).
+			Select("COUNT(*) AS total_authors, COALESCE(SUM(CASE WHEN author_reports.status IN ? THEN 1 ELSE 0 END), 0) AS authors_reviewed", []string{schema.ReportCompleted, schema.ReportFailed}).
+			Joins("JOIN author_reports ON author_reports.id = university_authors.author_report_id").
+			Where("university_authors.university_report_id = ?", report.ReportId).
+			Scan(&totals).Error; err != nil {
+			slog.Error("error counting authors of university report", "university_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+		content.TotalAuthors = totals.TotalAuthors
+		content.AuthorsReviewed = totals.AuthorsReviewed
+
+		if content.Flags, err = getUniversityFlagAuthors(txn, report.ReportId, orgId); err != nil {
+			slog.Error("error getting flagged authors of university report", "university_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		if content.Authors, err = getUniversityAuthorPage(txn, report.ReportId, orgId, query); err != nil {
+			slog.Error("error getting authors of university report", "university_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		if content.Departments, err = getUniversityDepartments(txn, report.ReportId, orgId); err != nil {
+			slog.Error("error getting departments of university report", "university_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		if err := json.Unmarshal(summary.YearlyTrends, &content.YearlyTrends); err != nil {
+			slog.Error("error parsing university report trends", "university_report_id", reportId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		return nil
+	}); err != nil {
+		return api.UniversityReport{}, err
+	}
+
+	return convertUniversityReport(report, content), nil
+}
@@ -0,0 +1,46 @@
+package utils
+
+import "sync/atomic"
+
+// Counts the pdfs downloaded and processed with grobid for a report. The
+// counts are updated concurrently by the download workers. All of the methods
+// can be called on a nil *PdfProgress, in which case the updates are ignored.
+type PdfProgress struct {
+	downloaded    atomic.Int64
+	failed        atomic.Int64
+	grobidPending atomic.Int64
+}
+
+func (p *PdfProgress) Downloaded() {
+	if p != nil {
+		p.downloaded.Add(1)
+	}
+}
+
+func (p *PdfProgress) DownloadFailed() {
+	if p != nil {
+		p.failed.Add(1)
+	}
+}
+
+// Records that a downloaded pdf is waiting for or being processed by grobid.
+func (p *PdfProgress) GrobidStarted() {
+	if p != nil {
+		p.grobidPending.Add(1)
+	}
+}
+
+func (p *PdfProgress) GrobidFinished() {
+	if p != nil {
+		p.grobidPending.Add(-1)
+	}
+}
+
+// Returns the number of pdfs downloaded, the number that failed to download,
+// and the number of grobid calls that are pending.
+func (p *PdfProgress) Counts() (int, int, int) {
+	if p == nil {
+		return 0, 0, 0
+	}
+	return int(p.downloaded.Load()), int(p.failed.Load()), int(p.grobidPending.Load())
+}
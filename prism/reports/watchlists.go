@@ -0,0 +1,736 @@
+package reports
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"prism/prism/api"
+	"prism/prism/reports/flaggers/eoc"
+	"prism/prism/schema"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWatchlistUpdateNotFound = errors.New("watchlist update not found")
+	ErrWatchlistNotFound       = errors.New("watchlist not found")
+	ErrWatchlistEntityNotFound = errors.New("watchlist entity not found")
+	ErrInvalidWatchlistEntity  = errors.New("invalid watchlist entity")
+)
+
+// The combined version of all of the watchlists.
+func watchlistsVersion(versions map[string]string) string {
+	hash := sha256.New()
+	for _, name := range slices.Sorted(maps.Keys(versions)) {
+		fmt.Fprintf(hash, "%s=%s\n", name, versions[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// Checks if the watchlists have changed since the last time this was called,
+// and if they have requeues the reports that were processed with different
+// versions of the watchlists so that entities that were added to the lists are
+// flagged without waiting for the reports to become stale. This is called by
+// each worker when it starts, the update is only recorded once so that the
+// reports are only requeued by the first worker with the new watchlists. If the
+// watchlists change back to a previous version it is recorded as a new update.
+// The first versions that are recorded are treated as the baseline and do not
+// requeue any reports. Returns the number of reports that were requeued.
+func (r *ReportManager) CheckWatchlistVersions(versions map[string]string) (int, error) {
+	data, err := json.Marshal(versions)
+	if err != nil {
+		return 0, fmt.Errorf("error serializing watchlist versions: %w", err)
+	}
+
+	update := schema.WatchlistUpdate{
+		Id:         uuid.New(),
+		Version:    watchlistsVersion(versions),
+		CreatedAt:  time.Now().UTC(),
+		Watchlists: data,
+	}
+
+	requeued := 0
+	err = r.db.Transaction(func(txn *gorm.DB) error {
+		var previous schema.WatchlistUpdate
+		result := txn.Order("created_at DESC").Limit(1).Find(&previous)
+		if result.Error != nil {
+			slog.Error("error getting previous watchlist update", "error", result.Error)
+			return ErrReportAccessFailed
+		}
+
+		changed := make([]string, 0)
+		if result.RowsAffected > 0 {
+			if previous.Version == update.Version {
+				return nil
+			}
+			update.PreviousId = previous.Id
+
+			var previousVersions map[string]string
+			if err := json.Unmarshal(previous.Watchlists, &previousVersions); err != nil {
+				slog.Error("error parsing previous watchlist versions", "version", previous.Version, "error", err)
+				return ErrReportAccessFailed
+			}
+			for _, name := range slices.Sorted(maps.Keys(versions)) {
+				if previousVersions[name] != versions[name] {
+					changed = append(changed, name)
+				}
+			}
+		}
+
+		if update.Changed, err = json.Marshal(changed); err != nil {
+			return fmt.Errorf("error serializing changed watchlists: %w", err)
+		}
+
+		insert := txn.Clauses(clause.OnConflict{DoNothing: true}).Create(&update)
+		if insert.Error != nil {
+			slog.Error("error recording watchlist update", "version", update.Version, "error", insert.Error)
+			return ErrReportAccessFailed
+		}
+
+		// Another worker has already recorded an update following the previous
+		// update, or these are the first versions that are recorded.
+		if insert.RowsAffected == 0 || result.RowsAffected == 0 {
+			return nil
+		}
+
+		// Reports that are queued or in progress are requeued when they complete if
+		// they were processed with other versions of the watchlists. Reports that
+		// have never completed are not requeued.
+		matching := txn.Model(&schema.AuthorReportWatchlist{}).Select("report_id").Where("report_id = author_reports.id")
+		conditions := txn.Where("1 = 0")
+		for name, version := range versions {
+			conditions = conditions.Or("watchlist = ? AND version = ?", name, version)
+		}
+		matching = matching.Where(conditions).Group("report_id").Having("COUNT(*) = ?", len(versions))
+
+		requeue := txn.Model(&schema.AuthorReport{}).
+			Where("status != ? AND status != ? AND last_updated_at > ?", schema.ReportInProgress, schema.ReportQueued, EarliestReportDate).
+			Where("NOT EXISTS (?)", matching).
+			Updates(map[string]any{
+				"status":            schema.ReportQueued,
+				"status_updated_at": time.Now().UTC(),
+				"priority":          schema.ReportPriorityRefresh,
+				"watchlist_update":  update.Id.String(),
+			})
+		if requeue.Error != nil {
+			slog.Error("error requeueing reports for watchlist update", "version", update.Version, "error", requeue.Error)
+			return ErrReportAccessFailed
+		}
+
+		requeued = int(requeue.RowsAffected)
+
+		if err := txn.Model(&update).Update("reports_requeued", requeued).Error; err != nil {
+			slog.Error("error updating watchlist update", "version", update.Version, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		slog.Info("watchlists changed, requeued reports", "version", update.Version, "changed", changed, "n_reports", requeued)
+
+		return nil
+	})
+
+	return requeued, err
+}
+
+// Returns the id of the latest watchlist update if the report should be
+// requeued for it when it completes. CheckWatchlistVersions does not requeue
+// reports that are queued or in progress, so a report that was processed with
+// other versions of the watchlists is requeued once it completes instead. The
+// report is not requeued again if it was already requeued for the update, so
+// that reports whose versions are not recorded because a flagger failed are
+// not requeued indefinitely.
+func watchlistUpdateToRequeue(txn *gorm.DB, reportId uuid.UUID, reportWatchlistUpdate string) (string, error) {
+	var latest schema.WatchlistUpdate
+	result := txn.Order("created_at DESC").Limit(1).Find(&latest)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 || latest.Id.String() == reportWatchlistUpdate {
+		return "", nil
+	}
+
+	var watchlists []schema.AuthorReportWatchlist
+	if err := txn.Find(&watchlists, "report_id = ?", reportId).Error; err != nil {
+		return "", err
+	}
+
+	versions := make(map[string]string, len(watchlists))
+	for _, watchlist := range watchlists {
+		versions[watchlist.Watchlist] = watchlist.Version
+	}
+	if watchlistsVersion(versions) == latest.Version {
+		return "", nil
+	}
+
+	return latest.Id.String(), nil
+}
+
+// Records the versions of the watchlists that the report was processed with.
+func (r *ReportManager) RecordAuthorReportWatchlists(id uuid.UUID, versions map[string]string) error {
+	return r.db.Transaction(func(txn *gorm.DB) error {
+		if err := txn.Delete(&schema.AuthorReportWatchlist{}, "report_id = ?", id).Error; err != nil {
+			slog.Error("error clearing author report watchlists", "author_report_id", id, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		if len(versions) == 0 {
+			return nil
+		}
+
+		watchlists := make([]schema.AuthorReportWatchlist, 0, len(versions))
+		for watchlist, version := range versions {
+			watchlists = append(watchlists, schema.AuthorReportWatchlist{ReportId: id, Watchlist: watchlist, Version: version})
+		}
+
+		if err := txn.Create(&watchlists).Error; err != nil {
+			slog.Error("error recording author report watchlists", "author_report_id", id, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		return nil
+	})
+}
+
+type flagTypeCount struct {
+	ReportId uuid.UUID
+	FlagType string
+	Count    int64
+}
+
+func (r *ReportManager) convertWatchlistUpdate(update schema.WatchlistUpdate) (api.WatchlistUpdate, error) {
+	result := api.WatchlistUpdate{
+		Id:              update.Id,
+		Version:         update.Version,
+		CreatedAt:       update.CreatedAt,
+		ReportsRequeued: update.ReportsRequeued,
+		NewFlags:        make(map[string]int64),
+	}
+
+	if err := json.Unmarshal(update.Watchlists, &result.Watchlists); err != nil {
+		slog.Error("error parsing watchlist versions", "version", update.Version, "error", err)
+		return api.WatchlistUpdate{}, ErrReportAccessFailed
+	}
+	if err := json.Unmarshal(update.Changed, &result.ChangedWatchlists); err != nil {
+		slog.Error("error parsing changed watchlists", "version", update.Version, "error", err)
+		return api.WatchlistUpdate{}, ErrReportAccessFailed
+	}
+
+	if err := r.db.Model(&schema.AuthorReport{}).Where("watchlist_update = ?", update.Id.String()).Count(&result.ReportsPending).Error; err != nil {
+		slog.Error("error counting pending reports for watchlist update", "watchlist_update_id", update.Id, "error", err)
+		return api.WatchlistUpdate{}, ErrReportAccessFailed
+	}
+
+	var counts []flagTypeCount
+	if err := r.db.Model(&schema.AuthorFlag{}).
+		Select("flag_type, COUNT(*) AS count").
+		Where("watchlist_update = ?", update.Id.String()).
+		Group("flag_type").
+		Find(&counts).Error; err != nil {
+		slog.Error("error counting new flags for watchlist update", "watchlist_update_id", update.Id, "error", err)
+		return api.WatchlistUpdate{}, ErrReportAccessFailed
+	}
+	for _, count := range counts {
+		result.NewFlags[count.FlagType] = count.Count
+	}
+
+	return result, nil
+}
+
+// Lists the watchlist updates, most recent first, with a summary of the new
+// flags that were found when the reports were reprocessed.
+func (r *ReportManager) ListWatchlistUpdates() ([]api.WatchlistUpdate, error) {
+	var updates []schema.WatchlistUpdate
+	if err := r.db.Order("created_at DESC").Find(&updates).Error; err != nil {
+		slog.Error("error listing watchlist updates", "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	results := make([]api.WatchlistUpdate, 0, len(updates))
+	for _, update := range updates {
+		result, err := r.convertWatchlistUpdate(update)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Returns the summary of the watchlist update, and the new flags of each report
+// that were found when it was reprocessed.
+func (r *ReportManager) GetWatchlistUpdate(id uuid.UUID) (api.WatchlistUpdateDetails, error) {
+	var update schema.WatchlistUpdate
+	if err := r.db.First(&update, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.WatchlistUpdateDetails{}, ErrWatchlistUpdateNotFound
+		}
+		slog.Error("error getting watchlist update", "watchlist_update_id", id, "error", err)
+		return api.WatchlistUpdateDetails{}, ErrReportAccessFailed
+	}
+
+	summary, err := r.convertWatchlistUpdate(update)
+	if err != nil {
+		return api.WatchlistUpdateDetails{}, err
+	}
+
+	var counts []flagTypeCount
+	if err := r.db.Model(&schema.AuthorFlag{}).
+		Select("report_id, flag_type, COUNT(*) AS count").
+		Where("watchlist_update = ?", id.String()).
+		Group("report_id, flag_type").
+		Find(&counts).Error; err != nil {
+		slog.Error("error counting new flags of reports for watchlist update", "watchlist_update_id", id, "error", err)
+		return api.WatchlistUpdateDetails{}, ErrReportAccessFailed
+	}
+
+	reportFlags := make(map[uuid.UUID]map[string]int64)
+	for _, count := range counts {
+		if reportFlags[count.ReportId] == nil {
+			reportFlags[count.ReportId] = make(map[string]int64)
+		}
+		reportFlags[count.ReportId][count.FlagType] = count.Count
+	}
+
+	var reports []schema.AuthorReport
+	if len(reportFlags) > 0 {
+		if err := r.db.Order("author_name").Find(&reports, "id IN ?", slices.Collect(maps.Keys(reportFlags))).Error; err != nil {
+			slog.Error("error getting reports for watchlist update", "watchlist_update_id", id, "error", err)
+			return api.WatchlistUpdateDetails{}, ErrReportAccessFailed
+		}
+	}
+
+	details := api.WatchlistUpdateDetails{WatchlistUpdate: summary, Reports: make([]api.WatchlistUpdateReport, 0, len(reports))}
+	for _, report := range reports {
+		details.Reports = append(details.Reports, api.WatchlistUpdateReport{
+			ReportId:   report.Id,
+			AuthorId:   report.AuthorId,
+			AuthorName: report.AuthorName,
+			Source:     report.Source,
+			NewFlags:   reportFlags[report.Id],
+		})
+	}
+
+	return details, nil
+}
+
+// Returns the entities of the watchlists that are in effect.
+func (r *ReportManager) LoadWatchlists() (*eoc.WatchlistSet, error) {
+	var entities []schema.WatchlistEntity
+	if err := r.db.
+		Where("removed_at IS NULL AND (effective_date IS NULL OR effective_date <= ?)", time.Now().UTC()).
+		Find(&entities).Error; err != nil {
+		slog.Error("error loading watchlists", "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	output := make([]eoc.Entity, 0, len(entities))
+	for _, entity := range entities {
+		var aliases []string
+		if err := json.Unmarshal(entity.Aliases, &aliases); err != nil {
+			slog.Error("error parsing watchlist entity aliases", "entity_id", entity.Id, "error", err)
+			return nil, ErrReportAccessFailed
+		}
+		output = append(output, eoc.Entity{
+			Watchlist:  entity.Watchlist,
+			Name:       entity.Name,
+			OpenAlexId: entity.OpenAlexId,
+			Aliases:    aliases,
+			Source:     entity.Source,
+		})
+	}
+
+	return eoc.NewWatchlistSet(output), nil
+}
+
+// Loads the watchlists into the store, and updates the aliases that disclosures
+// are matched against if they changed. Returns true if the watchlists changed.
+func (r *ReportManager) ReloadWatchlists(store *eoc.Store) (bool, error) {
+	watchlists, err := r.LoadWatchlists()
+	if err != nil {
+		return false, err
+	}
+
+	if !store.Update(watchlists) {
+		return false, nil
+	}
+
+	r.disclosureMatcher.SetAliases(watchlists.AliasToSource)
+
+	return true, nil
+}
+
+func convertWatchlistEntity(entity schema.WatchlistEntity) (api.WatchlistEntity, error) {
+	output := api.WatchlistEntity{
+		Id:            entity.Id,
+		Watchlist:     entity.Watchlist,
+		Name:          entity.Name,
+		OpenAlexId:    entity.OpenAlexId,
+		Source:        entity.Source,
+		Justification: entity.Justification,
+		CreatedBy:     entity.CreatedBy,
+		CreatedAt:     entity.CreatedAt,
+		UpdatedBy:     entity.UpdatedBy,
+		UpdatedAt:     entity.UpdatedAt,
+	}
+	if err := json.Unmarshal(entity.Aliases, &output.Aliases); err != nil {
+		slog.Error("error parsing watchlist entity aliases", "entity_id", entity.Id, "error", err)
+		return api.WatchlistEntity{}, ErrReportAccessFailed
+	}
+	if output.Aliases == nil {
+		output.Aliases = []string{}
+	}
+	if entity.EffectiveDate.Valid {
+		output.EffectiveDate = &entity.EffectiveDate.Time
+	}
+	if entity.RemovedBy.Valid {
+		output.RemovedBy = &entity.RemovedBy.UUID
+	}
+	if entity.RemovedAt.Valid {
+		output.RemovedAt = &entity.RemovedAt.Time
+	}
+	return output, nil
+}
+
+var openAlexIdRe = regexp.MustCompile(`^[AFIPSW]\d+$`)
+
+// Checks the entity and normalizes its fields, OpenAlex ids can be given with
+// or without the https://openalex.org/ prefix.
+func normalizeWatchlistEntity(watchlist string, entity api.WatchlistEntityRequest) (api.WatchlistEntityRequest, error) {
+	entity.Name = strings.TrimSpace(entity.Name)
+	entity.Source = strings.TrimSpace(entity.Source)
+	entity.Justification = strings.TrimSpace(entity.Justification)
+
+	entity.OpenAlexId = strings.TrimPrefix(strings.TrimSpace(entity.OpenAlexId), "https://openalex.org/")
+	if eoc.RequiresOpenAlexId(watchlist) {
+		if !openAlexIdRe.MatchString(entity.OpenAlexId) {
+			return entity, fmt.Errorf("%w: the %s watchlist requires a valid OpenAlex id, got '%s'", ErrInvalidWatchlistEntity, watchlist, entity.OpenAlexId)
+		}
+		entity.OpenAlexId = "https://openalex.org/" + entity.OpenAlexId
+		if entity.Name == "" {
+			entity.Name = entity.OpenAlexId
+		}
+	} else if entity.OpenAlexId != "" {
+		return entity, fmt.Errorf("%w: the %s watchlist is matched by name and does not use OpenAlex ids", ErrInvalidWatchlistEntity, watchlist)
+	}
+
+	if entity.Name == "" {
+		return entity, fmt.Errorf("%w: name is required", ErrInvalidWatchlistEntity)
+	}
+
+	aliases := make([]string, 0, len(entity.Aliases))
+	for _, alias := range entity.Aliases {
+		if alias = strings.TrimSpace(alias); alias != "" && alias != entity.Name && !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	entity.Aliases = aliases
+
+	return entity, nil
+}
+
+// Entities are identified by their OpenAlex id in the watchlists that use
+// them, and otherwise by their name.
+func watchlistEntityKey(watchlist, name, openAlexId string) string {
+	if eoc.RequiresOpenAlexId(watchlist) {
+		return openAlexId
+	}
+	return strings.ToLower(name)
+}
+
+func newWatchlistEntity(userId uuid.UUID, watchlist string, entity api.WatchlistEntityRequest, now time.Time) (schema.WatchlistEntity, error) {
+	aliases, err := json.Marshal(entity.Aliases)
+	if err != nil {
+		return schema.WatchlistEntity{}, fmt.Errorf("error serializing aliases: %w", err)
+	}
+
+	output := schema.WatchlistEntity{
+		Id:            uuid.New(),
+		Watchlist:     watchlist,
+		Name:          entity.Name,
+		OpenAlexId:    entity.OpenAlexId,
+		Aliases:       aliases,
+		Source:        entity.Source,
+		Justification: entity.Justification,
+		CreatedBy:     userId,
+		CreatedAt:     now,
+		UpdatedBy:     userId,
+		UpdatedAt:     now,
+	}
+	if entity.EffectiveDate != nil {
+		output.EffectiveDate = sql.NullTime{Time: entity.EffectiveDate.UTC(), Valid: true}
+	}
+	return output, nil
+}
+
+// Returns the keys of the entities in the watchlist that have not been removed.
+func activeWatchlistEntityKeys(txn *gorm.DB, watchlist string) (map[string]bool, error) {
+	var entities []schema.WatchlistEntity
+	if err := txn.Select("name", "open_alex_id").Find(&entities, "watchlist = ? AND removed_at IS NULL", watchlist).Error; err != nil {
+		slog.Error("error listing watchlist entities", "watchlist", watchlist, "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	keys := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		keys[watchlistEntityKey(watchlist, entity.Name, entity.OpenAlexId)] = true
+	}
+	return keys, nil
+}
+
+// Lists the watchlists with the number of entities in each.
+func (r *ReportManager) ListWatchlists() ([]api.Watchlist, error) {
+	var counts []struct {
+		Watchlist string
+		Count     int64
+	}
+	if err := r.db.Model(&schema.WatchlistEntity{}).
+		Select("watchlist, COUNT(*) AS count").
+		Where("removed_at IS NULL").
+		Group("watchlist").
+		Find(&counts).Error; err != nil {
+		slog.Error("error counting watchlist entities", "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	watchlists, err := r.LoadWatchlists()
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]api.Watchlist, 0, len(eoc.Watchlists))
+	for _, name := range eoc.Watchlists {
+		watchlist := api.Watchlist{Name: name, Version: watchlists.Versions[name]}
+		for _, count := range counts {
+			if count.Watchlist == name {
+				watchlist.Entities = count.Count
+			}
+		}
+		output = append(output, watchlist)
+	}
+
+	return output, nil
+}
+
+func (r *ReportManager) ListWatchlistEntities(watchlist string, includeRemoved bool) ([]api.WatchlistEntity, error) {
+	if !eoc.IsWatchlist(watchlist) {
+		return nil, ErrWatchlistNotFound
+	}
+
+	query := r.db.Order("name").Where("watchlist = ?", watchlist)
+	if !includeRemoved {
+		query = query.Where("removed_at IS NULL")
+	}
+
+	var entities []schema.WatchlistEntity
+	if err := query.Find(&entities).Error; err != nil {
+		slog.Error("error listing watchlist entities", "watchlist", watchlist, "error", err)
+		return nil, ErrReportAccessFailed
+	}
+
+	output := make([]api.WatchlistEntity, 0, len(entities))
+	for _, entity := range entities {
+		converted, err := convertWatchlistEntity(entity)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, converted)
+	}
+
+	return output, nil
+}
+
+// Adds an entity to the watchlist. The workers pick up the change the next time
+// they reload the watchlists.
+func (r *ReportManager) CreateWatchlistEntity(userId uuid.UUID, watchlist string, request api.WatchlistEntityRequest) (api.WatchlistEntity, error) {
+	if !eoc.IsWatchlist(watchlist) {
+		return api.WatchlistEntity{}, ErrWatchlistNotFound
+	}
+
+	request, err := normalizeWatchlistEntity(watchlist, request)
+	if err != nil {
+		return api.WatchlistEntity{}, err
+	}
+
+	entity, err := newWatchlistEntity(userId, watchlist, request, time.Now().UTC())
+	if err != nil {
+		return api.WatchlistEntity{}, err
+	}
+
+	err = r.db.Transaction(func(txn *gorm.DB) error {
+		existing, err := activeWatchlistEntityKeys(txn, watchlist)
+		if err != nil {
+			return err
+		}
+		if existing[watchlistEntityKey(watchlist, entity.Name, entity.OpenAlexId)] {
+			return fmt.Errorf("%w: the entity is already in the %s watchlist", ErrInvalidWatchlistEntity, watchlist)
+		}
+
+		if err := txn.Create(&entity).Error; err != nil {
+			slog.Error("error creating watchlist entity", "watchlist", watchlist, "error", err)
+			return ErrReportCreationFailed
+		}
+		return nil
+	})
+	if err != nil {
+		return api.WatchlistEntity{}, err
+	}
+
+	return convertWatchlistEntity(entity)
+}
+
+// Updates the name, aliases, source, effective date, and justification of the
+// entity.
+func (r *ReportManager) UpdateWatchlistEntity(userId uuid.UUID, watchlist string, entityId uuid.UUID, request api.WatchlistEntityRequest) (api.WatchlistEntity, error) {
+	if !eoc.IsWatchlist(watchlist) {
+		return api.WatchlistEntity{}, ErrWatchlistNotFound
+	}
+
+	request, err := normalizeWatchlistEntity(watchlist, request)
+	if err != nil {
+		return api.WatchlistEntity{}, err
+	}
+
+	aliases, err := json.Marshal(request.Aliases)
+	if err != nil {
+		return api.WatchlistEntity{}, fmt.Errorf("error serializing aliases: %w", err)
+	}
+
+	var entity schema.WatchlistEntity
+	err = r.db.Transaction(func(txn *gorm.DB) error {
+		if err := txn.First(&entity, "id = ? AND watchlist = ? AND removed_at IS NULL", entityId, watchlist).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWatchlistEntityNotFound
+			}
+			slog.Error("error getting watchlist entity", "entity_id", entityId, "error", err)
+			return ErrReportAccessFailed
+		}
+
+		key := watchlistEntityKey(watchlist, request.Name, request.OpenAlexId)
+		if key != watchlistEntityKey(watchlist, entity.Name, entity.OpenAlexId) {
+			existing, err := activeWatchlistEntityKeys(txn, watchlist)
+			if err != nil {
+				return err
+			}
+			if existing[key] {
+				return fmt.Errorf("%w: the entity is already in the %s watchlist", ErrInvalidWatchlistEntity, watchlist)
+			}
+		}
+
+		entity.Name = request.Name
+		entity.OpenAlexId = request.OpenAlexId
+		entity.Aliases = aliases
+		entity.Source = request.Source
+		entity.Justification = request.Justification
+		entity.EffectiveDate = sql.NullTime{}
+		if request.EffectiveDate != nil {
+			entity.EffectiveDate = sql.NullTime{Time: request.EffectiveDate.UTC(), Valid: true}
+		}
+		entity.UpdatedBy = userId
+		entity.UpdatedAt = time.Now().UTC()
+
+		if err := txn.Save(&entity).Error; err != nil {
+			slog.Error("error updating watchlist entity", "entity_id", entityId, "error", err)
+			return ErrReportAccessFailed
+		}
+		return nil
+	})
+	if err != nil {
+		return api.WatchlistEntity{}, err
+	}
+
+	return convertWatchlistEntity(entity)
+}
+
+// Marks the entity as removed, it is kept so that there is an audit trail of
+// the changes to the watchlists.
+func (r *ReportManager) RemoveWatchlistEntity(userId uuid.UUID, watchlist string, entityId uuid.UUID) error {
+	if !eoc.IsWatchlist(watchlist) {
+		return ErrWatchlistNotFound
+	}
+
+	result := r.db.Model(&schema.WatchlistEntity{}).
+		Where("id = ? AND watchlist = ? AND removed_at IS NULL", entityId, watchlist).
+		Updates(map[string]any{
+			"removed_by": uuid.NullUUID{UUID: userId, Valid: true},
+			"removed_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		slog.Error("error removing watchlist entity", "entity_id", entityId, "error", result.Error)
+		return ErrReportAccessFailed
+	}
+	if result.RowsAffected == 0 {
+		return ErrWatchlistEntityNotFound
+	}
+
+	return nil
+}
+
+// Adds the entities to the watchlist, entities that are already in the
+// watchlist are skipped. If any of the entities are invalid none of them are
+// added.
+func (r *ReportManager) ImportWatchlistEntities(userId uuid.UUID, watchlist string, requests []api.WatchlistEntityRequest) (api.WatchlistImportResult, error) {
+	if !eoc.IsWatchlist(watchlist) {
+		return api.WatchlistImportResult{}, ErrWatchlistNotFound
+	}
+
+	now := time.Now().UTC()
+
+	entities := make([]schema.WatchlistEntity, 0, len(requests))
+	for i, request := range requests {
+		request, err := normalizeWatchlistEntity(watchlist, request)
+		if err != nil {
+			return api.WatchlistImportResult{}, fmt.Errorf("entity %d: %w", i+1, err)
+		}
+		entity, err := newWatchlistEntity(userId, watchlist, request, now)
+		if err != nil {
+			return api.WatchlistImportResult{}, err
+		}
+		entities = append(entities, entity)
+	}
+
+	var result api.WatchlistImportResult
+	err := r.db.Transaction(func(txn *gorm.DB) error {
+		existing, err := activeWatchlistEntityKeys(txn, watchlist)
+		if err != nil {
+			return err
+		}
+
+		added := make([]schema.WatchlistEntity, 0, len(entities))
+		for _, entity := range entities {
+			key := watchlistEntityKey(watchlist, entity.Name, entity.OpenAlexId)
+			if existing[key] {
+				result.Skipped++
+				continue
+			}
+			existing[key] = true
+			added = append(added, entity)
+		}
+
+		if len(added) > 0 {
+			if err := txn.CreateInBatches(&added, 500).Error; err != nil {
+				slog.Error("error importing watchlist entities", "watchlist", watchlist, "error", err)
+				return ErrReportCreationFailed
+			}
+		}
+		result.Imported = len(added)
+
+		return nil
+	})
+	if err != nil {
+		return api.WatchlistImportResult{}, err
+	}
+
+	return result, nil
+}
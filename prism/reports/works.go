@@ -3,17 +3,21 @@ package reports
 import (
 	"fmt"
 	"log/slog"
+	"prism/prism/api"
 	"prism/prism/gscholar"
 	"prism/prism/llms"
 	"prism/prism/openalex"
 	"prism/prism/reports/utils"
+	"prism/prism/scopus"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
-func streamOpenAlexWorks(openalex openalex.KnowledgeBase, authorId string, startDate, endDate time.Time) chan openalex.WorkBatch {
-	return openalex.StreamWorks(authorId, startDate, endDate)
+func streamOpenAlexWorks(openalex openalex.KnowledgeBase, authorId string, startDate, endDate, updatedSince time.Time) chan openalex.WorkBatch {
+	return openalex.StreamWorks(authorId, startDate, endDate, updatedSince)
 }
 
 func findOAAuthorId(work openalex.Work, targetAuthorName string) string {
@@ -45,7 +49,7 @@ func findTargetAuthorIds(works []openalex.Work, targetAuthorName string) []strin
 	return targetAuthorIds
 }
 
-func streamGScholarWorks(oa openalex.KnowledgeBase, authorName, gScholarAuthorId string, startDate, endDate time.Time) chan openalex.WorkBatch {
+func streamGScholarWorks(oa openalex.KnowledgeBase, authorName, gScholarAuthorId string, startDate, endDate, updatedSince time.Time) chan openalex.WorkBatch {
 	outputCh := make(chan openalex.WorkBatch, 10)
 
 	go func() {
@@ -63,7 +67,7 @@ func streamGScholarWorks(oa openalex.KnowledgeBase, authorName, gScholarAuthorId
 				break
 			}
 
-			works, err := oa.FindWorksByTitle(batch, startDate, endDate)
+			works, err := oa.FindWorksByTitle(batch, startDate, endDate, updatedSince)
 			if err != nil {
 				slog.Error("error getting works from openalex", "error", err)
 				outputCh <- openalex.WorkBatch{Works: nil, TargetAuthorIds: nil, Error: err}
@@ -87,8 +91,7 @@ And so on. Here comes the snippet:
 %s
 `
 
-//lint:ignore U1000 streamUnstructuredWorks
-func streamUnstructuredWorks(oa openalex.KnowledgeBase, authorName, text string, startDate, endDate time.Time) chan openalex.WorkBatch {
+func streamUnstructuredWorks(oa openalex.KnowledgeBase, authorName, text string, startDate, endDate, updatedSince time.Time) chan openalex.WorkBatch {
 	outputCh := make(chan openalex.WorkBatch, 10)
 
 	go func() {
@@ -116,7 +119,7 @@ func streamUnstructuredWorks(oa openalex.KnowledgeBase, authorName, text string,
 
 		const batchSize = 20
 		for i := 0; i < len(titles); i += batchSize {
-			works, err := oa.FindWorksByTitle(titles[i:min(len(titles), i+batchSize)], startDate, endDate)
+			works, err := oa.FindWorksByTitle(titles[i:min(len(titles), i+batchSize)], startDate, endDate, updatedSince)
 			if err != nil {
 				slog.Error("error finding works for titles", "error", err)
 				outputCh <- openalex.WorkBatch{Works: nil, TargetAuthorIds: nil, Error: fmt.Errorf("error finding works: %w", err)}
@@ -130,23 +133,121 @@ func streamUnstructuredWorks(oa openalex.KnowledgeBase, authorName, text string,
 	return outputCh
 }
 
-//lint:ignore U1000 streamScopusWorks
-func streamScopusWorks(oa openalex.KnowledgeBase, authorName string, titles []string, startDate, endDate time.Time) chan openalex.WorkBatch {
+func streamScopusWorks(oa openalex.KnowledgeBase, client *scopus.Client, authorName, scopusAuthorId string, startDate, endDate, updatedSince time.Time) chan openalex.WorkBatch {
 	outputCh := make(chan openalex.WorkBatch, 10)
 
 	go func() {
 		defer close(outputCh)
 
-		const batchSize = 20
-		for i := 0; i < len(titles); i += batchSize {
-			works, err := oa.FindWorksByTitle(titles[i:min(len(titles), i+batchSize)], startDate, endDate)
+		workTitleIterator := client.NewAuthorPaperIterator(scopusAuthorId, startDate, endDate)
+		for {
+			batch, err := workTitleIterator.Next()
 			if err != nil {
-				slog.Error("error finding works for titles", "error", err)
-				outputCh <- openalex.WorkBatch{Works: nil, TargetAuthorIds: nil, Error: fmt.Errorf("error finding works: %w", err)}
+				slog.Error("error iterating over work titles in scopus", "error", err)
+				outputCh <- openalex.WorkBatch{Works: nil, TargetAuthorIds: nil, Error: err}
+				break
+			}
+			if batch == nil {
 				break
 			}
 
-			outputCh <- openalex.WorkBatch{Works: works, TargetAuthorIds: findTargetAuthorIds(works, authorName), Error: nil}
+			works, err := oa.FindWorksByTitle(batch, startDate, endDate, updatedSince)
+			if err != nil {
+				slog.Error("error getting works from openalex", "error", err)
+				outputCh <- openalex.WorkBatch{Works: nil, TargetAuthorIds: nil, Error: err}
+				break
+			}
+
+			outputCh <- openalex.WorkBatch{Works: works, TargetAuthorIds: findTargetAuthorIds(works, authorName)}
+		}
+	}()
+
+	return outputCh
+}
+
+type sourceWorkStream struct {
+	identity api.AuthorIdentity
+	stream   chan openalex.WorkBatch
+}
+
+func workDedupKeys(work openalex.Work) []string {
+	keys := make([]string, 0, 2)
+	if work.WorkId != "" {
+		keys = append(keys, "id:"+work.WorkId)
+	}
+	if doi := strings.ToLower(strings.TrimPrefix(work.DOI, "https://doi.org/")); doi != "" {
+		keys = append(keys, "doi:"+doi)
+	}
+	return keys
+}
+
+// Combines the works from the streams for each of the profiles of a merged
+// report. Works are deduplicated by DOI and OpenAlex id as they arrive, and each
+// batch is sent as soon as it is received so that only the keys of the works
+// that have been sent are kept in memory. Each work records the profile it was
+// first found in, since it has already been flagged by the time it is found in
+// any of the other profiles.
+func streamMergedWorks(streams []sourceWorkStream) chan openalex.WorkBatch {
+	outputCh := make(chan openalex.WorkBatch, 10)
+
+	go func() {
+		defer close(outputCh)
+
+		type profileBatch struct {
+			identity api.AuthorIdentity
+			batch    openalex.WorkBatch
+		}
+
+		batches := make(chan profileBatch, 10)
+
+		wg := sync.WaitGroup{}
+		for _, stream := range streams {
+			wg.Add(1)
+			go func(stream sourceWorkStream) {
+				defer wg.Done()
+				for batch := range stream.stream {
+					batches <- profileBatch{identity: stream.identity, batch: batch}
+				}
+			}(stream)
+		}
+
+		go func() {
+			wg.Wait()
+			close(batches)
+		}()
+
+		seen := make(map[string]bool)
+		targetAuthorIds := make([]string, 0)
+
+		for next := range batches {
+			if next.batch.Error != nil {
+				outputCh <- openalex.WorkBatch{Works: nil, TargetAuthorIds: nil, Error: fmt.Errorf("error getting works from %s profile %s: %w", next.identity.Source, next.identity.AuthorId, next.batch.Error)}
+				continue
+			}
+
+			for _, authorId := range next.batch.TargetAuthorIds {
+				if !slices.Contains(targetAuthorIds, authorId) {
+					targetAuthorIds = append(targetAuthorIds, authorId)
+				}
+			}
+
+			works := make([]openalex.Work, 0, len(next.batch.Works))
+			for _, work := range next.batch.Works {
+				keys := workDedupKeys(work)
+				if slices.ContainsFunc(keys, func(key string) bool { return seen[key] }) {
+					continue
+				}
+				for _, key := range keys {
+					seen[key] = true
+				}
+
+				work.Identities = []api.AuthorIdentity{next.identity}
+				works = append(works, work)
+			}
+
+			if len(works) > 0 {
+				outputCh <- openalex.WorkBatch{Works: works, TargetAuthorIds: slices.Clone(targetAuthorIds), Error: nil}
+			}
 		}
 	}()
 
@@ -0,0 +1,93 @@
+package reports
+
+import (
+	"errors"
+	"prism/prism/api"
+	"prism/prism/openalex"
+	"slices"
+	"testing"
+)
+
+func sendBatches(batches ...openalex.WorkBatch) chan openalex.WorkBatch {
+	ch := make(chan openalex.WorkBatch, len(batches))
+	for _, batch := range batches {
+		ch <- batch
+	}
+	close(ch)
+	return ch
+}
+
+func TestStreamMergedWorks(t *testing.T) {
+	oaStream1 := sendBatches(
+		openalex.WorkBatch{
+			Works:           []openalex.Work{{WorkId: "W1", DOI: "https://doi.org/10.1/a"}, {WorkId: "W2"}},
+			TargetAuthorIds: []string{"A1"},
+		},
+	)
+	oaStream2 := sendBatches(
+		openalex.WorkBatch{
+			Works:           []openalex.Work{{WorkId: "W3"}},
+			TargetAuthorIds: []string{"A2"},
+		},
+	)
+	gscholarStream := sendBatches(
+		openalex.WorkBatch{
+			// W4 is a duplicate of W1 in openalex with the same DOI.
+			Works:           []openalex.Work{{WorkId: "W4", DOI: "https://doi.org/10.1/A"}, {WorkId: "W3"}, {WorkId: "W5"}},
+			TargetAuthorIds: []string{"A1", "A2"},
+		},
+		openalex.WorkBatch{Error: errors.New("gscholar error")},
+	)
+
+	oa1 := api.AuthorIdentity{Source: api.OpenAlexSource, AuthorId: "A1"}
+	oa2 := api.AuthorIdentity{Source: api.OpenAlexSource, AuthorId: "A2"}
+	gscholar := api.AuthorIdentity{Source: api.GoogleScholarSource, AuthorId: "G1"}
+
+	merged := streamMergedWorks([]sourceWorkStream{
+		{identity: oa1, stream: oaStream1},
+		{identity: oa2, stream: oaStream2},
+		{identity: gscholar, stream: gscholarStream},
+	})
+
+	works := make(map[string][]api.AuthorIdentity)
+	targetAuthorIds := make([]string, 0)
+	nErrors := 0
+	for batch := range merged {
+		if batch.Error != nil {
+			nErrors++
+			continue
+		}
+		for _, work := range batch.Works {
+			if _, ok := works[work.WorkId]; ok {
+				t.Fatalf("duplicate work %s", work.WorkId)
+			}
+			works[work.WorkId] = work.Identities
+		}
+		targetAuthorIds = batch.TargetAuthorIds
+	}
+
+	if nErrors != 1 {
+		t.Fatalf("expected 1 error, got %d", nErrors)
+	}
+
+	// Since the streams are consumed concurrently either W1 or W4 could be
+	// returned, but not both, and W3 could be from either profile that it is in.
+	_, hasW1 := works["W1"]
+	_, hasW4 := works["W4"]
+	if len(works) != 4 || hasW1 == hasW4 {
+		t.Fatalf("incorrect merged works: %v", works)
+	}
+	if hasW1 && !slices.Equal(works["W1"], []api.AuthorIdentity{oa1}) || hasW4 && !slices.Equal(works["W4"], []api.AuthorIdentity{gscholar}) {
+		t.Fatalf("incorrect profiles for W1: %v", works)
+	}
+	if !slices.Equal(works["W2"], []api.AuthorIdentity{oa1}) ||
+		len(works["W3"]) != 1 || (works["W3"][0] != oa2 && works["W3"][0] != gscholar) ||
+		!slices.Equal(works["W5"], []api.AuthorIdentity{gscholar}) {
+		t.Fatalf("incorrect merged works: %v", works)
+	}
+
+	slices.Sort(targetAuthorIds)
+	if !slices.Equal(targetAuthorIds, []string{"A1", "A2"}) {
+		t.Fatalf("incorrect target author ids: %v", targetAuthorIds)
+	}
+}
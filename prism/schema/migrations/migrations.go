@@ -40,6 +40,121 @@ func GetMigrator(db *gorm.DB) *gormigrate.Gormigrate {
 			Migrate:  versions.Migration5,
 			Rollback: versions.Rollback5,
 		},
+		{
+			ID:       "6",
+			Migrate:  versions.Migration6,
+			Rollback: versions.Rollback6,
+		},
+		{
+			ID:       "7",
+			Migrate:  versions.Migration7,
+			Rollback: versions.Rollback7,
+		},
+		{
+			ID:       "8",
+			Migrate:  versions.Migration8,
+			Rollback: versions.Rollback8,
+		},
+		{
+			ID:       "9",
+			Migrate:  versions.Migration9,
+			Rollback: versions.Rollback9,
+		},
+		{
+			ID:       "10",
+			Migrate:  versions.Migration10,
+			Rollback: versions.Rollback10,
+		},
+		{
+			ID:       "11",
+			Migrate:  versions.Migration11,
+			Rollback: versions.Rollback11,
+		},
+		{
+			ID:       "12",
+			Migrate:  versions.Migration12,
+			Rollback: versions.Rollback12,
+		},
+		{
+			ID:       "13",
+			Migrate:  versions.Migration13,
+			Rollback: versions.Rollback13,
+		},
+		{
+			ID:       "14",
+			Migrate:  versions.Migration14,
+			Rollback: versions.Rollback14,
+		},
+		{
+			ID:       "15",
+			Migrate:  versions.Migration15,
+			Rollback: versions.Rollback15,
+		},
+		{
+			ID:       "16",
+			Migrate:  versions.Migration16,
+			Rollback: versions.Rollback16,
+		},
+		{
+			ID:       "17",
+			Migrate:  versions.Migration17,
+			Rollback: versions.Rollback17,
+		},
+		{
+			ID:       "18",
+			Migrate:  versions.Migration18,
+			Rollback: versions.Rollback18,
+		},
+		{
+			ID:       "19",
+			Migrate:  versions.Migration19,
+			Rollback: versions.Rollback19,
+		},
+		{
+			ID:       "20",
+			Migrate:  versions.Migration20,
+			Rollback: versions.Rollback20,
+		},
+		{
+			ID:       "21",
+			Migrate:  versions.Migration21,
+			Rollback: versions.Rollback21,
+		},
+		{
+			ID:       "22",
+			Migrate:  versions.Migration22,
+			Rollback: versions.Rollback22,
+		},
+		{
+			ID:       "23",
+			Migrate:  versions.Migration23,
+			Rollback: versions.Rollback23,
+		},
+		{
+			ID:       "24",
+			Migrate:  versions.Migration24,
+			Rollback: versions.Rollback24,
+		},
+		{
+			ID:       "25",
+			Migrate:  versions.Migration25,
+			Rollback: versions.Rollback25,
+		},
+		{
+			ID:       "26",
+			Migrate:  versions.Migration26,
+			Rollback: versions.Rollback26,
+		},
+		{
+			ID:       "27",
+			Migrate:  versions.Migration27,
+			Rollback: versions.Rollback27,
+		},
+		{
+			ID:       "28",
+			Migrate:  versions.Migration28,
+			Rollback: versions.Rollback28,
+		},
 	})
 
 	migrator.InitSchema(func(txn *gorm.DB) error {
@@ -49,10 +164,24 @@ func GetMigrator(db *gorm.DB) *gormigrate.Gormigrate {
 
 		log.Println("clean database detected, running full schema initialization")
 
-		return db.AutoMigrate(
+		if err := db.AutoMigrate(
 			&schema.AuthorReport{}, &schema.AuthorFlag{}, &schema.UserAuthorReport{},
 			&schema.AuthorReportHook{}, &schema.UniversityReport{}, &schema.UserUniversityReport{},
-		)
+			&schema.AuthorReportIdentity{}, &schema.FlagTriage{},
+			&schema.FlagSuppression{}, &schema.DisclosureDocument{}, &schema.FlagDisclosure{}, &schema.RiskWeights{}, &schema.UniversityAuthor{},
+			&schema.Organization{}, &schema.OrganizationMember{}, &schema.OrganizationInvite{},
+			&schema.AuditLogEntry{}, &schema.UsageCounter{}, &schema.AuthorReportBatch{}, &schema.AuthorReportBatchRow{},
+			&schema.ReportQueueGroup{}, &schema.AuthorReportFlagger{}, &schema.AuthorReportWatchlist{}, &schema.WatchlistUpdate{}, &schema.WatchlistEntity{},
+			&schema.UniversityReportSummary{}, &schema.UniversityAuthorSummary{}, &schema.UniversityAuthorFlagCount{},
+		); err != nil {
+			return err
+		}
+
+		if err := versions.SeedWatchlists(db); err != nil {
+			return err
+		}
+
+		return versions.PreventAuditLogChanges(db)
 	})
 
 	return migrator
@@ -0,0 +1,45 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration10(db *gorm.DB) error {
+	type FlagTriage struct {
+		ReportId   uuid.UUID `gorm:"type:uuid;primaryKey"`
+		FlagHash   string    `gorm:"type:char(64);primaryKey"`
+		Status     string    `gorm:"size:20;not null"`
+		Note       string
+		ReviewerId uuid.UUID `gorm:"type:uuid;not null"`
+		UpdatedAt  time.Time
+	}
+
+	type UserAuthorReport struct {
+		Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+		Triage []FlagTriage `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+	}
+
+	if err := db.Migrator().CreateTable(&FlagTriage{}); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateConstraint(&UserAuthorReport{}, "Triage"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback10(db *gorm.DB) error {
+	type FlagTriage struct{}
+
+	if err := db.Migrator().DropTable(&FlagTriage{}); err != nil {
+		return err
+	}
+
+	return nil
+}
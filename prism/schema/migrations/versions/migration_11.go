@@ -0,0 +1,43 @@
+package versions
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration11(db *gorm.DB) error {
+	type FlagSuppression struct {
+		Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+		AuthorId string `gorm:"not null;index"`
+		FlagType string `gorm:"size:40"`
+		Entity   string
+		FlagHash string `gorm:"size:64"`
+		Reason   string
+
+		CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+		CreatedAt time.Time
+
+		RemovedBy uuid.NullUUID `gorm:"type:uuid"`
+		RemovedAt sql.NullTime
+	}
+
+	if err := db.Migrator().CreateTable(&FlagSuppression{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback11(db *gorm.DB) error {
+	type FlagSuppression struct{}
+
+	if err := db.Migrator().DropTable(&FlagSuppression{}); err != nil {
+		return err
+	}
+
+	return nil
+}
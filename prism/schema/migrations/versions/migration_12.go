@@ -0,0 +1,64 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration12(db *gorm.DB) error {
+	type FlagDisclosure struct {
+		ReportId    uuid.UUID `gorm:"type:uuid;primaryKey"`
+		FlagHash    string    `gorm:"type:char(64);primaryKey"`
+		DocumentId  uuid.UUID `gorm:"type:uuid;primaryKey"`
+		DisclosedAt time.Time
+	}
+
+	type DisclosureDocument struct {
+		Id         uuid.UUID `gorm:"type:uuid;primaryKey"`
+		ReportId   uuid.UUID `gorm:"type:uuid;not null;index"`
+		Filename   string
+		Text       string
+		UploadedBy uuid.UUID `gorm:"type:uuid;not null"`
+		UploadedAt time.Time
+
+		FlagDisclosures []FlagDisclosure `gorm:"foreignKey:DocumentId;constraint:OnDelete:CASCADE"`
+	}
+
+	type UserAuthorReport struct {
+		Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+		Disclosures     []DisclosureDocument `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+		FlagDisclosures []FlagDisclosure     `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+	}
+
+	if err := db.Migrator().CreateTable(&DisclosureDocument{}, &FlagDisclosure{}); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateConstraint(&DisclosureDocument{}, "FlagDisclosures"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateConstraint(&UserAuthorReport{}, "Disclosures"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateConstraint(&UserAuthorReport{}, "FlagDisclosures"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback12(db *gorm.DB) error {
+	type FlagDisclosure struct{}
+	type DisclosureDocument struct{}
+
+	if err := db.Migrator().DropTable(&FlagDisclosure{}, &DisclosureDocument{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package versions
+
+import (
+	"gorm.io/gorm"
+)
+
+func Migration13(db *gorm.DB) error {
+	type FlagDisclosure struct {
+		Snippet    string
+		Page       int
+		Confidence float64
+	}
+
+	for _, column := range []string{"Snippet", "Page", "Confidence"} {
+		if err := db.Migrator().AddColumn(&FlagDisclosure{}, column); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Rollback13(db *gorm.DB) error {
+	type FlagDisclosure struct {
+		Snippet    string
+		Page       int
+		Confidence float64
+	}
+
+	for _, column := range []string{"Snippet", "Page", "Confidence"} {
+		if err := db.Migrator().DropColumn(&FlagDisclosure{}, column); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
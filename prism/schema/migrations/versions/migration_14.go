@@ -0,0 +1,26 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration14(db *gorm.DB) error {
+	type RiskWeights struct {
+		OrganizationId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Weights        []byte
+
+		UpdatedBy uuid.UUID `gorm:"type:uuid"`
+		UpdatedAt time.Time
+	}
+
+	return db.Migrator().CreateTable(&RiskWeights{})
+}
+
+func Rollback14(db *gorm.DB) error {
+	type RiskWeights struct{}
+
+	return db.Migrator().DropTable(&RiskWeights{})
+}
@@ -0,0 +1,21 @@
+package versions
+
+import (
+	"gorm.io/gorm"
+)
+
+func Migration15(db *gorm.DB) error {
+	type UniversityAuthor struct {
+		Department string
+	}
+
+	return db.Migrator().AddColumn(&UniversityAuthor{}, "Department")
+}
+
+func Rollback15(db *gorm.DB) error {
+	type UniversityAuthor struct {
+		Department string
+	}
+
+	return db.Migrator().DropColumn(&UniversityAuthor{}, "Department")
+}
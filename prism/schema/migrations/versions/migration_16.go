@@ -0,0 +1,37 @@
+package versions
+
+import (
+	"gorm.io/gorm"
+)
+
+func Migration16(db *gorm.DB) error {
+	type UniversityReport struct {
+		LookbackYears               int  `gorm:"not null;default:4"`
+		MinWorks                    int  `gorm:"not null;default:1"`
+		RequireLastKnownInstitution bool `gorm:"not null;default:false"`
+	}
+
+	for _, column := range []string{"LookbackYears", "MinWorks", "RequireLastKnownInstitution"} {
+		if err := db.Migrator().AddColumn(&UniversityReport{}, column); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Rollback16(db *gorm.DB) error {
+	type UniversityReport struct {
+		LookbackYears               int  `gorm:"not null;default:4"`
+		MinWorks                    int  `gorm:"not null;default:1"`
+		RequireLastKnownInstitution bool `gorm:"not null;default:false"`
+	}
+
+	for _, column := range []string{"LookbackYears", "MinWorks", "RequireLastKnownInstitution"} {
+		if err := db.Migrator().DropColumn(&UniversityReport{}, column); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration17(db *gorm.DB) error {
+	type OrganizationMember struct {
+		UserId         uuid.UUID `gorm:"type:uuid;primaryKey"`
+		OrganizationId uuid.UUID `gorm:"type:uuid;not null;index"`
+		Email          string
+		Role           string `gorm:"size:20;not null"`
+		JoinedAt       time.Time
+	}
+
+	type OrganizationInvite struct {
+		OrganizationId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Email          string    `gorm:"primaryKey"`
+		Role           string    `gorm:"size:20;not null"`
+		InvitedBy      uuid.UUID `gorm:"type:uuid;not null"`
+		InvitedAt      time.Time
+	}
+
+	type Organization struct {
+		Id   uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Name string    `gorm:"not null;uniqueIndex"`
+
+		CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+		CreatedAt time.Time
+
+		Members []OrganizationMember `gorm:"foreignKey:OrganizationId;constraint:OnDelete:CASCADE"`
+		Invites []OrganizationInvite `gorm:"foreignKey:OrganizationId;constraint:OnDelete:CASCADE"`
+	}
+
+	type UserAuthorReport struct {
+		SharedAccess string `gorm:"size:10;not null;default:''"`
+	}
+
+	if err := db.Migrator().CreateTable(&Organization{}, &OrganizationMember{}, &OrganizationInvite{}); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateConstraint(&Organization{}, "Members"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateConstraint(&Organization{}, "Invites"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().AddColumn(&UserAuthorReport{}, "SharedAccess"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback17(db *gorm.DB) error {
+	type UserAuthorReport struct {
+		SharedAccess string `gorm:"size:10;not null;default:''"`
+	}
+
+	if err := db.Migrator().DropColumn(&UserAuthorReport{}, "SharedAccess"); err != nil {
+		return err
+	}
+
+	type OrganizationInvite struct{}
+	type OrganizationMember struct{}
+	type Organization struct{}
+
+	if err := db.Migrator().DropTable(&OrganizationInvite{}, &OrganizationMember{}, &Organization{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Adds a trigger that rejects updates and deletes of audit log entries so that
+// the audit log is append only. This is also used when initializing the schema
+// of a new database.
+func PreventAuditLogChanges(db *gorm.DB) error {
+	if err := db.Exec(`CREATE OR REPLACE FUNCTION prevent_audit_log_changes() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit log entries cannot be modified';
+END;
+$$ LANGUAGE plpgsql`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`CREATE TRIGGER audit_log_entries_append_only
+BEFORE UPDATE OR DELETE ON audit_log_entries
+FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_changes()`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`CREATE TRIGGER audit_log_entries_no_truncate
+BEFORE TRUNCATE ON audit_log_entries
+FOR EACH STATEMENT EXECUTE FUNCTION prevent_audit_log_changes()`).Error
+}
+
+func Migration18(db *gorm.DB) error {
+	type AuditLogEntry struct {
+		Id        uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Timestamp time.Time `gorm:"not null;index"`
+
+		UserId uuid.UUID `gorm:"type:uuid;not null;index"`
+		Email  string
+
+		Action string `gorm:"size:40;not null"`
+		Method string `gorm:"size:10;not null"`
+		Path   string `gorm:"not null"`
+		Query  string
+
+		ReportId   uuid.NullUUID `gorm:"type:uuid;index"`
+		AuthorId   string        `gorm:"index"`
+		AuthorName string
+
+		StatusCode int
+		Outcome    string `gorm:"size:20;not null"`
+	}
+
+	if err := db.Migrator().CreateTable(&AuditLogEntry{}); err != nil {
+		return err
+	}
+
+	return PreventAuditLogChanges(db)
+}
+
+func Rollback18(db *gorm.DB) error {
+	type AuditLogEntry struct{}
+
+	if err := db.Migrator().DropTable(&AuditLogEntry{}); err != nil {
+		return err
+	}
+
+	return db.Exec("DROP FUNCTION IF EXISTS prevent_audit_log_changes").Error
+}
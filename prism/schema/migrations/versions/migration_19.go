@@ -0,0 +1,26 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration19(db *gorm.DB) error {
+	type UsageCounter struct {
+		OwnerId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Metric      string    `gorm:"size:40;primaryKey"`
+		PeriodStart time.Time `gorm:"primaryKey"`
+
+		Used int `gorm:"not null;default:0"`
+	}
+
+	return db.Migrator().CreateTable(&UsageCounter{})
+}
+
+func Rollback19(db *gorm.DB) error {
+	type UsageCounter struct{}
+
+	return db.Migrator().DropTable(&UsageCounter{})
+}
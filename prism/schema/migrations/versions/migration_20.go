@@ -0,0 +1,55 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration20(db *gorm.DB) error {
+	type UserAuthorReport struct {
+		Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+	}
+
+	type AuthorReportBatchRow struct {
+		BatchId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Row     int       `gorm:"primaryKey;autoIncrement:false"`
+
+		Name        string
+		Institution string
+		Orcid       string
+		OpenAlexId  string
+
+		Status     string `gorm:"size:20;not null"`
+		Message    string
+		Candidates []byte
+
+		UserReportId uuid.NullUUID     `gorm:"type:uuid;index"`
+		UserReport   *UserAuthorReport `gorm:"foreignKey:UserReportId;constraint:OnDelete:SET NULL"`
+	}
+
+	type AuthorReportBatch struct {
+		Id     uuid.UUID `gorm:"type:uuid;primaryKey"`
+		UserId uuid.UUID `gorm:"type:uuid;not null;index"`
+
+		Name          string
+		LookbackYears int
+		CreatedAt     time.Time
+
+		Rows []AuthorReportBatchRow `gorm:"foreignKey:BatchId;constraint:OnDelete:CASCADE"`
+	}
+
+	if err := db.Migrator().CreateTable(&AuthorReportBatch{}, &AuthorReportBatchRow{}); err != nil {
+		return err
+	}
+
+	return db.Migrator().CreateConstraint(&AuthorReportBatch{}, "Rows")
+}
+
+func Rollback20(db *gorm.DB) error {
+	type AuthorReportBatchRow struct{}
+	type AuthorReportBatch struct{}
+
+	return db.Migrator().DropTable(&AuthorReportBatchRow{}, &AuthorReportBatch{})
+}
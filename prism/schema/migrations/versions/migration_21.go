@@ -0,0 +1,21 @@
+package versions
+
+import (
+	"gorm.io/gorm"
+)
+
+func Migration21(db *gorm.DB) error {
+	type AuthorReport struct {
+		Progress []byte
+	}
+
+	return db.Migrator().AddColumn(&AuthorReport{}, "Progress")
+}
+
+func Rollback21(db *gorm.DB) error {
+	type AuthorReport struct {
+		Progress []byte
+	}
+
+	return db.Migrator().DropColumn(&AuthorReport{}, "Progress")
+}
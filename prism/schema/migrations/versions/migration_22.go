@@ -0,0 +1,54 @@
+package versions
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func Migration22(db *gorm.DB) error {
+	type AuthorReport struct {
+		Priority   int    `gorm:"not null;default:0"`
+		QueueGroup string `gorm:"size:40;not null;default:''"`
+	}
+
+	type ReportQueueGroup struct {
+		Name            string `gorm:"size:40;primaryKey"`
+		LastScheduledAt time.Time
+	}
+
+	for _, column := range []string{"Priority", "QueueGroup"} {
+		if err := db.Migrator().AddColumn(&AuthorReport{}, column); err != nil {
+			return err
+		}
+	}
+
+	// Reports that are already queued keep the order they had before priorities
+	// were added, where reports queued by users were processed first.
+	if err := db.Exec("UPDATE author_reports SET priority = CASE WHEN for_university_report THEN 1 ELSE 2 END WHERE status = 'queued'").Error; err != nil {
+		return err
+	}
+
+	return db.Migrator().CreateTable(&ReportQueueGroup{})
+}
+
+func Rollback22(db *gorm.DB) error {
+	type AuthorReport struct {
+		Priority   int
+		QueueGroup string
+	}
+
+	type ReportQueueGroup struct{}
+
+	if err := db.Migrator().DropTable(&ReportQueueGroup{}); err != nil {
+		return err
+	}
+
+	for _, column := range []string{"Priority", "QueueGroup"} {
+		if err := db.Migrator().DropColumn(&AuthorReport{}, column); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
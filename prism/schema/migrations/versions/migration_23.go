@@ -0,0 +1,61 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration23(db *gorm.DB) error {
+	type AuthorFlag struct {
+		Flagger        string `gorm:"size:40"`
+		FlaggerVersion string `gorm:"size:100"`
+	}
+
+	type AuthorReportFlagger struct {
+		ReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Flagger  string    `gorm:"size:40;primaryKey"`
+		Version  string    `gorm:"size:100"`
+		RanAt    time.Time
+	}
+
+	type AuthorReport struct {
+		Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+		Flaggers []AuthorReportFlagger `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+	}
+
+	for _, column := range []string{"Flagger", "FlaggerVersion"} {
+		if err := db.Migrator().AddColumn(&AuthorFlag{}, column); err != nil {
+			return err
+		}
+	}
+
+	if err := db.Migrator().CreateTable(&AuthorReportFlagger{}); err != nil {
+		return err
+	}
+
+	return db.Migrator().CreateConstraint(&AuthorReport{}, "Flaggers")
+}
+
+func Rollback23(db *gorm.DB) error {
+	type AuthorFlag struct {
+		Flagger        string
+		FlaggerVersion string
+	}
+
+	type AuthorReportFlagger struct{}
+
+	if err := db.Migrator().DropTable(&AuthorReportFlagger{}); err != nil {
+		return err
+	}
+
+	for _, column := range []string{"Flagger", "FlaggerVersion"} {
+		if err := db.Migrator().DropColumn(&AuthorFlag{}, column); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration24(db *gorm.DB) error {
+	type AuthorFlag struct {
+		WatchlistUpdate string `gorm:"size:64;not null;default:''"`
+	}
+
+	type AuthorReportWatchlist struct {
+		ReportId  uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Watchlist string    `gorm:"size:40;primaryKey"`
+		Version   string    `gorm:"size:64"`
+	}
+
+	type AuthorReport struct {
+		Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+		WatchlistUpdate string `gorm:"size:64;not null;default:''"`
+
+		Watchlists []AuthorReportWatchlist `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+	}
+
+	type WatchlistUpdate struct {
+		Version   string `gorm:"size:64;primaryKey"`
+		CreatedAt time.Time
+
+		Watchlists []byte
+		Changed    []byte
+
+		ReportsRequeued int
+	}
+
+	if err := db.Migrator().AddColumn(&AuthorFlag{}, "WatchlistUpdate"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().AddColumn(&AuthorReport{}, "WatchlistUpdate"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateTable(&AuthorReportWatchlist{}, &WatchlistUpdate{}); err != nil {
+		return err
+	}
+
+	return db.Migrator().CreateConstraint(&AuthorReport{}, "Watchlists")
+}
+
+func Rollback24(db *gorm.DB) error {
+	type AuthorFlag struct {
+		WatchlistUpdate string
+	}
+
+	type AuthorReport struct {
+		WatchlistUpdate string
+	}
+
+	type AuthorReportWatchlist struct{}
+
+	type WatchlistUpdate struct{}
+
+	if err := db.Migrator().DropTable(&AuthorReportWatchlist{}, &WatchlistUpdate{}); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().DropColumn(&AuthorReport{}, "WatchlistUpdate"); err != nil {
+		return err
+	}
+
+	return db.Migrator().DropColumn(&AuthorFlag{}, "WatchlistUpdate")
+}
@@ -0,0 +1,75 @@
+package versions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"prism/prism/reports/flaggers/eoc"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type watchlistEntity25 struct {
+	Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+	Watchlist     string `gorm:"size:40;not null;index"`
+	Name          string `gorm:"not null"`
+	OpenAlexId    string
+	Aliases       []byte
+	Source        string
+	EffectiveDate sql.NullTime
+	Justification string
+
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+	UpdatedBy uuid.UUID `gorm:"type:uuid"`
+	UpdatedAt time.Time
+
+	RemovedBy uuid.NullUUID `gorm:"type:uuid"`
+	RemovedAt sql.NullTime
+}
+
+func (watchlistEntity25) TableName() string {
+	return "watchlist_entities"
+}
+
+// Imports the watchlists that were built in before watchlists were stored in the
+// database. This is also used when initializing the schema of a new database.
+func SeedWatchlists(db *gorm.DB) error {
+	now := time.Now().UTC()
+
+	seed := eoc.SeedEntities()
+	entities := make([]watchlistEntity25, 0, len(seed))
+	for _, entity := range seed {
+		aliases, err := json.Marshal(entity.Aliases)
+		if err != nil {
+			return err
+		}
+		entities = append(entities, watchlistEntity25{
+			Id:            uuid.New(),
+			Watchlist:     entity.Watchlist,
+			Name:          entity.Name,
+			OpenAlexId:    entity.OpenAlexId,
+			Aliases:       aliases,
+			Source:        entity.Source,
+			Justification: "Imported from the built in watchlists",
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+
+	return db.CreateInBatches(&entities, 500).Error
+}
+
+func Migration25(db *gorm.DB) error {
+	if err := db.Migrator().CreateTable(&watchlistEntity25{}); err != nil {
+		return err
+	}
+
+	return SeedWatchlists(db)
+}
+
+func Rollback25(db *gorm.DB) error {
+	return db.Migrator().DropTable(&watchlistEntity25{})
+}
@@ -0,0 +1,64 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scopes the flag suppressions to the organization of the user that created
+// them. Suppressions created by users that are not in an organization are
+// marked as removed. Previously the flags that matched a suppression were
+// removed from the reports for the author, so the reports are reprocessed from
+// the earliest report date to restore them.
+func Migration26(db *gorm.DB) error {
+	type FlagSuppression struct {
+		OrganizationId uuid.UUID `gorm:"type:uuid;not null;index"`
+		AuthorName     string
+	}
+
+	if err := db.Exec("ALTER TABLE flag_suppressions ADD COLUMN organization_id uuid").Error; err != nil {
+		return err
+	}
+
+	if err := db.Migrator().AddColumn(&FlagSuppression{}, "AuthorName"); err != nil {
+		return err
+	}
+
+	if err := db.Exec(`UPDATE flag_suppressions SET organization_id = organization_members.organization_id
+FROM organization_members WHERE organization_members.user_id = flag_suppressions.created_by`).Error; err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	if err := db.Exec("UPDATE flag_suppressions SET organization_id = ?, removed_at = COALESCE(removed_at, ?) WHERE organization_id IS NULL", uuid.Nil, now).Error; err != nil {
+		return err
+	}
+
+	if err := db.Migrator().AlterColumn(&FlagSuppression{}, "OrganizationId"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateIndex(&FlagSuppression{}, "OrganizationId"); err != nil {
+		return err
+	}
+
+	earliestReportDate := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return db.Exec("UPDATE author_reports SET status = 'queued', status_updated_at = ?, last_updated_at = ? WHERE author_id IN (SELECT author_id FROM flag_suppressions)", now, earliestReportDate).Error
+}
+
+func Rollback26(db *gorm.DB) error {
+	type FlagSuppression struct {
+		OrganizationId uuid.UUID
+		AuthorName     string
+	}
+
+	if err := db.Migrator().DropColumn(&FlagSuppression{}, "AuthorName"); err != nil {
+		return err
+	}
+
+	return db.Migrator().DropColumn(&FlagSuppression{}, "OrganizationId")
+}
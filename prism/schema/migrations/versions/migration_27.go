@@ -0,0 +1,68 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration27(db *gorm.DB) error {
+	type UniversityAuthor struct {
+		UniversityReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		AuthorReportId     uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+		Department         string
+	}
+
+	type UniversityReportSummary struct {
+		UniversityReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		OrganizationId     uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+
+		ComputedAt time.Time
+
+		YearlyTrends []byte
+	}
+
+	type UniversityAuthorSummary struct {
+		UniversityReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		OrganizationId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+		AuthorReportId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+		FlagCount int
+		RiskScore float64
+		RiskTier  string `gorm:"size:20"`
+	}
+
+	type UniversityAuthorFlagCount struct {
+		UniversityReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		OrganizationId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+		AuthorReportId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+		FlagType           string    `gorm:"size:40;primaryKey"`
+
+		FlagCount int
+	}
+
+	if err := db.Migrator().CreateIndex(&UniversityAuthor{}, "AuthorReportId"); err != nil {
+		return err
+	}
+
+	return db.Migrator().CreateTable(&UniversityReportSummary{}, &UniversityAuthorSummary{}, &UniversityAuthorFlagCount{})
+}
+
+func Rollback27(db *gorm.DB) error {
+	type UniversityAuthor struct {
+		AuthorReportId uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	}
+
+	type UniversityReportSummary struct{}
+
+	type UniversityAuthorSummary struct{}
+
+	type UniversityAuthorFlagCount struct{}
+
+	if err := db.Migrator().DropTable(&UniversityReportSummary{}, &UniversityAuthorSummary{}, &UniversityAuthorFlagCount{}); err != nil {
+		return err
+	}
+
+	return db.Migrator().DropIndex(&UniversityAuthor{}, "AuthorReportId")
+}
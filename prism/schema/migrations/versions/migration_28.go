@@ -0,0 +1,106 @@
+package versions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Watchlist updates were identified by the version of the watchlists, so if
+// the watchlists changed back to a previous version the update was not
+// recorded. The updates are given an id and linked to the previous update, and
+// the reports and flags reference the update by its id instead of its version.
+func Migration28(db *gorm.DB) error {
+	type WatchlistUpdate struct {
+		Id         uuid.UUID `gorm:"type:uuid"`
+		PreviousId uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+		Version    string    `gorm:"size:64;not null;index"`
+		CreatedAt  time.Time
+	}
+
+	if err := db.Exec("ALTER TABLE watchlist_updates ADD COLUMN id uuid, ADD COLUMN previous_id uuid").Error; err != nil {
+		return err
+	}
+
+	var updates []WatchlistUpdate
+	if err := db.Order("created_at ASC").Find(&updates).Error; err != nil {
+		return err
+	}
+
+	previous := uuid.Nil
+	for _, update := range updates {
+		id := uuid.New()
+
+		if err := db.Model(&WatchlistUpdate{}).Where("version = ?", update.Version).Updates(map[string]any{"id": id, "previous_id": previous}).Error; err != nil {
+			return err
+		}
+
+		for _, table := range []string{"author_reports", "author_flags"} {
+			if err := db.Table(table).Where("watchlist_update = ?", update.Version).Update("watchlist_update", id.String()).Error; err != nil {
+				return err
+			}
+		}
+
+		previous = id
+	}
+
+	if err := db.Exec("ALTER TABLE watchlist_updates DROP CONSTRAINT watchlist_updates_pkey").Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("ALTER TABLE watchlist_updates ADD PRIMARY KEY (id), ALTER COLUMN previous_id SET NOT NULL").Error; err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateIndex(&WatchlistUpdate{}, "PreviousId"); err != nil {
+		return err
+	}
+
+	return db.Migrator().CreateIndex(&WatchlistUpdate{}, "Version")
+}
+
+func Rollback28(db *gorm.DB) error {
+	type WatchlistUpdate struct {
+		Id         uuid.UUID
+		PreviousId uuid.UUID
+		Version    string
+	}
+
+	var updates []WatchlistUpdate
+	if err := db.Find(&updates).Error; err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		for _, table := range []string{"author_reports", "author_flags"} {
+			if err := db.Table(table).Where("watchlist_update = ?", update.Id.String()).Update("watchlist_update", update.Version).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	// Only the latest update with each version can be kept.
+	if err := db.Exec(`DELETE FROM watchlist_updates WHERE EXISTS (SELECT 1 FROM watchlist_updates AS later
+WHERE later.version = watchlist_updates.version AND later.created_at > watchlist_updates.created_at)`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("ALTER TABLE watchlist_updates DROP CONSTRAINT watchlist_updates_pkey").Error; err != nil {
+		return err
+	}
+
+	if err := db.Migrator().DropColumn(&WatchlistUpdate{}, "PreviousId"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().DropColumn(&WatchlistUpdate{}, "Id"); err != nil {
+		return err
+	}
+
+	if err := db.Exec("DROP INDEX IF EXISTS idx_watchlist_updates_version").Error; err != nil {
+		return err
+	}
+
+	return db.Exec("ALTER TABLE watchlist_updates ADD PRIMARY KEY (version)").Error
+}
@@ -0,0 +1,29 @@
+package versions
+
+import (
+	"gorm.io/gorm"
+)
+
+func Migration6(db *gorm.DB) error {
+	type AuthorReport struct {
+		UnstructuredText string
+	}
+
+	if err := db.Migrator().AddColumn(&AuthorReport{}, "UnstructuredText"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback6(db *gorm.DB) error {
+	type AuthorReport struct {
+		UnstructuredText string
+	}
+
+	if err := db.Migrator().DropColumn(&AuthorReport{}, "UnstructuredText"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package versions
+
+import (
+	"gorm.io/gorm"
+)
+
+func Migration7(db *gorm.DB) error {
+	type AuthorReport struct {
+		Orcid string `gorm:"index"`
+	}
+
+	if err := db.Migrator().AddColumn(&AuthorReport{}, "Orcid"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateIndex(&AuthorReport{}, "Orcid"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback7(db *gorm.DB) error {
+	type AuthorReport struct {
+		Orcid string `gorm:"index"`
+	}
+
+	if err := db.Migrator().DropIndex(&AuthorReport{}, "Orcid"); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().DropColumn(&AuthorReport{}, "Orcid"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package versions
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func Migration8(db *gorm.DB) error {
+	type AuthorReportIdentity struct {
+		ReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+		Source   string    `gorm:"primaryKey"`
+		AuthorId string    `gorm:"primaryKey"`
+	}
+
+	type AuthorReport struct {
+		Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+		Identities []AuthorReportIdentity `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+	}
+
+	if err := db.Migrator().CreateTable(&AuthorReportIdentity{}); err != nil {
+		return err
+	}
+
+	if err := db.Migrator().CreateConstraint(&AuthorReport{}, "Identities"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback8(db *gorm.DB) error {
+	type AuthorReportIdentity struct{}
+
+	if err := db.Migrator().DropTable(&AuthorReportIdentity{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package versions
+
+import (
+	"gorm.io/gorm"
+)
+
+func Migration9(db *gorm.DB) error {
+	type AuthorReport struct {
+		LookbackYears int `gorm:"not null;default:0"`
+	}
+
+	if err := db.Migrator().AddColumn(&AuthorReport{}, "LookbackYears"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback9(db *gorm.DB) error {
+	type AuthorReport struct {
+		LookbackYears int `gorm:"not null;default:0"`
+	}
+
+	if err := db.Migrator().DropColumn(&AuthorReport{}, "LookbackYears"); err != nil {
+		return err
+	}
+
+	return nil
+}
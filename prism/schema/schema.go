@@ -14,6 +14,15 @@ const (
 	ReportCompleted  = "complete"
 )
 
+// Queued author reports are processed in order of priority. Reports requested
+// by users are processed first, then the authors of new university reports,
+// and finally the periodic refreshes of existing reports.
+const (
+	ReportPriorityRefresh     = 0
+	ReportPriorityUniversity  = 1
+	ReportPriorityInteractive = 2
+)
+
 type AuthorReport struct {
 	Id uuid.UUID `gorm:"type:uuid;primaryKey"`
 
@@ -22,14 +31,54 @@ type AuthorReport struct {
 	AuthorId          string `gorm:"index"`
 	AuthorName        string
 	Source            string
+	Orcid             string `gorm:"index"`
 	Affiliations      string
 	ResearchInterests string
+	UnstructuredText  string
+
+	// The number of years before the current date that the report covers, 0
+	// means that the report covers all works since the earliest report date.
+	LookbackYears int `gorm:"not null;default:0"`
 
 	StatusUpdatedAt     time.Time
 	Status              string `gorm:"size:20;not null"`
 	ForUniversityReport bool
 
+	Priority int `gorm:"not null;default:0"`
+	// Reports with the same priority are scheduled round robin between queue
+	// groups so that a single user or university cannot monopolize the workers.
+	// This is the id of the user or university report that queued the report.
+	QueueGroup string `gorm:"size:40;not null;default:''"`
+
+	// The json encoded api.ReportProgress of the last time the report was
+	// processed.
+	Progress []byte
+
+	// The id of the watchlist update that requeued the report, it is cleared
+	// once the report is reprocessed.
+	WatchlistUpdate string `gorm:"size:64;not null;default:''"`
+
 	Flags []AuthorFlag `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+
+	Flaggers []AuthorReportFlagger `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+
+	Watchlists []AuthorReportWatchlist `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+
+	// Only used for merged reports.
+	Identities []AuthorReportIdentity `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+}
+
+// Records when a report was last started for each queue group, the group that
+// has waited the longest is picked next.
+type ReportQueueGroup struct {
+	Name            string `gorm:"size:40;primaryKey"`
+	LastScheduledAt time.Time
+}
+
+type AuthorReportIdentity struct {
+	ReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Source   string    `gorm:"primaryKey"`
+	AuthorId string    `gorm:"primaryKey"`
 }
 
 type AuthorFlag struct {
@@ -38,6 +87,160 @@ type AuthorFlag struct {
 	FlagType string    `gorm:"size:40;not null"`
 	Date     sql.NullTime
 	Data     []byte
+
+	// The flagger and version of the flagger that found the flag.
+	Flagger        string `gorm:"size:40"`
+	FlaggerVersion string `gorm:"size:100"`
+
+	// The id of the watchlist update if the flag was first found when the
+	// report was reprocessed because of the update.
+	WatchlistUpdate string `gorm:"size:64;not null;default:''"`
+}
+
+// Records the version of each flagger the last time it was run successfully on
+// a report, this is used to decide which flaggers need to be run again when the
+// report is updated.
+type AuthorReportFlagger struct {
+	ReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Flagger  string    `gorm:"size:40;primaryKey"`
+	Version  string    `gorm:"size:100"`
+	RanAt    time.Time
+}
+
+// Records the version of each watchlist the last time the report was processed,
+// reports with outdated versions are requeued when the watchlists change.
+type AuthorReportWatchlist struct {
+	ReportId  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Watchlist string    `gorm:"size:40;primaryKey"`
+	Version   string    `gorm:"size:64"`
+}
+
+// A change to the watchlists that reports are processed with. The version is a
+// hash of the versions of all of the watchlists, it is not unique since the
+// watchlists can change back to a previous version, such as when an entity is
+// added and then removed. Each update follows the previous update, which is
+// unique so that a change is only recorded once when several workers see it.
+// The first update has no previous update and uses uuid.Nil.
+type WatchlistUpdate struct {
+	Id         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PreviousId uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	Version    string    `gorm:"size:64;not null;index"`
+	CreatedAt  time.Time
+
+	// The json encoded versions of each of the watchlists.
+	Watchlists []byte
+	// The json encoded names of the watchlists that changed since the previous
+	// update.
+	Changed []byte
+
+	ReportsRequeued int
+}
+
+// An entity in one of the watchlists that reports are flagged with. Entities
+// are not deleted when they are removed so that there is an audit trail of the
+// changes.
+type WatchlistEntity struct {
+	Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+	Watchlist string `gorm:"size:40;not null;index"`
+	// For the suspicious terms watchlist this is the term.
+	Name string `gorm:"not null"`
+	// Only used by the watchlists that are matched against OpenAlex ids.
+	OpenAlexId string
+	// The json encoded list of aliases of the entity.
+	Aliases []byte
+	// The list that the entity is from, e.g. the Entity List.
+	Source string
+	// The entity is not used until this date if it is set.
+	EffectiveDate sql.NullTime
+	Justification string
+
+	// CreatedBy is uuid.Nil for the entities that were imported from the built in
+	// watchlists.
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+	UpdatedBy uuid.UUID `gorm:"type:uuid"`
+	UpdatedAt time.Time
+
+	RemovedBy uuid.NullUUID `gorm:"type:uuid"`
+	RemovedAt sql.NullTime
+}
+
+// Suppresses flags that are known false positives for an author (e.g. because
+// the author's name collides with someone else's) in the reports for the author
+// that are viewed by members of the organization. A suppression matches either
+// the flag hash, or a flag type and an entity of the flag. Suppressions are not
+// deleted when they are removed so that there is an audit trail of the changes.
+type FlagSuppression struct {
+	Id uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+	OrganizationId uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	AuthorId string `gorm:"not null;index"`
+	// Optional, if set the suppression also applies to reports with a matching
+	// author name, such as reports created from unstructured text.
+	AuthorName string
+	FlagType   string `gorm:"size:40"`
+	Entity     string
+	FlagHash   string `gorm:"size:64"`
+	Reason     string
+
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+
+	RemovedBy uuid.NullUUID `gorm:"type:uuid"`
+	RemovedAt sql.NullTime
+}
+
+// The weights used to score the risk of reports. OrganizationId is uuid.Nil for
+// the default weights. Weights is the json encoded api.RiskWeights.
+type RiskWeights struct {
+	OrganizationId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Weights        []byte
+
+	UpdatedBy uuid.UUID `gorm:"type:uuid"`
+	UpdatedAt time.Time
+}
+
+const (
+	OrganizationRoleMember = "member"
+	OrganizationRoleAdmin  = "admin"
+
+	ReportAccessRead  = "read"
+	ReportAccessWrite = "write"
+)
+
+// Users can belong to at most one organization. Members of an organization can
+// see the author reports that other members share with the organization, and
+// the organization has its own risk weights.
+type Organization struct {
+	Id   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name string    `gorm:"not null;uniqueIndex"`
+
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+
+	Members []OrganizationMember `gorm:"foreignKey:OrganizationId;constraint:OnDelete:CASCADE"`
+	Invites []OrganizationInvite `gorm:"foreignKey:OrganizationId;constraint:OnDelete:CASCADE"`
+}
+
+type OrganizationMember struct {
+	UserId         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrganizationId uuid.UUID `gorm:"type:uuid;not null;index"`
+	// The email is stored so that the owners of shared reports can be shown.
+	Email    string
+	Role     string `gorm:"size:20;not null"`
+	JoinedAt time.Time
+}
+
+// Users are invited by email since that is the only thing that is known about
+// a user before they log in. The email is stored in lower case.
+type OrganizationInvite struct {
+	OrganizationId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Email          string    `gorm:"primaryKey"`
+	Role           string    `gorm:"size:20;not null"`
+	InvitedBy      uuid.UUID `gorm:"type:uuid;not null"`
+	InvitedAt      time.Time
 }
 
 type UserAuthorReport struct {
@@ -49,7 +252,60 @@ type UserAuthorReport struct {
 	ReportId uuid.UUID     `gorm:"type:uuid;not null"`
 	Report   *AuthorReport `gorm:"foreignKey:ReportId"`
 
+	// The access that the other members of the owner's organization have to the
+	// report, empty if the report is not shared.
+	SharedAccess string `gorm:"size:10;not null;default:''"`
+
 	Hooks []AuthorReportHook `gorm:"foreignKey:UserReportId;constraint:OnDelete:CASCADE"`
+
+	Triage []FlagTriage `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+
+	Disclosures     []DisclosureDocument `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+	FlagDisclosures []FlagDisclosure     `gorm:"foreignKey:ReportId;constraint:OnDelete:CASCADE"`
+}
+
+// Only the text extracted from the uploaded disclosure is stored, it is used to
+// check if new flags are disclosed when the report is updated.
+type DisclosureDocument struct {
+	Id         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ReportId   uuid.UUID `gorm:"type:uuid;not null;index"`
+	Filename   string
+	Text       string
+	UploadedBy uuid.UUID `gorm:"type:uuid;not null"`
+	UploadedAt time.Time
+
+	FlagDisclosures []FlagDisclosure `gorm:"foreignKey:DocumentId;constraint:OnDelete:CASCADE"`
+}
+
+// Records that a flag in a user report was disclosed by a disclosure document.
+type FlagDisclosure struct {
+	ReportId    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	FlagHash    string    `gorm:"type:char(64);primaryKey"`
+	DocumentId  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	DisclosedAt time.Time
+
+	Snippet    string
+	Page       int
+	Confidence float64
+}
+
+const (
+	TriageConfirmed     = "confirmed"
+	TriageDismissed     = "dismissed"
+	TriageNeedsFollowUp = "needs-follow-up"
+)
+
+// The triage is associated with the user report instead of the author report
+// so that it is not visible to other users with reports for the same author.
+// It is keyed on the flag hash, which is stable across report updates, so that
+// the triage is kept when the report is refreshed.
+type FlagTriage struct {
+	ReportId   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	FlagHash   string    `gorm:"type:char(64);primaryKey"`
+	Status     string    `gorm:"size:20;not null"`
+	Note       string
+	ReviewerId uuid.UUID `gorm:"type:uuid;not null"`
+	UpdatedAt  time.Time
 }
 
 type AuthorReportHook struct {
@@ -74,12 +330,62 @@ type UniversityReport struct {
 	UniversityName     string
 	UniversityLocation string
 
+	// Reports with different author discovery settings are kept distinct since
+	// they can have different authors.
+	LookbackYears               int  `gorm:"not null;default:4"`
+	MinWorks                    int  `gorm:"not null;default:1"`
+	RequireLastKnownInstitution bool `gorm:"not null;default:false"`
+
 	StatusUpdatedAt time.Time
 	Status          string `gorm:"size:20;not null"`
 
 	Authors []AuthorReport `gorm:"many2many:university_authors"`
 }
 
+// The join table for the authors of a university report, it is also used to
+// store the department of the author at the university.
+type UniversityAuthor struct {
+	UniversityReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	AuthorReportId     uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Department         string
+}
+
+// The summary of the flags of the authors of a university report, it is stored
+// so that the authors can be filtered, sorted, and paged in the database. The
+// risk scores depend on the risk weights and flag suppressions of the
+// organization, so there is a summary for each organization that views the
+// report, users that are not in an organization use uuid.Nil. The summary is
+// removed when the flags, authors, weights, or suppressions change so that it
+// is recomputed the next time the report is loaded.
+type UniversityReportSummary struct {
+	UniversityReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrganizationId     uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+
+	ComputedAt time.Time
+
+	// The json encoded []api.UniversityYearlyTrend.
+	YearlyTrends []byte
+}
+
+type UniversityAuthorSummary struct {
+	UniversityReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrganizationId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	AuthorReportId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+	FlagCount int
+	RiskScore float64
+	RiskTier  string `gorm:"size:20"`
+}
+
+type UniversityAuthorFlagCount struct {
+	UniversityReportId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrganizationId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	AuthorReportId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	FlagType           string    `gorm:"size:40;primaryKey"`
+
+	FlagCount int
+}
+
 type UserUniversityReport struct {
 	Id     uuid.UUID `gorm:"type:uuid;primaryKey"`
 	UserId uuid.UUID `gorm:"type:uuid;not null;index"`
@@ -89,3 +395,91 @@ type UserUniversityReport struct {
 	ReportId uuid.UUID         `gorm:"type:uuid;not null"`
 	Report   *UniversityReport `gorm:"foreignKey:ReportId"`
 }
+
+const (
+	AuditSuccess = "success"
+	AuditDenied  = "denied"
+	AuditFailure = "failure"
+)
+
+// Records an action taken by a user. Entries are never updated or deleted, this
+// is enforced by a trigger in the database.
+type AuditLogEntry struct {
+	Id        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Timestamp time.Time `gorm:"not null;index"`
+
+	UserId uuid.UUID `gorm:"type:uuid;not null;index"`
+	Email  string
+
+	Action string `gorm:"size:40;not null"`
+	Method string `gorm:"size:10;not null"`
+	Path   string `gorm:"not null"`
+	Query  string
+
+	// The user report (author or university) the action was on, if any. The
+	// author is stored so that the entries for an author can still be found
+	// after the report is deleted.
+	ReportId   uuid.NullUUID `gorm:"type:uuid;index"`
+	AuthorId   string        `gorm:"index"`
+	AuthorName string
+
+	StatusCode int
+	Outcome    string `gorm:"size:20;not null"`
+}
+
+const (
+	UsageAuthorReports     = "author-reports"
+	UsageUniversityReports = "university-reports"
+	UsageLLMCalls          = "llm-calls"
+)
+
+// Counts the usage of a metric by a user or organization for enforcing quotas.
+// There is a row for each period (i.e. day or month) the metric is used in.
+type UsageCounter struct {
+	// The id of the user or organization.
+	OwnerId     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Metric      string    `gorm:"size:40;primaryKey"`
+	PeriodStart time.Time `gorm:"primaryKey"`
+
+	Used int `gorm:"not null;default:0"`
+}
+
+const (
+	BatchRowCreated    = "created"
+	BatchRowAmbiguous  = "ambiguous"
+	BatchRowUnresolved = "unresolved"
+	BatchRowFailed     = "failed"
+)
+
+// A group of author reports created together from a roster file.
+type AuthorReportBatch struct {
+	Id     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserId uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	Name          string
+	LookbackYears int
+	CreatedAt     time.Time
+
+	Rows []AuthorReportBatchRow `gorm:"foreignKey:BatchId;constraint:OnDelete:CASCADE"`
+}
+
+// A row of the roster that a batch was created from. Rows that could not be
+// resolved to a single author have no report.
+type AuthorReportBatchRow struct {
+	BatchId uuid.UUID `gorm:"type:uuid;primaryKey"`
+	// The row number in the roster file.
+	Row int `gorm:"primaryKey;autoIncrement:false"`
+
+	Name        string
+	Institution string
+	Orcid       string
+	OpenAlexId  string
+
+	Status  string `gorm:"size:20;not null"`
+	Message string
+	// The json encoded candidate authors for ambiguous rows.
+	Candidates []byte
+
+	UserReportId uuid.NullUUID     `gorm:"type:uuid;index"`
+	UserReport   *UserAuthorReport `gorm:"foreignKey:UserReportId;constraint:OnDelete:SET NULL"`
+}
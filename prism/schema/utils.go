@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 
+	"prism/prism/schema/migrations/versions"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -61,10 +63,18 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	})
 
 	if err := db.AutoMigrate(&AuthorReport{}, &AuthorFlag{}, &UserAuthorReport{},
-		&AuthorReportHook{}, &UniversityReport{}, &UserUniversityReport{}); err != nil {
+		&AuthorReportHook{}, &UniversityReport{}, &UserUniversityReport{}, &AuthorReportIdentity{},
+		&FlagTriage{}, &FlagSuppression{}, &DisclosureDocument{}, &FlagDisclosure{}, &RiskWeights{}, &UniversityAuthor{},
+		&Organization{}, &OrganizationMember{}, &OrganizationInvite{}, &AuditLogEntry{}, &UsageCounter{},
+		&AuthorReportBatch{}, &AuthorReportBatchRow{}, &ReportQueueGroup{}, &AuthorReportFlagger{}, &AuthorReportWatchlist{}, &WatchlistUpdate{}, &WatchlistEntity{},
+		&UniversityReportSummary{}, &UniversityAuthorSummary{}, &UniversityAuthorFlagCount{}); err != nil {
 		t.Fatalf("error migrating tables: %v", err)
 	}
 
+	if err := versions.PreventAuditLogChanges(db); err != nil {
+		t.Fatalf("error adding audit log triggers: %v", err)
+	}
+
 	return db
 }
 
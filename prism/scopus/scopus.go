@@ -0,0 +1,299 @@
+package scopus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"prism/prism/api"
+	"prism/prism/monitoring"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const DefaultBaseUrl = "https://api.elsevier.com"
+
+var (
+	ErrScopusSearchFailed = errors.New("scopus search failed")
+	ErrAuthorNotFound     = errors.New("author not found")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+)
+
+type Client struct {
+	client *resty.Client
+}
+
+func NewClient(baseUrl, apiKey string) *Client {
+	return &Client{
+		client: resty.New().
+			SetBaseURL(baseUrl).
+			SetHeader("X-ELS-APIKey", apiKey).
+			SetHeader("Accept", "application/json").
+			AddRetryCondition(func(response *resty.Response, err error) bool {
+				if err != nil {
+					return true // The err can be non nil for some network errors.
+				}
+				// There's no reason to retry other 400 requests since the outcome should not change
+				return response != nil && (response.StatusCode() > 499 || response.StatusCode() == http.StatusTooManyRequests)
+			}).
+			SetRetryCount(2).
+			OnAfterResponse(func(client *resty.Client, response *resty.Response) error {
+				monitoring.ScopusCalls.WithLabelValues(strconv.Itoa(response.StatusCode())).Inc()
+				return nil
+			}),
+	}
+}
+
+// Scopus returns some fields as a single object if there is only one value, and
+// as a list if there are multiple values, this handles both cases.
+type oneOrMany[T any] []T
+
+func (o *oneOrMany[T]) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		var many []T
+		if err := json.Unmarshal(data, &many); err != nil {
+			return err
+		}
+		*o = many
+		return nil
+	}
+
+	var one T
+	if err := json.Unmarshal(data, &one); err != nil {
+		return err
+	}
+	*o = []T{one}
+	return nil
+}
+
+// Response Format: https://dev.elsevier.com/sc_author_search_views.html
+type scopusAuthor struct {
+	Identifier    string `json:"dc:identifier"`
+	PreferredName struct {
+		Surname   string `json:"surname"`
+		GivenName string `json:"given-name"`
+	} `json:"preferred-name"`
+	AffiliationCurrent oneOrMany[struct {
+		Name    string `json:"affiliation-name"`
+		Country string `json:"affiliation-country"`
+	}] `json:"affiliation-current"`
+	SubjectAreas oneOrMany[struct {
+		Name string `json:"$"`
+	}] `json:"subject-area"`
+}
+
+// Response Format: https://dev.elsevier.com/sc_search_views.html
+type scopusDocument struct {
+	Title     string `json:"dc:title"`
+	CoverDate string `json:"prism:coverDate"`
+	DOI       string `json:"prism:doi"`
+}
+
+type scopusResults[T any] struct {
+	SearchResults struct {
+		TotalResults string `json:"opensearch:totalResults"`
+		Entries      []T    `json:"entry"`
+	} `json:"search-results"`
+}
+
+func (r *scopusResults[T]) total() int {
+	total, err := strconv.Atoi(r.SearchResults.TotalResults)
+	if err != nil {
+		return 0
+	}
+	return total
+}
+
+const authorIdPrefix = "AUTHOR_ID:"
+
+func convertScopusAuthor(author scopusAuthor) api.Author {
+	institutions := make([]string, 0, len(author.AffiliationCurrent))
+	for _, affiliation := range author.AffiliationCurrent {
+		if affiliation.Name != "" {
+			institutions = append(institutions, affiliation.Name)
+		}
+	}
+
+	interests := make([]string, 0, len(author.SubjectAreas))
+	for _, area := range author.SubjectAreas {
+		interests = append(interests, area.Name)
+	}
+
+	return api.Author{
+		AuthorId:     strings.TrimPrefix(author.Identifier, authorIdPrefix),
+		AuthorName:   strings.TrimSpace(author.PreferredName.GivenName + " " + author.PreferredName.Surname),
+		Institutions: institutions,
+		Source:       api.ScopusSource,
+		Interests:    interests,
+	}
+}
+
+// Removes characters that have special meaning in the scopus query language.
+func sanitizeQueryTerm(term string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch r {
+		case '(', ')', '"', '{', '}', '\\':
+			return -1
+		}
+		return r
+	}, term))
+}
+
+func authorNameQuery(authorName, institutionName string) string {
+	parts := strings.Fields(sanitizeQueryTerm(authorName))
+	if len(parts) == 0 {
+		return ""
+	}
+
+	query := fmt.Sprintf("AUTHLASTNAME(%s)", parts[len(parts)-1])
+	if len(parts) > 1 {
+		query += fmt.Sprintf(" AND AUTHFIRST(%s)", strings.Join(parts[:len(parts)-1], " "))
+	}
+
+	if institution := sanitizeQueryTerm(institutionName); institution != "" {
+		query += fmt.Sprintf(" AND AFFIL(%s)", institution)
+	}
+
+	return query
+}
+
+func (c *Client) searchAuthors(query string, start, count int) ([]api.Author, int, error) {
+	res, err := c.client.R().
+		SetResult(&scopusResults[scopusAuthor]{}).
+		SetQueryParam("query", query).
+		SetQueryParam("start", strconv.Itoa(start)).
+		SetQueryParam("count", strconv.Itoa(count)).
+		Get("/content/search/author")
+
+	if err != nil {
+		slog.Error("scopus: author search failed", "query", query, "error", err)
+		return nil, 0, ErrScopusSearchFailed
+	}
+
+	if !res.IsSuccess() {
+		slog.Error("scopus: author search returned error", "status_code", res.StatusCode(), "body", res.String())
+		return nil, 0, ErrScopusSearchFailed
+	}
+
+	results := res.Result().(*scopusResults[scopusAuthor])
+
+	authors := make([]api.Author, 0, len(results.SearchResults.Entries))
+	for _, entry := range results.SearchResults.Entries {
+		// Scopus returns a single entry with an error field when there are no results.
+		if entry.Identifier != "" {
+			authors = append(authors, convertScopusAuthor(entry))
+		}
+	}
+
+	return authors, results.total(), nil
+}
+
+const authorSearchPageSize = 25
+
+// Returns the authors matching the name and (optional) institution. The cursor
+// can be passed to subsequent calls to get the next page of results, an empty
+// cursor is returned once all results have been returned.
+func (c *Client) SearchAuthors(authorName, institutionName, cursor string) ([]api.Author, string, error) {
+	start := 0
+	if cursor != "" {
+		var err error
+		start, err = strconv.Atoi(cursor)
+		if err != nil || start < 0 {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+
+	query := authorNameQuery(authorName, institutionName)
+	if query == "" {
+		return nil, "", nil
+	}
+
+	authors, total, err := c.searchAuthors(query, start, authorSearchPageSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if next := start + authorSearchPageSize; next < total {
+		nextCursor = strconv.Itoa(next)
+	}
+
+	return authors, nextCursor, nil
+}
+
+func (c *Client) GetAuthorDetails(authorId string) (api.Author, error) {
+	authors, _, err := c.searchAuthors(fmt.Sprintf("AU-ID(%s)", sanitizeQueryTerm(authorId)), 0, 1)
+	if err != nil {
+		return api.Author{}, err
+	}
+
+	if len(authors) < 1 {
+		return api.Author{}, ErrAuthorNotFound
+	}
+
+	return authors[0], nil
+}
+
+type AuthorPaperIterator struct {
+	client   *Client
+	authorId string
+	query    string
+	start    int
+	stopped  bool
+}
+
+func (c *Client) NewAuthorPaperIterator(authorId string, startDate, endDate time.Time) *AuthorPaperIterator {
+	// PUBYEAR only supports strict comparisons.
+	query := fmt.Sprintf("AU-ID(%s) AND PUBYEAR > %d AND PUBYEAR < %d", sanitizeQueryTerm(authorId), startDate.Year()-1, endDate.Year()+1)
+	return &AuthorPaperIterator{client: c, authorId: authorId, query: query, start: 0, stopped: false}
+}
+
+func (iter *AuthorPaperIterator) Next() ([]string, error) {
+	if iter.stopped {
+		return nil, nil
+	}
+
+	const batchSize = 25
+
+	res, err := iter.client.client.R().
+		SetResult(&scopusResults[scopusDocument]{}).
+		SetQueryParam("query", iter.query).
+		SetQueryParam("start", strconv.Itoa(iter.start)).
+		SetQueryParam("count", strconv.Itoa(batchSize)).
+		SetQueryParam("sort", "-coverDate").
+		Get("/content/search/scopus")
+
+	if err != nil {
+		slog.Error("scopus: paper search failed", "author_id", iter.authorId, "error", err)
+		return nil, fmt.Errorf("scopus error: %w", err)
+	}
+
+	if !res.IsSuccess() {
+		slog.Error("scopus: paper search returned error", "status_code", res.StatusCode(), "body", res.String())
+		return nil, ErrScopusSearchFailed
+	}
+
+	results := res.Result().(*scopusResults[scopusDocument])
+
+	titles := make([]string, 0, len(results.SearchResults.Entries))
+	for _, paper := range results.SearchResults.Entries {
+		if paper.Title != "" {
+			titles = append(titles, paper.Title)
+		}
+	}
+
+	iter.start += batchSize
+	if len(results.SearchResults.Entries) < batchSize || iter.start >= results.total() {
+		iter.stopped = true
+	}
+
+	if len(titles) == 0 && iter.stopped {
+		return nil, nil
+	}
+
+	return titles, nil
+}
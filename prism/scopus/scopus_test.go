@@ -0,0 +1,149 @@
+package scopus_test
+
+import (
+	"fmt"
+	"prism/prism/api"
+	"prism/prism/scopus"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testApiKey = "test-api-key"
+
+func fakeAuthors() []scopus.FakeAuthor {
+	authors := []scopus.FakeAuthor{
+		{
+			AuthorId:     "7004212771",
+			GivenName:    "Anshumali",
+			Surname:      "Shrivastava",
+			Affiliations: []string{"Rice University"},
+			SubjectAreas: []string{"Computer Science"},
+			Titles:       []string{"paper a", "paper b", "paper c"},
+		},
+	}
+
+	for i := range 30 {
+		authors = append(authors, scopus.FakeAuthor{
+			AuthorId:     fmt.Sprintf("%d", 1000+i),
+			GivenName:    "Bill",
+			Surname:      "Zhang",
+			Affiliations: []string{fmt.Sprintf("University %d", i)},
+		})
+	}
+
+	titles := make([]string, 0, 60)
+	for i := range 60 {
+		titles = append(titles, fmt.Sprintf("title %d", i))
+	}
+	authors[1].Titles = titles
+
+	return authors
+}
+
+func TestAuthorSearch(t *testing.T) {
+	server := scopus.StartFakeServer(t, testApiKey, fakeAuthors())
+	client := scopus.NewClient(server.URL, testApiKey)
+
+	authors, cursor, err := client.SearchAuthors("anshumali shrivastava", "rice university", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(authors) != 1 || authors[0].AuthorId != "7004212771" ||
+		authors[0].AuthorName != "Anshumali Shrivastava" ||
+		!slices.Equal(authors[0].Institutions, []string{"Rice University"}) ||
+		!slices.Equal(authors[0].Interests, []string{"Computer Science"}) ||
+		authors[0].Source != api.ScopusSource {
+		t.Fatal("incorrect authors returned")
+	}
+
+	if cursor != "" {
+		t.Fatal("cursor should be empty after last page")
+	}
+}
+
+func TestAuthorSearchWithCursor(t *testing.T) {
+	server := scopus.StartFakeServer(t, testApiKey, fakeAuthors())
+	client := scopus.NewClient(server.URL, testApiKey)
+
+	authors1, cursor, err := client.SearchAuthors("bill zhang", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(authors1) != 25 || cursor == "" {
+		t.Fatal("expected full first page and cursor")
+	}
+
+	authors2, cursor, err := client.SearchAuthors("bill zhang", "", cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(authors2) != 5 || cursor != "" {
+		t.Fatal("expected partial second page and no cursor")
+	}
+
+	for _, author := range authors2 {
+		if slices.ContainsFunc(authors1, func(a api.Author) bool { return a.AuthorId == author.AuthorId }) {
+			t.Fatal("pages should not overlap")
+		}
+	}
+
+	if _, _, err := client.SearchAuthors("bill zhang", "", "abc"); err != scopus.ErrInvalidCursor {
+		t.Fatal("expected invalid cursor error")
+	}
+}
+
+func TestGetAuthorDetails(t *testing.T) {
+	server := scopus.StartFakeServer(t, testApiKey, fakeAuthors())
+	client := scopus.NewClient(server.URL, testApiKey)
+
+	author, err := client.GetAuthorDetails("7004212771")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if author.AuthorName != "Anshumali Shrivastava" || !slices.Equal(author.Institutions, []string{"Rice University"}) {
+		t.Fatal("incorrect author details")
+	}
+
+	if _, err := client.GetAuthorDetails("123"); err != scopus.ErrAuthorNotFound {
+		t.Fatal("expected author not found error")
+	}
+}
+
+func TestInvalidApiKey(t *testing.T) {
+	server := scopus.StartFakeServer(t, testApiKey, fakeAuthors())
+	client := scopus.NewClient(server.URL, "wrong-key")
+
+	if _, _, err := client.SearchAuthors("anshumali shrivastava", "", ""); err != scopus.ErrScopusSearchFailed {
+		t.Fatal("expected search failure")
+	}
+}
+
+func TestAuthorPaperIterator(t *testing.T) {
+	server := scopus.StartFakeServer(t, testApiKey, fakeAuthors())
+	client := scopus.NewClient(server.URL, testApiKey)
+
+	iter := client.NewAuthorPaperIterator("1000", time.Now().AddDate(-4, 0, 0), time.Now())
+
+	titles := make([]string, 0)
+	batches := 0
+	for {
+		batch, err := iter.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if batch == nil {
+			break
+		}
+		batches++
+		titles = append(titles, batch...)
+	}
+
+	if batches != 3 || len(titles) != 60 || titles[0] != "title 0" || titles[59] != "title 59" {
+		t.Fatalf("incorrect titles returned: %d batches, %d titles", batches, len(titles))
+	}
+}
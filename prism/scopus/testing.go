@@ -0,0 +1,121 @@
+package scopus
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type FakeAuthor struct {
+	AuthorId     string
+	GivenName    string
+	Surname      string
+	Affiliations []string
+	SubjectAreas []string
+	Titles       []string
+}
+
+var (
+	fakeAuthorIdQuery = regexp.MustCompile(`AU-ID\(([^)]+)\)`)
+	fakeSurnameQuery  = regexp.MustCompile(`AUTHLASTNAME\(([^)]+)\)`)
+)
+
+// Starts an httptest server that mimics the subset of the scopus api used by the
+// client so that tests do not depend on a scopus api key. It only supports the
+// AU-ID and AUTHLASTNAME query terms.
+func StartFakeServer(t *testing.T, apiKey string, authors []FakeAuthor) *httptest.Server {
+	findAuthors := func(query string) []FakeAuthor {
+		matches := make([]FakeAuthor, 0)
+		if m := fakeAuthorIdQuery.FindStringSubmatch(query); m != nil {
+			for _, author := range authors {
+				if author.AuthorId == m[1] {
+					matches = append(matches, author)
+				}
+			}
+		} else if m := fakeSurnameQuery.FindStringSubmatch(query); m != nil {
+			for _, author := range authors {
+				if strings.EqualFold(author.Surname, m[1]) {
+					matches = append(matches, author)
+				}
+			}
+		}
+		return matches
+	}
+
+	page := func(r *http.Request, total int) (int, int) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+		return min(start, total), min(start+count, total)
+	}
+
+	writeResults := func(w http.ResponseWriter, total int, entries []map[string]any) {
+		if len(entries) == 0 {
+			entries = []map[string]any{{"error": "Result set was empty"}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"search-results": map[string]any{
+				"opensearch:totalResults": strconv.Itoa(total),
+				"entry":                   entries,
+			},
+		})
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/content/search/author", func(w http.ResponseWriter, r *http.Request) {
+		matches := findAuthors(r.URL.Query().Get("query"))
+		start, end := page(r, len(matches))
+
+		entries := make([]map[string]any, 0)
+		for _, author := range matches[start:end] {
+			affiliations := make([]map[string]any, 0, len(author.Affiliations))
+			for _, affiliation := range author.Affiliations {
+				affiliations = append(affiliations, map[string]any{"affiliation-name": affiliation})
+			}
+			areas := make([]map[string]any, 0, len(author.SubjectAreas))
+			for _, area := range author.SubjectAreas {
+				areas = append(areas, map[string]any{"$": area})
+			}
+			entries = append(entries, map[string]any{
+				"dc:identifier":       authorIdPrefix + author.AuthorId,
+				"preferred-name":      map[string]any{"surname": author.Surname, "given-name": author.GivenName},
+				"affiliation-current": affiliations,
+				"subject-area":        areas,
+			})
+		}
+
+		writeResults(w, len(matches), entries)
+	})
+
+	mux.HandleFunc("/content/search/scopus", func(w http.ResponseWriter, r *http.Request) {
+		titles := make([]string, 0)
+		for _, author := range findAuthors(r.URL.Query().Get("query")) {
+			titles = append(titles, author.Titles...)
+		}
+		start, end := page(r, len(titles))
+
+		entries := make([]map[string]any, 0)
+		for _, title := range titles[start:end] {
+			entries = append(entries, map[string]any{"dc:title": title})
+		}
+
+		writeResults(w, len(titles), entries)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-ELS-APIKey") != apiKey {
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
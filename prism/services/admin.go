@@ -0,0 +1,189 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"prism/prism/reports"
+	"prism/prism/schema"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Endpoints for operating the service, all of the routes require the admin role.
+type AdminService struct {
+	manager *reports.ReportManager
+	audit   *AuditLog
+}
+
+func NewAdminService(manager *reports.ReportManager, audit *AuditLog) AdminService {
+	return AdminService{manager: manager, audit: audit}
+}
+
+func (s *AdminService) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/queue", WrapRestHandler(s.GetReportQueue))
+	r.Get("/audit", WrapRestHandler(s.QueryAuditLog))
+	r.Get("/audit/export", s.ExportAuditLog)
+	r.Get("/watchlist-updates", WrapRestHandler(s.ListWatchlistUpdates))
+	r.Get("/watchlist-updates/{update_id}", WrapRestHandler(s.GetWatchlistUpdate))
+
+	r.Get("/watchlists", WrapRestHandler(s.ListWatchlists))
+	r.Get("/watchlists/{watchlist}/entities", WrapRestHandler(s.ListWatchlistEntities))
+	r.With(Audited(AuditWatchlistEntityCreate)).Post("/watchlists/{watchlist}/entities", WrapRestHandler(s.CreateWatchlistEntity))
+	r.With(Audited(AuditWatchlistEntityUpdate)).Put("/watchlists/{watchlist}/entities/{entity_id}", WrapRestHandler(s.UpdateWatchlistEntity))
+	r.With(Audited(AuditWatchlistEntityRemove)).Delete("/watchlists/{watchlist}/entities/{entity_id}", WrapRestHandler(s.RemoveWatchlistEntity))
+	r.With(Audited(AuditWatchlistImport)).Post("/watchlists/{watchlist}/import", WrapRestHandler(s.ImportWatchlistEntities))
+
+	return r
+}
+
+func (s *AdminService) GetReportQueue(r *http.Request) (any, error) {
+	stats, err := s.manager.GetReportQueueStats()
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return stats, nil
+}
+
+func (s *AdminService) ListWatchlistUpdates(r *http.Request) (any, error) {
+	updates, err := s.manager.ListWatchlistUpdates()
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return updates, nil
+}
+
+func (s *AdminService) GetWatchlistUpdate(r *http.Request) (any, error) {
+	id, err := URLParamUUID(r, "update_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	update, err := s.manager.GetWatchlistUpdate(id)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return update, nil
+}
+
+// Parses a timestamp in RFC3339 format or a date. If end is true a date
+// refers to the end of the day so that the date range is inclusive.
+func parseAuditTime(name, raw string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s '%s', expected a date or RFC3339 timestamp", name, raw)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func parseAuditLogQuery(r *http.Request) (AuditLogQuery, error) {
+	params := r.URL.Query()
+
+	query := AuditLogQuery{
+		Email:    params.Get("email"),
+		AuthorId: params.Get("author_id"),
+		Action:   params.Get("action"),
+		Outcome:  params.Get("outcome"),
+		Page:     1,
+		PageSize: DefaultAuditLogPageSize,
+	}
+
+	for name, value := range map[string]*uuid.UUID{"user_id": &query.UserId, "report_id": &query.ReportId} {
+		if raw := params.Get(name); raw != "" {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return AuditLogQuery{}, fmt.Errorf("invalid %s '%s'", name, raw)
+			}
+			*value = id
+		}
+	}
+
+	switch query.Outcome {
+	case "", schema.AuditSuccess, schema.AuditDenied, schema.AuditFailure:
+		// ok
+	default:
+		return AuditLogQuery{}, fmt.Errorf("invalid outcome '%s'", query.Outcome)
+	}
+
+	if raw := params.Get("start"); raw != "" {
+		start, err := parseAuditTime("start", raw, false)
+		if err != nil {
+			return AuditLogQuery{}, err
+		}
+		query.Start = start
+	}
+	if raw := params.Get("end"); raw != "" {
+		end, err := parseAuditTime("end", raw, true)
+		if err != nil {
+			return AuditLogQuery{}, err
+		}
+		query.End = end
+	}
+	if !query.Start.IsZero() && !query.End.IsZero() && !query.Start.Before(query.End) {
+		return AuditLogQuery{}, fmt.Errorf("start must be before end")
+	}
+
+	for name, value := range map[string]*int{"page": &query.Page, "page_size": &query.PageSize} {
+		if raw := params.Get(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				return AuditLogQuery{}, fmt.Errorf("invalid %s '%s'", name, raw)
+			}
+			*value = n
+		}
+	}
+	if query.PageSize > MaxAuditLogPageSize {
+		return AuditLogQuery{}, fmt.Errorf("page_size cannot exceed %d", MaxAuditLogPageSize)
+	}
+
+	return query, nil
+}
+
+func (s *AdminService) QueryAuditLog(r *http.Request) (any, error) {
+	query, err := parseAuditLogQuery(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	page, err := s.audit.Query(query)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return page, nil
+}
+
+func (s *AdminService) ExportAuditLog(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAuditLogQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filename := fmt.Sprintf("Audit Log %s.csv", time.Now().UTC().Format(time.DateOnly))
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Cache-Control", "no-store")
+
+	// The status cannot be changed once the export starts streaming, so errors
+	// are only logged.
+	if err := s.audit.ExportCSV(query, csv.NewWriter(w)); err != nil {
+		slog.Error("error exporting audit log", "error", err)
+	}
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"prism/prism/api"
+	"prism/prism/schema"
+	"prism/prism/services/auth"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	AuditAuthorReportCreate   = "author-report.create"
+	AuditAuthorReportView     = "author-report.view"
+	AuditAuthorReportDownload = "author-report.download"
+	AuditAuthorReportDelete   = "author-report.delete"
+	AuditDisclosureCheck      = "disclosure.check"
+
+	AuditUniversityReportCreate   = "university-report.create"
+	AuditUniversityReportView     = "university-report.view"
+	AuditUniversityReportDownload = "university-report.download"
+	AuditUniversityReportDelete   = "university-report.delete"
+
+	AuditReportBatchCreate   = "report-batch.create"
+	AuditReportBatchDownload = "report-batch.download"
+
+	AuditHookCreate = "hook.create"
+	AuditHookDelete = "hook.delete"
+
+	AuditWatchlistEntityCreate = "watchlist-entity.create"
+	AuditWatchlistEntityUpdate = "watchlist-entity.update"
+	AuditWatchlistEntityRemove = "watchlist-entity.remove"
+	AuditWatchlistImport       = "watchlist.import"
+
+	AuditSearch = "search"
+)
+
+const (
+	DefaultAuditLogPageSize = 100
+	MaxAuditLogPageSize     = 1000
+)
+
+var ErrAuditLogAccessFailed = errors.New("audit log access failed")
+
+type auditContextKey string
+
+const (
+	auditLogContextKey   auditContextKey = "audit_log"
+	auditEntryContextKey auditContextKey = "audit_entry"
+)
+
+// Records the actions of users for compliance, the routes that are recorded
+// are marked with the Audited middleware.
+type AuditLog struct {
+	db *gorm.DB
+}
+
+func NewAuditLog(db *gorm.DB) *AuditLog {
+	return &AuditLog{db: db}
+}
+
+// Makes the audit log available to the Audited middleware of the routes.
+func (a *AuditLog) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auditLogContextKey, a)))
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func auditOutcome(status int) string {
+	switch {
+	case status < 400:
+		return schema.AuditSuccess
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return schema.AuditDenied
+	default:
+		return schema.AuditFailure
+	}
+}
+
+// Records the request in the audit log with the given action. This must be
+// used after auth.Middleware. If the route has a {report_id} parameter the
+// report and its author are recorded, handlers that create reports record the
+// new report with setAuditReportId.
+func Audited(action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			audit, ok := r.Context().Value(auditLogContextKey).(*AuditLog)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userId, err := auth.GetUserId(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			email, _ := auth.GetUserEmail(r)
+
+			entry := &schema.AuditLogEntry{
+				Id:        uuid.New(),
+				Timestamp: time.Now().UTC(),
+				UserId:    userId,
+				Email:     email,
+				Action:    action,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Query:     r.URL.RawQuery,
+			}
+
+			// The author is found before the request is handled so that it is
+			// still recorded if the request deletes the report.
+			if reportId, err := URLParamUUID(r, "report_id"); err == nil {
+				entry.ReportId = uuid.NullUUID{UUID: reportId, Valid: true}
+				audit.findAuthor(entry)
+			}
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), auditEntryContextKey, entry)))
+
+			if entry.ReportId.Valid && entry.AuthorId == "" {
+				audit.findAuthor(entry)
+			}
+
+			entry.StatusCode = recorder.status
+			entry.Outcome = auditOutcome(recorder.status)
+
+			if err := audit.db.Create(entry).Error; err != nil {
+				slog.Error("error recording audit log entry", "action", action, "user_id", userId, "error", err)
+			}
+		})
+	}
+}
+
+func setAuditReportId(r *http.Request, reportId uuid.UUID) {
+	if entry, ok := r.Context().Value(auditEntryContextKey).(*schema.AuditLogEntry); ok {
+		entry.ReportId = uuid.NullUUID{UUID: reportId, Valid: true}
+	}
+}
+
+// Sets the author of the entry if the report is an author report.
+func (a *AuditLog) findAuthor(entry *schema.AuditLogEntry) {
+	var author struct {
+		AuthorId   string
+		AuthorName string
+	}
+	result := a.db.Model(&schema.UserAuthorReport{}).
+		Select("author_reports.author_id, author_reports.author_name").
+		Joins("JOIN author_reports ON author_reports.id = user_author_reports.report_id").
+		Where("user_author_reports.id = ?", entry.ReportId.UUID).
+		Limit(1).Scan(&author)
+	if result.Error != nil {
+		slog.Error("error finding author for audit log entry", "report_id", entry.ReportId.UUID, "error", result.Error)
+		return
+	}
+	entry.AuthorId = author.AuthorId
+	entry.AuthorName = author.AuthorName
+}
+
+type AuditLogQuery struct {
+	UserId   uuid.UUID
+	Email    string
+	AuthorId string
+	ReportId uuid.UUID
+	Action   string
+	Outcome  string
+
+	// Only entries in [Start, End) are returned, zero values are not used.
+	Start time.Time
+	End   time.Time
+
+	Page     int
+	PageSize int
+}
+
+func (q AuditLogQuery) apply(txn *gorm.DB) *gorm.DB {
+	if q.UserId != uuid.Nil {
+		txn = txn.Where("user_id = ?", q.UserId)
+	}
+	if q.Email != "" {
+		txn = txn.Where("LOWER(email) = LOWER(?)", q.Email)
+	}
+	if q.AuthorId != "" {
+		txn = txn.Where("author_id = ?", q.AuthorId)
+	}
+	if q.ReportId != uuid.Nil {
+		txn = txn.Where("report_id = ?", q.ReportId)
+	}
+	if q.Action != "" {
+		txn = txn.Where("action = ?", q.Action)
+	}
+	if q.Outcome != "" {
+		txn = txn.Where("outcome = ?", q.Outcome)
+	}
+	if !q.Start.IsZero() {
+		txn = txn.Where("timestamp >= ?", q.Start)
+	}
+	if !q.End.IsZero() {
+		txn = txn.Where("timestamp < ?", q.End)
+	}
+	return txn
+}
+
+func convertAuditLogEntry(entry schema.AuditLogEntry) api.AuditLogEntry {
+	output := api.AuditLogEntry{
+		Id:         entry.Id,
+		Timestamp:  entry.Timestamp,
+		UserId:     entry.UserId,
+		Email:      entry.Email,
+		Action:     entry.Action,
+		Method:     entry.Method,
+		Path:       entry.Path,
+		Query:      entry.Query,
+		AuthorId:   entry.AuthorId,
+		AuthorName: entry.AuthorName,
+		StatusCode: entry.StatusCode,
+		Outcome:    entry.Outcome,
+	}
+	if entry.ReportId.Valid {
+		output.ReportId = &entry.ReportId.UUID
+	}
+	return output
+}
+
+// Returns the page of entries matching the query, newest first.
+func (a *AuditLog) Query(query AuditLogQuery) (api.AuditLogPage, error) {
+	var total int64
+	if err := query.apply(a.db.Model(&schema.AuditLogEntry{})).Count(&total).Error; err != nil {
+		slog.Error("error counting audit log entries", "error", err)
+		return api.AuditLogPage{}, ErrAuditLogAccessFailed
+	}
+
+	var entries []schema.AuditLogEntry
+	if err := query.apply(a.db).Order("timestamp DESC").
+		Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize).
+		Find(&entries).Error; err != nil {
+		slog.Error("error querying audit log entries", "error", err)
+		return api.AuditLogPage{}, ErrAuditLogAccessFailed
+	}
+
+	output := api.AuditLogPage{
+		Total:    int(total),
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Entries:  make([]api.AuditLogEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		output.Entries = append(output.Entries, convertAuditLogEntry(entry))
+	}
+
+	return output, nil
+}
+
+var auditLogCSVHeaders = []string{"Timestamp", "User Id", "Email", "Action", "Method", "Path", "Query", "Report Id", "Author Id", "Author Name", "Status Code", "Outcome"}
+
+// Writes all of the entries matching the query as csv, oldest first. The page
+// of the query is ignored. The entries are streamed from the database since the
+// export can be large.
+func (a *AuditLog) ExportCSV(query AuditLogQuery, w *csv.Writer) error {
+	rows, err := query.apply(a.db.Model(&schema.AuditLogEntry{})).Order("timestamp ASC").Rows()
+	if err != nil {
+		slog.Error("error exporting audit log entries", "error", err)
+		return ErrAuditLogAccessFailed
+	}
+	defer rows.Close()
+
+	if err := w.Write(auditLogCSVHeaders); err != nil {
+		return fmt.Errorf("error writing audit log csv: %w", err)
+	}
+
+	for rows.Next() {
+		var entry schema.AuditLogEntry
+		if err := a.db.ScanRows(rows, &entry); err != nil {
+			slog.Error("error reading audit log entry", "error", err)
+			return ErrAuditLogAccessFailed
+		}
+
+		reportId := ""
+		if entry.ReportId.Valid {
+			reportId = entry.ReportId.UUID.String()
+		}
+
+		if err := w.Write([]string{
+			entry.Timestamp.Format(time.RFC3339), entry.UserId.String(), entry.Email, entry.Action, entry.Method, entry.Path, entry.Query,
+			reportId, entry.AuthorId, entry.AuthorName, strconv.Itoa(entry.StatusCode), entry.Outcome,
+		}); err != nil {
+			return fmt.Errorf("error writing audit log csv: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("error reading audit log entries", "error", err)
+		return ErrAuditLogAccessFailed
+	}
+
+	w.Flush()
+	return w.Error()
+}
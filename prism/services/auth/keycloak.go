@@ -87,6 +87,12 @@ func NewKeycloakAuth(realm string, args KeycloakArgs) (*KeycloakAuth, error) {
 	}
 	auth.logger.Info("client creation successful")
 
+	if err := auth.createRoles(adminToken); err != nil {
+		auth.logger.Error("role creation failed", "error", err)
+		return nil, err
+	}
+	auth.logger.Info("role creation successful")
+
 	return auth, nil
 }
 
@@ -176,6 +182,57 @@ func (auth *KeycloakAuth) createRealm(adminToken string) error {
 	return nil
 }
 
+// Creates the realm roles for the users. The analyst role is added to the
+// default roles of the realm so that existing and new users are analysts unless
+// an admin changes their role. The login client does not have full scope, so the
+// roles must be added to its scope to be included in the access tokens.
+func (auth *KeycloakAuth) createRoles(adminToken string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var analyst gocloak.Role
+	roles := make([]gocloak.Role, 0, len(Roles))
+	for _, role := range Roles {
+		_, err := auth.keycloak.CreateRealmRole(ctx, adminToken, auth.realm, gocloak.Role{
+			Name:        gocloak.StringP(role),
+			Description: gocloak.StringP("Prism " + role),
+		})
+		if err != nil && !isConflict(err) {
+			return fmt.Errorf("error creating realm role '%s': %w", role, err)
+		}
+
+		created, err := auth.keycloak.GetRealmRole(ctx, adminToken, auth.realm, role)
+		if err != nil {
+			return fmt.Errorf("error getting realm role '%s': %w", role, err)
+		}
+		roles = append(roles, *created)
+		if role == RoleAnalyst {
+			analyst = *created
+		}
+	}
+
+	if err := auth.keycloak.AddRealmRoleComposite(ctx, adminToken, auth.realm, "default-roles-"+auth.realm, []gocloak.Role{analyst}); err != nil {
+		return fmt.Errorf("error adding '%s' to the default roles: %w", RoleAnalyst, err)
+	}
+
+	clientName := auth.realm + "-login-client"
+	clients, err := auth.keycloak.GetClients(ctx, adminToken, auth.realm, gocloak.GetClientsParams{
+		ClientID: &clientName,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing clients for realm: %w", err)
+	}
+	if len(clients) != 1 || clients[0].ID == nil {
+		return fmt.Errorf("unable to find client '%s' for realm", clientName)
+	}
+
+	if err := auth.keycloak.CreateClientScopeMappingsRealmRoles(ctx, adminToken, auth.realm, *clients[0].ID, roles); err != nil {
+		return fmt.Errorf("error adding roles to client scope: %w", err)
+	}
+
+	return nil
+}
+
 func (auth *KeycloakAuth) createClient(adminToken string, redirectUrls []string, rootUrl string) error {
 	clientName := auth.realm + "-login-client"
 
@@ -238,28 +295,56 @@ func getToken(r *http.Request) (string, error) {
 	return "", fmt.Errorf("missing or invalid authorization header")
 }
 
-func (auth *KeycloakAuth) VerifyToken(token string) (uuid.UUID, string, error) {
+// Returns the realm roles from the claims of the access token.
+func realmRoles(claims map[string]any) []string {
+	access, ok := claims["realm_access"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	values, ok := access["roles"].([]any)
+	if !ok {
+		return nil
+	}
+
+	roles := make([]string, 0, len(values))
+	for _, value := range values {
+		if role, ok := value.(string); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func (auth *KeycloakAuth) VerifyToken(token string) (User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	userInfo, err := auth.keycloak.GetUserInfo(ctx, token, auth.realm)
 	if err != nil {
 		auth.logger.Error("unable to verify token with keycloak", "error", err)
-		return uuid.Nil, "", fmt.Errorf("unable to verify access token: %w", err)
+		return User{}, fmt.Errorf("unable to verify access token: %w", err)
 	}
 
 	if userInfo.Sub == nil {
 		auth.logger.Error("missing user identifier in keycloak response")
-		return uuid.Nil, "", fmt.Errorf("missing user identifier in keycloak response")
+		return User{}, fmt.Errorf("missing user identifier in keycloak response")
 	}
 
 	userId, err := uuid.Parse(*userInfo.Sub)
 	if err != nil {
 		auth.logger.Error("unable to parse user id from keycloak", "id", *userInfo.Sub, "error", err)
-		return uuid.Nil, "", fmt.Errorf("invalid uuid '%v' returned from keycloak: %v", *userInfo.Sub, err)
+		return User{}, fmt.Errorf("invalid uuid '%v' returned from keycloak: %v", *userInfo.Sub, err)
+	}
+
+	// The roles are not returned in the user info, they are in the claims of the
+	// access token, which is already verified by the user info request.
+	_, claims, err := auth.keycloak.DecodeAccessToken(ctx, token, auth.realm)
+	if err != nil {
+		auth.logger.Error("unable to decode access token", "error", err)
+		return User{}, fmt.Errorf("unable to decode access token: %w", err)
 	}
 
-	return userId, *userInfo.Email, nil
+	return User{Id: userId, Email: *userInfo.Email, Role: HighestRole(realmRoles(*claims))}, nil
 }
 
 // This is just for the purpose of integration tests. It is used to create users
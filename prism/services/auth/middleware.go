@@ -13,10 +13,42 @@ type contextKey string
 const (
 	userIdContextKey contextKey = "user_id"
 	emailContextKey  contextKey = "email_id"
+	roleContextKey   contextKey = "role"
 )
 
+// The roles are ordered, each role can do everything the roles before it can.
+// Viewers can only view reports, analysts can also create and work on reports,
+// and admins can also change settings that apply to all users.
+const (
+	RoleViewer  = "viewer"
+	RoleAnalyst = "analyst"
+	RoleAdmin   = "admin"
+)
+
+var roleRanks = map[string]int{RoleViewer: 0, RoleAnalyst: 1, RoleAdmin: 2}
+
+var Roles = []string{RoleViewer, RoleAnalyst, RoleAdmin}
+
+// Returns the highest of the roles, roles that are not one of the above roles
+// are ignored. Users without any of the roles are viewers.
+func HighestRole(roles []string) string {
+	highest := RoleViewer
+	for _, role := range roles {
+		if rank, ok := roleRanks[role]; ok && rank > roleRanks[highest] {
+			highest = role
+		}
+	}
+	return highest
+}
+
+type User struct {
+	Id    uuid.UUID
+	Email string
+	Role  string
+}
+
 type TokenVerifier interface {
-	VerifyToken(token string) (uuid.UUID, string, error)
+	VerifyToken(token string) (User, error)
 }
 
 func Middleware(verifier TokenVerifier) func(http.Handler) http.Handler {
@@ -28,15 +60,17 @@ func Middleware(verifier TokenVerifier) func(http.Handler) http.Handler {
 				return
 			}
 
-			userId, email, err := verifier.VerifyToken(token)
+			user, err := verifier.VerifyToken(token)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusUnauthorized)
 				return
 			}
 
 			reqCtx := r.Context()
-			reqCtx = context.WithValue(reqCtx, userIdContextKey, userId)
-			reqCtx = context.WithValue(reqCtx, emailContextKey, email)
+			reqCtx = context.WithValue(reqCtx, userIdContextKey, user.Id)
+			reqCtx = context.WithValue(reqCtx, emailContextKey, user.Email)
+			// Unknown roles are treated as the lowest role.
+			reqCtx = context.WithValue(reqCtx, roleContextKey, HighestRole([]string{user.Role}))
 			next.ServeHTTP(w, r.WithContext(reqCtx))
 		}
 
@@ -44,6 +78,29 @@ func Middleware(verifier TokenVerifier) func(http.Handler) http.Handler {
 	}
 }
 
+// Rejects requests from users whose role is lower than the given role. This
+// must be used after Middleware.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			userRole, err := GetUserRole(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if roleRanks[userRole] < roleRanks[role] {
+				http.Error(w, fmt.Sprintf("the %s role is required", role), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(handler)
+	}
+}
+
 func GetUserId(r *http.Request) (uuid.UUID, error) {
 	userUntyped := r.Context().Value(userIdContextKey)
 	if userUntyped == nil {
@@ -67,3 +124,15 @@ func GetUserEmail(r *http.Request) (string, error) {
 	}
 	return email, nil
 }
+
+func GetUserRole(r *http.Request) (string, error) {
+	roleUntyped := r.Context().Value(roleContextKey)
+	if roleUntyped == nil {
+		return "", fmt.Errorf("role field not found in request context")
+	}
+	role, ok := roleUntyped.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid value for role field")
+	}
+	return role, nil
+}
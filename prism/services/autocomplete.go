@@ -9,18 +9,22 @@ import (
 )
 
 type AutocompleteService struct {
-	openalex openalex.KnowledgeBase
+	openalex    openalex.KnowledgeBase
+	rateLimiter *RateLimiter
 }
 
-func NewAutoCompleteService(oa openalex.KnowledgeBase) AutocompleteService {
+func NewAutoCompleteService(oa openalex.KnowledgeBase, rateLimiter *RateLimiter) AutocompleteService {
 	return AutocompleteService{
-		openalex: oa,
+		openalex:    oa,
+		rateLimiter: rateLimiter,
 	}
 }
 
 func (s *AutocompleteService) Routes() chi.Router {
 	r := chi.NewRouter()
 
+	r.Use(s.rateLimiter.Middleware)
+
 	r.Get("/author", WrapRestHandler(s.AutocompleteAuthor))
 	r.Get("/institution", WrapRestHandler(s.AutocompleteInstitution))
 	r.Get("/paper", WrapRestHandler(s.AutocompletePaper))
@@ -14,16 +14,24 @@ type BackendService struct {
 	search       SearchService
 	autocomplete AutocompleteService
 	hooks        HookService
+	organization OrganizationService
+	admin        AdminService
+	usage        UsageService
 
+	audit    *AuditLog
 	userAuth auth.TokenVerifier
 }
 
-func NewBackend(report ReportService, search SearchService, autocomplete AutocompleteService, hooks HookService, userAuth auth.TokenVerifier) *BackendService {
+func NewBackend(report ReportService, search SearchService, autocomplete AutocompleteService, hooks HookService, organization OrganizationService, admin AdminService, usage UsageService, audit *AuditLog, userAuth auth.TokenVerifier) *BackendService {
 	return &BackendService{
 		report:       report,
 		search:       search,
 		autocomplete: autocomplete,
 		hooks:        hooks,
+		organization: organization,
+		admin:        admin,
+		usage:        usage,
+		audit:        audit,
 		userAuth:     userAuth,
 	}
 }
@@ -34,11 +42,15 @@ func (s *BackendService) Routes() chi.Router {
 	r.Use(middleware.Logger)
 	r.Use(monitoring.HandlerMetrics)
 	r.Use(middleware.Recoverer)
+	r.Use(s.audit.Middleware)
 
 	r.With(auth.Middleware(s.userAuth)).Mount("/report", s.report.Routes())
 	r.With(auth.Middleware(s.userAuth)).Mount("/search", s.search.Routes())
 	r.With(auth.Middleware(s.userAuth)).Mount("/autocomplete", s.autocomplete.Routes())
 	r.With(auth.Middleware(s.userAuth)).Mount("/hooks", s.hooks.Routes())
+	r.With(auth.Middleware(s.userAuth)).Mount("/organization", s.organization.Routes())
+	r.With(auth.Middleware(s.userAuth)).Mount("/usage", s.usage.Routes())
+	r.With(auth.Middleware(s.userAuth), auth.RequireRole(auth.RoleAdmin)).Mount("/admin", s.admin.Routes())
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
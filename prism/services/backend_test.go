@@ -1,12 +1,14 @@
 package services_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -17,8 +19,10 @@ import (
 	"prism/prism/openalex"
 	"prism/prism/reports"
 	"prism/prism/schema"
+	"prism/prism/scopus"
 	"prism/prism/search"
 	"prism/prism/services"
+	"prism/prism/services/auth"
 	"slices"
 	"strings"
 	"testing"
@@ -50,19 +54,28 @@ func newUser() string {
 	return userPrefix + uuid.NewString()
 }
 
+// Users created with newUser are analysts.
+func newUserWithRole(role string) string {
+	return newUser() + ":" + role
+}
+
 type MockTokenVerifier struct {
 	prefix string
 }
 
-func (m *MockTokenVerifier) VerifyToken(token string) (uuid.UUID, string, error) {
+func (m *MockTokenVerifier) VerifyToken(token string) (auth.User, error) {
 	if !strings.HasPrefix(token, m.prefix) {
-		return uuid.Nil, "", fmt.Errorf("invalid token")
+		return auth.User{}, fmt.Errorf("invalid token")
 	}
-	id, err := uuid.Parse(strings.TrimPrefix(token, m.prefix))
+	token, role, found := strings.Cut(strings.TrimPrefix(token, m.prefix), ":")
+	if !found {
+		role = auth.RoleAnalyst
+	}
+	id, err := uuid.Parse(token)
 	if err != nil {
-		return uuid.Nil, "", err
+		return auth.User{}, err
 	}
-	return id, id.String() + "@mock.com", nil
+	return auth.User{Id: id, Email: id.String() + "@mock.com", Role: role}, nil
 }
 
 type mockOpenAlex struct{}
@@ -71,8 +84,13 @@ func (m *mockOpenAlex) AutocompleteAuthor(query string) ([]api.Autocompletion, e
 	return nil, nil
 }
 
+const mockUnknownInstitution = "unknown university"
+
 func (m *mockOpenAlex) AutocompleteInstitution(query string) ([]api.Autocompletion, error) {
-	return nil, nil
+	if query == mockUnknownInstitution {
+		return []api.Autocompletion{}, nil
+	}
+	return []api.Autocompletion{{Id: "inst-" + query, Name: query}}, nil
 }
 
 func (m *mockOpenAlex) AutocompletePaper(query string) ([]api.Autocompletion, error) {
@@ -80,33 +98,74 @@ func (m *mockOpenAlex) AutocompletePaper(query string) ([]api.Autocompletion, er
 }
 
 func (m *mockOpenAlex) FindAuthors(authorName, institutionId string) ([]openalex.Author, error) {
-	return nil, nil
+	switch authorName {
+	case "ambiguous":
+		return []openalex.Author{{AuthorId: "https://openalex.org/A1", DisplayName: "ambiguous-1"}, {AuthorId: "https://openalex.org/A2", DisplayName: "ambiguous-2"}}, nil
+	case "unknown":
+		return []openalex.Author{}, nil
+	}
+	return []openalex.Author{{AuthorId: authorName + "-id", DisplayName: authorName + "-name"}}, nil
 }
 
+const mockUnknownOrcid = "0000-0000-0000-0000"
+
 func (m *mockOpenAlex) FindAuthorByOrcidId(orcidId string) (openalex.Author, error) {
-	return openalex.Author{}, nil
+	if orcidId == mockUnknownOrcid {
+		return openalex.Author{}, openalex.ErrAuthorNotFound
+	}
+	return openalex.Author{AuthorId: "orcid-author-id", DisplayName: "orcid-author-name"}, nil
 }
 
-func (m *mockOpenAlex) StreamWorks(authorId string, startDate, endDate time.Time) chan openalex.WorkBatch {
+func (m *mockOpenAlex) StreamWorks(authorId string, startDate, endDate, updatedSince time.Time) chan openalex.WorkBatch {
 	return nil
 }
 
-func (m *mockOpenAlex) FindWorksByTitle(titles []string, startDate, endDate time.Time) ([]openalex.Work, error) {
+func (m *mockOpenAlex) FindWorksByTitle(titles []string, startDate, endDate, updatedSince time.Time) ([]openalex.Work, error) {
 	return nil, nil
 }
 
+const mockUnknownAuthorId = "https://openalex.org/A404"
+
 func (m *mockOpenAlex) GetAuthor(authorId string) (openalex.Author, error) {
+	if authorId == mockUnknownAuthorId {
+		return openalex.Author{}, openalex.ErrAuthorNotFound
+	}
 	return openalex.Author{
+		AuthorId:     authorId,
+		DisplayName:  authorId + "-name",
 		Institutions: []openalex.Institution{{InstitutionName: authorId + "-affiliation1"}, {InstitutionName: authorId + "-affiliation2"}},
 		Concepts:     []string{authorId + "-interest1", authorId + "-interest2"},
 	}, nil
 }
 
-func (m *mockOpenAlex) GetInstitutionAuthors(institutionId string, startDate, endDate time.Time) ([]openalex.InstitutionAuthor, error) {
+func (m *mockOpenAlex) GetInstitutionAuthors(institutionId string, filter openalex.InstitutionAuthorFilter) ([]openalex.InstitutionAuthor, error) {
 	return nil, nil
 }
 
+const scopusTestApiKey = "scopus-test-key"
+
+var scopusTestAuthors = []scopus.FakeAuthor{
+	{
+		AuthorId:     "7004212771",
+		GivenName:    "Anshumali",
+		Surname:      "Shrivastava",
+		Affiliations: []string{"Rice University"},
+		SubjectAreas: []string{"Computer Science", "Mathematics"},
+	},
+	{
+		AuthorId:     "7004212772",
+		GivenName:    "Ann",
+		Surname:      "Shrivastava",
+		Affiliations: []string{"Other University"},
+	},
+}
+
 func createBackend(t *testing.T) (http.Handler, *gorm.DB) {
+	return createBackendWithLimits(t, reports.Quotas{}, 0)
+}
+
+// The rate limit applies to both search and autocomplete, 0 is unlimited.
+func createBackendWithLimits(t *testing.T, quotas reports.Quotas, requestsPerMinute int) (http.Handler, *gorm.DB) {
 	db := schema.SetupTestDB(t)
 
 	entities := []api.MatchedEntity{{Names: "abc university"}, {Names: "institute of xyz"}, {Names: "123 org"}}
@@ -118,11 +177,21 @@ func createBackend(t *testing.T) (http.Handler, *gorm.DB) {
 
 	oa := openalex.NewRemoteKnowledgeBase()
 
+	scopusServer := scopus.StartFakeServer(t, scopusTestApiKey, scopusTestAuthors)
+	scopusClient := scopus.NewClient(scopusServer.URL, scopusTestApiKey)
+
+	manager := reports.NewManager(db).SetQuotas(quotas)
+	audit := services.NewAuditLog(db)
+
 	backend := services.NewBackend(
-		services.NewReportService(reports.NewManager(db), licensing, &mockOpenAlex{}, "./resources"),
-		services.NewSearchService(oa, entities),
-		services.NewAutoCompleteService(oa),
+		services.NewReportService(manager, licensing, &mockOpenAlex{}, scopusClient, "./resources"),
+		services.NewSearchService(oa, scopusClient, entities, manager, services.NewRateLimiter(requestsPerMinute)),
+		services.NewAutoCompleteService(oa, services.NewRateLimiter(requestsPerMinute)),
 		services.NewHookService(db, map[string]services.Hook{}, 1*time.Second),
+		services.NewOrganizationService(manager),
+		services.NewAdminService(manager, audit),
+		services.NewUsageService(manager),
+		audit,
 		&MockTokenVerifier{prefix: userPrefix},
 	)
 
@@ -177,6 +246,10 @@ func Post(backend http.Handler, endpoint, token string, jsonBody any, result any
 	return mockRequest(backend, "POST", endpoint, token, jsonBody, result)
 }
 
+func Put(backend http.Handler, endpoint, token string, jsonBody any, result any) error {
+	return mockRequest(backend, "PUT", endpoint, token, jsonBody, result)
+}
+
 func Delete(backend http.Handler, endpoint, token string) error {
 	return mockRequest(backend, "DELETE", endpoint, token, nil, nil)
 }
@@ -372,6 +445,10 @@ func TestCheckDisclosure(t *testing.T) {
 	if !updatedReport.Content["TalentContracts"][0].(*api.TalentContractFlag).Disclosed {
 		t.Fatal("expected TalentContract flag to be marked as disclosed")
 	}
+	evidence := updatedReport.Content["TalentContracts"][0].(*api.TalentContractFlag).DisclosureEvidence
+	if evidence == nil || evidence.Page != 1 || evidence.Confidence != 1 || !strings.Contains(evidence.Snippet, "discloseme") {
+		t.Fatalf("incorrect disclosure evidence: %+v", evidence)
+	}
 
 	if len(updatedReport.Content["AssociationsWithDeniedEntities"]) != 1 {
 		t.Fatalf("expected 1 AssociationWithDeniedEntity flag; got %d", len(updatedReport.Content["AssociationsWithDeniedEntities"]))
@@ -379,6 +456,237 @@ func TestCheckDisclosure(t *testing.T) {
 	if updatedReport.Content["AssociationsWithDeniedEntities"][0].(*api.AssociationWithDeniedEntityFlag).Disclosed {
 		t.Fatal("expected AssociationWithDeniedEntity flag to remain undisclosed")
 	}
+
+	// The disclosure status is persisted.
+	persistedReport, err := getAuthorReport(backend, user, reportResp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !persistedReport.Content["TalentContracts"][0].IsDisclosed() {
+		t.Fatal("expected TalentContract flag to remain disclosed")
+	}
+
+	// New flags are checked against the stored disclosures when the report is updated.
+	if err := manager.UpdateAuthorReport(nextReport.Id, schema.ReportCompleted, time.Now(), []api.Flag{
+		&api.HighRiskFunderFlag{
+			Message: "Test disclosure flag - Funder",
+			Work:    api.WorkSummary{WorkId: "work-3", PublicationDate: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Funders: []string{"discloseme"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	refreshedReport, err := getAuthorReport(backend, user, reportResp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refreshedReport.Content["HighRiskFunders"]) != 1 || !refreshedReport.Content["HighRiskFunders"][0].IsDisclosed() {
+		t.Fatal("expected new HighRiskFunder flag to be marked as disclosed")
+	}
+
+	var documents []api.DisclosureDocument
+	if err := Get(backend, fmt.Sprintf("/report/author/%s/disclosures", reportResp.Id), user, &documents); err != nil {
+		t.Fatal(err)
+	}
+
+	talentHash := refreshedReport.Content["TalentContracts"][0].(*api.TalentContractFlag).FlagHash
+	funderHash := refreshedReport.Content["HighRiskFunders"][0].(*api.HighRiskFunderFlag).FlagHash
+
+	if len(documents) != 1 || documents[0].Filename != "sample.txt" || len(documents[0].DisclosedFlags) != 2 ||
+		!slices.ContainsFunc(documents[0].DisclosedFlags, func(f api.DisclosedFlag) bool { return f.FlagHash == talentHash }) ||
+		!slices.ContainsFunc(documents[0].DisclosedFlags, func(f api.DisclosedFlag) bool { return f.FlagHash == funderHash }) {
+		t.Fatalf("incorrect disclosure history: %+v", documents)
+	}
+
+	if err := Get(backend, fmt.Sprintf("/report/author/%s/disclosures", reportResp.Id), newUser(), &documents); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("other users should not be able to view disclosures: %v", err)
+	}
+
+	// Deleting the document removes the disclosures it satisfied.
+	if err := Delete(backend, fmt.Sprintf("/report/author/%s/disclosures/%s", reportResp.Id, documents[0].Id), user); err != nil {
+		t.Fatal(err)
+	}
+
+	finalReport, err := getAuthorReport(backend, user, reportResp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finalReport.Content["TalentContracts"][0].IsDisclosed() || finalReport.Content["HighRiskFunders"][0].IsDisclosed() {
+		t.Fatal("expected flags to be undisclosed after deleting the disclosure")
+	}
+}
+
+type disclosureFile struct {
+	name    string
+	content []byte
+}
+
+func checkDisclosure(backend http.Handler, user string, reportId uuid.UUID, files []disclosureFile) (api.CheckDisclosureResponse, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, file := range files {
+		part, err := writer.CreateFormFile("files", file.name)
+		if err != nil {
+			return api.CheckDisclosureResponse{}, err
+		}
+		if _, err := part.Write(file.content); err != nil {
+			return api.CheckDisclosureResponse{}, err
+		}
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/report/author/%s/check-disclosure", reportId), &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Add("Authorization", "Bearer "+user)
+
+	w := httptest.NewRecorder()
+	backend.ServeHTTP(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return api.CheckDisclosureResponse{}, fmt.Errorf("check disclosure returned status %d: %s", res.StatusCode, w.Body.String())
+	}
+
+	var result api.CheckDisclosureResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return api.CheckDisclosureResponse{}, err
+	}
+	return result, nil
+}
+
+func createDocx(t *testing.T, paragraphs ...string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	document, err := archive.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := ""
+	for _, p := range paragraphs {
+		body += "<w:p><w:r><w:t>" + p + "</w:t></w:r></w:p>"
+	}
+	if _, err := document.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body + `</w:body></w:document>`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func createXlsx(t *testing.T, sheets map[string][]string) []byte {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	for _, sheet := range []string{"Sheet1", "Sheet2"} {
+		if sheet != "Sheet1" {
+			if _, err := file.NewSheet(sheet); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i, value := range sheets[sheet] {
+			if err := file.SetCellValue(sheet, fmt.Sprintf("A%d", i+1), value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	buf, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckDisclosureFileFormats(t *testing.T) {
+	backend, db := createBackend(t)
+
+	user := newUser()
+
+	manager := reports.NewManager(db)
+
+	reportResp, err := createAuthorReport(backend, user, "disclosure-formats-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nextReport, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	affiliation := func(workId, entity string) api.Flag {
+		return &api.AuthorAffiliationFlag{
+			Message:      "Test affiliation flag",
+			Work:         api.WorkSummary{WorkId: workId, PublicationDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Affiliations: []string{entity},
+		}
+	}
+
+	if err := manager.UpdateAuthorReport(nextReport.Id, schema.ReportCompleted, time.Now(), []api.Flag{
+		affiliation("work-1", "Harbin Institute of Technology"),
+		affiliation("work-2", "Beihang University"),
+		affiliation("work-3", "Tianjin University"),
+		affiliation("work-4", "Fudan University"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := checkDisclosure(backend, user, reportResp.Id, []disclosureFile{
+		{name: "biosketch.docx", content: createDocx(t, "Positions", "Adjunct professor, Harbin Institute of Technology")},
+		{name: "support.xlsx", content: createXlsx(t, map[string][]string{"Sheet1": {"Current Support"}, "Sheet2": {"Pending Support", "Beihang University"}})},
+		{name: "profile.html", content: []byte("<html><head><title>Fudan University</title><script>var x = 'Fudan University';</script></head><body><p>Visiting scholar</p><p>Tianjin <b>University</b></p></body></html>")},
+		{name: "broken.docx", content: []byte("not a docx file")},
+		{name: "legacy.doc", content: []byte("Fudan University")},
+		{name: "empty.txt", content: []byte("   ")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errorFiles := make([]string, 0)
+	for _, fileErr := range result.FileErrors {
+		if fileErr.Error == "" {
+			t.Fatalf("expected error message for %s", fileErr.Filename)
+		}
+		errorFiles = append(errorFiles, fileErr.Filename)
+	}
+	if !slices.Equal(errorFiles, []string{"broken.docx", "legacy.doc", "empty.txt"}) {
+		t.Fatalf("incorrect file errors: %+v", result.FileErrors)
+	}
+
+	expected := map[string]int{
+		"Harbin Institute of Technology": 1,
+		"Beihang University":             2, // Sheets are treated as pages.
+		"Tianjin University":             1,
+	}
+
+	flags := result.Content[api.AuthorAffiliationType]
+	if len(flags) != 4 {
+		t.Fatalf("expected 4 flags, got %d", len(flags))
+	}
+	for _, flag := range flags {
+		entity := flag.GetEntities()[0]
+		evidence := flag.(*api.AuthorAffiliationFlag).DisclosureEvidence
+		page, ok := expected[entity]
+		if ok != flag.IsDisclosed() {
+			t.Fatalf("incorrect disclosure status for %s", entity)
+		}
+		if ok && (evidence == nil || evidence.Page != page) {
+			t.Fatalf("incorrect disclosure evidence for %s: %+v", entity, evidence)
+		}
+	}
+
+	var documents []api.DisclosureDocument
+	if err := Get(backend, fmt.Sprintf("/report/author/%s/disclosures", reportResp.Id), user, &documents); err != nil {
+		t.Fatal(err)
+	}
+	if len(documents) != 3 {
+		t.Fatalf("expected only the parsed files to be stored, got %d", len(documents))
+	}
 }
 
 func TestDownloadReportAllFormats(t *testing.T) {
@@ -567,134 +875,1514 @@ func TestAuthorReportEndpoints(t *testing.T) {
 	checkListAuthorReports(t, backend, user2, []string{"report2"})
 }
 
-func TestUniversityReportEndpoints(t *testing.T) {
-	backend, db := createBackend(t)
-	manager := reports.NewManager(db)
+func userEmail(user string) string {
+	return strings.TrimPrefix(user, userPrefix) + "@mock.com"
+}
 
-	user1, user2 := newUser(), newUser()
+func TestOrganizationEndpoints(t *testing.T) {
+	backend, _ := createBackend(t)
 
-	uniReportId, err := createUniversityReport(backend, user1, "uni-report1")
-	if err != nil {
-		t.Fatal(err)
+	admin, member, outsider := newUser(), newUser(), newUser()
+
+	if err := Get(backend, "/organization", admin, nil); err == nil {
+		t.Fatal("user should not be in an organization")
 	}
 
-	nextUniReport, err := manager.GetNextUniversityReport()
-	if err != nil {
-		t.Fatal(err)
+	if err := Post(backend, "/organization/create", admin, api.CreateOrganizationRequest{Name: " "}, nil); err == nil {
+		t.Fatal("organization name must be specified")
 	}
-	if nextUniReport == nil {
-		t.Fatal("next report should not be nil")
+
+	var created api.CreateReportResponse
+	if err := Post(backend, "/organization/create", admin, api.CreateOrganizationRequest{Name: "org"}, &created); err != nil {
+		t.Fatal(err)
 	}
 
-	if err := manager.UpdateUniversityReport(nextUniReport.Id, schema.ReportCompleted, nextUniReport.UpdateDate, []reports.UniversityAuthorReport{
-		{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource},
-	}); err != nil {
+	invite := api.InviteOrganizationMemberRequest{Email: userEmail(member)}
+	if err := Post(backend, "/organization/invites", outsider, invite, nil); err == nil {
+		t.Fatal("only admins can invite members")
+	}
+	if err := Post(backend, "/organization/invites", admin, api.InviteOrganizationMemberRequest{Email: "invalid"}, nil); err == nil {
+		t.Fatal("invite email must be valid")
+	}
+	if err := Post(backend, "/organization/invites", admin, invite, nil); err != nil {
 		t.Fatal(err)
 	}
 
-	nextAuthorReport, err := manager.GetNextAuthorReport()
-	if err != nil {
+	var invites []api.OrganizationInvite
+	if err := Get(backend, "/organization/invites", member, &invites); err != nil {
 		t.Fatal(err)
 	}
+	if len(invites) != 1 || invites[0].OrganizationId != created.Id || invites[0].OrganizationName != "org" || invites[0].Role != schema.OrganizationRoleMember {
+		t.Fatalf("incorrect invites: %v", invites)
+	}
 
-	if !nextAuthorReport.ForUniversityReport {
-		t.Fatal("next author report should be for university report")
+	if err := Post(backend, "/organization/invites/"+created.Id.String()+"/accept", outsider, nil, nil); err == nil {
+		t.Fatal("user without an invite should not be able to join")
+	}
+	if err := Post(backend, "/organization/invites/"+created.Id.String()+"/accept", member, nil, nil); err != nil {
+		t.Fatal(err)
 	}
 
-	if err := manager.UpdateAuthorReport(nextAuthorReport.Id, schema.ReportCompleted, time.Now(), []api.Flag{
-		&api.HighRiskFunderFlag{Work: api.WorkSummary{WorkId: "abc", PublicationDate: time.Now()}},
-	}); err != nil {
+	var org api.Organization
+	if err := Get(backend, "/organization", admin, &org); err != nil {
 		t.Fatal(err)
 	}
+	if org.Name != "org" || org.Role != schema.OrganizationRoleAdmin || len(org.Members) != 2 || len(org.Invites) != 0 ||
+		org.Members[1].Email != userEmail(member) {
+		t.Fatalf("incorrect organization: %+v", org)
+	}
 
-	uniReport, err := getUniversityReport(backend, user1, uniReportId.Id)
+	report, err := createAuthorReport(backend, admin, "report1")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if uniReport.UniversityId != "uni-report1-id" || uniReport.UniversityName != "uni-report1-name" || uniReport.UniversityLocation != "uni-report1-location" || uniReport.Status != "complete" || len(uniReport.Content.Flags[api.HighRiskFunderType]) != 1 {
-		t.Fatal("invalid university report returned")
+	if _, err := getAuthorReport(backend, member, report.Id); !errors.Is(err, ErrUnauthorized) {
+		t.Fatal("report should not be visible before it is shared")
 	}
 
-	if _, err := getUniversityReport(backend, user2, uniReportId.Id); err == nil || !strings.Contains(err.Error(), "user cannot access report") {
+	share := "/report/author/" + report.Id.String() + "/share"
+	if err := Put(backend, share, admin, api.ShareAuthorReportRequest{Access: "all"}, nil); err == nil {
+		t.Fatal("access must be valid")
+	}
+	if err := Put(backend, share, member, api.ShareAuthorReportRequest{Access: schema.ReportAccessRead}, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatal("only the owner can share the report")
+	}
+	if err := Put(backend, share, admin, api.ShareAuthorReportRequest{Access: schema.ReportAccessRead}, nil); err != nil {
 		t.Fatal(err)
 	}
 
-	checkListUniversityReports(t, backend, user1, []string{"uni-report1"})
+	checkListAuthorReports(t, backend, member, []string{})
 
-	if err := deleteUniversityReport(backend, user2, uniReport.Id); err == nil || !strings.Contains(err.Error(), "report not found") {
+	var orgReports []api.Report
+	if err := Get(backend, "/report/author/list?organization=true", member, &orgReports); err != nil {
 		t.Fatal(err)
 	}
-	checkListUniversityReports(t, backend, user1, []string{"uni-report1"})
+	if len(orgReports) != 1 || orgReports[0].Id != report.Id || orgReports[0].OwnerEmail != userEmail(admin) || orgReports[0].SharedAccess != schema.ReportAccessRead {
+		t.Fatalf("incorrect organization reports: %v", orgReports)
+	}
 
-	if err := deleteUniversityReport(backend, user1, uniReport.Id); err != nil {
+	if _, err := getAuthorReport(backend, member, report.Id); err != nil {
 		t.Fatal(err)
 	}
+	if _, err := getAuthorReport(backend, outsider, report.Id); !errors.Is(err, ErrUnauthorized) {
+		t.Fatal("report should not be visible outside the organization")
+	}
+	if err := Delete(backend, "/report/author/"+report.Id.String(), member); err == nil {
+		t.Fatal("only the owner can delete the report")
+	}
 
-	checkListUniversityReports(t, backend, user1, []string{})
+	if err := Delete(backend, share, admin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getAuthorReport(backend, member, report.Id); !errors.Is(err, ErrUnauthorized) {
+		t.Fatal("report should not be visible after it is unshared")
+	}
+
+	if err := Delete(backend, "/organization/members/"+strings.TrimPrefix(admin, userPrefix), member); !errors.Is(err, ErrUnauthorized) {
+		t.Fatal("only admins can remove other members")
+	}
+	if err := Delete(backend, "/organization/members/"+strings.TrimPrefix(member, userPrefix), member); err != nil {
+		t.Fatal(err)
+	}
+	if err := Get(backend, "/organization", member, nil); err == nil {
+		t.Fatal("member should have left the organization")
+	}
 }
 
-func TestAutocompleteAuthor(t *testing.T) {
+func TestRoleAccess(t *testing.T) {
 	backend, _ := createBackend(t)
 
-	user := newUser()
+	viewer, analyst, admin := newUserWithRole(auth.RoleViewer), newUser(), newUserWithRole(auth.RoleAdmin)
 
-	var results []api.Autocompletion
-	err := mockRequest(backend, "GET", "/autocomplete/author?query="+url.QueryEscape("anshumali shriva"), user, nil, &results)
-	if err != nil {
-		t.Fatal(err)
+	if _, err := createAuthorReport(backend, viewer, "report1"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("viewers cannot create reports: %v", err)
 	}
+	checkListAuthorReports(t, backend, viewer, []string{})
 
-	if len(results) == 0 {
-		t.Fatal("should have some results")
+	if err := Post(backend, "/report/university/create", viewer, api.CreateUniversityReportRequest{UniversityId: "1", UniversityName: "uni"}, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("viewers cannot create university reports: %v", err)
 	}
 
-	for _, res := range results {
-		if !strings.HasPrefix(res.Id, "https://openalex.org/") ||
-			!strings.EqualFold(res.Name, "Anshumali Shrivastava") {
-			t.Fatal("invalid result")
-		}
+	// Unknown roles are treated as viewers.
+	if _, err := createAuthorReport(backend, newUserWithRole("superuser"), "report1"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("unknown roles should not be able to create reports: %v", err)
 	}
-}
 
-func TestAutocompleteInstution(t *testing.T) {
-	backend, _ := createBackend(t)
+	if _, err := createAuthorReport(backend, analyst, "report1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createAuthorReport(backend, admin, "report2"); err != nil {
+		t.Fatal(err)
+	}
 
-	user := newUser()
+	if err := Get(backend, "/admin/queue", analyst, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("only admins can access admin endpoints: %v", err)
+	}
 
-	var results []api.Autocompletion
-	err := mockRequest(backend, "GET", "/autocomplete/institution?query="+url.QueryEscape("rice univer"), user, nil, &results)
-	if err != nil {
+	var stats api.ReportQueueStats
+	if err := Get(backend, "/admin/queue", admin, &stats); err != nil {
 		t.Fatal(err)
 	}
-
-	if len(results) == 0 {
-		t.Fatal("should have some results")
+	if stats.AuthorReports[schema.ReportQueued] != 2 || len(stats.UniversityReports) != 0 {
+		t.Fatalf("incorrect queue stats: %+v", stats)
 	}
+}
 
-	for _, res := range results {
-		if !strings.HasPrefix(res.Id, "https://openalex.org/") ||
-			!strings.EqualFold(res.Name, "Rice University") ||
-			!strings.EqualFold(res.Hint, "Houston, USA") {
-			t.Fatal("invalid result")
+func TestWatchlistUpdateEndpoints(t *testing.T) {
+	backend, db := createBackend(t)
+
+	admin := newUserWithRole(auth.RoleAdmin)
+
+	manager := reports.NewManager(db)
+	for _, versions := range []map[string]string{{"entities": "a"}, {"entities": "b"}} {
+		if _, err := manager.CreateAuthorReport(uuid.New(), reports.AuthorReportParams{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := manager.CheckWatchlistVersions(versions); err != nil {
+			t.Fatal(err)
 		}
 	}
-}
 
-func TestAutocompletePaper(t *testing.T) {
-	backend, _ := createBackend(t)
+	if err := Get(backend, "/admin/watchlist-updates", newUser(), nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("only admins can access watchlist updates: %v", err)
+	}
 
-	user := newUser()
+	var updates []api.WatchlistUpdate
+	if err := Get(backend, "/admin/watchlist-updates", admin, &updates); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || !slices.Equal(updates[0].ChangedWatchlists, []string{"entities"}) || updates[0].Watchlists["entities"] != "b" {
+		t.Fatalf("incorrect watchlist updates: %+v", updates)
+	}
 
-	query := "From Research to Production: Towards Scalable and Sustainable Neural Recommendation"
-	var results []api.Autocompletion
-	err := mockRequest(backend, "GET", "/autocomplete/paper?query="+url.QueryEscape(query), user, nil, &results)
-	if err != nil {
+	var details api.WatchlistUpdateDetails
+	if err := Get(backend, "/admin/watchlist-updates/"+updates[0].Id.String(), admin, &details); err != nil {
 		t.Fatal(err)
 	}
+	if details.Id != updates[0].Id || details.Version != updates[0].Version || len(details.Reports) != 0 {
+		t.Fatalf("incorrect watchlist update: %+v", details)
+	}
 
-	if len(results) != 1 {
-		t.Fatal("should have 1 result")
+	if err := Get(backend, "/admin/watchlist-updates/"+uuid.NewString(), admin, nil); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if err := Get(backend, "/admin/watchlist-updates/missing", admin, nil); err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Fatalf("expected invalid id error, got %v", err)
+	}
+}
+
+func importWatchlist(backend http.Handler, user, watchlist, filename string, content []byte) (api.WatchlistImportResult, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return api.WatchlistImportResult{}, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return api.WatchlistImportResult{}, err
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/admin/watchlists/"+watchlist+"/import", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Add("Authorization", "Bearer "+user)
+
+	w := httptest.NewRecorder()
+	backend.ServeHTTP(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return api.WatchlistImportResult{}, fmt.Errorf("import watchlist returned status %d: %s", res.StatusCode, w.Body.String())
+	}
+
+	var result api.WatchlistImportResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return api.WatchlistImportResult{}, err
+	}
+	return result, nil
+}
+
+func TestWatchlistEndpoints(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	admin := newUserWithRole(auth.RoleAdmin)
+
+	if err := Get(backend, "/admin/watchlists", newUser(), nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("only admins can access watchlists: %v", err)
+	}
+	if err := Post(backend, "/admin/watchlists/acknowledgements/entities", newUserWithRole(auth.RoleAnalyst), api.WatchlistEntityRequest{Name: "abc"}, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("only admins can change watchlists: %v", err)
+	}
+
+	var entity api.WatchlistEntity
+	if err := Post(backend, "/admin/watchlists/acknowledgements/entities", admin, api.WatchlistEntityRequest{Name: "Org A", Aliases: []string{"A"}, Source: "list"}, &entity); err != nil {
+		t.Fatal(err)
+	}
+	if entity.Name != "Org A" || entity.Watchlist != "acknowledgements" {
+		t.Fatalf("incorrect entity: %+v", entity)
+	}
+
+	if err := Post(backend, "/admin/watchlists/institutions/entities", admin, api.WatchlistEntityRequest{Name: "Org A"}, nil); err == nil || !strings.Contains(err.Error(), "status 422") {
+		t.Fatalf("expected invalid entity error, got %v", err)
+	}
+	if err := Post(backend, "/admin/watchlists/missing/entities", admin, api.WatchlistEntityRequest{Name: "Org A"}, nil); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	var updated api.WatchlistEntity
+	if err := Put(backend, "/admin/watchlists/acknowledgements/entities/"+entity.Id.String(), admin, api.WatchlistEntityRequest{Name: "Org A", Source: "other list", Justification: "moved"}, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Id != entity.Id || updated.Source != "other list" || len(updated.Aliases) != 0 {
+		t.Fatalf("incorrect updated entity: %+v", updated)
+	}
+
+	csvFile := strings.Join([]string{
+		"Name,Aliases,Source,Effective_Date,Justification",
+		"Org A,,list,,",
+		`Org B,"B;Org Bee",list,2020-01-02,"sanctioned, see notice"`,
+		",,,,",
+	}, "\n")
+	result, err := importWatchlist(backend, admin, "acknowledgements", "orgs.csv", []byte(csvFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 1 || result.Skipped != 1 {
+		t.Fatalf("incorrect import result: %+v", result)
+	}
+
+	csl := `{"total": 2, "results": [
+		{"name": "Org C", "alt_names": ["C Corp"], "source": "Entity List (EL) - Bureau of Industry and Security", "start_date": "2019-05-21", "federal_register_notice": "84 FR 22961", "remarks": "", "addresses": []},
+		{"name": "Org B", "alt_names": null, "source": "Entity List (EL) - Bureau of Industry and Security", "start_date": "", "federal_register_notice": "", "remarks": "dup"}
+	]}`
+	result, err = importWatchlist(backend, admin, "acknowledgements", "consolidated.json", []byte(csl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 1 || result.Skipped != 1 {
+		t.Fatalf("incorrect import result: %+v", result)
+	}
+
+	if _, err := importWatchlist(backend, admin, "funders", "consolidated.json", []byte(csl)); err == nil || !strings.Contains(err.Error(), "status 422") {
+		t.Fatalf("expected invalid entity error, got %v", err)
+	}
+	if _, err := importWatchlist(backend, admin, "acknowledgements", "orgs.csv", []byte("Name,Country\nOrg D,US")); err == nil || !strings.Contains(err.Error(), "status 422") {
+		t.Fatalf("expected invalid file error, got %v", err)
+	}
+	if _, err := importWatchlist(backend, admin, "acknowledgements", "orgs.txt", []byte(csvFile)); err == nil || !strings.Contains(err.Error(), "status 422") {
+		t.Fatalf("expected invalid file error, got %v", err)
+	}
+
+	if err := Delete(backend, "/admin/watchlists/acknowledgements/entities/"+entity.Id.String(), admin); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(backend, "/admin/watchlists/acknowledgements/entities/"+entity.Id.String(), admin); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	var entities []api.WatchlistEntity
+	if err := Get(backend, "/admin/watchlists/acknowledgements/entities", admin, &entities); err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 2 || entities[0].Name != "Org B" || entities[1].Name != "Org C" {
+		t.Fatalf("incorrect entities: %+v", entities)
+	}
+	orgB, orgC := entities[0], entities[1]
+	if !slices.Equal(orgB.Aliases, []string{"B", "Org Bee"}) || orgB.EffectiveDate == nil || orgB.Justification != "sanctioned, see notice" {
+		t.Fatalf("incorrect csv entity: %+v", orgB)
+	}
+	if !slices.Equal(orgC.Aliases, []string{"C Corp"}) || orgC.Justification != "Federal Register Notice: 84 FR 22961" ||
+		orgC.EffectiveDate == nil || !orgC.EffectiveDate.Equal(time.Date(2019, 5, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("incorrect consolidated screening list entity: %+v", orgC)
+	}
+
+	if err := Get(backend, "/admin/watchlists/acknowledgements/entities?include_removed=true", admin, &entities); err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 3 {
+		t.Fatalf("expected removed entity to be listed: %+v", entities)
+	}
+
+	var watchlists []api.Watchlist
+	if err := Get(backend, "/admin/watchlists", admin, &watchlists); err != nil {
+		t.Fatal(err)
+	}
+	for _, watchlist := range watchlists {
+		if expected := map[string]int64{"acknowledgements": 2}[watchlist.Name]; watchlist.Entities != expected || watchlist.Version == "" {
+			t.Fatalf("incorrect watchlist: %+v", watchlist)
+		}
+	}
+}
+
+func checkAuditLog(t *testing.T, backend http.Handler, admin, query string, expected map[string]string) api.AuditLogPage {
+	t.Helper()
+
+	var page api.AuditLogPage
+	if err := Get(backend, "/admin/audit?"+query, admin, &page); err != nil {
+		t.Fatal(err)
+	}
+
+	if page.Total != len(expected) || len(page.Entries) != len(expected) {
+		t.Fatalf("expected %d audit log entries for '%s', got %+v", len(expected), query, page)
+	}
+	for _, entry := range page.Entries {
+		if outcome, ok := expected[entry.Action]; !ok || entry.Outcome != outcome {
+			t.Fatalf("unexpected audit log entry for '%s': %+v", query, entry)
+		}
+	}
+
+	return page
+}
+
+func TestAuditLog(t *testing.T) {
+	backend, db := createBackend(t)
+
+	analyst, other, viewer, admin := newUser(), newUser(), newUserWithRole(auth.RoleViewer), newUserWithRole(auth.RoleAdmin)
+	analystId := strings.TrimPrefix(analyst, userPrefix)
+
+	report, err := createAuthorReport(backend, analyst, "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getAuthorReport(backend, analyst, report.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getAuthorReport(backend, other, report.Id); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected access error, got %v", err)
+	}
+	if _, err := createAuthorReport(backend, viewer, "audit"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("viewers cannot create reports: %v", err)
+	}
+	if err := Get(backend, "/search/authors-scopus?author_name=shrivastava", analyst, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := deleteAuthorReport(backend, analyst, report.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listing reports is not recorded.
+	checkListAuthorReports(t, backend, analyst, []string{})
+
+	page := checkAuditLog(t, backend, admin, "user_id="+analystId, map[string]string{
+		services.AuditAuthorReportCreate: schema.AuditSuccess,
+		services.AuditAuthorReportView:   schema.AuditSuccess,
+		services.AuditSearch:             schema.AuditSuccess,
+		services.AuditAuthorReportDelete: schema.AuditSuccess,
+	})
+	for _, entry := range page.Entries {
+		if entry.Email != userEmail(analyst) {
+			t.Fatalf("incorrect email in audit log entry: %+v", entry)
+		}
+		if entry.Action == services.AuditSearch {
+			if entry.Path != "/search/authors-scopus" || entry.Query != "author_name=shrivastava" || entry.ReportId != nil {
+				t.Fatalf("incorrect search audit log entry: %+v", entry)
+			}
+			continue
+		}
+		// The author is recorded for the creation and deletion of the report.
+		if entry.ReportId == nil || *entry.ReportId != report.Id || entry.AuthorId != "audit-id" || entry.AuthorName != "audit-name" {
+			t.Fatalf("incorrect report in audit log entry: %+v", entry)
+		}
+	}
+
+	checkAuditLog(t, backend, admin, "author_id=audit-id&outcome=success", map[string]string{
+		services.AuditAuthorReportCreate: schema.AuditSuccess,
+		services.AuditAuthorReportView:   schema.AuditSuccess,
+		services.AuditAuthorReportDelete: schema.AuditSuccess,
+	})
+	checkAuditLog(t, backend, admin, "outcome=denied", map[string]string{
+		services.AuditAuthorReportView:   schema.AuditDenied,
+		services.AuditAuthorReportCreate: schema.AuditDenied,
+	})
+	checkAuditLog(t, backend, admin, "email="+url.QueryEscape(userEmail(other)), map[string]string{
+		services.AuditAuthorReportView: schema.AuditDenied,
+	})
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	checkAuditLog(t, backend, admin, "action=author-report.view&outcome=success&start="+today+"&end="+today, map[string]string{
+		services.AuditAuthorReportView: schema.AuditSuccess,
+	})
+	checkAuditLog(t, backend, admin, "end="+yesterday, map[string]string{})
+
+	if err := Get(backend, "/admin/audit?user_id="+analystId+"&page=2&page_size=3", admin, &page); err != nil {
+		t.Fatal(err)
+	}
+	// Entries are returned newest first, so only the creation is on the second page.
+	if page.Total != 4 || page.Page != 2 || page.PageSize != 3 || len(page.Entries) != 1 || page.Entries[0].Action != services.AuditAuthorReportCreate {
+		t.Fatalf("incorrect audit log page: %+v", page)
+	}
+
+	if err := Get(backend, "/admin/audit?start=yesterday", admin, nil); err == nil {
+		t.Fatal("expected error for invalid start")
+	}
+	if err := Get(backend, "/admin/audit", analyst, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("only admins can access the audit log: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/admin/audit/export?user_id="+analystId, nil)
+	req.Header.Add("Authorization", "Bearer "+admin)
+	w := httptest.NewRecorder()
+	backend.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("audit log export failed with status %d: %s", w.Code, w.Body.String())
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || rows[0][0] != "Timestamp" || rows[1][3] != services.AuditAuthorReportCreate || rows[1][8] != "audit-id" {
+		t.Fatalf("incorrect audit log export: %v", rows)
+	}
+
+	// The audit log is append only.
+	if err := db.Model(&schema.AuditLogEntry{}).Where("user_id = ?", analystId).Update("outcome", schema.AuditDenied).Error; err == nil {
+		t.Fatal("expected audit log entries to not be updatable")
+	}
+	if err := db.Where("user_id = ?", analystId).Delete(&schema.AuditLogEntry{}).Error; err == nil {
+		t.Fatal("expected audit log entries to not be deletable")
+	}
+	checkAuditLog(t, backend, admin, "user_id="+analystId, map[string]string{
+		services.AuditAuthorReportCreate: schema.AuditSuccess,
+		services.AuditAuthorReportView:   schema.AuditSuccess,
+		services.AuditSearch:             schema.AuditSuccess,
+		services.AuditAuthorReportDelete: schema.AuditSuccess,
+	})
+}
+
+func TestQuotasAndRateLimits(t *testing.T) {
+	backend, _ := createBackendWithLimits(t, reports.Quotas{AuthorReportsPerDay: reports.QuotaLimit{User: 1}}, 2)
+
+	user := newUser()
+
+	if _, err := createAuthorReport(backend, user, "report1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createAuthorReport(backend, user, "report2"); err == nil || !strings.Contains(err.Error(), "status 429") {
+		t.Fatalf("expected quota error: %v", err)
+	}
+
+	var usage api.Usage
+	if err := Get(backend, "/usage", user, &usage); err != nil {
+		t.Fatal(err)
+	}
+	if len(usage.Quotas) != 3 {
+		t.Fatalf("incorrect usage: %+v", usage)
+	}
+	for _, quota := range usage.Quotas {
+		switch quota.Metric {
+		case schema.UsageAuthorReports:
+			if quota.User.Used != 1 || *quota.User.Limit != 1 || *quota.Remaining != 0 || !quota.ResetsAt.After(time.Now()) {
+				t.Fatalf("incorrect author report quota: %+v", quota)
+			}
+		default:
+			if quota.User.Used != 0 || quota.Remaining != nil {
+				t.Fatalf("incorrect quota: %+v", quota)
+			}
+		}
+	}
+
+	search := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/search/authors-scopus?author_name=shrivastava", nil)
+		req.Header.Add("Authorization", "Bearer "+user)
+		w := httptest.NewRecorder()
+		backend.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := search(user); w.Code != http.StatusOK {
+			t.Fatalf("search failed with status %d: %s", w.Code, w.Body.String())
+		}
+	}
+	if w := search(user); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected rate limit error, got status %d", w.Code)
+	}
+	// The limit is per user.
+	if w := search(newUser()); w.Code != http.StatusOK {
+		t.Fatalf("search failed with status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func createAuthorReportBatch(backend http.Handler, user, filename string, content []byte) (api.AuthorReportBatch, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return api.AuthorReportBatch{}, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return api.AuthorReportBatch{}, err
+	}
+	if err := writer.WriteField("lookback_years", "3"); err != nil {
+		return api.AuthorReportBatch{}, err
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/report/batch/create", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Add("Authorization", "Bearer "+user)
+
+	w := httptest.NewRecorder()
+	backend.ServeHTTP(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return api.AuthorReportBatch{}, fmt.Errorf("create batch returned status %d: %s", res.StatusCode, w.Body.String())
+	}
+
+	var batch api.AuthorReportBatch
+	if err := json.NewDecoder(res.Body).Decode(&batch); err != nil {
+		return api.AuthorReportBatch{}, err
+	}
+	return batch, nil
+}
+
+func TestAuthorReportBatch(t *testing.T) {
+	backend, db := createBackend(t)
+
+	user1, user2 := newUser(), newUser()
+
+	roster := strings.Join([]string{
+		"Name,Institution,ORCID,OpenAlex Id",
+		"alice,rice university,,",
+		"ambiguous,rice university,,",
+		"unknown,rice university,,",
+		"bob,,,",
+		",,https://orcid.org/0000-0001-0002-0003,",
+		"carol,,0000-0000-0000-0000,",
+		"dave,,,A123",
+		"",
+		"erin," + mockUnknownInstitution + ",,",
+		"frank,,,A404",
+	}, "\n")
+
+	if _, err := createAuthorReportBatch(backend, newUserWithRole(auth.RoleViewer), "roster.csv", []byte(roster)); err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Fatalf("viewers should not be able to create batches: %v", err)
+	}
+	if _, err := createAuthorReportBatch(backend, user1, "roster.txt", []byte(roster)); err == nil || !strings.Contains(err.Error(), "unsupported file type") {
+		t.Fatalf("expected file type error: %v", err)
+	}
+	if _, err := createAuthorReportBatch(backend, user1, "roster.csv", []byte("Institution\nrice university")); err == nil || !strings.Contains(err.Error(), "name column") {
+		t.Fatalf("expected missing column error: %v", err)
+	}
+
+	batch, err := createAuthorReportBatch(backend, user1, "roster.csv", []byte(roster))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type expectedRow struct {
+		row      int
+		status   string
+		authorId string
+	}
+	expected := []expectedRow{
+		{2, schema.BatchRowCreated, "alice-id"},
+		{3, schema.BatchRowAmbiguous, ""},
+		{4, schema.BatchRowUnresolved, ""},
+		{5, schema.BatchRowUnresolved, ""},
+		{6, schema.BatchRowCreated, "orcid-author-id"},
+		{7, schema.BatchRowUnresolved, ""},
+		{8, schema.BatchRowCreated, "https://openalex.org/A123"},
+		{10, schema.BatchRowUnresolved, ""},
+		{11, schema.BatchRowUnresolved, ""},
+	}
+
+	if batch.Name != "roster" || batch.LookbackYears != 3 || len(batch.Rows) != len(expected) {
+		t.Fatalf("incorrect batch: %+v", batch)
+	}
+	for i, row := range batch.Rows {
+		if row.Row != expected[i].row || row.Status != expected[i].status {
+			t.Fatalf("incorrect row %d: %+v", i, row)
+		}
+		if expected[i].authorId != "" && (row.Report == nil || row.Report.AuthorId != expected[i].authorId || row.Report.Status != schema.ReportQueued) {
+			t.Fatalf("incorrect report for row %d: %+v", i, row.Report)
+		}
+		if expected[i].authorId == "" && (row.Report != nil || row.Message == "") {
+			t.Fatalf("row %d should have a message and no report: %+v", i, row)
+		}
+	}
+	if len(batch.Rows[1].Candidates) != 2 || batch.Rows[1].Candidates[0].AuthorId != "https://openalex.org/A1" {
+		t.Fatalf("incorrect candidates: %+v", batch.Rows[1].Candidates)
+	}
+	if batch.Rows[6].Report.AuthorName != "https://openalex.org/A123-name" || batch.Rows[6].Report.Affiliations != "https://openalex.org/A123-affiliation1, https://openalex.org/A123-affiliation2" {
+		t.Fatalf("incorrect report for OpenAlex id: %+v", batch.Rows[6].Report)
+	}
+
+	// The reports created for the batch are regular reports of the user.
+	var listed []api.Report
+	if err := Get(backend, "/report/author/list", user1, &listed); err != nil || len(listed) != 3 {
+		t.Fatalf("expected 3 reports: %v, %v", listed, err)
+	}
+
+	// Ambiguous rows are resolved by picking one of the candidates.
+	resolve := func(user string, row int, authorId string) (api.CreateReportResponse, error) {
+		var res api.CreateReportResponse
+		err := Post(backend, fmt.Sprintf("/report/batch/%s/rows/%d/resolve", batch.Id, row), user, api.ResolveAuthorReportBatchRowRequest{AuthorId: authorId}, &res)
+		return res, err
+	}
+
+	if _, err := resolve(user2, 3, "A2"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("other users should not be able to resolve rows: %v", err)
+	}
+	if _, err := resolve(user1, 99, "A2"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected row not found: %v", err)
+	}
+	resolved, err := resolve(user1, 3, "A2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolve(user1, 3, "A1"); err == nil || !strings.Contains(err.Error(), "status 409") {
+		t.Fatalf("expected conflict for row with report: %v", err)
+	}
+
+	var updated api.AuthorReportBatch
+	if err := Get(backend, "/report/batch/"+batch.Id.String(), user1, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if row := updated.Rows[1]; row.Status != schema.BatchRowCreated || row.Report == nil || row.Report.Id != resolved.Id || row.Report.AuthorId != "https://openalex.org/A2" || row.Candidates != nil {
+		t.Fatalf("incorrect resolved row: %+v", row)
+	}
+
+	if err := Get(backend, "/report/batch/"+batch.Id.String(), user2, nil); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("other users should not be able to view the batch: %v", err)
+	}
+
+	var batches []api.AuthorReportBatch
+	if err := Get(backend, "/report/batch/list", user1, &batches); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 1 || batches[0].Rows != nil || batches[0].RowStatuses[schema.BatchRowCreated] != 4 ||
+		batches[0].RowStatuses[schema.BatchRowUnresolved] != 5 || batches[0].ReportStatuses[schema.ReportQueued] != 4 {
+		t.Fatalf("incorrect batches: %+v", batches)
+	}
+
+	// Only completed reports are included in the download.
+	manager := reports.NewManager(db)
+	next, err := manager.GetNextAuthorReport()
+	if err != nil || next == nil {
+		t.Fatalf("expected next report: %v", err)
+	}
+	if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, time.Now(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/report/batch/%s/download?format=xlsx", batch.Id), nil)
+	req.Header.Add("Authorization", "Bearer "+user1)
+	w := httptest.NewRecorder()
+	backend.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("download failed with status %d: %s", w.Code, w.Body.String())
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.File) != 2 || archive.File[0].Name != "Summary.csv" || !strings.HasSuffix(archive.File[1].Name, "Report.xlsx") {
+		t.Fatalf("incorrect archive files: %v", archive.File)
+	}
+
+	summary, err := archive.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(summary).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(expected)+1 || records[1][0] != "2" || records[1][7] != "alice-name" {
+		t.Fatalf("incorrect summary: %v", records)
+	}
+
+	// Deleting the batch keeps the reports.
+	if err := Delete(backend, "/report/batch/"+batch.Id.String(), user2); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("other users should not be able to delete the batch: %v", err)
+	}
+	if err := Delete(backend, "/report/batch/"+batch.Id.String(), user1); err != nil {
+		t.Fatal(err)
+	}
+	if err := Get(backend, "/report/batch/list", user1, &batches); err != nil || len(batches) != 0 {
+		t.Fatalf("expected no batches: %v, %v", batches, err)
+	}
+	if err := Get(backend, "/report/author/list", user1, &listed); err != nil || len(listed) != 4 {
+		t.Fatalf("expected 4 reports: %v, %v", listed, err)
+	}
+}
+
+func TestUnstructuredAuthorReport(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user1, user2 := newUser(), newUser()
+
+	create := func(user, text string) (api.CreateReportResponse, error) {
+		req := api.CreateAuthorReportRequest{
+			AuthorName:       "unstructured-name",
+			Source:           api.UnstructuredSource,
+			UnstructuredText: text,
+		}
+		var res api.CreateReportResponse
+		err := Post(backend, "/report/author/create", user, req, &res)
+		return res, err
+	}
+
+	if _, err := create(user1, "  "); err == nil || !strings.Contains(err.Error(), "UnstructuredText must be specified") {
+		t.Fatalf("expected error for missing text: %v", err)
+	}
+
+	cv := "Publications: Quantum Widgets for Everyone (2021); Scaling Widgets (2022)"
+
+	report1, err := create(user1, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report2, err := create(user1, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report1.Id != report2.Id {
+		t.Fatal("same text should reuse the same report for a user")
+	}
+
+	report3, err := create(user2, cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data1, err := getAuthorReport(backend, user1, report1.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data3, err := getAuthorReport(backend, user2, report3.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data1.AuthorId != data3.AuthorId || !strings.HasPrefix(data1.AuthorId, "unstructured:") ||
+		data1.AuthorName != "unstructured-name" || data1.Source != api.UnstructuredSource {
+		t.Fatalf("invalid unstructured report: %+v", data1)
+	}
+
+	report4, err := create(user1, cv+"; Another Widget Paper (2023)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data4, err := getAuthorReport(backend, user1, report4.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data4.AuthorId == data1.AuthorId {
+		t.Fatal("different text should create a different report")
+	}
+}
+
+func TestOrcidAuthorReport(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user1, user2 := newUser(), newUser()
+
+	create := func(user, orcid, source string) (api.CreateReportResponse, error) {
+		req := api.CreateAuthorReportRequest{Orcid: orcid, Source: source}
+		var res api.CreateReportResponse
+		err := Post(backend, "/report/author/create", user, req, &res)
+		return res, err
+	}
+
+	if _, err := create(user1, "1234", ""); err == nil || !strings.Contains(err.Error(), "invalid ORCID") {
+		t.Fatalf("expected error for invalid orcid: %v", err)
+	}
+
+	if _, err := create(user1, "0000-0002-1825-0097", api.GoogleScholarSource); err == nil || !strings.Contains(err.Error(), "status 422") {
+		t.Fatalf("expected error for non openalex source: %v", err)
+	}
+
+	if _, err := create(user1, mockUnknownOrcid, ""); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected error for unknown orcid: %v", err)
+	}
+
+	// This report is for the same author as the ORCID, but created from the
+	// author name search.
+	report1, err := createAuthorReport(backend, user1, "orcid-author")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report2, err := create(user2, "https://orcid.org/0000-0002-1825-0097", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data1, err := getAuthorReport(backend, user1, report1.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data2, err := getAuthorReport(backend, user2, report2.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range []api.Report{data1, data2} {
+		if data.AuthorId != "orcid-author-id" || data.AuthorName != "orcid-author-name" ||
+			data.Source != api.OpenAlexSource || data.Orcid != "0000-0002-1825-0097" {
+			t.Fatalf("invalid orcid report: %+v", data)
+		}
+	}
+}
+
+func TestMergedAuthorReport(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user := newUser()
+
+	create := func(identities []api.AuthorIdentity) (api.CreateReportResponse, error) {
+		req := api.CreateAuthorReportRequest{
+			AuthorName: "merged-name",
+			Source:     api.MergedSource,
+			Identities: identities,
+		}
+		var res api.CreateReportResponse
+		err := Post(backend, "/report/author/create", user, req, &res)
+		return res, err
+	}
+
+	if _, err := create([]api.AuthorIdentity{{AuthorId: "a-id", Source: api.OpenAlexSource}, {AuthorId: "a-id", Source: api.OpenAlexSource}}); err == nil || !strings.Contains(err.Error(), "at least 2 distinct Identities") {
+		t.Fatalf("expected error for single identity: %v", err)
+	}
+
+	if _, err := create([]api.AuthorIdentity{{AuthorId: "a-id", Source: api.OpenAlexSource}, {AuthorId: "b-id", Source: api.UnstructuredSource}}); err == nil || !strings.Contains(err.Error(), "invalid Source") {
+		t.Fatalf("expected error for unstructured identity: %v", err)
+	}
+
+	report1, err := create([]api.AuthorIdentity{
+		{AuthorId: "b-id", Source: api.OpenAlexSource},
+		{AuthorId: "7004212771", Source: api.ScopusSource},
+		{AuthorId: "a-id", Source: api.OpenAlexSource},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same identities in a different order should reuse the report.
+	report2, err := create([]api.AuthorIdentity{
+		{AuthorId: "a-id", Source: api.OpenAlexSource},
+		{AuthorId: "b-id", Source: api.OpenAlexSource},
+		{AuthorId: "7004212771", Source: api.ScopusSource},
+		{AuthorId: "a-id", Source: api.OpenAlexSource},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report1.Id != report2.Id {
+		t.Fatal("same identities should reuse the same report")
+	}
+
+	data, err := getAuthorReport(backend, user, report1.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedIdentities := []api.AuthorIdentity{
+		{AuthorId: "a-id", Source: api.OpenAlexSource},
+		{AuthorId: "b-id", Source: api.OpenAlexSource},
+		{AuthorId: "7004212771", Source: api.ScopusSource},
+	}
+
+	if !strings.HasPrefix(data.AuthorId, "merged:") || data.Source != api.MergedSource ||
+		data.Affiliations != "a-id-affiliation1, a-id-affiliation2, b-id-affiliation1, b-id-affiliation2, Rice University" ||
+		data.ResearchInterests != "a-id-interest1, a-id-interest2, b-id-interest1, b-id-interest2, Computer Science, Mathematics" ||
+		!slices.Equal(data.Identities, expectedIdentities) {
+		t.Fatalf("invalid merged report: %+v", data)
+	}
+}
+
+func TestAuthorReportLookbackYears(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user := newUser()
+
+	create := func(lookbackYears int) (api.CreateReportResponse, error) {
+		req := api.CreateAuthorReportRequest{
+			AuthorId:      "lookback-id",
+			AuthorName:    "lookback-name",
+			Source:        api.OpenAlexSource,
+			LookbackYears: lookbackYears,
+		}
+		var res api.CreateReportResponse
+		err := Post(backend, "/report/author/create", user, req, &res)
+		return res, err
+	}
+
+	for _, invalid := range []int{-1, 100} {
+		if _, err := create(invalid); err == nil || !strings.Contains(err.Error(), "LookbackYears must be between") {
+			t.Fatalf("expected error for lookback of %d years: %v", invalid, err)
+		}
+	}
+
+	allTime, err := create(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recent, err := create(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if allTime.Id == recent.Id {
+		t.Fatal("reports with different lookback windows should be distinct")
+	}
+
+	data, err := getAuthorReport(backend, user, recent.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.AuthorId != "lookback-id" || data.LookbackYears != 5 {
+		t.Fatalf("invalid report: %+v", data)
+	}
+}
+
+func TestFlagTriage(t *testing.T) {
+	backend, db := createBackend(t)
+
+	user1, user2 := newUser(), newUser()
+
+	reportResp, err := createAuthorReport(backend, user1, "triage-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := reports.NewManager(db)
+
+	content := func() []api.Flag {
+		return []api.Flag{
+			&api.HighRiskFunderFlag{
+				Message: "Test High Risk Funder",
+				Work:    api.WorkSummary{WorkId: "work-1", PublicationDate: time.Now()},
+				Funders: []string{"funder"},
+			},
+		}
+	}
+
+	next, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, time.Now(), content()); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := getAuthorReport(backend, user1, reportResp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flags := report.Content[api.HighRiskFunderType]
+	if len(flags) != 1 || flags[0].GetTriage() != nil {
+		t.Fatalf("incorrect report content: %v", report.Content)
+	}
+	flagHash := flags[0].(*api.HighRiskFunderFlag).FlagHash
+	endpoint := fmt.Sprintf("/report/author/%s/flags/%s", reportResp.Id, flagHash)
+
+	if err := Put(backend, endpoint, user1, api.UpdateFlagTriageRequest{Status: "unknown"}, nil); err == nil || !strings.Contains(err.Error(), "Status must be one of") {
+		t.Fatalf("expected error for invalid status: %v", err)
+	}
+
+	missingFlag := fmt.Sprintf("/report/author/%s/flags/%s", reportResp.Id, strings.Repeat("0", 64))
+	if err := Put(backend, missingFlag, user1, api.UpdateFlagTriageRequest{Status: schema.TriageConfirmed}, nil); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected error for missing flag: %v", err)
+	}
+
+	if err := Put(backend, endpoint, user2, api.UpdateFlagTriageRequest{Status: schema.TriageConfirmed}, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("other users should not be able to triage flags: %v", err)
+	}
+
+	var triage api.FlagTriage
+	if err := Put(backend, endpoint, user1, api.UpdateFlagTriageRequest{Status: schema.TriageDismissed, Note: "different author"}, &triage); err != nil {
+		t.Fatal(err)
+	}
+
+	if triage.Status != schema.TriageDismissed || triage.Note != "different author" || triage.ReviewerId.String() != user1[len(userPrefix):] {
+		t.Fatalf("incorrect triage: %+v", triage)
+	}
+
+	// The triage should be kept when the report is refreshed.
+	if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, time.Now(), content()); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = getAuthorReport(backend, user1, reportResp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flagTriage := report.Content[api.HighRiskFunderType][0].GetTriage()
+	if flagTriage == nil || flagTriage.Status != schema.TriageDismissed || flagTriage.Note != "different author" {
+		t.Fatalf("incorrect triage in report: %+v", flagTriage)
+	}
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/report/author/%s/download?format=csv", reportResp.Id), bytes.NewBufferString("{}"))
+	req.Header.Add("Authorization", "Bearer "+user1)
+	w := httptest.NewRecorder()
+	backend.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Triage Status,dismissed") {
+		t.Fatalf("triage should be included in download: %s", w.Body.String())
+	}
+
+	if err := Delete(backend, endpoint, user1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Get(backend, endpoint, user1, &triage); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("triage should be deleted: %v", err)
+	}
+}
+
+func TestFlagSuppressionEndpoints(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user := newUserWithRole(auth.RoleAdmin)
+
+	analystReq := api.CreateFlagSuppressionRequest{AuthorId: "1", FlagType: api.TalentContractType, Entity: "entity", Reason: "reason"}
+	if err := Post(backend, "/report/suppressions/create", newUser(), analystReq, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("only admins can create suppressions: %v", err)
+	}
+
+	if err := Post(backend, "/report/suppressions/create", user, analystReq, nil); err == nil || !strings.Contains(err.Error(), "status 422") {
+		t.Fatalf("admins must be in an organization to create suppressions: %v", err)
+	}
+
+	if err := Post(backend, "/organization/create", user, api.CreateOrganizationRequest{Name: "org"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(req api.CreateFlagSuppressionRequest) (api.FlagSuppression, error) {
+		var res api.FlagSuppression
+		err := Post(backend, "/report/suppressions/create", user, req, &res)
+		return res, err
+	}
+
+	invalid := []api.CreateFlagSuppressionRequest{
+		{FlagHash: strings.Repeat("a", 64)},
+		{AuthorId: "1"},
+		{AuthorId: "1", FlagType: api.PotentialAuthorAffiliationType},
+		{AuthorId: "1", FlagType: "unknown", Entity: "University X"},
+		{AuthorId: "1", FlagHash: "abc"},
+		{AuthorId: "1", FlagHash: strings.Repeat("a", 64), Entity: "University X"},
+	}
+	for _, req := range invalid {
+		if _, err := create(req); err == nil || !strings.Contains(err.Error(), "status 422") {
+			t.Fatalf("expected error for invalid suppression %+v: %v", req, err)
+		}
+	}
+
+	suppression, err := create(api.CreateFlagSuppressionRequest{
+		AuthorId: "1", FlagType: api.PotentialAuthorAffiliationType, Entity: "University X", Reason: "different author",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if suppression.AuthorId != "1" || suppression.Entity != "University X" || suppression.CreatedBy.String() != user[len(userPrefix):] {
+		t.Fatalf("incorrect suppression: %+v", suppression)
+	}
+
+	var suppressions []api.FlagSuppression
+	if err := Get(backend, "/report/suppressions/list?author_id=1", user, &suppressions); err != nil {
+		t.Fatal(err)
+	}
+	if len(suppressions) != 1 || suppressions[0].Id != suppression.Id {
+		t.Fatalf("incorrect suppressions: %v", suppressions)
+	}
+
+	// Suppressions are only listed for the members of the organization.
+	if err := Get(backend, "/report/suppressions/list?author_id=1", newUser(), &suppressions); err != nil {
+		t.Fatal(err)
+	}
+	if len(suppressions) != 0 {
+		t.Fatalf("suppressions of other organizations should not be listed: %v", suppressions)
+	}
+
+	if err := Delete(backend, "/report/suppressions/"+suppression.Id.String(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Delete(backend, "/report/suppressions/"+suppression.Id.String(), user); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected error for removed suppression: %v", err)
+	}
+
+	if err := Get(backend, "/report/suppressions/list?author_id=1", user, &suppressions); err != nil {
+		t.Fatal(err)
+	}
+	if len(suppressions) != 0 {
+		t.Fatalf("removed suppressions should not be listed: %v", suppressions)
+	}
+
+	if err := Get(backend, "/report/suppressions/list?author_id=1&include_removed=true", user, &suppressions); err != nil {
+		t.Fatal(err)
+	}
+	if len(suppressions) != 1 || suppressions[0].RemovedBy == nil {
+		t.Fatalf("removed suppressions should be listed with include_removed: %v", suppressions)
+	}
+}
+
+func TestRiskScore(t *testing.T) {
+	backend, db := createBackend(t)
+
+	user := newUser()
+
+	reportResp, err := createAuthorReport(backend, user, "risk-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := reports.NewManager(db)
+
+	next, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateAuthorReport(next.Id, schema.ReportCompleted, time.Now(), []api.Flag{
+		&api.TalentContractFlag{
+			Message: "Test Talent Contract",
+			Work:    api.WorkSummary{WorkId: "work-1", PublicationDate: time.Now()},
+		},
+		&api.PotentialAuthorAffiliationFlag{
+			Message:    "Test Potential Affiliation",
+			University: "university",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := getAuthorReport(backend, user, reportResp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Risk == nil || math.Abs(report.Risk.Score-13) > 0.01 || report.Risk.Tier != reports.RiskTierMedium || len(report.Risk.FlagScores) != 2 {
+		t.Fatalf("incorrect risk score: %+v", report.Risk)
+	}
+	potentialHash := report.Content[api.PotentialAuthorAffiliationType][0].(*api.PotentialAuthorAffiliationFlag).FlagHash
+	if report.Risk.FlagScores[potentialHash] != 3 {
+		t.Fatalf("incorrect flag score: %+v", report.Risk.FlagScores)
+	}
+
+	var weights api.RiskWeights
+	if err := Get(backend, "/report/risk-weights", user, &weights); err != nil {
+		t.Fatal(err)
+	}
+	if weights.FlagTypes[api.TalentContractType] != 10 || weights.HighTierThreshold != 30 {
+		t.Fatalf("expected default weights: %+v", weights)
+	}
+
+	if err := Put(backend, "/report/risk-weights", user, weights, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("only admins can update the weights: %v", err)
+	}
+
+	admin := newUserWithRole(auth.RoleAdmin)
+
+	invalid := reports.DefaultRiskWeights()
+	invalid.MediumTierThreshold = 100
+	if err := Put(backend, "/report/risk-weights", admin, invalid, nil); err == nil || !strings.Contains(err.Error(), "status 422") {
+		t.Fatalf("expected error for invalid weights: %v", err)
+	}
+
+	// Flag types that are not set use the default weight.
+	weights.FlagTypes = map[string]float64{api.TalentContractType: 30}
+	weights.HighTierThreshold = 25
+
+	// The default weights are shared by all users that are not in an
+	// organization, so they cannot be updated.
+	if err := Put(backend, "/report/risk-weights", admin, weights, nil); err == nil || !strings.Contains(err.Error(), "status 422") {
+		t.Fatalf("admins must be in an organization to update the weights: %v", err)
+	}
+
+	var org api.CreateReportResponse
+	if err := Post(backend, "/organization/create", admin, api.CreateOrganizationRequest{Name: "org"}, &org); err != nil {
+		t.Fatal(err)
+	}
+	if err := Post(backend, "/organization/invites", admin, api.InviteOrganizationMemberRequest{Email: userEmail(user)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Post(backend, "/organization/invites/"+org.Id.String()+"/accept", user, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Put(backend, "/report/risk-weights", admin, weights, &weights); err != nil {
+		t.Fatal(err)
+	}
+	if weights.FlagTypes[api.TalentContractType] != 30 || weights.FlagTypes[api.PotentialAuthorAffiliationType] != 3 {
+		t.Fatalf("incorrect updated weights: %+v", weights)
+	}
+
+	// The new weights apply to existing reports.
+	report, err = getAuthorReport(backend, user, reportResp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(report.Risk.Score-33) > 0.01 || report.Risk.Tier != reports.RiskTierHigh {
+		t.Fatalf("incorrect risk score after updating weights: %+v", report.Risk)
+	}
+
+	// Users that are not in the organization still use the default weights.
+	if err := Get(backend, "/report/risk-weights", newUser(), &weights); err != nil {
+		t.Fatal(err)
+	}
+	if weights.FlagTypes[api.TalentContractType] != 10 || weights.HighTierThreshold != 30 {
+		t.Fatalf("expected default weights for user without an organization: %+v", weights)
+	}
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/report/author/%s/download?format=csv", reportResp.Id), bytes.NewBufferString("{}"))
+	req.Header.Add("Authorization", "Bearer "+user)
+	w := httptest.NewRecorder()
+	backend.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Risk Tier,High") || !strings.Contains(w.Body.String(), "Risk Score,3.00") {
+		t.Fatalf("risk score should be included in download: %s", w.Body.String())
+	}
+}
+
+func TestUniversityReportEndpoints(t *testing.T) {
+	backend, db := createBackend(t)
+	manager := reports.NewManager(db)
+
+	user1, user2 := newUser(), newUser()
+
+	uniReportId, err := createUniversityReport(backend, user1, "uni-report1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nextUniReport, err := manager.GetNextUniversityReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextUniReport == nil {
+		t.Fatal("next report should not be nil")
+	}
+
+	if err := manager.UpdateUniversityReport(nextUniReport.Id, schema.ReportCompleted, nextUniReport.UpdateDate, []reports.UniversityAuthorReport{
+		{AuthorId: "1", AuthorName: "author1", Source: api.OpenAlexSource},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	nextAuthorReport, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !nextAuthorReport.ForUniversityReport {
+		t.Fatal("next author report should be for university report")
+	}
+
+	if err := manager.UpdateAuthorReport(nextAuthorReport.Id, schema.ReportCompleted, time.Now(), []api.Flag{
+		&api.HighRiskFunderFlag{Work: api.WorkSummary{WorkId: "abc", PublicationDate: time.Now()}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	uniReport, err := getUniversityReport(backend, user1, uniReportId.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uniReport.UniversityId != "uni-report1-id" || uniReport.UniversityName != "uni-report1-name" || uniReport.UniversityLocation != "uni-report1-location" || uniReport.Status != "complete" || len(uniReport.Content.Flags[api.HighRiskFunderType]) != 1 {
+		t.Fatal("invalid university report returned")
+	}
+
+	if _, err := getUniversityReport(backend, user2, uniReportId.Id); err == nil || !strings.Contains(err.Error(), "user cannot access report") {
+		t.Fatal(err)
+	}
+
+	checkListUniversityReports(t, backend, user1, []string{"uni-report1"})
+
+	if err := deleteUniversityReport(backend, user2, uniReport.Id); err == nil || !strings.Contains(err.Error(), "report not found") {
+		t.Fatal(err)
+	}
+	checkListUniversityReports(t, backend, user1, []string{"uni-report1"})
+
+	if err := deleteUniversityReport(backend, user1, uniReport.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	checkListUniversityReports(t, backend, user1, []string{})
+}
+
+func TestDownloadUniversityReportAllFormats(t *testing.T) {
+	backend, db := createBackend(t)
+	manager := reports.NewManager(db)
+
+	user := newUser()
+
+	uniReportId, err := createUniversityReport(backend, user, "uni-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	download := func(format string) *http.Response {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/report/university/%s/download?format=%s", uniReportId.Id, format), nil)
+		req.Header.Add("Authorization", "Bearer "+user)
+		w := httptest.NewRecorder()
+		backend.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	if res := download("csv"); res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected incomplete report to not be downloadable, got %d", res.StatusCode)
+	}
+
+	nextUniReport, err := manager.GetNextUniversityReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateUniversityReport(nextUniReport.Id, schema.ReportCompleted, nextUniReport.UpdateDate, []reports.UniversityAuthorReport{
+		{AuthorId: "https://openalex.org/A1", AuthorName: "author1", Source: api.OpenAlexSource, Department: "Department of Physics"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	nextAuthorReport, err := manager.GetNextAuthorReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UpdateAuthorReport(nextAuthorReport.Id, schema.ReportCompleted, time.Now(), []api.Flag{
+		&api.TalentContractFlag{Work: api.WorkSummary{WorkId: "abc", PublicationDate: time.Now()}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTypes := map[string]string{
+		"csv":  "text/csv",
+		"pdf":  "application/pdf",
+		"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	}
+	for format, contentType := range expectedTypes {
+		res := download(format)
+		defer res.Body.Close()
+
+		fileBytes, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != contentType || len(fileBytes) == 0 {
+			t.Fatalf("invalid download for format %s: status=%d content-type=%s", format, res.StatusCode, res.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(res.Header.Get("Content-Disposition"), "uni-download-name Report."+format) {
+			t.Fatalf("incorrect filename for format %s: %s", format, res.Header.Get("Content-Disposition"))
+		}
+
+		switch format {
+		case "pdf":
+			if !strings.HasPrefix(string(fileBytes), "%PDF") {
+				t.Fatal("downloaded file does not appear to be a valid PDF")
+			}
+		case "xlsx":
+			f, err := excelize.OpenReader(bytes.NewReader(fileBytes))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Contains(f.GetSheetList(), "Talent Contracts") || !slices.Contains(f.GetSheetList(), "Authors") {
+				t.Fatalf("missing sheets in excel file: %v", f.GetSheetList())
+			}
+			if name, err := f.GetCellValue("Talent Contracts", "A2"); err != nil || name != "author1" {
+				t.Fatalf("expected author in talent contracts sheet, got %s: %v", name, err)
+			}
+			if id, err := f.GetCellValue("Talent Contracts", "H2"); err != nil || id != nextAuthorReport.Id.String() {
+				t.Fatalf("expected author report id, got %s: %v", id, err)
+			}
+		case "csv":
+			// The summary rows have fewer fields than the author rows.
+			reader := csv.NewReader(bytes.NewReader(fileBytes))
+			reader.FieldsPerRecord = -1
+			records, err := reader.ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := records[len(records)-1]
+			if last[0] != "Talent Contracts" || last[1] != "author1" || last[4] != "Department of Physics" || last[5] != "1" || last[8] != nextAuthorReport.Id.String() {
+				t.Fatalf("incorrect csv row: %v", last)
+			}
+		}
+	}
+
+	if res := download("docx"); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected unsupported format to fail, got %d", res.StatusCode)
+	}
+}
+
+func TestAutocompleteAuthor(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user := newUser()
+
+	var results []api.Autocompletion
+	err := mockRequest(backend, "GET", "/autocomplete/author?query="+url.QueryEscape("anshumali shriva"), user, nil, &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) == 0 {
+		t.Fatal("should have some results")
+	}
+
+	for _, res := range results {
+		if !strings.HasPrefix(res.Id, "https://openalex.org/") ||
+			!strings.EqualFold(res.Name, "Anshumali Shrivastava") {
+			t.Fatal("invalid result")
+		}
+	}
+}
+
+func TestAutocompleteInstution(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user := newUser()
+
+	var results []api.Autocompletion
+	err := mockRequest(backend, "GET", "/autocomplete/institution?query="+url.QueryEscape("rice univer"), user, nil, &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) == 0 {
+		t.Fatal("should have some results")
+	}
+
+	for _, res := range results {
+		if !strings.HasPrefix(res.Id, "https://openalex.org/") ||
+			!strings.EqualFold(res.Name, "Rice University") ||
+			!strings.EqualFold(res.Hint, "Houston, USA") {
+			t.Fatal("invalid result")
+		}
+	}
+}
+
+func TestAutocompletePaper(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user := newUser()
+
+	query := "From Research to Production: Towards Scalable and Sustainable Neural Recommendation"
+	var results []api.Autocompletion
+	err := mockRequest(backend, "GET", "/autocomplete/paper?query="+url.QueryEscape(query), user, nil, &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatal("should have 1 result")
 	}
 
 	expectedTitle := "From Research to Production: Towards Scalable and Sustainable Neural Recommendation Models on Commodity CPU Hardware"
@@ -765,6 +2453,27 @@ func TestSearchAuthors(t *testing.T) {
 		}
 	})
 
+	t.Run("Search By ORCID Endpoint", func(t *testing.T) {
+		orcidId := "https://orcid.org/0000-0002-5042-2856"
+
+		url := fmt.Sprintf("/search/authors-by-orcid?orcid=%s", url.QueryEscape(orcidId))
+		var results []api.Author
+		err := mockRequest(backend, "GET", url, user, nil, &results)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results) != 1 || !strings.HasPrefix(results[0].AuthorId, "https://openalex.org/") ||
+			results[0].AuthorName != "Anshumali Shrivastava" ||
+			results[0].Source != "openalex" {
+			t.Fatal("incorrect authors returned")
+		}
+
+		if err := Get(backend, "/search/authors-by-orcid?orcid=1234", user, nil); err == nil || !strings.Contains(err.Error(), "status 400") {
+			t.Fatalf("expected error for invalid orcid: %v", err)
+		}
+	})
+
 	t.Run("Search By Paper Title", func(t *testing.T) {
 		title := "From Research to Production: Towards Scalable and Sustainable Neural Recommendation Models on Commodity CPU Hardware"
 
@@ -879,6 +2588,73 @@ func createReportHook(backend http.Handler, reportId uuid.UUID, user string, pay
 	}, nil)
 }
 
+func TestSearchScopusAuthors(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user := newUser()
+
+	if err := Get(backend, "/search/authors-scopus", user, nil); err == nil || !strings.Contains(err.Error(), "author_name must be specified") {
+		t.Fatalf("expected error for missing author_name: %v", err)
+	}
+
+	var results api.ScopusSearchResults
+	if err := Get(backend, "/search/authors-scopus?author_name=shrivastava", user, &results); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results.Authors) != 2 || results.Cursor != "" {
+		t.Fatalf("incorrect authors returned: %+v", results)
+	}
+
+	if results.Authors[0].AuthorId != "7004212771" ||
+		results.Authors[0].AuthorName != "Anshumali Shrivastava" ||
+		!slices.Equal(results.Authors[0].Institutions, []string{"Rice University"}) ||
+		results.Authors[0].Source != api.ScopusSource {
+		t.Fatal("incorrect authors returned")
+	}
+
+	if err := Get(backend, "/search/authors-scopus?author_name=shrivastava&cursor=abc", user, nil); err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Fatalf("expected error for invalid cursor: %v", err)
+	}
+}
+
+func TestScopusAuthorReport(t *testing.T) {
+	backend, _ := createBackend(t)
+
+	user := newUser()
+
+	create := func(authorId string) (api.CreateReportResponse, error) {
+		req := api.CreateAuthorReportRequest{
+			AuthorId:   authorId,
+			AuthorName: "Anshumali Shrivastava",
+			Source:     api.ScopusSource,
+		}
+		var res api.CreateReportResponse
+		err := Post(backend, "/report/author/create", user, req, &res)
+		return res, err
+	}
+
+	if _, err := create("123"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected error for unknown scopus author: %v", err)
+	}
+
+	report, err := create("7004212771")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := getAuthorReport(backend, user, report.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.AuthorId != "7004212771" || data.Source != api.ScopusSource ||
+		data.Affiliations != "Rice University" ||
+		data.ResearchInterests != "Computer Science, Mathematics" {
+		t.Fatalf("invalid scopus report: %+v", data)
+	}
+}
+
 func TestHooks(t *testing.T) {
 	db := schema.SetupTestDB(t)
 
@@ -894,12 +2670,17 @@ func TestHooks(t *testing.T) {
 	manager := reports.NewManager(db).SetAuthorReportUpdateInterval(2 * time.Second)
 
 	hookService := services.NewHookService(db, map[string]services.Hook{"test": mockHook}, 1*time.Second)
+	audit := services.NewAuditLog(db)
 
 	backend := services.NewBackend(
-		services.NewReportService(manager, licensing, &mockOpenAlex{}, "./resources"),
-		services.NewSearchService(oa, nil),
-		services.NewAutoCompleteService(oa),
+		services.NewReportService(manager, licensing, &mockOpenAlex{}, nil, "./resources"),
+		services.NewSearchService(oa, nil, nil, manager, nil),
+		services.NewAutoCompleteService(oa, nil),
 		hookService,
+		services.NewOrganizationService(manager),
+		services.NewAdminService(manager, audit),
+		services.NewUsageService(manager),
+		audit,
 		&MockTokenVerifier{prefix: userPrefix},
 	)
 
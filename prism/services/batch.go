@@ -0,0 +1,678 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"prism/prism/api"
+	"prism/prism/openalex"
+	"prism/prism/reports"
+	"prism/prism/schema"
+	"prism/prism/services/auth"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	maxRosterRows        = 500
+	maxBatchNameLength   = 200
+	rosterResolveWorkers = 10
+)
+
+// The accepted headers for each roster column, headers are compared after
+// removing everything except letters and digits and converting to lowercase.
+var rosterColumnHeaders = map[string][]string{
+	"name":        {"name", "author", "authorname", "fullname"},
+	"institution": {"institution", "affiliation", "university", "institutionname"},
+	"orcid":       {"orcid", "orcidid"},
+	"openalex":    {"openalexid", "openalex", "openalexauthorid", "authorid"},
+}
+
+func normalizeRosterHeader(header string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, header)
+}
+
+type rosterRecord struct {
+	row    int
+	fields []string
+}
+
+// Returns the records of the roster file along with their row number in the
+// file, the header is row 1.
+func readRosterRecords(fileHeader *multipart.FileHeader) ([]rosterRecord, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("error opening uploaded roster", "filename", fileHeader.Filename, "error", err)
+		return nil, errors.New("unable to open file")
+	}
+	defer file.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(fileHeader.Filename)); ext {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+
+		records := make([]rosterRecord, 0)
+		for {
+			fields, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid csv file: %w", err)
+			}
+			row, _ := reader.FieldPos(0)
+			records = append(records, rosterRecord{row: row, fields: fields})
+		}
+		return records, nil
+	case ".xlsx":
+		fileBytes, err := io.ReadAll(file)
+		if err != nil {
+			slog.Error("error reading uploaded roster", "filename", fileHeader.Filename, "error", err)
+			return nil, errors.New("unable to read file")
+		}
+
+		xlsx, err := excelize.OpenReader(bytes.NewReader(fileBytes))
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx file: %w", err)
+		}
+		defer xlsx.Close()
+
+		sheets := xlsx.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("invalid xlsx file: no sheets found")
+		}
+
+		// Only the first sheet is read.
+		rows, err := xlsx.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx file: error reading sheet '%s': %w", sheets[0], err)
+		}
+
+		records := make([]rosterRecord, 0, len(rows))
+		for i, fields := range rows {
+			records = append(records, rosterRecord{row: i + 1, fields: fields})
+		}
+		return records, nil
+	default:
+		return nil, fmt.Errorf("unsupported file type '%s', expected .csv or .xlsx", ext)
+	}
+}
+
+// Parses the roster records into rows, the first non blank record must be the
+// header. Blank rows are skipped.
+func parseRoster(records []rosterRecord) ([]reports.BatchRosterRow, error) {
+	isBlank := func(fields []string) bool {
+		for _, field := range fields {
+			if strings.TrimSpace(field) != "" {
+				return false
+			}
+		}
+		return true
+	}
+
+	for len(records) > 0 && isBlank(records[0].fields) {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, errors.New("roster is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0].fields {
+		normalized := normalizeRosterHeader(header)
+		for column, headers := range rosterColumnHeaders {
+			for _, h := range headers {
+				if normalized == h {
+					if _, ok := columns[column]; ok {
+						return nil, fmt.Errorf("roster has multiple columns for %s", column)
+					}
+					columns[column] = i
+				}
+			}
+		}
+	}
+
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("roster must have a header row with a name column")
+	}
+
+	field := func(fields []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	rows := make([]reports.BatchRosterRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if isBlank(record.fields) {
+			continue
+		}
+		if len(rows) == maxRosterRows {
+			return nil, fmt.Errorf("roster cannot have more than %d rows", maxRosterRows)
+		}
+		rows = append(rows, reports.BatchRosterRow{
+			Row:         record.row,
+			Name:        field(record.fields, "name"),
+			Institution: field(record.fields, "institution"),
+			Orcid:       field(record.fields, "orcid"),
+			OpenAlexId:  field(record.fields, "openalex"),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("roster has no rows")
+	}
+
+	return rows, nil
+}
+
+var (
+	openalexAuthorIdRe      = regexp.MustCompile(`^A\d+$`)
+	openalexInstitutionIdRe = regexp.MustCompile(`^I\d+$`)
+)
+
+// Returns the full OpenAlex url for an id such as A1234 or https://openalex.org/A1234,
+// this is the form that is used for the ids of OpenAlex reports.
+func parseOpenAlexId(id string, re *regexp.Regexp) (string, bool) {
+	id = strings.TrimSpace(id)
+	for _, prefix := range []string{"https://openalex.org/", "http://openalex.org/", "openalex.org/"} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	id = strings.ToUpper(id)
+
+	if !re.MatchString(id) {
+		return "", false
+	}
+	return "https://openalex.org/" + id, true
+}
+
+func batchAuthor(author openalex.Author, orcid string) *reports.BatchAuthor {
+	return &reports.BatchAuthor{
+		AuthorId:          author.AuthorId,
+		AuthorName:        author.DisplayName,
+		Orcid:             orcid,
+		Affiliations:      strings.Join(author.InstitutionNames(), ", "),
+		ResearchInterests: strings.Join(author.Concepts, ", "),
+	}
+}
+
+func unresolvedRow(row *reports.BatchRosterRow, format string, args ...any) {
+	row.Status = schema.BatchRowUnresolved
+	row.Message = fmt.Sprintf(format, args...)
+}
+
+func failedRow(row *reports.BatchRosterRow, err error) {
+	slog.Error("error resolving roster row", "row", row.Row, "error", err)
+	row.Status = schema.BatchRowFailed
+	row.Message = "unable to search OpenAlex, the row can be resolved manually"
+}
+
+// Resolves the row to an OpenAlex author. The OpenAlex id is used if present,
+// followed by the ORCID, and finally the name and institution. The institutions
+// map the institutions in the roster to their OpenAlex ids.
+func (s *ReportService) resolveRosterRow(row *reports.BatchRosterRow, institutions map[string]string) {
+	var orcid string
+	if row.Orcid != "" {
+		parsed, err := parseOrcid(row.Orcid)
+		if err != nil {
+			unresolvedRow(row, "%s", err.Error())
+			return
+		}
+		orcid = parsed
+	}
+
+	if row.OpenAlexId != "" {
+		authorId, ok := parseOpenAlexId(row.OpenAlexId, openalexAuthorIdRe)
+		if !ok {
+			unresolvedRow(row, "invalid OpenAlex id '%s', expected format A0000000000", row.OpenAlexId)
+			return
+		}
+
+		author, err := s.openalex.GetAuthor(authorId)
+		if err != nil {
+			if errors.Is(err, openalex.ErrAuthorNotFound) {
+				unresolvedRow(row, "no author found for OpenAlex id '%s'", row.OpenAlexId)
+			} else {
+				failedRow(row, err)
+			}
+			return
+		}
+		row.Author = batchAuthor(author, orcid)
+		return
+	}
+
+	if orcid != "" {
+		author, err := s.openalex.FindAuthorByOrcidId(orcid)
+		if err != nil {
+			if errors.Is(err, openalex.ErrAuthorNotFound) {
+				unresolvedRow(row, "no author found for ORCID '%s'", orcid)
+			} else {
+				failedRow(row, err)
+			}
+			return
+		}
+		row.Author = batchAuthor(author, orcid)
+		return
+	}
+
+	if row.Name == "" {
+		unresolvedRow(row, "a name, ORCID, or OpenAlex id must be specified")
+		return
+	}
+	if row.Institution == "" {
+		unresolvedRow(row, "an institution must be specified to search for the author by name")
+		return
+	}
+
+	institutionId := institutions[row.Institution]
+	if institutionId == "" {
+		unresolvedRow(row, "no institution found matching '%s'", row.Institution)
+		return
+	}
+
+	authors, err := s.openalex.FindAuthors(row.Name, institutionId)
+	if err != nil {
+		failedRow(row, err)
+		return
+	}
+
+	switch len(authors) {
+	case 0:
+		unresolvedRow(row, "no author found matching '%s' at '%s'", row.Name, row.Institution)
+	case 1:
+		row.Author = batchAuthor(authors[0], "")
+	default:
+		row.Status = schema.BatchRowAmbiguous
+		row.Message = fmt.Sprintf("%d authors found matching '%s' at '%s'", len(authors), row.Name, row.Institution)
+		row.Candidates = make([]api.Author, 0, len(authors))
+		for _, author := range authors {
+			row.Candidates = append(row.Candidates, api.Author{
+				AuthorId:     author.AuthorId,
+				AuthorName:   author.DisplayName,
+				Institutions: author.InstitutionNames(),
+				Source:       api.OpenAlexSource,
+				Interests:    author.Concepts,
+			})
+		}
+	}
+}
+
+// Returns the OpenAlex id of each institution in the roster, institutions that
+// cannot be found are omitted. Each institution is only searched for once since
+// rosters usually contain many authors from the same institutions.
+func (s *ReportService) resolveRosterInstitutions(rows []reports.BatchRosterRow) map[string]string {
+	institutions := make(map[string]string)
+	searched := make(map[string]bool)
+
+	for _, row := range rows {
+		if row.Institution == "" || row.OpenAlexId != "" || row.Orcid != "" || searched[row.Institution] {
+			continue
+		}
+		searched[row.Institution] = true
+
+		if id, ok := parseOpenAlexId(row.Institution, openalexInstitutionIdRe); ok {
+			institutions[row.Institution] = id
+			continue
+		}
+
+		results, err := s.openalex.AutocompleteInstitution(row.Institution)
+		if err != nil {
+			slog.Error("error searching for roster institution", "institution", row.Institution, "error", err)
+			continue
+		}
+		if len(results) > 0 {
+			institutions[row.Institution] = results[0].Id
+		}
+	}
+
+	return institutions
+}
+
+func (s *ReportService) resolveRoster(rows []reports.BatchRosterRow) {
+	institutions := s.resolveRosterInstitutions(rows)
+
+	queue := make(chan int, len(rows))
+	for i := range rows {
+		queue <- i
+	}
+	close(queue)
+
+	wg := sync.WaitGroup{}
+	for range min(rosterResolveWorkers, len(rows)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				s.resolveRosterRow(&rows[i], institutions)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *ReportService) CreateAuthorReportBatch(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	fileHeaders := r.MultipartForm.File["file"]
+	if len(fileHeaders) != 1 {
+		return nil, CodedError(errors.New("exactly one roster file must be uploaded"), http.StatusBadRequest)
+	}
+
+	lookbackYears := 0
+	if value := r.FormValue("lookback_years"); value != "" {
+		lookbackYears, err = strconv.Atoi(value)
+		if err != nil {
+			return nil, CodedError(errors.New("invalid value for lookback_years"), http.StatusBadRequest)
+		}
+	}
+	if err := checkLookbackYears(lookbackYears); err != nil {
+		return nil, CodedError(err, http.StatusUnprocessableEntity)
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = strings.TrimSuffix(fileHeaders[0].Filename, filepath.Ext(fileHeaders[0].Filename))
+	}
+	if len(name) > maxBatchNameLength {
+		return nil, CodedError(fmt.Errorf("name cannot exceed %d characters", maxBatchNameLength), http.StatusUnprocessableEntity)
+	}
+
+	records, err := readRosterRecords(fileHeaders[0])
+	if err != nil {
+		return nil, CodedError(err, http.StatusUnprocessableEntity)
+	}
+
+	rows, err := parseRoster(records)
+	if err != nil {
+		return nil, CodedError(err, http.StatusUnprocessableEntity)
+	}
+
+	if err := s.licensing.VerifyLicense(); err != nil {
+		slog.Error("cannot create new report batch, unable to verify license", "error", err)
+		return nil, CodedError(err, licensingErrorStatus(err))
+	}
+
+	s.resolveRoster(rows)
+
+	batchId, err := s.manager.CreateAuthorReportBatch(userId, name, lookbackYears, rows)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	batch, err := s.manager.GetAuthorReportBatch(userId, batchId)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return batch, nil
+}
+
+func (s *ReportService) ListAuthorReportBatches(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	batches, err := s.manager.ListAuthorReportBatches(userId)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return batches, nil
+}
+
+func (s *ReportService) GetAuthorReportBatch(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	batchId, err := URLParamUUID(r, "batch_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	batch, err := s.manager.GetAuthorReportBatch(userId, batchId)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return batch, nil
+}
+
+func (s *ReportService) ResolveAuthorReportBatchRow(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	batchId, err := URLParamUUID(r, "batch_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	row, err := strconv.Atoi(chi.URLParam(r, "row"))
+	if err != nil {
+		return nil, CodedError(fmt.Errorf("invalid row '%s'", chi.URLParam(r, "row")), http.StatusBadRequest)
+	}
+
+	params, err := ParseRequestBody[api.ResolveAuthorReportBatchRowRequest](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	authorId, ok := parseOpenAlexId(params.AuthorId, openalexAuthorIdRe)
+	if !ok {
+		return nil, CodedError(fmt.Errorf("invalid OpenAlex id '%s', expected format A0000000000", params.AuthorId), http.StatusUnprocessableEntity)
+	}
+
+	if err := s.licensing.VerifyLicense(); err != nil {
+		slog.Error("cannot create new report, unable to verify license", "error", err)
+		return nil, CodedError(err, licensingErrorStatus(err))
+	}
+
+	author, err := s.openalex.GetAuthor(authorId)
+	if err != nil {
+		if errors.Is(err, openalex.ErrAuthorNotFound) {
+			return nil, CodedError(fmt.Errorf("no author found for OpenAlex id '%s'", params.AuthorId), http.StatusNotFound)
+		}
+		slog.Error("error getting author details", "author_id", authorId, "error", err)
+		return nil, CodedError(errors.New("unable to get author details"), http.StatusInternalServerError)
+	}
+	reportId, err := s.manager.ResolveAuthorReportBatchRow(userId, batchId, row, *batchAuthor(author, ""))
+	if err != nil {
+		if errors.Is(err, reports.ErrBatchRowHasReport) {
+			return nil, CodedError(err, http.StatusConflict)
+		}
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	setAuditReportId(r, reportId)
+
+	return api.CreateReportResponse{Id: reportId}, nil
+}
+
+func (s *ReportService) DeleteAuthorReportBatch(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	batchId, err := URLParamUUID(r, "batch_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if err := s.manager.DeleteAuthorReportBatch(userId, batchId); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
+var batchFilenameRe = regexp.MustCompile(`[/\\:*?"<>|]+`)
+
+func generateBatchSummaryCSV(batch api.AuthorReportBatch) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	headers := []string{"Row", "Name", "Institution", "ORCID", "OpenAlex Id", "Status", "Message", "Report Author", "Report Author Id", "Report Status"}
+	if err := writer.Write(headers); err != nil {
+		return nil, err
+	}
+
+	for _, row := range batch.Rows {
+		record := []string{strconv.Itoa(row.Row), row.Name, row.Institution, row.Orcid, row.OpenAlexId, row.Status, row.Message, "", "", ""}
+		if row.Report != nil {
+			record[7], record[8], record[9] = row.Report.AuthorName, row.Report.AuthorId, row.Report.Status
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Downloads a zip file with a summary of the rows of the batch and each of the
+// completed reports in the requested format.
+func (s *ReportService) DownloadAuthorReportBatch(w http.ResponseWriter, r *http.Request) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	batchId, err := URLParamUUID(r, "batch_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	var generate func(api.Report) ([]byte, error)
+	var ext string
+	switch format {
+	case "csv":
+		generate, ext = generateCSV, "csv"
+	case "pdf":
+		generate = func(report api.Report) ([]byte, error) {
+			return generatePDF(report, s.resourceFolder, false, "")
+		}
+		ext = "pdf"
+	case "excel", "xlsx":
+		generate, ext = generateExcel, "xlsx"
+	default:
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := s.manager.GetAuthorReportBatch(userId, batchId)
+	if err != nil {
+		http.Error(w, err.Error(), reportErrorStatus(err))
+		return
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	addFile := func(name string, content []byte) error {
+		file, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(content)
+		return err
+	}
+
+	summary, err := generateBatchSummaryCSV(batch)
+	if err != nil {
+		slog.Error("error generating batch summary", "batch_id", batchId, "error", err)
+		http.Error(w, "error generating batch summary", http.StatusInternalServerError)
+		return
+	}
+	if err := addFile("Summary.csv", summary); err != nil {
+		slog.Error("error writing batch summary to archive", "batch_id", batchId, "error", err)
+		http.Error(w, "error generating archive", http.StatusInternalServerError)
+		return
+	}
+
+	for _, row := range batch.Rows {
+		if row.Report == nil || row.Report.Status != schema.ReportCompleted {
+			continue
+		}
+
+		report, err := s.manager.GetAuthorReport(userId, row.Report.Id)
+		if err != nil {
+			http.Error(w, err.Error(), reportErrorStatus(err))
+			return
+		}
+
+		fileBytes, err := generate(report)
+		if err != nil {
+			slog.Error("error generating batch report download", "batch_id", batchId, "report_id", report.Id, "format", format, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// The row is included in the filename since the same author can appear
+		// in multiple rows.
+		filename := fmt.Sprintf("Row %d - %s Report.%s", row.Row, batchFilenameRe.ReplaceAllString(report.AuthorName, "_"), ext)
+		if err := addFile(filename, fileBytes); err != nil {
+			slog.Error("error writing report to archive", "batch_id", batchId, "report_id", report.Id, "error", err)
+			http.Error(w, "error generating archive", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		slog.Error("error closing batch archive", "batch_id", batchId, "error", err)
+		http.Error(w, "error generating archive", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", batchFilenameRe.ReplaceAllString(batch.Name, "_")))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.Error("error writing file bytes", "error", err)
+		http.Error(w, "error writing file", http.StatusInternalServerError)
+	}
+}
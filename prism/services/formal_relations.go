@@ -12,6 +12,8 @@ import (
 	"prism/prism/api"
 	"prism/prism/gscholar"
 	"prism/prism/llms"
+	"prism/prism/schema"
+	"prism/prism/services/auth"
 	"strings"
 )
 
@@ -106,6 +108,11 @@ const (
 )
 
 func (s *SearchService) FormalRelations(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
 	query := r.URL.Query()
 	author, institution := query.Get("author"), query.Get("institution")
 
@@ -126,6 +133,10 @@ func (s *SearchService) FormalRelations(r *http.Request) (any, error) {
 
 	llm := llms.New()
 
+	if err := s.manager.ConsumeQuota(userId, schema.UsageLLMCalls); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
 	answer, err := llm.Generate(prompt, nil)
 	if err != nil {
 		slog.Error("formal relations: initial llm generaton failed", "error", err)
@@ -151,6 +162,10 @@ func (s *SearchService) FormalRelations(r *http.Request) (any, error) {
 
 	verificationPrompt := fmt.Sprintf(formalRelationsVerficationPromptTemplate, author, institution, link, content, answer)
 
+	if err := s.manager.ConsumeQuota(userId, schema.UsageLLMCalls); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
 	verification, err := llm.Generate(verificationPrompt, nil)
 	if err != nil {
 		slog.Error("formal relations: verification llm generaton failed", "error", err)
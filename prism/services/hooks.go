@@ -49,8 +49,8 @@ func (s *HookService) Routes() chi.Router {
 
 	r.Get("/", WrapRestHandler(s.ListAvailableHooks))
 	r.Get("/{report_id}", WrapRestHandler(s.ListHooks))
-	r.Post("/{report_id}", WrapRestHandler(s.CreateHook))
-	r.Delete("/{report_id}/{hook_id}", WrapRestHandler(s.DeleteHook))
+	r.With(Audited(AuditHookCreate), auth.RequireRole(auth.RoleAnalyst)).Post("/{report_id}", WrapRestHandler(s.CreateHook))
+	r.With(Audited(AuditHookDelete), auth.RequireRole(auth.RoleAnalyst)).Delete("/{report_id}/{hook_id}", WrapRestHandler(s.DeleteHook))
 
 	return r
 }
@@ -156,6 +156,7 @@ func (s *HookService) RunNextHook() {
 			Limit(10).
 			Preload("Report").
 			Preload("Report.Flags").
+			Preload("Report.Identities").
 			Preload("Hooks").
 			Joins("JOIN author_reports ON author_reports.id = user_author_reports.report_id").
 			Joins("JOIN author_report_hooks ON author_report_hooks.user_report_id = user_author_reports.id").
@@ -170,6 +171,10 @@ func (s *HookService) RunNextHook() {
 				return fmt.Errorf("error converting report content: %w", err)
 			}
 
+			if err := reports.ApplyFlagSuppressions(txn, report.UserId, &content); err != nil {
+				return fmt.Errorf("error applying flag suppressions: %w", err)
+			}
+
 			for _, hook := range report.Hooks {
 				exec, ok := s.hooks[hook.Action]
 				if !ok {
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"prism/prism/api"
+	"prism/prism/reports"
+	"prism/prism/schema"
+	"prism/prism/services/auth"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const maxOrganizationNameLength = 200
+
+type OrganizationService struct {
+	manager *reports.ReportManager
+}
+
+func NewOrganizationService(manager *reports.ReportManager) OrganizationService {
+	return OrganizationService{manager: manager}
+}
+
+func (s *OrganizationService) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", WrapRestHandler(s.GetOrganization))
+	r.Post("/create", WrapRestHandler(s.CreateOrganization))
+	r.Post("/invites", WrapRestHandler(s.InviteMember))
+	r.Get("/invites", WrapRestHandler(s.ListInvites))
+	r.Delete("/invites/{email}", WrapRestHandler(s.RevokeInvite))
+	r.Post("/invites/{organization_id}/accept", WrapRestHandler(s.AcceptInvite))
+	r.Delete("/members/{user_id}", WrapRestHandler(s.RemoveMember))
+
+	return r
+}
+
+func organizationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, reports.ErrOrganizationNotFound), errors.Is(err, reports.ErrUserNotInOrganization),
+		errors.Is(err, reports.ErrMemberNotFound), errors.Is(err, reports.ErrInviteNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, reports.ErrNotOrganizationAdmin):
+		return http.StatusForbidden
+	case errors.Is(err, reports.ErrOrganizationNameTaken), errors.Is(err, reports.ErrUserInOrganization),
+		errors.Is(err, reports.ErrLastOrganizationAdmin):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (s *OrganizationService) GetOrganization(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	org, err := s.manager.GetUserOrganization(userId)
+	if err != nil {
+		return nil, CodedError(err, organizationErrorStatus(err))
+	}
+
+	return org, nil
+}
+
+func (s *OrganizationService) CreateOrganization(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	email, err := auth.GetUserEmail(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	params, err := ParseRequestBody[api.CreateOrganizationRequest](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	name := strings.TrimSpace(params.Name)
+	if name == "" {
+		return nil, CodedError(errors.New("Name must be specified"), http.StatusUnprocessableEntity)
+	}
+	if len(name) > maxOrganizationNameLength {
+		return nil, CodedError(errors.New("Name is too long"), http.StatusUnprocessableEntity)
+	}
+
+	id, err := s.manager.CreateOrganization(userId, email, name)
+	if err != nil {
+		return nil, CodedError(err, organizationErrorStatus(err))
+	}
+
+	return api.CreateReportResponse{Id: id}, nil
+}
+
+func (s *OrganizationService) InviteMember(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	params, err := ParseRequestBody[api.InviteOrganizationMemberRequest](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if _, err := mail.ParseAddress(params.Email); err != nil {
+		return nil, CodedError(errors.New("invalid Email"), http.StatusUnprocessableEntity)
+	}
+
+	switch params.Role {
+	case "":
+		params.Role = schema.OrganizationRoleMember
+	case schema.OrganizationRoleMember, schema.OrganizationRoleAdmin:
+		// ok
+	default:
+		return nil, CodedError(errors.New("Role must be either 'member' or 'admin'"), http.StatusUnprocessableEntity)
+	}
+
+	if err := s.manager.InviteOrganizationMember(userId, params.Email, params.Role); err != nil {
+		return nil, CodedError(err, organizationErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
+func (s *OrganizationService) ListInvites(r *http.Request) (any, error) {
+	email, err := auth.GetUserEmail(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	invites, err := s.manager.ListOrganizationInvites(email)
+	if err != nil {
+		return nil, CodedError(err, organizationErrorStatus(err))
+	}
+
+	return invites, nil
+}
+
+func (s *OrganizationService) RevokeInvite(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	if err := s.manager.RevokeOrganizationInvite(userId, chi.URLParam(r, "email")); err != nil {
+		return nil, CodedError(err, organizationErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
+func (s *OrganizationService) AcceptInvite(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	email, err := auth.GetUserEmail(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	orgId, err := URLParamUUID(r, "organization_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if err := s.manager.AcceptOrganizationInvite(userId, email, orgId); err != nil {
+		return nil, CodedError(err, organizationErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
+func (s *OrganizationService) RemoveMember(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	memberId, err := URLParamUUID(r, "user_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if err := s.manager.RemoveOrganizationMember(userId, memberId); err != nil {
+		return nil, CodedError(err, organizationErrorStatus(err))
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"prism/prism/services/auth"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How long a user's bucket is kept after their last request. Buckets that are
+// unused for this long are full, so removing them doesn't change the limit.
+const rateLimitBucketTTL = 10 * time.Minute
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// Limits the number of requests each user can make per minute with a token
+// bucket, allowing bursts of up to a minute's worth of requests. The buckets are
+// kept in memory so the limit applies to each backend instance separately.
+type RateLimiter struct {
+	mu sync.Mutex
+
+	requestsPerMinute int
+	buckets           map[uuid.UUID]*tokenBucket
+	lastCleanup       time.Time
+}
+
+// Returns nil if requestsPerMinute is 0, the middleware of a nil rate limiter
+// does not limit requests.
+func NewRateLimiter(requestsPerMinute int) *RateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		requestsPerMinute: requestsPerMinute,
+		buckets:           make(map[uuid.UUID]*tokenBucket),
+		lastCleanup:       time.Now(),
+	}
+}
+
+// Returns if the user can make a request, and if not how long until they can.
+func (l *RateLimiter) allow(userId uuid.UUID) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	capacity := float64(l.requestsPerMinute)
+	perSecond := capacity / 60
+
+	if now.Sub(l.lastCleanup) > rateLimitBucketTTL {
+		for id, bucket := range l.buckets {
+			if now.Sub(bucket.lastRefill) > rateLimitBucketTTL {
+				delete(l.buckets, id)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	bucket, ok := l.buckets[userId]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, lastRefill: now}
+		l.buckets[userId] = bucket
+	}
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.lastRefill).Seconds()*perSecond)
+	bucket.lastRefill = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second))
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+// Rejects requests with status 429 once the user exceeds the limit. This must
+// be used after auth.Middleware.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, err := auth.GetUserId(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if ok, retryAfter := l.allow(userId); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, fmt.Sprintf("rate limit of %d requests per minute exceeded", l.requestsPerMinute), http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,11 +1,14 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"prism/prism/api"
@@ -14,7 +17,11 @@ import (
 	"prism/prism/openalex"
 	"prism/prism/reports"
 	"prism/prism/schema"
+	"prism/prism/scopus"
 	"prism/prism/services/auth"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,14 +33,16 @@ type ReportService struct {
 	manager        *reports.ReportManager
 	licensing      *licensing.LicenseVerifier
 	openalex       openalex.KnowledgeBase
+	scopus         *scopus.Client
 	resourceFolder string
 }
 
-func NewReportService(manager *reports.ReportManager, licensing *licensing.LicenseVerifier, openalex openalex.KnowledgeBase, resourceFolder string) ReportService {
+func NewReportService(manager *reports.ReportManager, licensing *licensing.LicenseVerifier, openalex openalex.KnowledgeBase, scopusClient *scopus.Client, resourceFolder string) ReportService {
 	return ReportService{
 		manager:        manager,
 		licensing:      licensing,
 		openalex:       openalex,
+		scopus:         scopusClient,
 		resourceFolder: resourceFolder,
 	}
 }
@@ -136,20 +145,51 @@ func convertReportRequestToReport(request *ReportRequest) (api.Report, string, e
 func (s *ReportService) Routes() chi.Router {
 	r := chi.NewRouter()
 
+	analyst := auth.RequireRole(auth.RoleAnalyst)
+	admin := auth.RequireRole(auth.RoleAdmin)
+
 	r.Route("/author", func(r chi.Router) {
 		r.Get("/list", WrapRestHandler(s.List))
-		r.Post("/create", WrapRestHandler(s.CreateReport))
-		r.Get("/{report_id}", WrapRestHandler(s.GetReport))
-		r.Delete("/{report_id}", WrapRestHandler(s.DeleteAuthorReport))
-		r.Post("/{report_id}/check-disclosure", WrapRestHandler(s.CheckDisclosure))
-		r.Post("/{report_id}/download", s.DownloadReport)
+		r.With(Audited(AuditAuthorReportCreate), analyst).Post("/create", WrapRestHandler(s.CreateReport))
+		r.With(Audited(AuditAuthorReportView)).Get("/{report_id}", WrapRestHandler(s.GetReport))
+		r.With(Audited(AuditAuthorReportDelete), analyst).Delete("/{report_id}", WrapRestHandler(s.DeleteAuthorReport))
+		r.With(analyst).Put("/{report_id}/share", WrapRestHandler(s.ShareAuthorReport))
+		r.With(analyst).Delete("/{report_id}/share", WrapRestHandler(s.UnshareAuthorReport))
+		r.With(Audited(AuditDisclosureCheck), analyst).Post("/{report_id}/check-disclosure", WrapRestHandler(s.CheckDisclosure))
+		r.Get("/{report_id}/disclosures", WrapRestHandler(s.ListDisclosures))
+		r.With(analyst).Delete("/{report_id}/disclosures/{document_id}", WrapRestHandler(s.DeleteDisclosure))
+		r.With(Audited(AuditAuthorReportDownload)).Post("/{report_id}/download", s.DownloadReport)
+		r.Get("/{report_id}/flags/{flag_hash}", WrapRestHandler(s.GetFlagTriage))
+		r.With(analyst).Put("/{report_id}/flags/{flag_hash}", WrapRestHandler(s.UpdateFlagTriage))
+		r.With(analyst).Delete("/{report_id}/flags/{flag_hash}", WrapRestHandler(s.DeleteFlagTriage))
+	})
+
+	// Suppressions apply to the reports of all members of the organization, so
+	// only admins can change them.
+	r.Route("/suppressions", func(r chi.Router) {
+		r.Get("/list", WrapRestHandler(s.ListFlagSuppressions))
+		r.With(admin).Post("/create", WrapRestHandler(s.CreateFlagSuppression))
+		r.With(admin).Delete("/{suppression_id}", WrapRestHandler(s.RemoveFlagSuppression))
+	})
+
+	r.Get("/risk-weights", WrapRestHandler(s.GetRiskWeights))
+	r.With(admin).Put("/risk-weights", WrapRestHandler(s.UpdateRiskWeights))
+
+	r.Route("/batch", func(r chi.Router) {
+		r.Get("/list", WrapRestHandler(s.ListAuthorReportBatches))
+		r.With(Audited(AuditReportBatchCreate), analyst).Post("/create", WrapRestHandler(s.CreateAuthorReportBatch))
+		r.Get("/{batch_id}", WrapRestHandler(s.GetAuthorReportBatch))
+		r.With(analyst).Delete("/{batch_id}", WrapRestHandler(s.DeleteAuthorReportBatch))
+		r.With(Audited(AuditAuthorReportCreate), analyst).Post("/{batch_id}/rows/{row}/resolve", WrapRestHandler(s.ResolveAuthorReportBatchRow))
+		r.With(Audited(AuditReportBatchDownload)).Get("/{batch_id}/download", s.DownloadAuthorReportBatch)
 	})
 
 	r.Route("/university", func(r chi.Router) {
 		r.Get("/list", WrapRestHandler(s.ListUniversityReports))
-		r.Post("/create", WrapRestHandler(s.CreateUniversityReport))
-		r.Get("/{report_id}", WrapRestHandler(s.GetUniversityReport))
-		r.Delete("/{report_id}", WrapRestHandler(s.DeleteUniversityReport))
+		r.With(Audited(AuditUniversityReportCreate), analyst).Post("/create", WrapRestHandler(s.CreateUniversityReport))
+		r.With(Audited(AuditUniversityReportView)).Get("/{report_id}", WrapRestHandler(s.GetUniversityReport))
+		r.With(Audited(AuditUniversityReportDelete), analyst).Delete("/{report_id}", WrapRestHandler(s.DeleteUniversityReport))
+		r.With(Audited(AuditUniversityReportDownload)).Get("/{report_id}/download", s.DownloadUniversityReport)
 	})
 
 	return r
@@ -161,7 +201,15 @@ func (s *ReportService) List(r *http.Request) (any, error) {
 		return nil, CodedError(err, http.StatusInternalServerError)
 	}
 
-	reports, err := s.manager.ListAuthorReports(userId)
+	includeOrganization := false
+	if value := r.URL.Query().Get("organization"); value != "" {
+		includeOrganization, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, CodedError(errors.New("invalid value for organization"), http.StatusBadRequest)
+		}
+	}
+
+	reports, err := s.manager.ListAuthorReports(userId, includeOrganization)
 	if err != nil {
 		return nil, CodedError(err, http.StatusInternalServerError)
 	}
@@ -185,11 +233,105 @@ func (s *ReportService) getAuthorAffiliationsAndInterests(source, authorId strin
 			return nil, nil, CodedError(errors.New("unable to get author details"), http.StatusInternalServerError)
 		}
 		return details.Institutions, details.Interests, nil
+	case api.ScopusSource:
+		if s.scopus == nil {
+			return nil, nil, CodedError(ErrScopusNotConfigured, http.StatusNotImplemented)
+		}
+		details, err := s.scopus.GetAuthorDetails(authorId)
+		if err != nil {
+			slog.Error("error getting author details", "source", source, "author_id", authorId, "error", err)
+			if errors.Is(err, scopus.ErrAuthorNotFound) {
+				return nil, nil, CodedError(err, http.StatusNotFound)
+			}
+			return nil, nil, CodedError(errors.New("unable to get author details"), http.StatusInternalServerError)
+		}
+		return details.Institutions, details.Interests, nil
+	case api.UnstructuredSource:
+		// There is no external profile for an unstructured report, everything is
+		// extracted from the provided text when the report is processed.
+		return nil, nil, nil
 	default:
 		return nil, nil, CodedError(errors.New("invalid Source"), http.StatusUnprocessableEntity)
 	}
 }
 
+// This is a limit on the size of the text that is passed to the LLM to extract
+// work titles from for unstructured reports.
+const maxUnstructuredTextLength = 100000
+
+func unstructuredAuthorId(text string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(text)))
+	return "unstructured:" + hex.EncodeToString(hash[:])
+}
+
+func mergedAuthorId(identities []api.AuthorIdentity) string {
+	hash := sha256.New()
+	for _, identity := range identities {
+		hash.Write([]byte(identity.Source + "\n" + identity.AuthorId + "\n"))
+	}
+	return "merged:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// Checks that the identities for a merged report are valid, and returns them in
+// a canonical order without duplicates so that the same set of identities will
+// always map to the same report.
+func parseMergedIdentities(identities []api.AuthorIdentity) ([]api.AuthorIdentity, error) {
+	output := make([]api.AuthorIdentity, 0, len(identities))
+	for _, identity := range identities {
+		switch identity.Source {
+		case api.OpenAlexSource, api.GoogleScholarSource, api.ScopusSource:
+			// ok
+		default:
+			return nil, fmt.Errorf("invalid Source '%s' for merged report identity", identity.Source)
+		}
+		if identity.AuthorId == "" {
+			return nil, errors.New("AuthorId must be specified for merged report identities")
+		}
+		if !slices.Contains(output, identity) {
+			output = append(output, identity)
+		}
+	}
+
+	if len(output) < 2 {
+		return nil, errors.New("at least 2 distinct Identities must be specified for merged reports")
+	}
+
+	slices.SortFunc(output, api.CompareAuthorIdentities)
+
+	return output, nil
+}
+
+func (s *ReportService) getMergedAffiliationsAndInterests(identities []api.AuthorIdentity) ([]string, []string, error) {
+	affiliations, interests := make([]string, 0), make([]string, 0)
+	for _, identity := range identities {
+		identityAffiliations, identityInterests, err := s.getAuthorAffiliationsAndInterests(identity.Source, identity.AuthorId)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, affiliation := range identityAffiliations {
+			if !slices.Contains(affiliations, affiliation) {
+				affiliations = append(affiliations, affiliation)
+			}
+		}
+		for _, interest := range identityInterests {
+			if !slices.Contains(interests, interest) {
+				interests = append(interests, interest)
+			}
+		}
+	}
+	return affiliations, interests, nil
+}
+
+func checkLookbackYears(lookbackYears int) error {
+	// Works before the earliest report date are never included, so a larger
+	// lookback would be equivalent to not specifying one.
+	maxLookbackYears := time.Now().UTC().Year() - reports.EarliestReportDate.Year()
+	if lookbackYears < 0 || lookbackYears > maxLookbackYears {
+		return fmt.Errorf("LookbackYears must be between 0 and %d", maxLookbackYears)
+	}
+	return nil
+}
+
 func (s *ReportService) CreateReport(r *http.Request) (any, error) {
 	userId, err := auth.GetUserId(r)
 	if err != nil {
@@ -201,6 +343,64 @@ func (s *ReportService) CreateReport(r *http.Request) (any, error) {
 		return nil, CodedError(err, http.StatusBadRequest)
 	}
 
+	if params.Source == api.UnstructuredSource {
+		if strings.TrimSpace(params.UnstructuredText) == "" {
+			return nil, CodedError(errors.New("UnstructuredText must be specified for unstructured reports"), http.StatusUnprocessableEntity)
+		}
+		if len(params.UnstructuredText) > maxUnstructuredTextLength {
+			return nil, CodedError(fmt.Errorf("UnstructuredText cannot exceed %d characters", maxUnstructuredTextLength), http.StatusUnprocessableEntity)
+		}
+		// There is no external id for unstructured reports, so the id is derived
+		// from the text so that reports for the same text can be reused.
+		params.AuthorId = unstructuredAuthorId(params.UnstructuredText)
+	} else {
+		params.UnstructuredText = ""
+	}
+
+	if params.Source == api.MergedSource {
+		identities, err := parseMergedIdentities(params.Identities)
+		if err != nil {
+			return nil, CodedError(err, http.StatusUnprocessableEntity)
+		}
+		// The id of a merged report is derived from its identities so that reports
+		// for the same set of identities can be reused.
+		params.Identities = identities
+		params.AuthorId = mergedAuthorId(identities)
+	} else {
+		params.Identities = nil
+	}
+
+	if params.Orcid != "" {
+		if params.Source == "" {
+			params.Source = api.OpenAlexSource
+		}
+		if params.Source != api.OpenAlexSource {
+			return nil, CodedError(errors.New("Orcid can only be specified for openalex reports"), http.StatusUnprocessableEntity)
+		}
+
+		orcid, err := parseOrcid(params.Orcid)
+		if err != nil {
+			return nil, CodedError(err, http.StatusUnprocessableEntity)
+		}
+
+		author, err := s.openalex.FindAuthorByOrcidId(orcid)
+		if err != nil {
+			if errors.Is(err, openalex.ErrAuthorNotFound) {
+				return nil, CodedError(fmt.Errorf("no author found for ORCID '%s'", orcid), http.StatusNotFound)
+			}
+			slog.Error("error resolving orcid", "orcid", orcid, "error", err)
+			return nil, CodedError(errors.New("unable to resolve ORCID"), http.StatusInternalServerError)
+		}
+
+		// The ORCID is resolved to an openalex author id so that the report will be
+		// reused if the same author is found via the author name search.
+		params.AuthorId = author.AuthorId
+		params.Orcid = orcid
+		if params.AuthorName == "" {
+			params.AuthorName = author.DisplayName
+		}
+	}
+
 	if params.AuthorId == "" {
 		return nil, CodedError(errors.New("AuthorId must be specified"), http.StatusUnprocessableEntity)
 	}
@@ -210,27 +410,48 @@ func (s *ReportService) CreateReport(r *http.Request) (any, error) {
 	}
 
 	switch params.Source {
-	case api.OpenAlexSource, api.GoogleScholarSource, api.ScopusSource, api.UnstructuredSource:
+	case api.OpenAlexSource, api.GoogleScholarSource, api.ScopusSource, api.UnstructuredSource, api.MergedSource:
 		// ok
 	default:
 		return nil, CodedError(errors.New("invalid Source"), http.StatusUnprocessableEntity)
 	}
 
+	if err := checkLookbackYears(params.LookbackYears); err != nil {
+		return nil, CodedError(err, http.StatusUnprocessableEntity)
+	}
+
 	if err := s.licensing.VerifyLicense(); err != nil {
 		slog.Error("cannot create new report, unable to verify license", "error", err)
 		return nil, CodedError(err, licensingErrorStatus(err))
 	}
 
-	affiliations, researchInterests, err := s.getAuthorAffiliationsAndInterests(params.Source, params.AuthorId)
+	var affiliations, researchInterests []string
+	if params.Source == api.MergedSource {
+		affiliations, researchInterests, err = s.getMergedAffiliationsAndInterests(params.Identities)
+	} else {
+		affiliations, researchInterests, err = s.getAuthorAffiliationsAndInterests(params.Source, params.AuthorId)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	id, err := s.manager.CreateAuthorReport(userId, params.AuthorId, params.AuthorName, params.Source, strings.Join(affiliations, ", "), strings.Join(researchInterests, ", "))
+	id, err := s.manager.CreateAuthorReport(userId, reports.AuthorReportParams{
+		AuthorId:          params.AuthorId,
+		AuthorName:        params.AuthorName,
+		Source:            params.Source,
+		Orcid:             params.Orcid,
+		Affiliations:      strings.Join(affiliations, ", "),
+		ResearchInterests: strings.Join(researchInterests, ", "),
+		UnstructuredText:  params.UnstructuredText,
+		Identities:        params.Identities,
+		LookbackYears:     params.LookbackYears,
+	})
 	if err != nil {
-		return nil, CodedError(err, http.StatusInternalServerError)
+		return nil, CodedError(err, reportErrorStatus(err))
 	}
 
+	setAuditReportId(r, id)
+
 	return api.CreateReportResponse{Id: id}, nil
 }
 
@@ -271,6 +492,213 @@ func (s *ReportService) DeleteAuthorReport(r *http.Request) (any, error) {
 	return nil, nil
 }
 
+func (s *ReportService) ShareAuthorReport(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	id, err := URLParamUUID(r, "report_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	params, err := ParseRequestBody[api.ShareAuthorReportRequest](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	switch params.Access {
+	case schema.ReportAccessRead, schema.ReportAccessWrite:
+		// ok
+	default:
+		return nil, CodedError(fmt.Errorf("Access must be either '%s' or '%s'", schema.ReportAccessRead, schema.ReportAccessWrite), http.StatusUnprocessableEntity)
+	}
+
+	if err := s.manager.ShareAuthorReport(userId, id, params.Access); err != nil {
+		if errors.Is(err, reports.ErrUserNotInOrganization) {
+			return nil, CodedError(err, http.StatusUnprocessableEntity)
+		}
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
+func (s *ReportService) UnshareAuthorReport(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	id, err := URLParamUUID(r, "report_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if err := s.manager.ShareAuthorReport(userId, id, ""); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
+var flagHashRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+const maxTriageNoteLength = 5000
+
+func parseFlagTriageParams(r *http.Request) (uuid.UUID, uuid.UUID, string, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", CodedError(err, http.StatusInternalServerError)
+	}
+
+	reportId, err := URLParamUUID(r, "report_id")
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", CodedError(err, http.StatusBadRequest)
+	}
+
+	flagHash := strings.ToLower(chi.URLParam(r, "flag_hash"))
+	if !flagHashRe.MatchString(flagHash) {
+		return uuid.Nil, uuid.Nil, "", CodedError(fmt.Errorf("invalid flag hash '%s'", flagHash), http.StatusBadRequest)
+	}
+
+	return userId, reportId, flagHash, nil
+}
+
+func (s *ReportService) GetFlagTriage(r *http.Request) (any, error) {
+	userId, reportId, flagHash, err := parseFlagTriageParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	triage, err := s.manager.GetFlagTriage(userId, reportId, flagHash)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return triage, nil
+}
+
+func (s *ReportService) UpdateFlagTriage(r *http.Request) (any, error) {
+	userId, reportId, flagHash, err := parseFlagTriageParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := ParseRequestBody[api.UpdateFlagTriageRequest](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	switch params.Status {
+	case schema.TriageConfirmed, schema.TriageDismissed, schema.TriageNeedsFollowUp:
+		// ok
+	default:
+		return nil, CodedError(fmt.Errorf("Status must be one of '%s', '%s', or '%s'", schema.TriageConfirmed, schema.TriageDismissed, schema.TriageNeedsFollowUp), http.StatusUnprocessableEntity)
+	}
+
+	if len(params.Note) > maxTriageNoteLength {
+		return nil, CodedError(fmt.Errorf("Note cannot exceed %d characters", maxTriageNoteLength), http.StatusUnprocessableEntity)
+	}
+
+	triage, err := s.manager.UpdateFlagTriage(userId, reportId, flagHash, params.Status, params.Note)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return triage, nil
+}
+
+func (s *ReportService) DeleteFlagTriage(r *http.Request) (any, error) {
+	userId, reportId, flagHash, err := parseFlagTriageParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.manager.DeleteFlagTriage(userId, reportId, flagHash); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
+func (s *ReportService) CreateFlagSuppression(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	params, err := ParseRequestBody[api.CreateFlagSuppressionRequest](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if params.AuthorId == "" {
+		return nil, CodedError(errors.New("AuthorId must be specified"), http.StatusUnprocessableEntity)
+	}
+
+	if params.FlagHash != "" {
+		if params.FlagType != "" || params.Entity != "" {
+			return nil, CodedError(errors.New("FlagType and Entity cannot be specified with FlagHash"), http.StatusUnprocessableEntity)
+		}
+		if !flagHashRe.MatchString(strings.ToLower(params.FlagHash)) {
+			return nil, CodedError(fmt.Errorf("invalid FlagHash '%s'", params.FlagHash), http.StatusUnprocessableEntity)
+		}
+	} else {
+		if params.FlagType == "" || strings.TrimSpace(params.Entity) == "" {
+			return nil, CodedError(errors.New("either FlagHash or FlagType and Entity must be specified"), http.StatusUnprocessableEntity)
+		}
+		if _, err := api.ParseFlag(params.FlagType, []byte("{}")); err != nil {
+			return nil, CodedError(fmt.Errorf("invalid FlagType '%s'", params.FlagType), http.StatusUnprocessableEntity)
+		}
+	}
+
+	suppression, err := s.manager.CreateFlagSuppression(userId, params)
+	if err != nil {
+		if errors.Is(err, reports.ErrUserNotInOrganization) {
+			return nil, CodedError(err, http.StatusUnprocessableEntity)
+		}
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return suppression, nil
+}
+
+func (s *ReportService) ListFlagSuppressions(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	includeRemoved := r.URL.Query().Get("include_removed") == "true"
+
+	suppressions, err := s.manager.ListFlagSuppressions(userId, r.URL.Query().Get("author_id"), includeRemoved)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return suppressions, nil
+}
+
+func (s *ReportService) RemoveFlagSuppression(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	id, err := URLParamUUID(r, "suppression_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if err := s.manager.RemoveFlagSuppression(userId, id); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
 func (s *ReportService) CheckDisclosure(r *http.Request) (any, error) {
 	userId, err := auth.GetUserId(r)
 	if err != nil {
@@ -292,46 +720,110 @@ func (s *ReportService) CheckDisclosure(r *http.Request) (any, error) {
 		return nil, CodedError(errors.New("no files uploaded"), http.StatusBadRequest)
 	}
 
-	var allFileTexts []string
+	report, err := s.manager.GetAuthorReport(userId, reportId)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	if report.Status != schema.ReportCompleted {
+		return nil, CodedError(errors.New("cannot process disclosures for report unless report status is complete"), http.StatusUnprocessableEntity)
+	}
+
+	var uploads []reports.DisclosureUpload
+	fileErrors := make([]api.DisclosureFileError, 0)
 	for _, fileHeader := range fileHeaders {
-		file, err := fileHeader.Open()
+		text, err := readDisclosureFile(fileHeader)
 		if err != nil {
-			slog.Error("error opening uploaded file", "filename", fileHeader.Filename, "error", err)
+			fileErrors = append(fileErrors, api.DisclosureFileError{Filename: fileHeader.Filename, Error: err.Error()})
 			continue
 		}
 
-		fileBytes, err := io.ReadAll(file)
-		file.Close()
-		if err != nil {
-			slog.Error("error reading file bytes", "filename", fileHeader.Filename, "error", err)
-			continue
-		}
+		uploads = append(uploads, reports.DisclosureUpload{Filename: fileHeader.Filename, Text: text})
+	}
 
-		ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-		text, err := parseFileContent(ext, fileBytes)
-		if err != nil {
-			slog.Error("error parsing file content", "filename", fileHeader.Filename, "error", err)
-			continue
-		}
+	// The disclosures are stored so that flags found when the report is updated
+	// are also checked against them.
+	if err := s.manager.AddDisclosureDocuments(userId, reportId, uploads); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	report, err = s.manager.GetAuthorReport(userId, reportId)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return api.CheckDisclosureResponse{Report: report, FileErrors: fileErrors}, nil
+}
 
-		allFileTexts = append(allFileTexts, text)
+// Returns the text of the uploaded file, the error is returned to the user.
+func readDisclosureFile(fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("error opening uploaded file", "filename", fileHeader.Filename, "error", err)
+		return "", errors.New("unable to open file")
 	}
+	defer file.Close()
 
-	report, err := s.manager.GetAuthorReport(userId, reportId)
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		slog.Error("error reading file bytes", "filename", fileHeader.Filename, "error", err)
+		return "", errors.New("unable to read file")
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	text, err := parseFileContent(ext, fileBytes)
+	if err != nil {
+		slog.Error("error parsing file content", "filename", fileHeader.Filename, "error", err)
+		return "", err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("no text found in file")
+	}
+
+	return text, nil
+}
+
+func (s *ReportService) ListDisclosures(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
 	if err != nil {
 		return nil, CodedError(err, http.StatusInternalServerError)
 	}
 
-	if report.Status != schema.ReportCompleted {
-		return nil, CodedError(errors.New("cannot process disclosures for report unless report status is complete"), http.StatusUnprocessableEntity)
+	reportId, err := URLParamUUID(r, "report_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
 	}
 
-	for _, flags := range report.Content {
-		for _, flag := range flags {
-			updateFlagDisclosure(flag, allFileTexts)
-		}
+	documents, err := s.manager.ListDisclosureDocuments(userId, reportId)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
 	}
-	return report, nil
+
+	return documents, nil
+}
+
+func (s *ReportService) DeleteDisclosure(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	reportId, err := URLParamUUID(r, "report_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	documentId, err := URLParamUUID(r, "document_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if err := s.manager.DeleteDisclosureDocument(userId, reportId, documentId); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return nil, nil
 }
 
 func (s *ReportService) DownloadReport(w http.ResponseWriter, r *http.Request) {
@@ -377,6 +869,14 @@ func (s *ReportService) DownloadReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if containsReportContent {
+		report.Risk, err = s.manager.ScoreReportContent(userId, report.Content)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	format := r.URL.Query().Get("format")
 	if format == "" {
 		format = "csv"
@@ -423,6 +923,50 @@ func (s *ReportService) DownloadReport(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *ReportService) GetRiskWeights(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	weights, err := s.manager.GetRiskWeights(userId)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return weights, nil
+}
+
+func (s *ReportService) UpdateRiskWeights(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	params, err := ParseRequestBody[api.RiskWeights](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if err := reports.ValidateRiskWeights(params); err != nil {
+		return nil, CodedError(err, http.StatusUnprocessableEntity)
+	}
+
+	if err := s.manager.UpdateRiskWeights(userId, params); err != nil {
+		if errors.Is(err, reports.ErrUserNotInOrganization) {
+			return nil, CodedError(err, http.StatusUnprocessableEntity)
+		}
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	weights, err := s.manager.GetRiskWeights(userId)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return weights, nil
+}
+
 func (s *ReportService) ListUniversityReports(r *http.Request) (any, error) {
 	userId, err := auth.GetUserId(r)
 	if err != nil {
@@ -460,19 +1004,59 @@ func (s *ReportService) CreateUniversityReport(r *http.Request) (any, error) {
 		return nil, CodedError(errors.New("UniversityLocation must be specified"), http.StatusUnprocessableEntity)
 	}
 
+	// A lookback of 0 uses the default lookback for university reports.
+	if err := checkLookbackYears(params.LookbackYears); err != nil {
+		return nil, CodedError(err, http.StatusUnprocessableEntity)
+	}
+
+	if params.MinWorks < 0 {
+		return nil, CodedError(errors.New("MinWorks must not be negative"), http.StatusUnprocessableEntity)
+	}
+
 	if err := s.licensing.VerifyLicense(); err != nil {
 		slog.Error("cannot create new report, unable to verify license", "error", err)
 		return nil, CodedError(err, licensingErrorStatus(err))
 	}
 
-	id, err := s.manager.CreateUniversityReport(userId, params.UniversityId, params.UniversityName, params.UniversityLocation)
+	id, err := s.manager.CreateUniversityReport(userId, params.UniversityId, params.UniversityName, params.UniversityLocation, params.UniversityAuthorDiscovery)
 	if err != nil {
-		return nil, CodedError(err, http.StatusInternalServerError)
+		return nil, CodedError(err, reportErrorStatus(err))
 	}
 
+	setAuditReportId(r, id)
+
 	return api.CreateReportResponse{Id: id}, nil
 }
 
+func parseUniversityAuthorQuery(r *http.Request) (reports.UniversityAuthorQuery, error) {
+	params := r.URL.Query()
+
+	query := reports.UniversityAuthorQuery{
+		SortBy:     params.Get("sort"),
+		Order:      params.Get("order"),
+		FlagType:   params.Get("flag_type"),
+		Department: params.Get("department"),
+		RiskTier:   params.Get("tier"),
+		Search:     params.Get("search"),
+	}
+
+	for name, value := range map[string]*int{"page": &query.Page, "page_size": &query.PageSize} {
+		if raw := params.Get(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				return reports.UniversityAuthorQuery{}, fmt.Errorf("invalid %s '%s'", name, raw)
+			}
+			*value = n
+		}
+	}
+
+	if err := query.Validate(); err != nil {
+		return reports.UniversityAuthorQuery{}, err
+	}
+
+	return query, nil
+}
+
 func (s *ReportService) GetUniversityReport(r *http.Request) (any, error) {
 	userId, err := auth.GetUserId(r)
 	if err != nil {
@@ -484,7 +1068,12 @@ func (s *ReportService) GetUniversityReport(r *http.Request) (any, error) {
 		return nil, CodedError(err, http.StatusBadRequest)
 	}
 
-	report, err := s.manager.GetUniversityReport(userId, id)
+	authorQuery, err := parseUniversityAuthorQuery(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	report, err := s.manager.GetUniversityReport(userId, id, authorQuery)
 	if err != nil {
 		return nil, CodedError(err, reportErrorStatus(err))
 	}
@@ -492,6 +1081,79 @@ func (s *ReportService) GetUniversityReport(r *http.Request) (any, error) {
 	return report, nil
 }
 
+func (s *ReportService) DownloadUniversityReport(w http.ResponseWriter, r *http.Request) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reportId, err := URLParamUUID(r, "report_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The filters and sorting of the authors are the same as when getting the
+	// report, but all of the matching authors are included in the download.
+	authorQuery, err := parseUniversityAuthorQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	authorQuery.Page = 1
+	authorQuery.PageSize = reports.AllUniversityAuthors
+
+	report, err := s.manager.GetUniversityReport(userId, reportId, authorQuery)
+	if err != nil {
+		http.Error(w, err.Error(), reportErrorStatus(err))
+		return
+	}
+
+	if report.Status != schema.ReportCompleted {
+		http.Error(w, "cannot download report unless report status is complete", http.StatusUnprocessableEntity)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	var fileBytes []byte
+	var contentType, filename string
+	switch format {
+	case "csv":
+		fileBytes, err = generateUniversityCSV(report)
+		contentType = "text/csv"
+		filename = fmt.Sprintf("%s Report.csv", report.UniversityName)
+	case "pdf":
+		fileBytes, err = generateUniversityPDF(report, s.resourceFolder)
+		contentType = "application/pdf"
+		filename = fmt.Sprintf("%s Report.pdf", report.UniversityName)
+	case "excel", "xlsx":
+		fileBytes, err = generateUniversityExcel(report)
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		filename = fmt.Sprintf("%s Report.xlsx", report.UniversityName)
+	default:
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("error generating university report download", "university_report_id", reportId, "format", format, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(fileBytes); err != nil {
+		slog.Error("error writing file bytes", "error", err)
+		http.Error(w, "error writing file", http.StatusInternalServerError)
+	}
+}
+
 func (s *ReportService) DeleteUniversityReport(r *http.Request) (any, error) {
 	userId, err := auth.GetUserId(r)
 	if err != nil {
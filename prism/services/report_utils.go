@@ -1,41 +1,39 @@
 package services
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"prism/prism/api"
+	"regexp"
 	"slices"
 	"strings"
 	"time"
 
-	"github.com/bbalet/stopwords"
 	"github.com/gen2brain/go-fitz"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/xuri/excelize/v2"
+	"golang.org/x/net/html"
 )
 
-func updateFlagDisclosure(flag api.Flag, allFileTexts []string) {
-	entities := filterTokens(flag.GetEntities())
-
-DisclosureCheck:
-	for _, entity := range entities {
-		for _, txt := range allFileTexts {
-			if strings.Contains(strings.ToLower(txt), entity) {
-				flag.MarkDisclosed()
-				break DisclosureCheck
-			}
-		}
-	}
-}
-
 func parseFileContent(ext string, fileBytes []byte) (string, error) {
 	switch ext {
 	case ".txt":
 		return string(fileBytes), nil
 	case ".pdf":
 		return extractTextFromPDF(fileBytes)
+	case ".docx":
+		return extractTextFromDocx(fileBytes)
+	case ".xlsx":
+		return extractTextFromXlsx(fileBytes)
+	case ".html", ".htm":
+		return extractTextFromHTML(fileBytes)
 	default:
 		return "", fmt.Errorf("unsupported file extension: %s", ext)
 	}
@@ -55,34 +53,222 @@ func extractTextFromPDF(fileBytes []byte) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		if i > 0 {
+			// Page separator, used to report the page a disclosure is found on.
+			textBuilder.WriteString("\f")
+		}
 		textBuilder.WriteString(text)
 	}
 
 	return textBuilder.String(), nil
 }
 
-func removeStopwords(text string) string {
-	return stopwords.CleanString(text, "en", false)
+// Limits the size of the xml files read from docx files, since they are
+// compressed.
+const maxDocxPartSize = 64 << 20
+
+var docxPartRe = regexp.MustCompile(`^word/(document|header\d*|footer\d*|footnotes|endnotes)\.xml$`)
+
+func extractTextFromDocxPart(file *zip.File, builder *strings.Builder) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	decoder := xml.NewDecoder(io.LimitReader(reader, maxDocxPartSize))
+
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				builder.WriteString("\t")
+			case "br", "cr":
+				builder.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				builder.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				builder.Write(token)
+			}
+		}
+	}
 }
 
-func filterTokens(tokens []string) []string {
-	var filtered []string
-	for _, token := range tokens {
-		token = strings.TrimSpace(token)
-		if token == "" {
-			continue
+func extractTextFromDocx(fileBytes []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(fileBytes), int64(len(fileBytes)))
+	if err != nil {
+		return "", fmt.Errorf("invalid docx file: %w", err)
+	}
+
+	var document *zip.File
+	others := make([]*zip.File, 0)
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			document = file
+		} else if docxPartRe.MatchString(file.Name) {
+			others = append(others, file)
 		}
-		// If the token contains spaces, assume it’s a multi-word phrase and keep it as-is.
-		if strings.Contains(token, " ") {
-			filtered = append(filtered, strings.ToLower(token))
-		} else {
-			cleaned := removeStopwords(token)
-			if cleaned != "" {
-				filtered = append(filtered, cleaned)
+	}
+
+	if document == nil {
+		return "", errors.New("invalid docx file: missing word/document.xml")
+	}
+
+	// The main document is first, followed by the headers, footers, and notes.
+	slices.SortFunc(others, func(a, b *zip.File) int { return strings.Compare(a.Name, b.Name) })
+
+	var textBuilder strings.Builder
+	for _, part := range append([]*zip.File{document}, others...) {
+		if err := extractTextFromDocxPart(part, &textBuilder); err != nil {
+			return "", fmt.Errorf("invalid docx file: error reading %s: %w", part.Name, err)
+		}
+	}
+
+	return textBuilder.String(), nil
+}
+
+// Each sheet is treated as a page, so disclosures report the sheet they are
+// found on.
+func extractTextFromXlsx(fileBytes []byte) (string, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(fileBytes))
+	if err != nil {
+		return "", fmt.Errorf("invalid xlsx file: %w", err)
+	}
+	defer file.Close()
+
+	var textBuilder strings.Builder
+	for i, sheet := range file.GetSheetList() {
+		rows, err := file.GetRows(sheet)
+		if err != nil {
+			return "", fmt.Errorf("invalid xlsx file: error reading sheet '%s': %w", sheet, err)
+		}
+
+		if i > 0 {
+			textBuilder.WriteString("\f")
+		}
+		for _, row := range rows {
+			textBuilder.WriteString(strings.Join(row, "\t"))
+			textBuilder.WriteString("\n")
+		}
+	}
+
+	return textBuilder.String(), nil
+}
+
+// Elements that separate blocks of text, a newline is added for them so that
+// the text of adjacent elements is not joined.
+var htmlBlockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "footer": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// Elements whose content is not displayed.
+var htmlSkippedElements = map[string]bool{
+	"head": true, "noscript": true, "script": true, "style": true, "template": true,
+}
+
+func extractTextFromHTML(fileBytes []byte) (string, error) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(fileBytes))
+
+	var textBuilder strings.Builder
+	skipDepth := 0
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return "", fmt.Errorf("invalid html file: %w", err)
+			}
+			return textBuilder.String(), nil
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if htmlSkippedElements[tag] {
+				if tokenType == html.StartTagToken {
+					skipDepth++
+				} else if tokenType == html.EndTagToken && skipDepth > 0 {
+					skipDepth--
+				}
+			}
+			if htmlBlockElements[tag] {
+				textBuilder.WriteString("\n")
+			}
+		case html.TextToken:
+			if skipDepth == 0 {
+				textBuilder.Write(tokenizer.Text())
 			}
 		}
 	}
-	return filtered
+}
+
+var triageHeaders = []string{"Triage Status", "Triage Note", "Triaged At"}
+
+func triageDetailFields(flag api.Flag) []api.KeyValue {
+	triage := flag.GetTriage()
+	if triage == nil {
+		return nil
+	}
+	return []api.KeyValue{
+		{Key: triageHeaders[0], Value: triage.Status},
+		{Key: triageHeaders[1], Value: triage.Note},
+		{Key: triageHeaders[2], Value: triage.UpdatedAt.Format(time.DateOnly)},
+	}
+}
+
+const riskScoreHeader = "Risk Score"
+
+func riskDetailFields(report api.Report, flag api.Flag) []api.KeyValue {
+	if report.Risk == nil {
+		return nil
+	}
+	hash := flag.Hash()
+	return []api.KeyValue{{Key: riskScoreHeader, Value: fmt.Sprintf("%.2f", report.Risk.FlagScores[hex.EncodeToString(hash[:])])}}
+}
+
+func riskSummaryRows(report api.Report) [][]string {
+	if report.Risk == nil {
+		return nil
+	}
+	return [][]string{
+		{riskScoreHeader, fmt.Sprintf("%.2f", report.Risk.Score)},
+		{"Risk Tier", capitalizeTier(report.Risk.Tier)},
+	}
+}
+
+func capitalizeTier(tier string) string {
+	if tier == "" {
+		return tier
+	}
+	return strings.ToUpper(tier[:1]) + tier[1:]
+}
+
+// Returns the detail fields of the flag followed by the risk score and triage
+// fields that are added to the downloads.
+func flagDownloadFields(report api.Report, flag api.Flag) []api.KeyValue {
+	fields := flag.GetDetailFields()
+	fields = append(fields, riskDetailFields(report, flag)...)
+	return append(fields, triageDetailFields(flag)...)
 }
 
 func generateCSV(report api.Report) ([]byte, error) {
@@ -99,6 +285,7 @@ func generateCSV(report api.Report) ([]byte, error) {
 		{"Downloaded At", time.Now().Format(time.RFC3339)},
 		{"Author Name", report.AuthorName},
 	}
+	rows = append(rows, riskSummaryRows(report)...)
 
 	if err := writer.WriteAll(rows); err != nil {
 		return nil, err
@@ -113,7 +300,7 @@ func generateCSV(report api.Report) ([]byte, error) {
 			if err := writer.Write([]string{"Flag Title", flag.GetHeading()}); err != nil {
 				return nil, err
 			}
-			for _, kv := range flag.GetDetailFields() {
+			for _, kv := range flagDownloadFields(report, flag) {
 				if err := writer.Write([]string{kv.Key, kv.Value}); err != nil {
 					return nil, err
 				}
@@ -144,9 +331,11 @@ func generateExcel(report api.Report) ([]byte, error) {
 		{"Report ID", report.Id.String()},
 		{"Downloaded At", time.Now().Format("02 Jan 2006 15:04")},
 		{"Author Name", report.AuthorName},
-		{},
-		{"Flag Summary"},
 	}
+	for _, row := range riskSummaryRows(report) {
+		summaryData = append(summaryData, []interface{}{row[0], row[1]})
+	}
+	summaryData = append(summaryData, []interface{}{}, []interface{}{"Flag Summary"})
 
 	for _, row := range summaryData {
 		if len(row) > 0 {
@@ -215,13 +404,17 @@ func generateExcel(report api.Report) ([]byte, error) {
 				headers = append(headers, kv.Key)
 			}
 		}
+		if report.Risk != nil {
+			headers = append(headers, riskScoreHeader)
+		}
+		headers = append(headers, triageHeaders...)
 		if err := writeHeaders(f, groupName, headers); err != nil {
 			return nil, err
 		}
 
 		for j, flag := range flags {
 			data := map[string]string{}
-			for _, kv := range flag.GetDetailFields() {
+			for _, kv := range flagDownloadFields(report, flag) {
 				data[kv.Key] = kv.Value
 			}
 			if err := writeRow(f, groupName, headers, j+2, data); err != nil {
@@ -360,6 +553,22 @@ func setupPDFFooter(pdf *gofpdf.Fpdf) {
 }
 
 func setupPDFCoverPage(pdf *gofpdf.Fpdf, report api.Report, resourceFolder string, timeRange string) {
+	details := [][]string{
+		{"Author Name", report.AuthorName},
+		{"Downloaded At", time.Now().Format("Jan 2, 2006")},
+		{"Report ID", report.Id.String()},
+	}
+	details = append(details, riskSummaryRows(report)...)
+	// insert timeline after the author name
+	if timeRange != "" {
+		timelineRow := []string{"Timeline", timeRange}
+		details = append(details[:1], append([][]string{timelineRow}, details[1:]...)...)
+	}
+
+	setupPDFCoverPageDetails(pdf, resourceFolder, "Individual Report", details)
+}
+
+func setupPDFCoverPageDetails(pdf *gofpdf.Fpdf, resourceFolder string, title string, details [][]string) {
 	pdf.AddPage()
 
 	// add prism logo to the front page
@@ -381,20 +590,9 @@ func setupPDFCoverPage(pdf *gofpdf.Fpdf, report api.Report, resourceFolder strin
 	pdf.SetY(175)
 	pdf.SetFont("Arial", "B", 14)
 	pdf.SetFillColor(200, 200, 255)
-	pdf.CellFormat(0, 10, "Individual Report", "0", 1, "C", true, 0, "")
+	pdf.CellFormat(0, 10, title, "0", 1, "C", true, 0, "")
 	pdf.Ln(2)
 
-	details := [][]string{
-		{"Author Name", report.AuthorName},
-		{"Downloaded At", time.Now().Format("Jan 2, 2006")},
-		{"Report ID", report.Id.String()},
-	}
-	// insert timeline after the author name
-	if timeRange != "" {
-		timelineRow := []string{"Timeline", timeRange}
-		details = append(details[:1], append([][]string{timelineRow}, details[1:]...)...)
-	}
-
 	pdf.SetFont("Arial", "", 12)
 
 	for _, row := range details {
@@ -406,7 +604,7 @@ func setupPDFCoverPage(pdf *gofpdf.Fpdf, report api.Report, resourceFolder strin
 	pdf.Ln(5)
 }
 
-func setupPDFFlagGroup(pdf *gofpdf.Fpdf, flags []api.Flag, useDisclosure bool) error {
+func setupPDFFlagGroup(pdf *gofpdf.Fpdf, report api.Report, flags []api.Flag, useDisclosure bool) error {
 	if len(flags) == 0 {
 		return nil
 	}
@@ -434,7 +632,12 @@ func setupPDFFlagGroup(pdf *gofpdf.Fpdf, flags []api.Flag, useDisclosure bool) e
 		pdf.CellFormat(0, 10, fmt.Sprintf("Issue %d", flagIndex+1), "", 1, "L", true, 0, "")
 		pdf.Ln(3)
 
-		for _, kv := range flag.GetDetailsFieldsForReport(useDisclosure) {
+		fields := flag.GetDetailsFieldsForReport(useDisclosure)
+		for _, kv := range append(riskDetailFields(report, flag), triageDetailFields(flag)...) {
+			fields = append(fields, api.KeyValueURL{Key: kv.Key, Value: kv.Value})
+		}
+
+		for _, kv := range fields {
 			keyWidth := 50.0
 			pageWidth, _ := pdf.GetPageSize()
 			left, _, right, _ := pdf.GetMargins()
@@ -518,7 +721,7 @@ func generatePDF(report api.Report, resourceFolder string, containsDisclosure bo
 				startPage: startPage,
 			})
 
-			if err := setupPDFFlagGroup(pdf, group.flags, containsDisclosure); err != nil {
+			if err := setupPDFFlagGroup(pdf, report, group.flags, containsDisclosure); err != nil {
 				return nil, err
 			}
 		}
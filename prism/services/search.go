@@ -11,7 +11,11 @@ import (
 	"prism/prism/openalex"
 	"prism/prism/reports"
 	"prism/prism/reports/utils"
+	"prism/prism/schema"
+	"prism/prism/scopus"
 	"prism/prism/search"
+	"prism/prism/services/auth"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,14 +29,24 @@ const institutionNameSimilarityThreshold = 0.8
 type SearchService struct {
 	openalex openalex.KnowledgeBase
 
+	// scopus is nil if no scopus api key is configured.
+	scopus *scopus.Client
+
 	// entitySearch EntitySearch
 	entitySearch *search.ManyToOneIndex[api.MatchedEntity]
+
+	// Used for the quota on LLM calls.
+	manager     *reports.ReportManager
+	rateLimiter *RateLimiter
 }
 
-func NewSearchService(oa openalex.KnowledgeBase, entities []api.MatchedEntity) SearchService {
+func NewSearchService(oa openalex.KnowledgeBase, scopusClient *scopus.Client, entities []api.MatchedEntity, manager *reports.ReportManager, rateLimiter *RateLimiter) SearchService {
 	return SearchService{
 		openalex:     oa,
+		scopus:       scopusClient,
 		entitySearch: NewEntitySearch(entities),
+		manager:      manager,
+		rateLimiter:  rateLimiter,
 	}
 }
 
@@ -73,8 +87,12 @@ func hybridInstitutionNamesSort(originalInstitution string, institutionNames []s
 func (s *SearchService) Routes() chi.Router {
 	r := chi.NewRouter()
 
+	r.Use(Audited(AuditSearch), s.rateLimiter.Middleware)
+
 	r.Get("/authors", WrapRestHandler(s.SearchOpenAlex))
 	r.Get("/authors-advanced", WrapRestHandler(s.SearchGoogleScholar))
+	r.Get("/authors-scopus", WrapRestHandler(s.SearchScopus))
+	r.Get("/authors-by-orcid", WrapRestHandler(s.SearchOrcid))
 	r.Get("/match-entities", WrapRestHandler(s.MatchEntities))
 
 	return r
@@ -109,22 +127,9 @@ func (s *SearchService) SearchOpenAlex(r *http.Request) (any, error) {
 
 		return results, nil
 	} else if query.Get("orcid") != "" {
-		author, err := s.openalex.FindAuthorByOrcidId(query.Get("orcid"))
-		if err != nil {
-			if errors.Is(err, openalex.ErrAuthorNotFound) {
-				return []api.Author{}, nil
-			}
-			return nil, CodedError(err, http.StatusInternalServerError)
-		}
-		return []api.Author{{
-			AuthorId:     author.AuthorId,
-			AuthorName:   author.DisplayName,
-			Institutions: author.InstitutionNames(),
-			Source:       api.OpenAlexSource,
-			Interests:    author.Concepts,
-		}}, nil
+		return s.searchByOrcid(query.Get("orcid"))
 	} else if query.Get("paper_title") != "" {
-		papers, err := s.openalex.FindWorksByTitle([]string{query.Get("paper_title")}, reports.EarliestReportDate, time.Now())
+		papers, err := s.openalex.FindWorksByTitle([]string{query.Get("paper_title")}, reports.EarliestReportDate, time.Now(), time.Time{})
 		if err != nil {
 			return nil, CodedError(err, http.StatusInternalServerError)
 		}
@@ -151,6 +156,55 @@ func (s *SearchService) SearchOpenAlex(r *http.Request) (any, error) {
 
 }
 
+var orcidRe = regexp.MustCompile(`^[0-9]{4}-[0-9]{4}-[0-9]{4}-[0-9]{3}[0-9X]$`)
+
+// Accepts either a bare ORCID or an orcid.org url and returns the bare ORCID.
+func parseOrcid(orcid string) (string, error) {
+	orcid = strings.TrimSpace(orcid)
+	for _, prefix := range []string{"https://orcid.org/", "http://orcid.org/", "orcid.org/"} {
+		orcid = strings.TrimPrefix(orcid, prefix)
+	}
+	orcid = strings.ToUpper(orcid)
+
+	if !orcidRe.MatchString(orcid) {
+		return "", fmt.Errorf("invalid ORCID '%s', expected format 0000-0000-0000-0000", orcid)
+	}
+
+	return orcid, nil
+}
+
+func (s *SearchService) searchByOrcid(orcid string) ([]api.Author, error) {
+	orcid, err := parseOrcid(orcid)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	author, err := s.openalex.FindAuthorByOrcidId(orcid)
+	if err != nil {
+		if errors.Is(err, openalex.ErrAuthorNotFound) {
+			return []api.Author{}, nil
+		}
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return []api.Author{{
+		AuthorId:     author.AuthorId,
+		AuthorName:   author.DisplayName,
+		Institutions: author.InstitutionNames(),
+		Source:       api.OpenAlexSource,
+		Interests:    author.Concepts,
+	}}, nil
+}
+
+func (s *SearchService) SearchOrcid(r *http.Request) (any, error) {
+	orcid := r.URL.Query().Get("orcid")
+	if orcid == "" {
+		return nil, CodedError(errors.New("orcid must be specified"), http.StatusBadRequest)
+	}
+
+	return s.searchByOrcid(orcid)
+}
+
 func filterAuthorsBySimilarity(authors []api.Author, queryName string) []api.Author {
 	const minSimilarity = 0.5
 
@@ -196,6 +250,36 @@ func (s *SearchService) SearchGoogleScholar(r *http.Request) (any, error) {
 	return api.GScholarSearchResults{Authors: filterAuthorsBySimilarity(results, author), Cursor: nextCursor}, nil
 }
 
+var ErrScopusNotConfigured = errors.New("scopus is not configured")
+
+func (s *SearchService) SearchScopus(r *http.Request) (any, error) {
+	if s.scopus == nil {
+		return nil, CodedError(ErrScopusNotConfigured, http.StatusNotImplemented)
+	}
+
+	query := r.URL.Query()
+	author, institution, cursor := query.Get("author_name"), query.Get("institution_name"), query.Get("cursor")
+	if author == "" {
+		return nil, CodedError(errors.New("author_name must be specified"), http.StatusBadRequest)
+	}
+
+	slog.Info("searching scopus", "author_name", author, "institution_name", institution, "cursor", cursor)
+
+	results, nextCursor, err := s.scopus.SearchAuthors(author, institution, cursor)
+	if err != nil {
+		if errors.Is(err, scopus.ErrInvalidCursor) {
+			return nil, CodedError(err, http.StatusBadRequest)
+		}
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	if results == nil {
+		results = []api.Author{}
+	}
+
+	return api.ScopusSearchResults{Authors: results, Cursor: nextCursor}, nil
+}
+
 func formatForPrompt(e api.MatchedEntity, id int) string {
 	base := fmt.Sprintf("[ID] %d\n[ENTITY START]\n[NAMES START]\n%s\n[NAMES END]\n", id, e.Names)
 	if e.Address != "" {
@@ -235,6 +319,11 @@ const (
 )
 
 func (s *SearchService) MatchEntities(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
 	query := r.URL.Query().Get("query")
 
 	results := s.entitySearch.Query(query, 15)
@@ -255,6 +344,10 @@ func (s *SearchService) MatchEntities(r *http.Request) (any, error) {
 	// TODO(question): does the prompt make sense with the entities in front?
 	prompt := strings.Join(candidates, "\n") + "\n\n" + fmt.Sprintf(matchEntitiesPrompt, query)
 
+	if err := s.manager.ConsumeQuota(userId, schema.UsageLLMCalls); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
 	response, err := llms.New().Generate(prompt, nil)
 	if err != nil {
 		slog.Error("match entities: llm generaton failed", "error", err)
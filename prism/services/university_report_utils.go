@@ -0,0 +1,388 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"prism/prism/api"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+// Some flags use their message as the heading, so the headings for the flag
+// types in university reports are listed here.
+var universityFlagTypeHeadings = map[string]string{
+	api.TalentContractType:               "Talent Contracts",
+	api.AssociationsWithDeniedEntityType: "Funding from Denied Entities",
+	api.HighRiskFunderType:               "High Risk Funding Sources",
+	api.AuthorAffiliationType:            "Affiliations with High Risk Foreign Institutes",
+	api.PotentialAuthorAffiliationType:   "Appointments at High Risk Foreign Institutes",
+	api.MiscHighRiskAssociationType:      "Miscellaneous High Risk Connections",
+	api.CoauthorAffiliationType:          "Co-authors' affiliations with High Risk Foreign Institutes",
+	api.MultipleAffiliationType:          "Multiple Affiliations",
+	api.HighRiskPublisherType:            "High Risk Publishers",
+	api.HighRiskCoauthorType:             "High Risk Coauthors",
+}
+
+func universityFlagTypeHeading(flagType string) string {
+	if heading, ok := universityFlagTypeHeadings[flagType]; ok {
+		return heading
+	}
+	return flagType
+}
+
+type universityFlagGroup struct {
+	flagType string
+	heading  string
+	authors  []api.UniversityAuthorSummary
+}
+
+// Groups the authors in the report by the types of flags they have. Only the
+// authors in the report's author page are included, so that downloads respect
+// any filters used when getting the report.
+func universityFlagGroups(report api.UniversityReport) []universityFlagGroup {
+	authors := make(map[string]api.UniversityAuthorSummary)
+	if report.Content.Authors != nil {
+		for _, author := range report.Content.Authors.Authors {
+			authors[author.AuthorId] = author
+		}
+	}
+
+	groups := make([]universityFlagGroup, 0, len(report.Content.Flags))
+	for flagType, flags := range report.Content.Flags {
+		group := universityFlagGroup{flagType: flagType, heading: universityFlagTypeHeading(flagType)}
+		for _, flag := range flags {
+			if author, ok := authors[flag.AuthorId]; ok {
+				group.authors = append(group.authors, author)
+			}
+		}
+		if len(group.authors) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	slices.SortFunc(groups, func(a, b universityFlagGroup) int {
+		return strings.Compare(a.heading, b.heading)
+	})
+
+	return groups
+}
+
+func universityReportSummaryRows(report api.UniversityReport, downloadedAt string) [][]string {
+	rows := [][]string{
+		{"Report ID", report.Id.String()},
+		{"Downloaded At", downloadedAt},
+		{"University Name", report.UniversityName},
+		{"University Location", report.UniversityLocation},
+		{"Total Authors", strconv.Itoa(report.Content.TotalAuthors)},
+		{"Authors Reviewed", strconv.Itoa(report.Content.AuthorsReviewed)},
+		{"Lookback Years", strconv.Itoa(report.LookbackYears)},
+		{"Min Works", strconv.Itoa(report.MinWorks)},
+	}
+	if report.Content.Authors != nil {
+		rows = append(rows, []string{"Authors Exported", strconv.Itoa(len(report.Content.Authors.Authors))})
+	}
+	return rows
+}
+
+var universityAuthorHeaders = []string{"Author Name", "Author ID", "Source", "Department", "Flag Count", riskScoreHeader, "Risk Tier", "Author Report ID"}
+
+func universityAuthorRow(author api.UniversityAuthorSummary, flagCount int) []string {
+	return []string{
+		author.AuthorName,
+		author.AuthorId,
+		author.Source,
+		author.Department,
+		strconv.Itoa(flagCount),
+		fmt.Sprintf("%.2f", author.RiskScore),
+		capitalizeTier(author.RiskTier),
+		author.AuthorReportId.String(),
+	}
+}
+
+var universityDepartmentHeaders = []string{"Department", "Total Authors", "Flagged Authors", "High Risk Authors", "Flag Count", riskScoreHeader}
+
+func universityDepartmentRow(department api.UniversityDepartment) []string {
+	name := department.Department
+	if name == "" {
+		name = "Unknown"
+	}
+	return []string{
+		name,
+		strconv.Itoa(department.TotalAuthors),
+		strconv.Itoa(department.FlaggedAuthors),
+		strconv.Itoa(department.HighRiskAuthors),
+		strconv.Itoa(department.FlagCount),
+		fmt.Sprintf("%.2f", department.RiskScore),
+	}
+}
+
+func generateUniversityCSV(report api.UniversityReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write([]string{"Field", "Value"}); err != nil {
+		return nil, err
+	}
+
+	if err := writer.WriteAll(universityReportSummaryRows(report, time.Now().Format(time.RFC3339))); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Write([]string{}); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Write(append([]string{"Flag Type"}, universityAuthorHeaders...)); err != nil {
+		return nil, err
+	}
+
+	for _, group := range universityFlagGroups(report) {
+		for _, author := range group.authors {
+			if err := writer.Write(append([]string{group.heading}, universityAuthorRow(author, author.FlagCounts[group.flagType])...)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Writes the headers and rows into the sheet.
+func writeUniversityRows(f *excelize.File, sheet string, headers []string, rows [][]string) error {
+	if err := writeHeaders(f, sheet, headers); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		data := make(map[string]string, len(headers))
+		for j, header := range headers {
+			data[header] = row[j]
+		}
+		if err := writeRow(f, sheet, headers, i+2, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func generateUniversityExcel(report api.UniversityReport) ([]byte, error) {
+	f := excelize.NewFile()
+
+	summarySheet := "Summary"
+	if err := f.SetSheetName("Sheet1", summarySheet); err != nil {
+		return nil, err
+	}
+
+	boldStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summaryRows := universityReportSummaryRows(report, time.Now().Format("02 Jan 2006 15:04"))
+	summaryRows = append(summaryRows, []string{}, []string{"Flag Summary", "Authors"})
+	groups := universityFlagGroups(report)
+	for _, group := range groups {
+		summaryRows = append(summaryRows, []string{group.heading, strconv.Itoa(len(group.authors))})
+	}
+
+	for i, row := range summaryRows {
+		for j, value := range row {
+			cell, err := excelize.CoordinatesToCellName(j+1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if err := f.SetCellValue(summarySheet, cell, value); err != nil {
+				return nil, err
+			}
+			if j == 0 {
+				if err := f.SetCellStyle(summarySheet, cell, cell, boldStyle); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if len(report.Content.Departments) > 0 {
+		departmentSheet := "Departments"
+		if _, err := f.NewSheet(departmentSheet); err != nil {
+			return nil, err
+		}
+		rows := make([][]string, 0, len(report.Content.Departments))
+		for _, department := range report.Content.Departments {
+			rows = append(rows, universityDepartmentRow(department))
+		}
+		if err := writeUniversityRows(f, departmentSheet, universityDepartmentHeaders, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if report.Content.Authors != nil {
+		authorSheet := "Authors"
+		if _, err := f.NewSheet(authorSheet); err != nil {
+			return nil, err
+		}
+		rows := make([][]string, 0, len(report.Content.Authors.Authors))
+		for _, author := range report.Content.Authors.Authors {
+			rows = append(rows, universityAuthorRow(author, author.FlagCount))
+		}
+		if err := writeUniversityRows(f, authorSheet, universityAuthorHeaders, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, group := range groups {
+		sheet := sanitizeSheetName(group.heading)
+		if _, err := f.NewSheet(sheet); err != nil {
+			return nil, err
+		}
+		rows := make([][]string, 0, len(group.authors))
+		for _, author := range group.authors {
+			rows = append(rows, universityAuthorRow(author, author.FlagCounts[group.flagType]))
+		}
+		if err := writeUniversityRows(f, sheet, universityAuthorHeaders, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if idx, err := f.GetSheetIndex(summarySheet); err == nil {
+		f.SetActiveSheet(idx)
+	}
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Writes a table with the given column widths.
+func writePDFTable(pdf *gofpdf.Fpdf, headers []string, widths []float64, rows [][]string) {
+	writeHeader := func() {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for i, header := range headers {
+			pdf.CellFormat(widths[i], 7, header, "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 9)
+	}
+
+	writeHeader()
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	for _, row := range rows {
+		if pdf.GetY()+7 > pageHeight-bottom {
+			pdf.AddPage()
+			writeHeader()
+		}
+		for j, value := range row {
+			// Long values are truncated so that each row fits on a single line.
+			for len(value) > 0 && pdf.GetStringWidth(value) > widths[j]-2 {
+				value = value[:len(value)-1]
+			}
+			pdf.CellFormat(widths[j], 7, value, "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(5)
+}
+
+func setupPDFUniversitySection(pdf *gofpdf.Fpdf, heading string) {
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 14)
+	pdf.SetFillColor(200, 200, 255)
+	pdf.CellFormat(0, 10, heading, "0", 1, "C", true, 0, "")
+	pdf.Ln(3)
+}
+
+var pdfUniversityAuthorColumns = []string{"Author Name", "Department", "Flag Count", riskScoreHeader, "Risk Tier"}
+var pdfUniversityAuthorWidths = []float64{50, 50, 20, 25, 25}
+
+func pdfUniversityAuthorRows(authors []api.UniversityAuthorSummary, flagCount func(api.UniversityAuthorSummary) int) [][]string {
+	rows := make([][]string, 0, len(authors))
+	for _, author := range authors {
+		rows = append(rows, []string{
+			author.AuthorName,
+			author.Department,
+			strconv.Itoa(flagCount(author)),
+			fmt.Sprintf("%.2f", author.RiskScore),
+			capitalizeTier(author.RiskTier),
+		})
+	}
+	return rows
+}
+
+func generateUniversityPDF(report api.UniversityReport, resourceFolder string) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 30, 20)
+	pdf.SetAutoPageBreak(true, 20)
+
+	setupPDFCoverPageDetails(pdf, resourceFolder, "University Report", universityReportSummaryRows(report, time.Now().Format("Jan 2, 2006")))
+
+	// we set the footer here so that cover also has a page number
+	setupPDFFooter(pdf)
+
+	pdf.AliasNbPages("{nb}")
+
+	// we set the header after the cover page so that header logo starts from page 2
+	setupPDFHeader(pdf, resourceFolder, report.UniversityName)
+
+	groups := universityFlagGroups(report)
+
+	setupPDFUniversitySection(pdf, "Flag Summary")
+	summaryRows := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		flags := 0
+		for _, author := range group.authors {
+			flags += author.FlagCounts[group.flagType]
+		}
+		summaryRows = append(summaryRows, []string{group.heading, strconv.Itoa(len(group.authors)), strconv.Itoa(flags)})
+	}
+	writePDFTable(pdf, []string{"Flag Type", "Authors", "Flags"}, []float64{110, 30, 30}, summaryRows)
+
+	if len(report.Content.Departments) > 0 {
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 8, "Departments", "", 1, "L", false, 0, "")
+		rows := make([][]string, 0, len(report.Content.Departments))
+		for _, department := range report.Content.Departments {
+			rows = append(rows, universityDepartmentRow(department))
+		}
+		writePDFTable(pdf, []string{"Department", "Authors", "Flagged", "High Risk", "Flags", riskScoreHeader}, []float64{60, 20, 20, 20, 20, 30}, rows)
+	}
+
+	if report.Content.Authors != nil {
+		setupPDFUniversitySection(pdf, "Authors by Risk")
+		rows := pdfUniversityAuthorRows(report.Content.Authors.Authors, func(author api.UniversityAuthorSummary) int {
+			return author.FlagCount
+		})
+		writePDFTable(pdf, pdfUniversityAuthorColumns, pdfUniversityAuthorWidths, rows)
+	}
+
+	for _, group := range groups {
+		setupPDFUniversitySection(pdf, group.heading)
+		rows := pdfUniversityAuthorRows(group.authors, func(author api.UniversityAuthorSummary) int {
+			return author.FlagCounts[group.flagType]
+		})
+		writePDFTable(pdf, pdfUniversityAuthorColumns, pdfUniversityAuthorWidths, rows)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"net/http"
+	"prism/prism/reports"
+	"prism/prism/services/auth"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type UsageService struct {
+	manager *reports.ReportManager
+}
+
+func NewUsageService(manager *reports.ReportManager) UsageService {
+	return UsageService{manager: manager}
+}
+
+func (s *UsageService) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", WrapRestHandler(s.GetUsage))
+
+	return r
+}
+
+func (s *UsageService) GetUsage(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	usage, err := s.manager.GetUsage(userId)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return usage, nil
+}
@@ -110,10 +110,16 @@ func licensingErrorStatus(err error) int {
 
 func reportErrorStatus(err error) int {
 	switch {
-	case errors.Is(err, reports.ErrReportNotFound):
+	case errors.Is(err, reports.ErrReportNotFound), errors.Is(err, reports.ErrFlagNotFound), errors.Is(err, reports.ErrSuppressionNotFound),
+		errors.Is(err, reports.ErrDisclosureNotFound), errors.Is(err, reports.ErrBatchNotFound), errors.Is(err, reports.ErrBatchRowNotFound),
+		errors.Is(err, reports.ErrWatchlistUpdateNotFound), errors.Is(err, reports.ErrWatchlistNotFound), errors.Is(err, reports.ErrWatchlistEntityNotFound):
 		return http.StatusNotFound
+	case errors.Is(err, reports.ErrInvalidWatchlistEntity):
+		return http.StatusUnprocessableEntity
 	case errors.Is(err, reports.ErrUserCannotAccessReport):
 		return http.StatusForbidden
+	case errors.Is(err, reports.ErrQuotaExceeded):
+		return http.StatusTooManyRequests
 	}
 	return http.StatusInternalServerError
 }
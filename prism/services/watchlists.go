@@ -0,0 +1,293 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"prism/prism/api"
+	"prism/prism/reports/flaggers/eoc"
+	"prism/prism/services/auth"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *AdminService) ListWatchlists(r *http.Request) (any, error) {
+	watchlists, err := s.manager.ListWatchlists()
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	return watchlists, nil
+}
+
+func (s *AdminService) ListWatchlistEntities(r *http.Request) (any, error) {
+	includeRemoved := r.URL.Query().Get("include_removed") == "true"
+
+	entities, err := s.manager.ListWatchlistEntities(chi.URLParam(r, "watchlist"), includeRemoved)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return entities, nil
+}
+
+func (s *AdminService) CreateWatchlistEntity(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	params, err := ParseRequestBody[api.WatchlistEntityRequest](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	entity, err := s.manager.CreateWatchlistEntity(userId, chi.URLParam(r, "watchlist"), params)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return entity, nil
+}
+
+func (s *AdminService) UpdateWatchlistEntity(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	id, err := URLParamUUID(r, "entity_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	params, err := ParseRequestBody[api.WatchlistEntityRequest](r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	entity, err := s.manager.UpdateWatchlistEntity(userId, chi.URLParam(r, "watchlist"), id, params)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return entity, nil
+}
+
+func (s *AdminService) RemoveWatchlistEntity(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	id, err := URLParamUUID(r, "entity_id")
+	if err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	if err := s.manager.RemoveWatchlistEntity(userId, chi.URLParam(r, "watchlist"), id); err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return nil, nil
+}
+
+func (s *AdminService) ImportWatchlistEntities(r *http.Request) (any, error) {
+	userId, err := auth.GetUserId(r)
+	if err != nil {
+		return nil, CodedError(err, http.StatusInternalServerError)
+	}
+
+	watchlist := chi.URLParam(r, "watchlist")
+	if !eoc.IsWatchlist(watchlist) {
+		return nil, CodedError(fmt.Errorf("watchlist '%s' not found", watchlist), http.StatusNotFound)
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, CodedError(err, http.StatusBadRequest)
+	}
+
+	fileHeaders := r.MultipartForm.File["file"]
+	if len(fileHeaders) != 1 {
+		return nil, CodedError(errors.New("exactly one watchlist file must be uploaded"), http.StatusBadRequest)
+	}
+
+	entities, err := readWatchlistFile(fileHeaders[0])
+	if err != nil {
+		return nil, CodedError(err, http.StatusUnprocessableEntity)
+	}
+
+	result, err := s.manager.ImportWatchlistEntities(userId, watchlist, entities)
+	if err != nil {
+		return nil, CodedError(err, reportErrorStatus(err))
+	}
+
+	return result, nil
+}
+
+// Reads the entities from an uploaded csv file, or from a json file in the
+// format of the Consolidated Screening List.
+func readWatchlistFile(fileHeader *multipart.FileHeader) ([]api.WatchlistEntityRequest, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("error opening uploaded watchlist", "filename", fileHeader.Filename, "error", err)
+		return nil, errors.New("unable to open file")
+	}
+	defer file.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(fileHeader.Filename)); ext {
+	case ".csv":
+		return parseWatchlistCsv(file)
+	case ".json":
+		return parseConsolidatedScreeningList(file)
+	default:
+		return nil, fmt.Errorf("unsupported file type '%s', expected .csv or .json", ext)
+	}
+}
+
+// Parses effective dates, which can be a date or a timestamp in RFC3339 format.
+func parseEffectiveDate(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective date '%s', expected YYYY-MM-DD", raw)
+	}
+	return &t, nil
+}
+
+var watchlistCsvColumns = []string{"name", "openalex_id", "aliases", "source", "effective_date", "justification"}
+
+// The csv file must have a header row, the columns are name, openalex_id,
+// aliases, source, effective_date, and justification. Only one of name or
+// openalex_id is required, aliases are separated by semicolons.
+func parseWatchlistCsv(file io.Reader) ([]api.WatchlistEntityRequest, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv file: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !slices.Contains(watchlistCsvColumns, column) {
+			return nil, fmt.Errorf("unknown column '%s', expected columns %s", column, strings.Join(watchlistCsvColumns, ", "))
+		}
+		columns[column] = i
+	}
+	_, hasName := columns["name"]
+	_, hasId := columns["openalex_id"]
+	if !hasName && !hasId {
+		return nil, errors.New("csv file must have a name or openalex_id column")
+	}
+
+	entities := make([]api.WatchlistEntityRequest, 0)
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv file: %w", err)
+		}
+		row, _ := reader.FieldPos(0)
+
+		field := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+
+		entity := api.WatchlistEntityRequest{
+			Name:          field("name"),
+			OpenAlexId:    field("openalex_id"),
+			Source:        field("source"),
+			Justification: field("justification"),
+		}
+		if entity.Name == "" && entity.OpenAlexId == "" {
+			continue
+		}
+		if aliases := field("aliases"); aliases != "" {
+			entity.Aliases = strings.Split(aliases, ";")
+		}
+		if entity.EffectiveDate, err = parseEffectiveDate(field("effective_date")); err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		entities = append(entities, entity)
+	}
+
+	return entities, nil
+}
+
+// The fields used from the Consolidated Screening List published by the
+// International Trade Administration, https://www.trade.gov/consolidated-screening-list.
+type consolidatedScreeningList struct {
+	Results []struct {
+		Name                  string   `json:"name"`
+		AltNames              []string `json:"alt_names"`
+		Source                string   `json:"source"`
+		StartDate             string   `json:"start_date"`
+		FederalRegisterNotice string   `json:"federal_register_notice"`
+		Remarks               string   `json:"remarks"`
+	} `json:"results"`
+}
+
+func parseConsolidatedScreeningList(file io.Reader) ([]api.WatchlistEntityRequest, error) {
+	var list consolidatedScreeningList
+	if err := json.NewDecoder(file).Decode(&list); err != nil {
+		return nil, fmt.Errorf("invalid consolidated screening list file: %w", err)
+	}
+
+	entities := make([]api.WatchlistEntityRequest, 0, len(list.Results))
+	for i, result := range list.Results {
+		if strings.TrimSpace(result.Name) == "" {
+			continue
+		}
+
+		justification := make([]string, 0, 2)
+		if notice := strings.TrimSpace(result.FederalRegisterNotice); notice != "" {
+			justification = append(justification, "Federal Register Notice: "+notice)
+		}
+		if remarks := strings.TrimSpace(result.Remarks); remarks != "" {
+			justification = append(justification, remarks)
+		}
+
+		effectiveDate, err := parseEffectiveDate(result.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("result %d: %w", i+1, err)
+		}
+
+		entities = append(entities, api.WatchlistEntityRequest{
+			Name:          result.Name,
+			Aliases:       result.AltNames,
+			Source:        result.Source,
+			EffectiveDate: effectiveDate,
+			Justification: strings.Join(justification, ". "),
+		})
+	}
+
+	return entities, nil
+}